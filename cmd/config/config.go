@@ -1,6 +1,12 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 //Router params
 
@@ -8,6 +14,362 @@ const (
 	Port = ":8080"
 )
 
+//Environment
+
+const (
+	AdminTokenEnv     = "GO_MANAGE_ADMIN_TOKEN"
+	PurgeRetentionEnv = "GO_MANAGE_PURGE_RETENTION"
+	PurgeIntervalEnv  = "GO_MANAGE_PURGE_INTERVAL"
+
+	PasswordMinLengthEnv     = "GO_MANAGE_PASSWORD_MIN_LENGTH"
+	PasswordMaxLengthEnv     = "GO_MANAGE_PASSWORD_MAX_LENGTH"
+	PasswordRequireUpperEnv  = "GO_MANAGE_PASSWORD_REQUIRE_UPPER"
+	PasswordRequireLowerEnv  = "GO_MANAGE_PASSWORD_REQUIRE_LOWER"
+	PasswordRequireDigitEnv  = "GO_MANAGE_PASSWORD_REQUIRE_DIGIT"
+	PasswordRequireSymbolEnv = "GO_MANAGE_PASSWORD_REQUIRE_SYMBOL"
+	PasswordDisallowIDEnv    = "GO_MANAGE_PASSWORD_DISALLOW_IDENTITY"
+	PasswordHistoryEnv       = "GO_MANAGE_PASSWORD_HISTORY"
+	BreachedPasswordsFileEnv = "GO_MANAGE_BREACHED_PASSWORDS_FILE"
+
+	PasswordHashEnv      = "GO_MANAGE_PASSWORD_HASH"
+	BcryptCostEnv        = "GO_MANAGE_BCRYPT_COST"
+	Argon2MemoryEnv      = "GO_MANAGE_ARGON2_MEMORY"
+	Argon2IterationsEnv  = "GO_MANAGE_ARGON2_ITERATIONS"
+	Argon2ParallelismEnv = "GO_MANAGE_ARGON2_PARALLELISM"
+	SessionTTLEnv        = "GO_MANAGE_SESSION_TTL"
+
+	LoginFreeAttemptsEnv     = "GO_MANAGE_LOGIN_FREE_ATTEMPTS"
+	LoginBackoffBaseEnv      = "GO_MANAGE_LOGIN_BACKOFF_BASE"
+	LoginBackoffMaxEnv       = "GO_MANAGE_LOGIN_BACKOFF_MAX"
+	LoginLockoutThresholdEnv = "GO_MANAGE_LOGIN_LOCKOUT_THRESHOLD"
+	LoginLockoutDurationEnv  = "GO_MANAGE_LOGIN_LOCKOUT_DURATION"
+	LoginIPLimitEnv          = "GO_MANAGE_LOGIN_IP_LIMIT"
+	LoginWindowEnv           = "GO_MANAGE_LOGIN_WINDOW"
+
+	RateLimitEnvPrefix = "GO_MANAGE_RATE_LIMIT_"
+	TrustedProxiesEnv  = "GO_MANAGE_TRUSTED_PROXIES"
+
+	TOTPIssuerEnv = "GO_MANAGE_TOTP_ISSUER"
+
+	OIDCIssuerEnv      = "GO_MANAGE_OIDC_ISSUER"
+	OIDCKeyRotationEnv = "GO_MANAGE_OIDC_KEY_ROTATION"
+
+	FederationProvidersFileEnv = "GO_MANAGE_FEDERATION_PROVIDERS_FILE"
+
+	TenantsEnv      = "GO_MANAGE_TENANTS"
+	TenantDomainEnv = "GO_MANAGE_TENANT_DOMAIN"
+
+	ImportBatchSizeEnv = "GO_MANAGE_IMPORT_BATCH_SIZE"
+
+	DBPathEnv = "GO_MANAGE_DB_PATH"
+
+	BackupDirEnv       = "GO_MANAGE_BACKUP_DIR"
+	BackupRetentionEnv = "GO_MANAGE_BACKUP_RETENTION"
+	BackupIntervalEnv  = "GO_MANAGE_BACKUP_INTERVAL"
+)
+
+const (
+	DefaultPurgeRetention = 30 * 24 * time.Hour
+	DefaultPurgeInterval  = time.Hour
+
+	DefaultPasswordMinLength     = 8
+	DefaultPasswordMaxLength     = 72
+	DefaultPasswordRequireUpper  = true
+	DefaultPasswordRequireLower  = true
+	DefaultPasswordRequireDigit  = true
+	DefaultPasswordRequireSymbol = false
+	DefaultPasswordDisallowID    = true
+	DefaultPasswordHistory       = 5
+
+	DefaultPasswordHash      = HashArgon2id
+	DefaultBcryptCost        = 12
+	DefaultArgon2Memory      = 64 * 1024
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 2
+	DefaultSessionTTL        = 24 * time.Hour
+
+	DefaultLoginFreeAttempts     = 3
+	DefaultLoginBackoffBase      = time.Second
+	DefaultLoginBackoffMax       = 5 * time.Minute
+	DefaultLoginLockoutThreshold = 10
+	DefaultLoginLockoutDuration  = 15 * time.Minute
+	DefaultLoginIPLimit          = 50
+	DefaultLoginWindow           = 15 * time.Minute
+)
+
+//MFA params
+
+const (
+	DefaultTOTPIssuer = "Go-Manage"
+
+	TOTPSecretSize     = 20
+	TOTPDigits         = 6
+	TOTPPeriod         = 30 * time.Second
+	TOTPSkew           = 1
+	RecoveryCodeCount  = 10
+	RecoveryCodeLength = 10
+	MFAChallengeTTL    = 5 * time.Minute
+
+	SessionUserKey    = "session_user"
+	SessionCookieName = "go_manage_session"
+)
+
+//API key params
+
+const (
+	APIKeyScheme        = "ApiKey "
+	APIKeyPrefix        = "gm_"
+	APIKeyPrefixLength  = 8
+	APIKeySecretSize    = 32
+	APIKeyTouchInterval = time.Minute
+	APIKeyContextKey    = "api_key"
+	APIKeyActorPrefix   = "apikey:"
+
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var APIKeyScopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+//OIDC params
+
+const (
+	DefaultOIDCIssuer      = "http://localhost:8080"
+	DefaultOIDCKeyRotation = 30 * 24 * time.Hour
+
+	OIDCDiscoveryPath = "/.well-known/openid-configuration"
+	OIDCAuthorizePath = "/oauth/authorize"
+	OIDCTokenPath     = "/oauth/token"
+	OIDCUserInfoPath  = "/oauth/userinfo"
+	OIDCJWKSPath      = "/oauth/jwks"
+
+	OIDCCodeTTL          = time.Minute
+	OIDCTokenTTL         = time.Hour
+	OIDCCodeSize         = 32
+	OIDCClientSecretSize = 32
+	OIDCSigningKeyBits   = 2048
+	OIDCSigningAlg       = "RS256"
+	OIDCIDTokenType      = "JWT"
+	OIDCAccessTokenType  = "at+jwt"
+	OIDCPKCEMethod       = "S256"
+	OIDCResponseType     = "code"
+	OIDCGrantType        = "authorization_code"
+	OIDCSubjectType      = "public"
+	OIDCTokenType        = "Bearer"
+
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthInvalidToken            = "invalid_token"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthServerError             = "server_error"
+)
+
+var (
+	OIDCScopes            = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+	OIDCClaims            = []string{"sub", "iss", "aud", "exp", "iat", "nonce", "preferred_username", "name", "given_name", "family_name", "email"}
+	OIDCClientAuthMethods = []string{"client_secret_basic", "client_secret_post", "none"}
+)
+
+//Federation params
+
+const (
+	FederationStateTTL    = 10 * time.Minute
+	FederationStateSize   = 32
+	FederationHTTPTimeout = 10 * time.Second
+	FederationClockSkew   = time.Minute
+	FederationActorPrefix = "oidc:"
+	FederationMaxBody     = 1 << 20
+
+	ClaimGivenName         = "given_name"
+	ClaimFamilyName        = "family_name"
+	ClaimEmail             = "email"
+	ClaimEmailVerified     = "email_verified"
+	ClaimPreferredUsername = "preferred_username"
+)
+
+//Group params
+
+const (
+	GroupRoleOwner   = "owner"
+	GroupRoleManager = "manager"
+	GroupRoleMember  = "member"
+)
+
+var GroupRoles = []string{GroupRoleOwner, GroupRoleManager, GroupRoleMember}
+
+//Permission params
+
+const (
+	PermUsersRead    = "users:read"
+	PermUsersWrite   = "users:write"
+	PermUsersDelete  = "users:delete"
+	PermGroupsRead   = "groups:read"
+	PermGroupsWrite  = "groups:write"
+	PermGroupsDelete = "groups:delete"
+	PermAuditRead    = "audit:read"
+	PermRolesManage  = "roles:manage"
+
+	PermissionWildcard = "*"
+	PermissionSelf     = ":self"
+	BaselineRole       = "baseline"
+)
+
+var (
+	Permissions         = []string{PermUsersRead, PermUsersWrite, PermUsersDelete, PermGroupsRead, PermGroupsWrite, PermGroupsDelete, PermAuditRead, PermRolesManage}
+	SelfPermissions     = []string{PermUsersRead, PermUsersWrite, PermUsersDelete}
+	BaselinePermissions = []string{PermUsersRead + PermissionSelf, PermUsersWrite + PermissionSelf}
+	ScopePermissions    = map[string][]string{
+		ScopeRead:  {PermUsersRead, PermGroupsRead},
+		ScopeWrite: {PermUsersWrite, PermGroupsWrite},
+		ScopeAdmin: {PermissionWildcard},
+	}
+)
+
+//Import params
+
+const (
+	DefaultImportBatchSize = 500
+	ImportMaxLineSize      = 64 * 1024
+
+	ImportConflictSkip   = "skip"
+	ImportConflictUpdate = "update"
+	ImportConflictFail   = "fail"
+)
+
+var (
+	ImportConflictModes = []string{ImportConflictSkip, ImportConflictUpdate, ImportConflictFail}
+	ImportColumns       = []string{"name", "surname", "username", "email", "password"}
+)
+
+//Export params
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatXLSX   = "xlsx"
+	ExportFileName     = "users"
+	ExportSheetName    = "Users"
+)
+
+var (
+	ExportFormats = []string{ExportFormatCSV, ExportFormatNDJSON, ExportFormatXLSX}
+	ExportColumns = []string{"id", "name", "surname", "username", "email", "version", "created_at", "updated_at", "last_login_at", "password_changed_at"}
+)
+
+//Batch params
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+var BatchModes = []string{BatchModeAtomic, BatchModeBestEffort}
+
+//CLI params
+
+const (
+	CLIName          = "go-manage"
+	CLIOutputJSON    = "json"
+	CLIOutputTable   = "table"
+	DefaultSeedCount = 10
+	SeedEmailDomain  = "example.com"
+)
+
+var CLIOutputs = []string{CLIOutputJSON, CLIOutputTable}
+
+//Tenant params
+
+const (
+	DefaultTenant    = "default"
+	TenantHeader     = "X-Tenant-ID"
+	TenantContextKey = "tenant"
+)
+
+// TenantEntryRoutes may select a tenant by header or subdomain without a
+// credential bound to it: they are how a caller obtains that credential.
+var TenantEntryRoutes = []string{"/login", "/login/mfa", "/login/:provider", "/login/:provider/callback",
+	OIDCDiscoveryPath, OIDCJWKSPath, OIDCAuthorizePath, OIDCTokenPath, OIDCUserInfoPath}
+
+//Rate limit params
+
+const (
+	RateLimitRead  = "read"
+	RateLimitWrite = "write"
+	RateLimitLogin = "login"
+	RateLimitAdmin = "admin"
+
+	RateLimitDisabled = "off"
+
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+)
+
+var DefaultRateLimits = map[string]string{
+	RateLimitRead:  "300/1m",
+	RateLimitWrite: "60/1m",
+	RateLimitLogin: "20/1m",
+	RateLimitAdmin: "120/1m",
+}
+
+func EnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func EnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+func EnvString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func EnvList(key string, fallback []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+func EnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
 //Database params
 
 const (
@@ -15,25 +377,518 @@ const (
 	DBPath   = "internal/data/users.db"
 )
 
+//Backup params
+
+const (
+	DefaultBackupDir       = "internal/data/backups"
+	DefaultBackupRetention = 7
+	DefaultBackupInterval  = time.Duration(0)
+	BackupFilePrefix       = "users-"
+	RestorePointFilePrefix = "restore-"
+	BackupFileExt          = ".db"
+	BackupTimeLayout       = "20060102T150405.000Z"
+	IntegrityOK            = "ok"
+)
+
 //Database queries
 
 const (
-	CreateTableQuery   = `CREATE TABLE users (id TEXT NOT NULL UNIQUE PRIMARY KEY, name TEXT NOT NULL, surname TEXT NOT NULL, username TEXT NOT NULL UNIQUE, email TEXT NOT NULL UNIQUE, password TEXT NOT NULL UNIQUE);`
-	SearchUserQuery    = `SELECT * FROM users WHERE username=?;`
-	SaveUserQuery      = `INSERT INTO users (id,name,surname,username,email,password) VALUES (?,?,?,?,?,?);`
-	DeleteUserQuery    = `DELETE FROM users WHERE username = ?;`
-	UpdateUserQuery    = `UPDATE users SET name = ?, surname = ?, email = ? WHERE username = ?;`
-	ChangeUserPwdQuery = `UPDATE users SET password = ? WHERE username = ?;`
+	UserColumns = `id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at`
+
+	CreateTableQuery    = `CREATE TABLE users (id TEXT NOT NULL UNIQUE PRIMARY KEY, name TEXT NOT NULL, surname TEXT NOT NULL, username TEXT NOT NULL UNIQUE, email TEXT NOT NULL UNIQUE, password TEXT NOT NULL UNIQUE);`
+	SearchUserQuery     = `SELECT ` + UserColumns + ` FROM users WHERE username=? AND tenant_id=? AND deleted_at IS NULL;`
+	SearchUserByIDQuery = `SELECT ` + UserColumns + ` FROM users WHERE id=? AND tenant_id=? AND deleted_at IS NULL;`
+	ListUsersQuery      = `SELECT ` + UserColumns + ` FROM users WHERE tenant_id=? AND deleted_at IS NULL`
+	SaveUserQuery       = `INSERT INTO users (id,name,surname,username,email,password,version,created_at,updated_at,password_changed_at,tenant_id) VALUES (?,?,?,?,?,?,?,?,?,?,?);`
+	DeleteUserQuery     = `UPDATE users SET deleted_at = ?, updated_at = ?, version = version + 1 WHERE username = ? AND version = ? AND tenant_id = ? AND deleted_at IS NULL;`
+	RestoreUserQuery    = `UPDATE users SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE id = (SELECT id FROM users WHERE username = ? AND tenant_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1);`
+	PurgeUsersQuery     = `DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ? AND tenant_id = ?;`
+	PurgeTenantsQuery   = `SELECT DISTINCT tenant_id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY tenant_id;`
+	UpdateUserQuery     = `UPDATE users SET name = ?, surname = ?, username = ?, email = ?, updated_at = ?, version = version + 1 WHERE username = ? AND version = ? AND tenant_id = ? AND deleted_at IS NULL;`
+	ChangeUserPwdQuery  = `UPDATE users SET password = ?, password_changed_at = ?, updated_at = ?, version = version + 1 WHERE username = ? AND tenant_id = ? AND deleted_at IS NULL;`
+	RecordLoginQuery    = `UPDATE users SET last_login_at = ? WHERE username = ? AND tenant_id = ? AND deleted_at IS NULL;`
+	BackupQuery         = `VACUUM INTO ?;`
+	IntegrityCheckQuery = `PRAGMA integrity_check;`
 )
 
+//Audit queries
+
+const (
+	AuditColumns = `id, occurred_at, actor, action, target, changes, request_id, ip`
+
+	SaveAuditQuery  = `INSERT INTO audit_events (` + AuditColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?,?,?);`
+	ListAuditQuery  = `SELECT ` + AuditColumns + ` FROM audit_events WHERE tenant_id = ?`
+	AuditOrderQuery = ` ORDER BY occurred_at DESC, id DESC LIMIT ? OFFSET ?;`
+)
+
+//Password history queries
+
+const (
+	SavePasswordHistoryQuery  = `INSERT INTO password_history (user_id, password, created_at) VALUES (?,?,?);`
+	PasswordHistoryQuery      = `SELECT password FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?;`
+	PurgePasswordHistoryQuery = `DELETE FROM password_history WHERE user_id NOT IN (SELECT id FROM users);`
+)
+
+//Savepoint queries
+
+const (
+	SavepointQuery         = `SAVEPOINT batch_operation;`
+	ReleaseSavepointQuery  = `RELEASE SAVEPOINT batch_operation;`
+	RollbackSavepointQuery = `ROLLBACK TO SAVEPOINT batch_operation;`
+)
+
+//Session queries
+
+const (
+	RehashPasswordQuery = `UPDATE users SET password = ? WHERE id = ? AND password = ? AND tenant_id = ?;`
+	SaveSessionQuery    = `INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES (?,?,?,?);`
+	PurgeSessionsQuery  = `DELETE FROM sessions WHERE expires_at <= ? OR user_id NOT IN (SELECT id FROM users);`
+)
+
+//MFA queries
+
+const (
+	TOTPColumns = `user_id, secret, confirmed_at, last_used_step, created_at`
+
+	SearchTOTPQuery          = `SELECT ` + TOTPColumns + ` FROM totp_secrets WHERE user_id = ?;`
+	SaveTOTPQuery            = `INSERT INTO totp_secrets (` + TOTPColumns + `) VALUES (?,?,?,?,?) ON CONFLICT(user_id) DO UPDATE SET secret = excluded.secret, confirmed_at = excluded.confirmed_at, last_used_step = excluded.last_used_step, created_at = excluded.created_at;`
+	ConfirmTOTPQuery         = `UPDATE totp_secrets SET confirmed_at = ?, last_used_step = ? WHERE user_id = ? AND confirmed_at IS NULL;`
+	UseTOTPStepQuery         = `UPDATE totp_secrets SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?;`
+	DeleteTOTPQuery          = `DELETE FROM totp_secrets WHERE user_id = ?;`
+	SaveRecoveryCodeQuery    = `INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?,?,?);`
+	UseRecoveryCodeQuery     = `UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL;`
+	DeleteRecoveryCodesQuery = `DELETE FROM recovery_codes WHERE user_id = ?;`
+	SaveChallengeQuery       = `INSERT INTO login_challenges (token_hash, user_id, created_at, expires_at) VALUES (?,?,?,?);`
+	SearchChallengeQuery     = `SELECT user_id FROM login_challenges WHERE token_hash = ? AND expires_at > ?;`
+	DeleteChallengeQuery     = `DELETE FROM login_challenges WHERE token_hash = ? OR expires_at <= ?;`
+	PurgeTOTPQuery           = `DELETE FROM totp_secrets WHERE user_id NOT IN (SELECT id FROM users);`
+	PurgeRecoveryCodesQuery  = `DELETE FROM recovery_codes WHERE user_id NOT IN (SELECT id FROM users);`
+	SearchSessionUserQuery   = `SELECT ` + UserColumns + ` FROM users WHERE id = (SELECT user_id FROM sessions WHERE token_hash = ? AND expires_at > ?) AND tenant_id = ? AND deleted_at IS NULL;`
+)
+
+//API key queries
+
+const (
+	APIKeyColumns = `id, name, prefix, key_hash, scopes, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+	SaveAPIKeyQuery           = `INSERT INTO api_keys (` + APIKeyColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?,?,?,?,?);`
+	ListAPIKeysQuery          = `SELECT ` + APIKeyColumns + ` FROM api_keys WHERE tenant_id = ? ORDER BY created_at DESC, id;`
+	SearchAPIKeyByPrefixQuery = `SELECT ` + APIKeyColumns + ` FROM api_keys WHERE prefix = ? AND tenant_id = ?;`
+	RevokeAPIKeyQuery         = `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL AND tenant_id = ?;`
+	TouchAPIKeyQuery          = `UPDATE api_keys SET last_used_at = ? WHERE id = ?;`
+)
+
+//OIDC queries
+
+const (
+	OAuthClientColumns       = `id, name, secret_hash, redirect_uris, public, created_by, created_at`
+	AuthorizationCodeColumns = `code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, created_at, expires_at`
+	SigningKeyColumns        = `id, private_key, created_at, retired_at`
+
+	SaveOAuthClientQuery         = `INSERT INTO oauth_clients (` + OAuthClientColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?,?);`
+	ListOAuthClientsQuery        = `SELECT ` + OAuthClientColumns + ` FROM oauth_clients WHERE tenant_id = ? ORDER BY created_at DESC, id;`
+	SearchOAuthClientQuery       = `SELECT ` + OAuthClientColumns + ` FROM oauth_clients WHERE id = ? AND tenant_id = ?;`
+	DeleteOAuthClientQuery       = `DELETE FROM oauth_clients WHERE id = ? AND tenant_id = ?;`
+	DeleteClientCodesQuery       = `DELETE FROM oauth_codes WHERE client_id = ?;`
+	SaveAuthorizationCodeQuery   = `INSERT INTO oauth_codes (` + AuthorizationCodeColumns + `) VALUES (?,?,?,?,?,?,?,?,?);`
+	SearchAuthorizationCodeQuery = `SELECT ` + AuthorizationCodeColumns + ` FROM oauth_codes WHERE code_hash = ? AND expires_at > ?;`
+	DeleteAuthorizationCodeQuery = `DELETE FROM oauth_codes WHERE code_hash = ?;`
+	PurgeAuthorizationCodesQuery = `DELETE FROM oauth_codes WHERE expires_at <= ? OR user_id NOT IN (SELECT id FROM users);`
+	SaveSigningKeyQuery          = `INSERT INTO signing_keys (` + SigningKeyColumns + `) VALUES (?,?,?,?);`
+	ListSigningKeysQuery         = `SELECT ` + SigningKeyColumns + ` FROM signing_keys WHERE retired_at IS NULL OR retired_at > ? ORDER BY created_at DESC, id;`
+	RetireSigningKeysQuery       = `UPDATE signing_keys SET retired_at = ? WHERE retired_at IS NULL AND id <> ?;`
+	PurgeSigningKeysQuery        = `DELETE FROM signing_keys WHERE retired_at IS NOT NULL AND retired_at <= ?;`
+)
+
+//Federation queries
+
+const (
+	IdentityColumns        = `id, user_id, provider, issuer, subject, email, created_at, last_login_at`
+	FederationStateColumns = `state_hash, provider, nonce, code_verifier, user_id, created_at, expires_at`
+
+	SaveIdentityQuery          = `INSERT INTO external_identities (` + IdentityColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?,?,?);`
+	SearchIdentityQuery        = `SELECT ` + IdentityColumns + ` FROM external_identities WHERE issuer = ? AND subject = ? AND tenant_id = ?;`
+	ListIdentitiesQuery        = `SELECT ` + IdentityColumns + ` FROM external_identities WHERE user_id = ? ORDER BY created_at, id;`
+	DeleteIdentityQuery        = `DELETE FROM external_identities WHERE id = ? AND user_id = ?;`
+	TouchIdentityQuery         = `UPDATE external_identities SET last_login_at = ? WHERE id = ?;`
+	PurgeIdentitiesQuery       = `DELETE FROM external_identities WHERE user_id NOT IN (SELECT id FROM users);`
+	SaveFederationStateQuery   = `INSERT INTO federation_states (` + FederationStateColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?,?);`
+	SearchFederationStateQuery = `SELECT ` + FederationStateColumns + ` FROM federation_states WHERE state_hash = ? AND expires_at > ? AND tenant_id = ?;`
+	DeleteFederationStateQuery = `DELETE FROM federation_states WHERE state_hash = ?;`
+	PurgeFederationStatesQuery = `DELETE FROM federation_states WHERE expires_at <= ?;`
+)
+
+//Group queries
+
+const (
+	GroupColumns       = `id, name, description, parent_id, created_at, updated_at`
+	GroupMemberColumns = `group_id, user_id, role, created_at`
+
+	groupSubtree       = `WITH RECURSIVE subtree(id) AS (SELECT ? UNION SELECT groups.id FROM groups JOIN subtree ON groups.parent_id = subtree.id) `
+	memberSelect       = `SELECT m.group_id, m.user_id, u.username, m.role, m.created_at FROM group_members m JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL `
+	membershipSelect   = `SELECT g.id, g.name, g.description, g.parent_id, g.created_at, g.updated_at, m.role, m.via_group_id, m.created_at `
+	directMemberships  = `SELECT group_id, NULL AS via_group_id, role, created_at FROM group_members WHERE user_id = ?`
+	inheritedAncestors = `SELECT groups.parent_id, COALESCE(memberships.via_group_id, memberships.group_id), memberships.role, memberships.created_at FROM groups JOIN memberships ON groups.id = memberships.group_id WHERE groups.parent_id IS NOT NULL`
+
+	SaveGroupQuery            = `INSERT INTO groups (` + GroupColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?);`
+	SearchGroupQuery          = `SELECT ` + GroupColumns + ` FROM groups WHERE id = ? AND tenant_id = ?;`
+	ListGroupsQuery           = `SELECT ` + GroupColumns + ` FROM groups WHERE tenant_id = ? ORDER BY name, id;`
+	UpdateGroupQuery          = `UPDATE groups SET name = ?, description = ?, parent_id = ?, updated_at = ? WHERE id = ? AND tenant_id = ?;`
+	DeleteGroupQuery          = `DELETE FROM groups WHERE id = ? AND NOT EXISTS (SELECT 1 FROM groups child WHERE child.parent_id = ?) AND tenant_id = ?;`
+	InSubtreeQuery            = groupSubtree + `SELECT COUNT(*) FROM subtree WHERE id = ?;`
+	SaveGroupMemberQuery      = `INSERT INTO group_members (` + GroupMemberColumns + `) VALUES (?,?,?,?) ON CONFLICT(group_id, user_id) DO UPDATE SET role = excluded.role;`
+	SearchGroupMemberQuery    = memberSelect + `WHERE m.group_id = ? AND m.user_id = ?;`
+	DeleteGroupMemberQuery    = `DELETE FROM group_members WHERE group_id = ? AND user_id = ?;`
+	DeleteGroupMembersQuery   = `DELETE FROM group_members WHERE group_id = ?;`
+	ListGroupMembersQuery     = memberSelect + `WHERE m.group_id = ? ORDER BY u.username, m.group_id;`
+	ListNestedMembersQuery    = groupSubtree + memberSelect + `JOIN subtree ON subtree.id = m.group_id ORDER BY u.username, m.group_id;`
+	ListMembershipsQuery      = `WITH memberships AS (` + directMemberships + `) ` + membershipSelect + `FROM memberships m JOIN groups g ON g.id = m.group_id ORDER BY g.name, g.id;`
+	ListNestedMembershipQuery = `WITH RECURSIVE memberships(group_id, via_group_id, role, created_at) AS (` + directMemberships + ` UNION ` + inheritedAncestors + `) ` + membershipSelect + `FROM memberships m JOIN groups g ON g.id = m.group_id ORDER BY g.name, g.id, m.via_group_id;`
+	PurgeGroupMembersQuery    = `DELETE FROM group_members WHERE user_id NOT IN (SELECT id FROM users);`
+)
+
+//Role queries
+
+const (
+	RoleColumns = `id, name, description, permissions, created_at, updated_at`
+
+	SaveRoleQuery              = `INSERT INTO roles (` + RoleColumns + `, tenant_id) VALUES (?,?,?,?,?,?,?);`
+	SearchRoleQuery            = `SELECT ` + RoleColumns + ` FROM roles WHERE id = ? AND tenant_id = ?;`
+	ListRolesQuery             = `SELECT ` + RoleColumns + ` FROM roles WHERE tenant_id = ? ORDER BY name, id;`
+	UpdateRoleQuery            = `UPDATE roles SET name = ?, description = ?, permissions = ?, updated_at = ? WHERE id = ? AND tenant_id = ?;`
+	DeleteRoleQuery            = `DELETE FROM roles WHERE id = ? AND tenant_id = ?;`
+	AssignRoleQuery            = `INSERT INTO user_roles (user_id, role_id, created_at) VALUES (?,?,?) ON CONFLICT(user_id, role_id) DO NOTHING;`
+	UnassignRoleQuery          = `DELETE FROM user_roles WHERE user_id = ? AND role_id = ?;`
+	DeleteRoleAssignmentsQuery = `DELETE FROM user_roles WHERE role_id = ?;`
+	ListUserRolesQuery         = `SELECT r.id, r.name, r.description, r.permissions, r.created_at, r.updated_at FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = ? AND r.tenant_id = ? ORDER BY r.name, r.id;`
+	PurgeRoleAssignmentsQuery  = `DELETE FROM user_roles WHERE user_id NOT IN (SELECT id FROM users);`
+)
+
+//Tenant queries
+
+const (
+	SearchSessionTenantQuery = `SELECT u.tenant_id FROM sessions s JOIN users u ON u.id = s.user_id WHERE s.token_hash = ? AND s.expires_at > ?;`
+	SearchAPIKeyTenantQuery  = `SELECT tenant_id FROM api_keys WHERE prefix = ?;`
+)
+
+//Login throttle queries
+
+const (
+	ThrottleColumns = `throttle_key, failures, window_started_at, locked_until`
+
+	SearchThrottleQuery = `SELECT ` + ThrottleColumns + ` FROM login_throttles WHERE throttle_key = ?;`
+	SaveThrottleQuery   = `INSERT INTO login_throttles (` + ThrottleColumns + `) VALUES (?,?,?,?) ON CONFLICT(throttle_key) DO UPDATE SET failures = excluded.failures, window_started_at = excluded.window_started_at, locked_until = excluded.locked_until;`
+	DeleteThrottleQuery = `DELETE FROM login_throttles WHERE throttle_key = ?;`
+	PurgeThrottlesQuery = `DELETE FROM login_throttles WHERE window_started_at < ? AND (locked_until IS NULL OR locked_until < ?);`
+)
+
+//Login throttle params
+
+const (
+	ThrottleUserPrefix = "user:"
+	ThrottleIPPrefix   = "ip:"
+)
+
+//Password hashing params
+
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+
+	Argon2Version    = 19
+	Argon2SaltLength = 16
+	Argon2KeyLength  = 32
+	SessionTokenSize = 32
+	DummyPassword    = "go-manage-dummy-password"
+)
+
+//Audit params
+
+const (
+	AuditContextKey = "audit"
+	AnonymousActor  = "anonymous"
+	AdminActor      = "admin"
+	SystemActor     = "system"
+	CLIActor        = "cli"
+	RedactedValue   = "[REDACTED]"
+	RequestIDHeader = "X-Request-ID"
+)
+
+const (
+	AuditActionCreate         = "user.create"
+	AuditActionUpdate         = "user.update"
+	AuditActionDelete         = "user.delete"
+	AuditActionRestore        = "user.restore"
+	AuditActionChangePassword = "user.change_password"
+	AuditActionPurge          = "user.purge"
+	AuditActionLockout        = "user.lockout"
+	AuditActionUnlock         = "user.unlock"
+	AuditActionEnableMFA      = "user.mfa_enable"
+	AuditActionDisableMFA     = "user.mfa_disable"
+	AuditActionRecoveryCodes  = "user.recovery_codes_regenerate"
+	AuditActionCreateAPIKey   = "apikey.create"
+	AuditActionRevokeAPIKey   = "apikey.revoke"
+	AuditActionCreateClient   = "oauth.client_create"
+	AuditActionDeleteClient   = "oauth.client_delete"
+	AuditActionRotateKey      = "oauth.key_rotate"
+	AuditActionLinkIdentity   = "identity.link"
+	AuditActionUnlinkIdentity = "identity.unlink"
+	AuditActionCreateGroup    = "group.create"
+	AuditActionUpdateGroup    = "group.update"
+	AuditActionDeleteGroup    = "group.delete"
+	AuditActionSetMember      = "group.member_set"
+	AuditActionRemoveMember   = "group.member_remove"
+	AuditActionCreateRole     = "role.create"
+	AuditActionUpdateRole     = "role.update"
+	AuditActionDeleteRole     = "role.delete"
+	AuditActionAssignRole     = "role.assign"
+	AuditActionUnassignRole   = "role.unassign"
+	AuditActionBackup         = "database.backup"
+)
+
+//API versioning
+
+const (
+	APIVersionKey         = "api_version"
+	APIVersionHeader      = "API-Version"
+	DefaultAPIVersion     = "v1"
+	VendorMediaTypePrefix = "application/vnd.go-manage."
+	VendorMediaTypeSuffix = "+json"
+)
+
+//OpenAPI params
+
+const (
+	OpenAPIPath           = "/openapi.json"
+	DocsPath              = "/docs"
+	OpenAPIVersion        = "3.1.0"
+	APITitle              = "Go-Manage API"
+	APIDocVersion         = "1.0.0"
+	APIDescription        = "User management API. Versioned routes live under /api/v1 and /api/v2; unversioned /api paths are routed by the Accept media type (application/vnd.go-manage.<version>+json) and default to v1. Every request is scoped to the tenant of the presented session or API key; the X-Tenant-ID header or the subdomain must match it, and may only choose the tenant on login and OpenID Connect routes or with the admin token."
+	AdminSecurityScheme   = "adminToken"
+	SessionSecurityScheme = "sessionToken"
+	APIKeySecurityScheme  = "apiKey"
+)
+
+//Deprecation params
+
+const (
+	UsersResourcePath = "/api/v1/users"
+	AuditResourcePath = "/api/v1/audit"
+)
+
+var (
+	LegacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	LegacySunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+//Validation params
+
+const (
+	UsernamePattern = `^[a-zA-Z0-9._-]+$`
+)
+
+//Listing params
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 100
+	DefaultSortField = "created_at"
+)
+
+var (
+	UserSortFields      = []string{"username", "created_at", "updated_at", "last_login_at", "password_changed_at"}
+	UserTimestampFields = []string{"created_at", "updated_at", "last_login_at", "password_changed_at"}
+)
+
+//Database migrations
+
+const (
+	CreateMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY);`
+	CurrentMigrationQuery      = `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`
+	SaveMigrationQuery         = `INSERT INTO schema_migrations (version) VALUES (?);`
+)
+
+var Migrations = []string{
+	`ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;`,
+	`ALTER TABLE users ADD COLUMN created_at DATETIME;`,
+	`ALTER TABLE users ADD COLUMN updated_at DATETIME;`,
+	`ALTER TABLE users ADD COLUMN last_login_at DATETIME;`,
+	`ALTER TABLE users ADD COLUMN password_changed_at DATETIME;`,
+	`UPDATE users SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;`,
+	`ALTER TABLE users ADD COLUMN deleted_at DATETIME;`,
+	`CREATE TABLE audit_events (id TEXT NOT NULL PRIMARY KEY, occurred_at DATETIME NOT NULL, actor TEXT NOT NULL, action TEXT NOT NULL, target TEXT NOT NULL, changes TEXT, request_id TEXT, ip TEXT);`,
+	`CREATE INDEX audit_events_occurred_at ON audit_events (occurred_at);`,
+	`CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events BEGIN SELECT RAISE(ABORT, 'audit events are immutable'); END;`,
+	`CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events BEGIN SELECT RAISE(ABORT, 'audit events are immutable'); END;`,
+	`CREATE TABLE password_history (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id TEXT NOT NULL, password TEXT NOT NULL, created_at DATETIME NOT NULL);`,
+	`CREATE INDEX password_history_user_id ON password_history (user_id, id);`,
+	`CREATE TABLE sessions (token_hash TEXT NOT NULL PRIMARY KEY, user_id TEXT NOT NULL, created_at DATETIME NOT NULL, expires_at DATETIME NOT NULL);`,
+	`CREATE INDEX sessions_user_id ON sessions (user_id);`,
+	`CREATE TABLE login_throttles (throttle_key TEXT NOT NULL PRIMARY KEY, failures INTEGER NOT NULL, window_started_at DATETIME NOT NULL, locked_until DATETIME);`,
+	`CREATE TABLE totp_secrets (user_id TEXT NOT NULL PRIMARY KEY, secret TEXT NOT NULL, confirmed_at DATETIME, last_used_step INTEGER NOT NULL DEFAULT 0, created_at DATETIME NOT NULL);`,
+	`CREATE TABLE recovery_codes (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id TEXT NOT NULL, code_hash TEXT NOT NULL, created_at DATETIME NOT NULL, used_at DATETIME);`,
+	`CREATE INDEX recovery_codes_user_id ON recovery_codes (user_id, code_hash);`,
+	`CREATE TABLE login_challenges (token_hash TEXT NOT NULL PRIMARY KEY, user_id TEXT NOT NULL, created_at DATETIME NOT NULL, expires_at DATETIME NOT NULL);`,
+	`CREATE TABLE api_keys (id TEXT NOT NULL PRIMARY KEY, name TEXT NOT NULL, prefix TEXT NOT NULL UNIQUE, key_hash TEXT NOT NULL, scopes TEXT NOT NULL, created_by TEXT NOT NULL, created_at DATETIME NOT NULL, expires_at DATETIME, last_used_at DATETIME, revoked_at DATETIME);`,
+	`CREATE TABLE oauth_clients (id TEXT NOT NULL PRIMARY KEY, name TEXT NOT NULL, secret_hash TEXT NOT NULL, redirect_uris TEXT NOT NULL, public INTEGER NOT NULL DEFAULT 0, created_by TEXT NOT NULL, created_at DATETIME NOT NULL);`,
+	`CREATE TABLE oauth_codes (code_hash TEXT NOT NULL PRIMARY KEY, client_id TEXT NOT NULL, user_id TEXT NOT NULL, redirect_uri TEXT NOT NULL, scope TEXT NOT NULL, nonce TEXT NOT NULL, code_challenge TEXT NOT NULL, created_at DATETIME NOT NULL, expires_at DATETIME NOT NULL);`,
+	`CREATE TABLE signing_keys (id TEXT NOT NULL PRIMARY KEY, private_key TEXT NOT NULL, created_at DATETIME NOT NULL, retired_at DATETIME);`,
+	`CREATE TABLE external_identities (id TEXT NOT NULL PRIMARY KEY, user_id TEXT NOT NULL, provider TEXT NOT NULL, issuer TEXT NOT NULL, subject TEXT NOT NULL, email TEXT NOT NULL, created_at DATETIME NOT NULL, last_login_at DATETIME, UNIQUE (issuer, subject));`,
+	`CREATE INDEX external_identities_user_id ON external_identities (user_id);`,
+	`CREATE TABLE federation_states (state_hash TEXT NOT NULL PRIMARY KEY, provider TEXT NOT NULL, nonce TEXT NOT NULL, code_verifier TEXT NOT NULL, user_id TEXT NOT NULL, created_at DATETIME NOT NULL, expires_at DATETIME NOT NULL);`,
+	`CREATE TABLE groups (id TEXT NOT NULL PRIMARY KEY, name TEXT NOT NULL UNIQUE, description TEXT NOT NULL, parent_id TEXT, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL);`,
+	`CREATE INDEX groups_parent_id ON groups (parent_id);`,
+	`CREATE TABLE group_members (group_id TEXT NOT NULL, user_id TEXT NOT NULL, role TEXT NOT NULL, created_at DATETIME NOT NULL, PRIMARY KEY (group_id, user_id));`,
+	`CREATE INDEX group_members_user_id ON group_members (user_id);`,
+	`CREATE TABLE users_tenant (id TEXT NOT NULL UNIQUE PRIMARY KEY, tenant_id TEXT NOT NULL DEFAULT 'default', name TEXT NOT NULL, surname TEXT NOT NULL, username TEXT NOT NULL, email TEXT NOT NULL, password TEXT NOT NULL UNIQUE, version INTEGER NOT NULL DEFAULT 1, created_at DATETIME, updated_at DATETIME, last_login_at DATETIME, password_changed_at DATETIME, deleted_at DATETIME, UNIQUE (tenant_id, username), UNIQUE (tenant_id, email));`,
+	`INSERT INTO users_tenant (id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at, deleted_at) SELECT id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at, deleted_at FROM users;`,
+	`DROP TABLE users;`,
+	`ALTER TABLE users_tenant RENAME TO users;`,
+	`CREATE TABLE groups_tenant (id TEXT NOT NULL PRIMARY KEY, tenant_id TEXT NOT NULL DEFAULT 'default', name TEXT NOT NULL, description TEXT NOT NULL, parent_id TEXT, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL, UNIQUE (tenant_id, name));`,
+	`INSERT INTO groups_tenant (id, name, description, parent_id, created_at, updated_at) SELECT id, name, description, parent_id, created_at, updated_at FROM groups;`,
+	`DROP TABLE groups;`,
+	`ALTER TABLE groups_tenant RENAME TO groups;`,
+	`CREATE INDEX groups_parent_id ON groups (parent_id);`,
+	`ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';`,
+	`ALTER TABLE audit_events ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';`,
+	`CREATE INDEX audit_events_tenant_id ON audit_events (tenant_id, occurred_at);`,
+	`CREATE TABLE roles (id TEXT NOT NULL PRIMARY KEY, tenant_id TEXT NOT NULL DEFAULT 'default', name TEXT NOT NULL, description TEXT NOT NULL, permissions TEXT NOT NULL, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL, UNIQUE (tenant_id, name));`,
+	`CREATE TABLE user_roles (user_id TEXT NOT NULL, role_id TEXT NOT NULL, created_at DATETIME NOT NULL, PRIMARY KEY (user_id, role_id));`,
+	`CREATE INDEX user_roles_role_id ON user_roles (role_id);`,
+	`ALTER TABLE oauth_clients ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';`,
+	`ALTER TABLE federation_states ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';`,
+	`CREATE TABLE external_identities_tenant (id TEXT NOT NULL PRIMARY KEY, tenant_id TEXT NOT NULL DEFAULT 'default', user_id TEXT NOT NULL, provider TEXT NOT NULL, issuer TEXT NOT NULL, subject TEXT NOT NULL, email TEXT NOT NULL, created_at DATETIME NOT NULL, last_login_at DATETIME, UNIQUE (tenant_id, issuer, subject));`,
+	`INSERT INTO external_identities_tenant (id, tenant_id, user_id, provider, issuer, subject, email, created_at, last_login_at) SELECT i.id, COALESCE(u.tenant_id, 'default'), i.user_id, i.provider, i.issuer, i.subject, i.email, i.created_at, i.last_login_at FROM external_identities i LEFT JOIN users u ON u.id = i.user_id;`,
+	`DROP TABLE external_identities;`,
+	`ALTER TABLE external_identities_tenant RENAME TO external_identities;`,
+	`CREATE INDEX external_identities_user_id ON external_identities (user_id);`,
+	`CREATE TABLE users_soft_delete (id TEXT NOT NULL PRIMARY KEY, tenant_id TEXT NOT NULL DEFAULT 'default', name TEXT NOT NULL, surname TEXT NOT NULL, username TEXT NOT NULL, email TEXT NOT NULL, password TEXT NOT NULL, version INTEGER NOT NULL DEFAULT 1, created_at DATETIME, updated_at DATETIME, last_login_at DATETIME, password_changed_at DATETIME, deleted_at DATETIME);`,
+	`INSERT INTO users_soft_delete (id, tenant_id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at, deleted_at) SELECT id, tenant_id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at, deleted_at FROM users;`,
+	`DROP TABLE users;`,
+	`ALTER TABLE users_soft_delete RENAME TO users;`,
+	`CREATE UNIQUE INDEX users_tenant_username ON users (tenant_id, username) WHERE deleted_at IS NULL;`,
+	`CREATE UNIQUE INDEX users_tenant_email ON users (tenant_id, email) WHERE deleted_at IS NULL;`,
+}
+
 //Repository test queries
 
 const (
-	TestSearchQuery    = `SELECT \* FROM users WHERE username=\?`
-	TestSaveQuery      = "INSERT INTO users"
-	TestDeleteQuery    = `DELETE\s+FROM\s+users\s+WHERE\s+username\s*=\s*\?;`
-	TestUpdateQuery    = `UPDATE users SET name = \?, surname = \?, email = \? WHERE username = \?;`
-	TestChangePwdQuery = "UPDATE users SET"
+	TestSearchQuery               = `SELECT id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at FROM users WHERE username=\? AND tenant_id=\? AND deleted_at IS NULL`
+	TestSearchByIDQuery           = `SELECT id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at FROM users WHERE id=\? AND tenant_id=\? AND deleted_at IS NULL`
+	TestListQuery                 = `SELECT id, name, surname, username, email, password, version, created_at, updated_at, last_login_at, password_changed_at FROM users WHERE tenant_id=\? AND deleted_at IS NULL`
+	TestSaveQuery                 = "INSERT INTO users"
+	TestDeleteQuery               = `UPDATE users SET deleted_at = \?, updated_at = \?, version = version \+ 1 WHERE username = \? AND version = \? AND tenant_id = \? AND deleted_at IS NULL;`
+	TestRestoreQuery              = `UPDATE users SET deleted_at = NULL, updated_at = \?, version = version \+ 1 WHERE id = \(SELECT id FROM users WHERE username = \? AND tenant_id = \? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1\);`
+	TestPurgeQuery                = `DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < \? AND tenant_id = \?;`
+	TestPurgeTenantsQuery         = `SELECT DISTINCT tenant_id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < \? ORDER BY tenant_id;`
+	TestUpdateQuery               = `UPDATE users SET name = \?, surname = \?, username = \?, email = \?, updated_at = \?, version = version \+ 1 WHERE username = \? AND version = \? AND tenant_id = \? AND deleted_at IS NULL;`
+	TestChangePwdQuery            = "UPDATE users SET password"
+	TestLoginQuery                = `UPDATE users SET last_login_at = \? WHERE username = \? AND tenant_id = \? AND deleted_at IS NULL;`
+	TestSaveAuditQuery            = `INSERT INTO audit_events`
+	TestSaveHistoryQuery          = `INSERT INTO password_history`
+	TestHistoryQuery              = `SELECT password FROM password_history WHERE user_id = \? ORDER BY id DESC LIMIT \?;`
+	TestPurgeHistoryQuery         = `DELETE FROM password_history WHERE user_id NOT IN`
+	TestRehashQuery               = `UPDATE users SET password = \? WHERE id = \? AND password = \? AND tenant_id = \?;`
+	TestSaveSessionQuery          = `INSERT INTO sessions`
+	TestPurgeSessionsQuery        = `DELETE FROM sessions WHERE expires_at <= \? OR user_id NOT IN \(SELECT id FROM users\);`
+	TestSearchThrottleQuery       = `SELECT throttle_key, failures, window_started_at, locked_until FROM login_throttles WHERE throttle_key = \?;`
+	TestSaveThrottleQuery         = `INSERT INTO login_throttles`
+	TestDeleteThrottleQuery       = `DELETE FROM login_throttles WHERE throttle_key = \?;`
+	TestPurgeThrottlesQuery       = `DELETE FROM login_throttles WHERE window_started_at < \?`
+	TestSearchTOTPQuery           = `SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM totp_secrets WHERE user_id = \?;`
+	TestSaveTOTPQuery             = `INSERT INTO totp_secrets`
+	TestConfirmTOTPQuery          = `UPDATE totp_secrets SET confirmed_at = \?, last_used_step = \? WHERE user_id = \? AND confirmed_at IS NULL;`
+	TestUseTOTPStepQuery          = `UPDATE totp_secrets SET last_used_step = \? WHERE user_id = \? AND last_used_step < \?;`
+	TestDeleteTOTPQuery           = `DELETE FROM totp_secrets WHERE user_id = \?;`
+	TestSaveRecoveryCodeQuery     = `INSERT INTO recovery_codes`
+	TestUseRecoveryCodeQuery      = `UPDATE recovery_codes SET used_at = \? WHERE user_id = \? AND code_hash = \? AND used_at IS NULL;`
+	TestDeleteRecoveryCodesQuery  = `DELETE FROM recovery_codes WHERE user_id = \?;`
+	TestSaveChallengeQuery        = `INSERT INTO login_challenges`
+	TestSearchChallengeQuery      = `SELECT user_id FROM login_challenges WHERE token_hash = \? AND expires_at > \?;`
+	TestDeleteChallengeQuery      = `DELETE FROM login_challenges WHERE token_hash = \? OR expires_at <= \?;`
+	TestPurgeTOTPQuery            = `DELETE FROM totp_secrets WHERE user_id NOT IN`
+	TestPurgeRecoveryCodesQuery   = `DELETE FROM recovery_codes WHERE user_id NOT IN`
+	TestSearchSessionUserQuery    = `FROM users WHERE id = \(SELECT user_id FROM sessions WHERE token_hash = \? AND expires_at > \?\) AND tenant_id = \? AND deleted_at IS NULL;`
+	TestSaveAPIKeyQuery           = `INSERT INTO api_keys`
+	TestListAPIKeysQuery          = `SELECT id, name, prefix, key_hash, scopes, created_by, created_at, expires_at, last_used_at, revoked_at FROM api_keys WHERE tenant_id = \? ORDER BY created_at DESC, id;`
+	TestSearchAPIKeyByPrefixQuery = `SELECT id, name, prefix, key_hash, scopes, created_by, created_at, expires_at, last_used_at, revoked_at FROM api_keys WHERE prefix = \? AND tenant_id = \?;`
+	TestRevokeAPIKeyQuery         = `UPDATE api_keys SET revoked_at = \? WHERE id = \? AND revoked_at IS NULL AND tenant_id = \?;`
+	TestTouchAPIKeyQuery          = `UPDATE api_keys SET last_used_at = \? WHERE id = \?;`
+	TestSaveOAuthClientQuery      = `INSERT INTO oauth_clients`
+	TestListOAuthClientsQuery     = `SELECT id, name, secret_hash, redirect_uris, public, created_by, created_at FROM oauth_clients WHERE tenant_id = \? ORDER BY created_at DESC, id;`
+	TestSearchOAuthClientQuery    = `SELECT id, name, secret_hash, redirect_uris, public, created_by, created_at FROM oauth_clients WHERE id = \? AND tenant_id = \?;`
+	TestDeleteOAuthClientQuery    = `DELETE FROM oauth_clients WHERE id = \? AND tenant_id = \?;`
+	TestDeleteClientCodesQuery    = `DELETE FROM oauth_codes WHERE client_id = \?;`
+	TestSaveAuthCodeQuery         = `INSERT INTO oauth_codes`
+	TestSearchAuthCodeQuery       = `SELECT code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, created_at, expires_at FROM oauth_codes WHERE code_hash = \? AND expires_at > \?;`
+	TestDeleteAuthCodeQuery       = `DELETE FROM oauth_codes WHERE code_hash = \?;`
+	TestPurgeAuthCodesQuery       = `DELETE FROM oauth_codes WHERE expires_at <= \?`
+	TestSaveSigningKeyQuery       = `INSERT INTO signing_keys`
+	TestListSigningKeysQuery      = `SELECT id, private_key, created_at, retired_at FROM signing_keys WHERE retired_at IS NULL OR retired_at > \?`
+	TestRetireSigningKeysQuery    = `UPDATE signing_keys SET retired_at = \? WHERE retired_at IS NULL AND id <> \?;`
+	TestPurgeSigningKeysQuery     = `DELETE FROM signing_keys WHERE retired_at IS NOT NULL AND retired_at <= \?;`
+	TestSaveIdentityQuery         = `INSERT INTO external_identities`
+	TestSearchIdentityQuery       = `SELECT id, user_id, provider, issuer, subject, email, created_at, last_login_at FROM external_identities WHERE issuer = \? AND subject = \? AND tenant_id = \?;`
+	TestListIdentitiesQuery       = `SELECT id, user_id, provider, issuer, subject, email, created_at, last_login_at FROM external_identities WHERE user_id = \?`
+	TestDeleteIdentityQuery       = `DELETE FROM external_identities WHERE id = \? AND user_id = \?;`
+	TestTouchIdentityQuery        = `UPDATE external_identities SET last_login_at = \? WHERE id = \?;`
+	TestPurgeIdentitiesQuery      = `DELETE FROM external_identities WHERE user_id NOT IN`
+	TestSaveFedStateQuery         = `INSERT INTO federation_states`
+	TestSearchFedStateQuery       = `SELECT state_hash, provider, nonce, code_verifier, user_id, created_at, expires_at FROM federation_states WHERE state_hash = \? AND expires_at > \? AND tenant_id = \?;`
+	TestDeleteFedStateQuery       = `DELETE FROM federation_states WHERE state_hash = \?;`
+	TestPurgeFedStatesQuery       = `DELETE FROM federation_states WHERE expires_at <= \?;`
+	TestSaveGroupQuery            = `INSERT INTO groups`
+	TestSearchGroupQuery          = `SELECT id, name, description, parent_id, created_at, updated_at FROM groups WHERE id = \? AND tenant_id = \?;`
+	TestListGroupsQuery           = `SELECT id, name, description, parent_id, created_at, updated_at FROM groups WHERE tenant_id = \? ORDER BY name, id;`
+	TestUpdateGroupQuery          = `UPDATE groups SET name = \?, description = \?, parent_id = \?, updated_at = \? WHERE id = \? AND tenant_id = \?;`
+	TestDeleteGroupQuery          = `DELETE FROM groups WHERE id = \? AND NOT EXISTS`
+	TestInSubtreeQuery            = `WITH RECURSIVE subtree\(id\) AS .* SELECT COUNT\(\*\) FROM subtree WHERE id = \?;`
+	TestSaveGroupMemberQuery      = `INSERT INTO group_members`
+	TestSearchGroupMemberQuery    = `FROM group_members m JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL WHERE m.group_id = \? AND m.user_id = \?;`
+	TestDeleteGroupMemberQuery    = `DELETE FROM group_members WHERE group_id = \? AND user_id = \?;`
+	TestDeleteGroupMembersQuery   = `DELETE FROM group_members WHERE group_id = \?;`
+	TestListGroupMembersQuery     = `FROM group_members m JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL WHERE m.group_id = \? ORDER BY`
+	TestListNestedMembersQuery    = `WITH RECURSIVE subtree\(id\) AS .* JOIN subtree ON subtree.id = m.group_id`
+	TestListMembershipsQuery      = `WITH memberships AS`
+	TestListNestedMembershipQuery = `WITH RECURSIVE memberships`
+	TestPurgeGroupMembersQuery    = `DELETE FROM group_members WHERE user_id NOT IN`
+	TestSessionTenantQuery        = `SELECT u.tenant_id FROM sessions s JOIN users u ON u.id = s.user_id WHERE s.token_hash = \? AND s.expires_at > \?;`
+	TestAPIKeyTenantQuery         = `SELECT tenant_id FROM api_keys WHERE prefix = \?;`
+	TestSaveRoleQuery             = `INSERT INTO roles`
+	TestSearchRoleQuery           = `SELECT id, name, description, permissions, created_at, updated_at FROM roles WHERE id = \? AND tenant_id = \?;`
+	TestListRolesQuery            = `SELECT id, name, description, permissions, created_at, updated_at FROM roles WHERE tenant_id = \? ORDER BY name, id;`
+	TestUpdateRoleQuery           = `UPDATE roles SET name = \?, description = \?, permissions = \?, updated_at = \? WHERE id = \? AND tenant_id = \?;`
+	TestDeleteRoleQuery           = `DELETE FROM roles WHERE id = \? AND tenant_id = \?;`
+	TestAssignRoleQuery           = `INSERT INTO user_roles`
+	TestUnassignRoleQuery         = `DELETE FROM user_roles WHERE user_id = \? AND role_id = \?;`
+	TestDeleteAssignmentsQuery    = `DELETE FROM user_roles WHERE role_id = \?;`
+	TestListUserRolesQuery        = `FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = \? AND r.tenant_id = \?`
+	TestSavepointQuery            = `SAVEPOINT batch_operation`
+	TestReleaseQuery              = `RELEASE SAVEPOINT batch_operation`
+	TestRollbackToQuery           = `ROLLBACK TO SAVEPOINT batch_operation`
+	TestBackupQuery               = `VACUUM INTO \?`
+	TestListAuditQuery            = `SELECT id, occurred_at, actor, action, target, changes, request_id, ip FROM audit_events WHERE tenant_id = \?`
+)
+
+var (
+	TestUserColumns = []string{"id", "name", "surname", "username", "email", "password", "version", "created_at", "updated_at", "last_login_at", "password_changed_at"}
+	TestTime        = time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	TestClock       = func() time.Time { return TestTime }
+
+	TestAuditColumns       = []string{"id", "occurred_at", "actor", "action", "target", "changes", "request_id", "ip"}
+	TestThrottleColumns    = []string{"throttle_key", "failures", "window_started_at", "locked_until"}
+	TestTOTPColumns        = []string{"user_id", "secret", "confirmed_at", "last_used_step", "created_at"}
+	TestAPIKeyColumns      = []string{"id", "name", "prefix", "key_hash", "scopes", "created_by", "created_at", "expires_at", "last_used_at", "revoked_at"}
+	TestOAuthClientColumns = []string{"id", "name", "secret_hash", "redirect_uris", "public", "created_by", "created_at"}
+	TestAuthCodeColumns    = []string{"code_hash", "client_id", "user_id", "redirect_uri", "scope", "nonce", "code_challenge", "created_at", "expires_at"}
+	TestSigningKeyColumns  = []string{"id", "private_key", "created_at", "retired_at"}
+	TestIdentityColumns    = []string{"id", "user_id", "provider", "issuer", "subject", "email", "created_at", "last_login_at"}
+	TestFedStateColumns    = []string{"state_hash", "provider", "nonce", "code_verifier", "user_id", "created_at", "expires_at"}
+	TestGroupColumns       = []string{"id", "name", "description", "parent_id", "created_at", "updated_at"}
+	TestGroupMemberColumns = []string{"group_id", "user_id", "username", "role", "created_at"}
+	TestMembershipColumns  = []string{"id", "name", "description", "parent_id", "created_at", "updated_at", "role", "via_group_id", "member_since"}
+	TestRoleColumns        = []string{"id", "name", "description", "permissions", "created_at", "updated_at"}
 )
 
 //Errors
@@ -46,6 +901,88 @@ var (
 	ErrUserNotFound         = errors.New("user not found")
 	ErrEmptyQueryParam      = errors.New("empty query param")
 	ErrChangingPassword     = errors.New("error changing user password")
+	ErrVersionMismatch      = errors.New("user was modified by another request")
+	ErrPreconditionRequired = errors.New("missing If-Match header")
+	ErrInvalidVersion       = errors.New("invalid If-Match header")
+	ErrWeakETag             = errors.New("If-Match requires a strong entity tag")
+	ErrFieldNotPatchable    = errors.New("field cannot be patched")
+	ErrFieldRequired        = errors.New("field cannot be empty")
+	ErrInvalidUsername      = errors.New("invalid username")
+	ErrFieldLength          = errors.New("field length out of range")
+	ErrValidationFailed     = errors.New("validation failed")
+	ErrBreachedPassword     = errors.New("password appears in a known data breach")
+	ErrPasswordReused       = errors.New("password was used recently")
+	ErrInvalidCredentials   = errors.New("invalid username or password")
+	ErrUnsupportedHash      = errors.New("unsupported password hash")
+	ErrLoginThrottled       = errors.New("too many failed login attempts")
+	ErrAccountLocked        = errors.New("account is temporarily locked")
+	ErrRateLimited          = errors.New("rate limit exceeded")
+	ErrInvalidRateLimit     = errors.New("invalid rate limit")
+	ErrForbidden            = errors.New("forbidden")
+	ErrMFAAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled       = errors.New("two-factor enrollment has not been started")
+	ErrInvalidMFACode       = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("invalid or expired login challenge")
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrInvalidScope         = errors.New("invalid api key scope")
+	ErrInvalidExpiry        = errors.New("expiry must be in the future")
+	ErrOAuthClientNotFound  = errors.New("oauth client not found")
+	ErrInvalidRedirectURI   = errors.New("invalid redirect uri")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrUnknownProvider      = errors.New("unknown identity provider")
+	ErrInvalidProvider      = errors.New("invalid identity provider configuration")
+	ErrInvalidLoginState    = errors.New("invalid or expired login state")
+	ErrFederationDenied     = errors.New("identity provider denied the login")
+	ErrFederationFailed     = errors.New("identity provider request failed")
+	ErrIdentityNotLinked    = errors.New("external identity is not linked to any user")
+	ErrIdentityLinked       = errors.New("external identity is already linked to another user")
+	ErrIdentityNotFound     = errors.New("external identity not found")
+	ErrGroupNotFound        = errors.New("group not found")
+	ErrGroupAlreadyExists   = errors.New("group already exists")
+	ErrGroupHasSubgroups    = errors.New("group still has subgroups")
+	ErrGroupCycle           = errors.New("a group cannot be nested under itself or its subgroups")
+	ErrInvalidGroupRole     = errors.New("invalid group role")
+	ErrMemberNotFound       = errors.New("group member not found")
+	ErrRoleNotFound         = errors.New("role not found")
+	ErrRoleAlreadyExists    = errors.New("role already exists")
+	ErrRoleNotAssigned      = errors.New("role is not assigned to the user")
+	ErrInvalidPermission    = errors.New("invalid permission")
+	ErrInvalidConflictMode  = errors.New("invalid on_conflict mode")
+	ErrInvalidImportHeader  = errors.New("invalid import header")
+	ErrDuplicateImportRow   = errors.New("user appears more than once in the import")
+	ErrInvalidExportFormat  = errors.New("invalid export format")
+	ErrInvalidBatchMode     = errors.New("invalid batch mode")
+	ErrInvalidBatchOp       = errors.New("invalid batch operation")
+	ErrBatchAborted         = errors.New("not applied because another operation in the batch failed")
+	ErrUnknownCommand       = errors.New("unknown command")
+	ErrInvalidOutput        = errors.New("invalid output format")
+	ErrMissingArgument      = errors.New("missing argument")
+	ErrBackupExists         = errors.New("backup file already exists")
+	ErrBackupNotFound       = errors.New("backup not found")
+	ErrBackupCorrupt        = errors.New("backup failed the integrity check")
+	ErrTenantNotFound       = errors.New("tenant not found")
+	ErrTenantMismatch       = errors.New("credential does not belong to the requested tenant")
+	ErrTenantSelection      = errors.New("selecting a tenant requires a credential bound to it")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrInvalidBody          = errors.New("invalid request body")
+	ErrInvalidSortField     = errors.New("invalid sort field")
+	ErrInvalidFilter        = errors.New("invalid filter")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrRecordingAudit       = errors.New("error recording audit event")
+	ErrVersionNotAcceptable = errors.New("requested API version is not available")
+	ErrRouteNotFound        = errors.New("route not found")
+)
+
+//Media types
+
+const (
+	JSONMediaType       = "application/json"
+	MergePatchMediaType = "application/merge-patch+json"
+	FormMediaType       = "application/x-www-form-urlencoded"
+	CSVMediaType        = "text/csv"
+	NDJSONMediaType     = "application/x-ndjson"
+	XLSXMediaType       = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 )
 
 //Handler messages
@@ -57,4 +994,39 @@ const (
 	DeleteMessage    = "user deleted successfully"
 	UpdateMessage    = "user updated successfully"
 	ChangePwdMessage = "user password changed successfully"
+	ListMessage      = "users listed successfully"
+	RestoreMessage   = "user restored successfully"
+	AuditMessage     = "audit events listed successfully"
+	LoginMessage     = "user logged in successfully"
+	MFARequired      = "second factor required"
+	TOTPEnrollMsg    = "scan the provisioning URI and confirm with a code"
+	RecoveryCodesMsg = "store these recovery codes somewhere safe"
+	APIKeyMessage    = "api key created successfully; store it now, it will not be shown again"
+	APIKeysMessage   = "api keys listed successfully"
+	OAuthClientMsg   = "oauth client registered successfully; store the secret now, it will not be shown again"
+	OAuthClientsMsg  = "oauth clients listed successfully"
+	IdentityLinkMsg  = "external identity linked successfully"
+	IdentitiesMsg    = "external identities listed successfully"
+	GroupCreateMsg   = "group created successfully"
+	GroupMessage     = "group found successfully"
+	GroupUpdateMsg   = "group updated successfully"
+	GroupsMessage    = "groups listed successfully"
+	MemberMessage    = "group member saved successfully"
+	MembersMessage   = "group members listed successfully"
+	RoleCreateMsg    = "role created successfully"
+	RoleMessage      = "role found successfully"
+	RoleUpdateMsg    = "role updated successfully"
+	RolesMessage     = "roles listed successfully"
+	PermissionsMsg   = "permissions listed successfully"
+	DecisionMessage  = "permission evaluated successfully"
+	ImportMessage    = "users imported"
+	ImportDryRunMsg  = "dry run finished; nothing was saved"
+	SeedPasswordMsg  = "password of the created demo users; store it now, it will not be shown again"
+	ExportMessage    = "users exported"
+	BatchMessage     = "batch executed"
+	BatchRolledBack  = "batch rolled back"
+	MigrateMessage   = "database migrated"
+	BackupMessage    = "backup created"
+	BackupsMessage   = "backups listed successfully"
+	RestoreDBMessage = "database restored"
 )
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type app struct {
+	dbPath   string
+	tenant   string
+	output   string
+	readOnly bool
+	out      io.Writer
+	conn     *sql.DB
+}
+
+func (a *app) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.dbPath, "db", config.EnvString(config.DBPathEnv, config.DBPath), "path to the SQLite database")
+	fs.StringVar(&a.tenant, "tenant", config.DefaultTenant, "tenant to operate on")
+	fs.StringVar(&a.output, "output", config.CLIOutputTable, "output format: json or table")
+}
+
+func (a *app) open() (*sql.DB, error) {
+	if !slices.Contains(config.CLIOutputs, a.output) {
+		return nil, config.ErrInvalidOutput
+	}
+	if a.conn == nil {
+		open := data.Open
+		if a.readOnly {
+			open = data.OpenReadOnly
+		}
+		conn, connErr := open(a.dbPath)
+		if connErr != nil {
+			return nil, connErr
+		}
+		a.conn = conn
+	}
+	return a.conn, nil
+}
+
+func (a *app) services() (*services.UserServices, error) {
+	conn, connErr := a.open()
+	if connErr != nil {
+		return nil, connErr
+	}
+
+	passwords, passwordsErr := password.NewChecker(password.PolicyFromEnv(), os.Getenv(config.BreachedPasswordsFileEnv))
+	if passwordsErr != nil {
+		return nil, passwordsErr
+	}
+	hasher, hasherErr := password.HasherFromEnv()
+	if hasherErr != nil {
+		return nil, hasherErr
+	}
+
+	return &services.UserServices{
+		DB:              conn,
+		Repo:            repository.UserRepository{DB: conn},
+		Passwords:       passwords,
+		Hasher:          &hasher,
+		ImportBatchSize: config.EnvInt(config.ImportBatchSizeEnv, config.DefaultImportBatchSize),
+		BackupDir:       a.backupDir(),
+		BackupRetention: config.EnvInt(config.BackupRetentionEnv, config.DefaultBackupRetention),
+	}, nil
+}
+
+func (a *app) context() context.Context {
+	ctx := services.WithTenant(context.Background(), a.tenant)
+	return context.WithValue(ctx, config.AuditContextKey, models.AuditContext{Actor: config.CLIActor})
+}
+
+func (a *app) backupDir() string {
+	return config.EnvString(config.BackupDirEnv, config.DefaultBackupDir)
+}
+
+func (a *app) close() {
+	if a.conn != nil {
+		a.conn.Close()
+		a.conn = nil
+	}
+}
+
+func (a *app) print(value any, table func(w *tabwriter.Writer)) error {
+	if a.output == config.CLIOutputJSON {
+		encoder := json.NewEncoder(a.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+func (a *app) printUsers(users []models.User) error {
+	return a.print(users, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "ID\tUSERNAME\tNAME\tSURNAME\tEMAIL\tVERSION\tCREATED_AT\tLAST_LOGIN_AT")
+		for _, user := range users {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n", user.ID, user.Username, user.Name, user.Surname, user.Email, user.Version, formatTime(&user.CreatedAt), formatTime(user.LastLoginAt))
+		}
+	})
+}
+
+func (a *app) printUser(user models.User) error {
+	return a.print(user, func(w *tabwriter.Writer) {
+		rows := [][2]string{
+			{"ID", user.ID},
+			{"USERNAME", user.Username},
+			{"NAME", user.Name},
+			{"SURNAME", user.Surname},
+			{"EMAIL", user.Email},
+			{"VERSION", strconv.Itoa(user.Version)},
+			{"CREATED_AT", formatTime(&user.CreatedAt)},
+			{"UPDATED_AT", formatTime(&user.UpdatedAt)},
+			{"LAST_LOGIN_AT", formatTime(user.LastLoginAt)},
+			{"PASSWORD_CHANGED_AT", formatTime(user.PasswordChangedAt)},
+		}
+		for _, row := range rows {
+			fmt.Fprintf(w, "%s\t%s\n", row[0], row[1])
+		}
+	})
+}
+
+func (a *app) printBackups(backups []models.Backup) error {
+	return a.print(backups, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "NAME\tSIZE\tCREATED_AT")
+		for _, backup := range backups {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", backup.Name, backup.Size, formatTime(&backup.CreatedAt))
+		}
+	})
+}
+
+func (a *app) printMessage(message string, fields map[string]any) error {
+	value := map[string]any{"message": message}
+	for key, field := range fields {
+		value[key] = field
+	}
+	return a.print(value, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, message)
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%v\n", strings.ToUpper(key), fields[key])
+		}
+	})
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
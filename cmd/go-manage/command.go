@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go-manage/cmd/config"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name     string
+	usage    string
+	summary  string
+	commands []*command
+	flags    func(fs *flag.FlagSet)
+	run      func(app *app, fs *flag.FlagSet) error
+}
+
+func (c *command) execute(app *app, path string, args []string) error {
+	if c.commands != nil {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			c.help(path)
+			return nil
+		}
+		for _, sub := range c.commands {
+			if sub.name == args[0] {
+				return sub.execute(app, path+" "+sub.name, args[1:])
+			}
+		}
+		c.help(path)
+		return fmt.Errorf("%w %q", config.ErrUnknownCommand, args[0])
+	}
+
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n\n%s\n\nFlags:\n", path, c.usage, c.summary)
+		fs.PrintDefaults()
+	}
+	app.register(fs)
+	if c.flags != nil {
+		c.flags(fs)
+	}
+	if err := parseInterspersed(fs, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	return c.run(app, fs)
+}
+
+func (c *command) help(path string) {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\n", path)
+	if c.summary != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, sub := range c.commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", sub.name, sub.summary)
+	}
+}
+
+func parseInterspersed(fs *flag.FlagSet, args []string) error {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		if args[0] == "--" {
+			positional = append(positional, args[1:]...)
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return fs.Parse(append([]string{"--"}, positional...))
+}
+
+func argument(fs *flag.FlagSet, name string) (string, error) {
+	value := strings.TrimSpace(fs.Arg(0))
+	if value == "" {
+		return "", fmt.Errorf("%w <%s>", config.ErrMissingArgument, name)
+	}
+	return value, nil
+}
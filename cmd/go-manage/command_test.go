@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go-manage/cmd/config"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testApp(t *testing.T) string {
+	t.Setenv(config.PasswordHashEnv, config.HashBcrypt)
+	t.Setenv(config.BcryptCostEnv, "4")
+	t.Setenv(config.BackupDirEnv, filepath.Join(t.TempDir(), "backups"))
+	t.Setenv(config.DBPathEnv, filepath.Join(t.TempDir(), "users.db"))
+	return config.EnvString(config.DBPathEnv, config.DBPath)
+}
+
+func runCLI(args ...string) (string, error) {
+	var out bytes.Buffer
+	cli := &app{out: &out}
+	defer cli.close()
+
+	root := rootCommand()
+	err := root.execute(cli, root.name, args)
+	return out.String(), err
+}
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		Name               string
+		Args               []string
+		ExpectedPositional []string
+		ExpectedName       string
+		ExpectedForce      bool
+		ExpectedErr        string
+	}{
+		{
+			Name:               "Flags before the argument",
+			Args:               []string{"--name", "John", "johndoe"},
+			ExpectedPositional: []string{"johndoe"},
+			ExpectedName:       "John",
+		},
+		{
+			Name:               "Flags after the argument",
+			Args:               []string{"johndoe", "--name=John", "--force"},
+			ExpectedPositional: []string{"johndoe"},
+			ExpectedName:       "John",
+			ExpectedForce:      true,
+		},
+		{
+			Name:               "Everything after the terminator is positional",
+			Args:               []string{"johndoe", "--", "--name", "John"},
+			ExpectedPositional: []string{"johndoe", "--name", "John"},
+		},
+		{
+			Name:        "Unknown flag",
+			Args:        []string{"johndoe", "--nope"},
+			ExpectedErr: "flag provided but not defined: -nope",
+		},
+		{
+			Name:        "Missing flag value",
+			Args:        []string{"johndoe", "--name"},
+			ExpectedErr: "flag needs an argument: -name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(&bytes.Buffer{})
+			name := fs.String("name", "", "")
+			force := fs.Bool("force", false, "")
+
+			err := parseInterspersed(fs, tt.Args)
+
+			if tt.ExpectedErr != "" {
+				assert.EqualError(t, err, tt.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.ExpectedPositional, fs.Args())
+			assert.Equal(t, tt.ExpectedName, *name)
+			assert.Equal(t, tt.ExpectedForce, *force)
+		})
+	}
+}
+
+func TestArgument(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Args          []string
+		ExpectedValue string
+		ExpectedErr   error
+	}{
+		{Name: "Present", Args: []string{"johndoe"}, ExpectedValue: "johndoe"},
+		{Name: "Trimmed", Args: []string{"  johndoe "}, ExpectedValue: "johndoe"},
+		{Name: "Missing", ExpectedErr: config.ErrMissingArgument},
+		{Name: "Blank", Args: []string{"  "}, ExpectedErr: config.ErrMissingArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			require.NoError(t, fs.Parse(tt.Args))
+
+			value, err := argument(fs, "username")
+
+			assert.ErrorIs(t, err, tt.ExpectedErr)
+			assert.Equal(t, tt.ExpectedValue, value)
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	testApp(t)
+
+	tests := []struct {
+		Name        string
+		Args        []string
+		ExpectedErr error
+	}{
+		{Name: "Root help", Args: []string{}},
+		{Name: "Group help", Args: []string{"user", "--help"}},
+		{Name: "Command help", Args: []string{"user", "list", "-h"}},
+		{Name: "Unknown command", Args: []string{"nope"}, ExpectedErr: config.ErrUnknownCommand},
+		{Name: "Unknown subcommand", Args: []string{"user", "nope"}, ExpectedErr: config.ErrUnknownCommand},
+		{Name: "Invalid output", Args: []string{"user", "list", "--output", "xml"}, ExpectedErr: config.ErrInvalidOutput},
+		{Name: "Missing argument", Args: []string{"user", "get"}, ExpectedErr: config.ErrMissingArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := runCLI(tt.Args...)
+
+			assert.ErrorIs(t, err, tt.ExpectedErr)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/models"
+	"go-manage/internal/services"
+	"os"
+)
+
+func dbCommand() *command {
+	return &command{
+		name:    "db",
+		summary: "Database maintenance",
+		commands: []*command{
+			dbMigrateCommand(),
+			dbBackupCommand(),
+			dbBackupsCommand(),
+			dbRestoreCommand(),
+		},
+	}
+}
+
+func dbMigrateCommand() *command {
+	return &command{
+		name:    "migrate",
+		summary: "Create the database if needed and apply pending migrations.",
+		run: func(app *app, fs *flag.FlagSet) error {
+			conn, err := app.open()
+			if err != nil {
+				return err
+			}
+
+			version, versionErr := data.SchemaVersion(conn)
+			if versionErr != nil {
+				return versionErr
+			}
+			return app.printMessage(config.MigrateMessage, map[string]any{"database": app.dbPath, "version": version})
+		},
+	}
+}
+
+func dbBackupCommand() *command {
+	var output string
+	return &command{
+		name:    "backup",
+		usage:   "[--out PATH]",
+		summary: "Take a consistent snapshot into the backup directory, or into --out, and verify its integrity.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&output, "out", "", "write a one-off backup to this file instead of the backup directory (must not exist)")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				backup, backupErr := userService.CreateBackup(app.context())
+				if backupErr != nil {
+					return backupErr
+				}
+				return app.printBackups([]models.Backup{backup})
+			}
+
+			if backupErr := data.Backup(app.conn, output); backupErr != nil {
+				return backupErr
+			}
+			if checkErr := data.CheckIntegrity(output); checkErr != nil {
+				os.Remove(output)
+				return checkErr
+			}
+			return app.printMessage(config.BackupMessage, map[string]any{"database": app.dbPath, "backup": output})
+		},
+	}
+}
+
+func dbBackupsCommand() *command {
+	return &command{
+		name:    "backups",
+		summary: "List the snapshots in the backup directory, newest first.",
+		run: func(app *app, fs *flag.FlagSet) error {
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			backups, listErr := userService.ListBackups(app.context())
+			if listErr != nil {
+				return listErr
+			}
+			return app.printBackups(backups)
+		},
+	}
+}
+
+func dbRestoreCommand() *command {
+	var snapshot bool
+	return &command{
+		name:    "restore",
+		usage:   "<backup name | path> [--snapshot=false]",
+		summary: "Replace the database with a verified backup. Stop the API first: open connections keep using the replaced file.",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&snapshot, "snapshot", true, "back up the current database before replacing it")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			source, argErr := argument(fs, "backup")
+			if argErr != nil {
+				return argErr
+			}
+
+			userService := &services.UserServices{BackupDir: app.backupDir()}
+			if _, statErr := os.Stat(source); statErr != nil {
+				if source, argErr = userService.BackupPath(source); argErr != nil {
+					return argErr
+				}
+			}
+
+			fields := map[string]any{"database": app.dbPath, "backup": source}
+			if _, statErr := os.Stat(app.dbPath); snapshot && statErr == nil {
+				current, err := app.services()
+				if err != nil {
+					return err
+				}
+				backup, backupErr := current.CreateRestorePoint(app.context())
+				if backupErr != nil {
+					return backupErr
+				}
+				fields["snapshot"] = backup.Name
+				app.close()
+			}
+
+			if restoreErr := data.Restore(source, app.dbPath); restoreErr != nil {
+				return restoreErr
+			}
+			return app.printMessage(config.RestoreDBMessage, fields)
+		},
+	}
+}
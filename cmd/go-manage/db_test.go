@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBCommands(t *testing.T) {
+	dbPath := testApp(t)
+	snapshot := filepath.Join(t.TempDir(), "snapshot.db")
+
+	tests := []struct {
+		Name             string
+		Args             []string
+		ExpectedErr      string
+		ExpectedContains []string
+	}{
+		{
+			Name:             "Migrate",
+			Args:             []string{"db", "migrate"},
+			ExpectedContains: []string{config.MigrateMessage, dbPath},
+		},
+		{
+			Name: "Create a user",
+			Args: []string{"user", "create", "--username", "johndoe", "--name", "John", "--surname", "Doe", "--email", "johndoe@example.com", "--password", "Sup3r-Secret-pass"},
+		},
+		{
+			Name:             "Backup to the backup directory",
+			Args:             []string{"db", "backup"},
+			ExpectedContains: []string{"NAME", ".db"},
+		},
+		{
+			Name:             "Backup to a file",
+			Args:             []string{"db", "backup", "--out", snapshot},
+			ExpectedContains: []string{config.BackupMessage, snapshot},
+		},
+		{
+			Name:        "Backup to an existing file",
+			Args:        []string{"db", "backup", "--out", snapshot},
+			ExpectedErr: config.ErrBackupExists.Error(),
+		},
+		{
+			Name: "Delete the user",
+			Args: []string{"user", "delete", "johndoe"},
+		},
+		{
+			Name:             "Restore",
+			Args:             []string{"db", "restore", snapshot},
+			ExpectedContains: []string{config.RestoreDBMessage, "SNAPSHOT"},
+		},
+		{
+			Name:             "Restored user",
+			Args:             []string{"user", "get", "johndoe"},
+			ExpectedContains: []string{"johndoe@example.com"},
+		},
+		{
+			Name:        "Restore an unknown backup",
+			Args:        []string{"db", "restore", "missing.db"},
+			ExpectedErr: config.ErrBackupNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := runCLI(tt.Args...)
+
+			if tt.ExpectedErr != "" {
+				assert.ErrorContains(t, err, tt.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, expected := range tt.ExpectedContains {
+				assert.Contains(t, out, expected)
+			}
+		})
+	}
+
+	out, err := runCLI("db", "backups", "--output", "json")
+	require.NoError(t, err)
+	var backups []models.Backup
+	require.NoError(t, json.Unmarshal([]byte(out), &backups))
+	assert.Len(t, backups, 2)
+
+	_, statErr := os.Stat(dbPath)
+	assert.NoError(t, statErr)
+}
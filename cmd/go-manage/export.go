@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/exporter"
+	"go-manage/internal/models"
+	"os"
+	"time"
+)
+
+func userExportCommand() *command {
+	var format, output, order string
+	var filter models.UserFilter
+	ranges := map[string]*[2]string{}
+	return &command{
+		name:    "export",
+		usage:   "[--format csv|ndjson|xlsx] [--out PATH] [--sort FIELD] [--order asc|desc] [--limit N] [--offset N] [--<field>_after T] [--<field>_before T]",
+		summary: "Stream the users of the tenant as CSV, NDJSON or XLSX. The database is opened read-only.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&format, "format", config.ExportFormatCSV, "output format: csv, ndjson or xlsx")
+			fs.StringVar(&output, "out", "", "output file (defaults to stdout)")
+			fs.StringVar(&filter.Sort, "sort", "", "sort field")
+			fs.StringVar(&order, "order", "", "sort order (asc or desc)")
+			fs.IntVar(&filter.Limit, "limit", 0, "maximum number of users (0 exports all)")
+			fs.IntVar(&filter.Offset, "offset", 0, "number of users to skip")
+			for _, field := range config.UserTimestampFields {
+				bounds := &[2]string{}
+				ranges[field] = bounds
+				fs.StringVar(&bounds[0], field+"_after", "", "only users with "+field+" at or after this RFC3339 time")
+				fs.StringVar(&bounds[1], field+"_before", "", "only users with "+field+" before this RFC3339 time")
+			}
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			filter.Desc = order == "desc"
+			for _, field := range config.UserTimestampFields {
+				timeRange := models.TimeRange{Field: field}
+				var err error
+				if timeRange.After, err = parseTime(ranges[field][0]); err != nil {
+					return fmt.Errorf("invalid %s_after: %w", field, err)
+				}
+				if timeRange.Before, err = parseTime(ranges[field][1]); err != nil {
+					return fmt.Errorf("invalid %s_before: %w", field, err)
+				}
+				if timeRange.After != nil || timeRange.Before != nil {
+					filter.Ranges = append(filter.Ranges, timeRange)
+				}
+			}
+
+			app.readOnly = true
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			out := app.out
+			if output != "" {
+				file, createErr := os.Create(output)
+				if createErr != nil {
+					return createErr
+				}
+				defer file.Close()
+				out = file
+			}
+
+			writer, writerErr := exporter.NewWriter(format, out)
+			if writerErr != nil {
+				return writerErr
+			}
+
+			exported := 0
+			exportErr := userService.ExportUsers(app.context(), filter, func(user models.User) error {
+				exported++
+				return writer.Write(user)
+			})
+			if exportErr != nil {
+				return exportErr
+			}
+			if closeErr := writer.Close(); closeErr != nil {
+				return closeErr
+			}
+
+			fmt.Fprintf(os.Stderr, "%s: %d\n", config.ExportMessage, exported)
+			return nil
+		},
+	}
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"os"
+)
+
+func main() {
+	root := rootCommand()
+
+	app := &app{out: os.Stdout}
+	err := root.execute(app, root.name, os.Args[1:])
+	app.close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func rootCommand() *command {
+	return &command{
+		name:    config.CLIName,
+		summary: "Administer go-manage users and database without going through the HTTP API.",
+		commands: []*command{
+			userCommand(),
+			dbCommand(),
+			seedCommand(),
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/importer"
+	"go-manage/internal/models"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+)
+
+func seedCommand() *command {
+	var file, prefix, password string
+	var count int
+	var generated bool
+	var options models.ImportOptions
+	return &command{
+		name:    "seed",
+		usage:   "[--file users.csv|users.ndjson] [--count N --prefix P] [--on-conflict skip|update|fail] [--dry-run]",
+		summary: "Load users from a CSV/NDJSON file, or generate N demo users when no file is given.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&file, "file", "", "CSV or NDJSON file to load")
+			fs.IntVar(&count, "count", config.DefaultSeedCount, "number of demo users to generate when --file is empty")
+			fs.StringVar(&prefix, "prefix", "demo", "username prefix for generated users")
+			fs.StringVar(&password, "password", "", "password for generated users (random and printed when empty)")
+			fs.StringVar(&options.OnConflict, "on-conflict", config.ImportConflictSkip, "what to do with existing users: skip, update or fail")
+			fs.BoolVar(&options.DryRun, "dry-run", false, "validate without saving")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			var reader importer.Reader
+			if file != "" {
+				body, openErr := os.Open(file)
+				if openErr != nil {
+					return openErr
+				}
+				defer body.Close()
+
+				mediaType := config.CSVMediaType
+				if ext := strings.ToLower(filepath.Ext(file)); ext == ".ndjson" || ext == ".jsonl" {
+					mediaType = config.NDJSONMediaType
+				}
+				if reader, err = importer.NewReader(mediaType, body); err != nil {
+					return err
+				}
+			} else {
+				if password == "" {
+					password = "Seed-" + uuid.New().String()
+					generated = true
+				}
+				reader = &demoReader{count: count, prefix: prefix, password: password}
+			}
+
+			report, importErr := userService.ImportUsers(app.context(), reader, options)
+			if importErr != nil {
+				return importErr
+			}
+			seeded := seedReport{ImportReport: report}
+			if generated && !report.DryRun && report.Created > 0 {
+				seeded.Password = password
+			}
+			return app.printReport(seeded)
+		},
+	}
+}
+
+type demoReader struct {
+	count    int
+	prefix   string
+	password string
+	row      int
+}
+
+func (dr *demoReader) Next() (importer.Record, error) {
+	if dr.row >= dr.count {
+		return importer.Record{}, io.EOF
+	}
+	dr.row++
+
+	username := fmt.Sprintf("%s%03d", dr.prefix, dr.row)
+	return importer.Record{Row: dr.row, Request: models.CreateUserRequest{
+		Name:     "Demo",
+		Surname:  fmt.Sprintf("User %d", dr.row),
+		Username: username,
+		Email:    username + "@" + config.SeedEmailDomain,
+		Password: dr.password,
+	}}, nil
+}
+
+type seedReport struct {
+	models.ImportReport
+	Password string `json:"password,omitempty"`
+}
+
+func (a *app) printReport(report seedReport) error {
+	return a.print(report, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "TOTAL\t%d\nCREATED\t%d\nUPDATED\t%d\nSKIPPED\t%d\nFAILED\t%d\nPASSWORDS_IGNORED\t%d\n", report.Total, report.Created, report.Updated, report.Skipped, report.Failed, report.PasswordsIgnored)
+		if report.DryRun {
+			fmt.Fprintln(w, config.ImportDryRunMsg)
+		}
+		for _, importErr := range report.Errors {
+			fmt.Fprintf(w, "row %d\t%s\t%s\n", importErr.Row, importErr.Username, importErr.Error)
+		}
+		if report.Password != "" {
+			fmt.Fprintf(w, "PASSWORD\t%s\n%s\n", report.Password, config.SeedPasswordMsg)
+		}
+	})
+}
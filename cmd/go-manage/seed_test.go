@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedCommand(t *testing.T) {
+	testApp(t)
+	file := filepath.Join(t.TempDir(), "users.csv")
+	require.NoError(t, os.WriteFile(file, []byte("name,surname,username,email,password\nJohn,Doe,johndoe,johndoe@example.com,Sup3r-Secret-pass\nJane,Doe,janedoe,not-an-email,Sup3r-Secret-pass\n"), 0o600))
+
+	tests := []struct {
+		Name             string
+		Args             []string
+		ExpectedErr      string
+		ExpectedCreated  int
+		ExpectedSkipped  int
+		ExpectedFailed   int
+		ExpectedPassword bool
+		ExpectedLogin    string
+	}{
+		{
+			Name:            "Dry run",
+			Args:            []string{"seed", "--count", "2", "--dry-run"},
+			ExpectedCreated: 2,
+		},
+		{
+			Name:             "Generated password is printed",
+			Args:             []string{"seed", "--count", "2"},
+			ExpectedCreated:  2,
+			ExpectedPassword: true,
+			ExpectedLogin:    "demo001",
+		},
+		{
+			Name:            "Existing users are skipped",
+			Args:            []string{"seed", "--count", "2"},
+			ExpectedSkipped: 2,
+		},
+		{
+			Name:            "Given password is not printed",
+			Args:            []string{"seed", "--count", "1", "--prefix", "demo-pw", "--password", "Sup3r-Secret-pass"},
+			ExpectedCreated: 1,
+		},
+		{
+			Name:            "File",
+			Args:            []string{"seed", "--file", file},
+			ExpectedCreated: 1,
+			ExpectedFailed:  1,
+		},
+		{
+			Name:        "Missing file",
+			Args:        []string{"seed", "--file", filepath.Join(t.TempDir(), "missing.csv")},
+			ExpectedErr: "missing.csv",
+		},
+		{
+			Name:        "Invalid conflict mode",
+			Args:        []string{"seed", "--on-conflict", "merge"},
+			ExpectedErr: config.ErrInvalidConflictMode.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := runCLI(append(tt.Args, "--output", "json")...)
+
+			if tt.ExpectedErr != "" {
+				assert.ErrorContains(t, err, tt.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var report seedReport
+			require.NoError(t, json.Unmarshal([]byte(out), &report))
+			assert.Equal(t, tt.ExpectedCreated, report.Created)
+			assert.Equal(t, tt.ExpectedSkipped, report.Skipped)
+			assert.Equal(t, tt.ExpectedFailed, report.Failed)
+			assert.Equal(t, tt.ExpectedPassword, report.Password != "")
+
+			if tt.ExpectedLogin != "" {
+				cli := &app{dbPath: config.EnvString(config.DBPathEnv, config.DBPath), tenant: config.DefaultTenant, output: config.CLIOutputTable}
+				defer cli.close()
+				userService, servicesErr := cli.services()
+				require.NoError(t, servicesErr)
+				_, loginErr := userService.Login(cli.context(), models.LoginRequest{Username: tt.ExpectedLogin, Password: report.Password})
+				assert.NoError(t, loginErr)
+			}
+		})
+	}
+}
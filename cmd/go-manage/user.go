@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/services"
+	"os"
+	"strings"
+)
+
+func userCommand() *command {
+	return &command{
+		name:    "user",
+		summary: "Manage user accounts",
+		commands: []*command{
+			userCreateCommand(),
+			userGetCommand(),
+			userListCommand(),
+			userUpdateCommand(),
+			userDeleteCommand(),
+			userSetPasswordCommand(),
+			userExportCommand(),
+		},
+	}
+}
+
+func userCreateCommand() *command {
+	var request models.CreateUserRequest
+	return &command{
+		name:    "create",
+		usage:   "--username U --name N --surname S --email E [--password P]",
+		summary: "Create a user. The password is read from stdin when --password is omitted.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&request.Username, "username", "", "username")
+			fs.StringVar(&request.Name, "name", "", "first name")
+			fs.StringVar(&request.Surname, "surname", "", "surname")
+			fs.StringVar(&request.Email, "email", "", "email address")
+			fs.StringVar(&request.Password, "password", "", "password (read from stdin when empty)")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+			if request.Password, err = readPassword(request.Password); err != nil {
+				return err
+			}
+
+			created, createErr := userService.CreateUser(app.context(), request)
+			if createErr != nil {
+				return createErr
+			}
+			return app.printUser(created)
+		},
+	}
+}
+
+func userGetCommand() *command {
+	var id string
+	return &command{
+		name:    "get",
+		usage:   "<username> | --id ID",
+		summary: "Show a single user by username or ID.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&id, "id", "", "search by user ID instead of username")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			var user models.User
+			if id != "" {
+				user, err = userService.SearchUserByID(app.context(), id)
+			} else {
+				username, argErr := argument(fs, "username")
+				if argErr != nil {
+					return argErr
+				}
+				user, err = userService.SearchUser(app.context(), username)
+			}
+			if err != nil {
+				return err
+			}
+			return app.printUser(user)
+		},
+	}
+}
+
+func userListCommand() *command {
+	var filter models.UserFilter
+	var order string
+	return &command{
+		name:    "list",
+		usage:   "[--sort FIELD] [--order asc|desc] [--limit N] [--offset N]",
+		summary: "List users of the tenant.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&filter.Sort, "sort", "", "sort field")
+			fs.StringVar(&order, "order", "", "sort order (asc or desc)")
+			fs.IntVar(&filter.Limit, "limit", config.DefaultListLimit, "maximum number of users")
+			fs.IntVar(&filter.Offset, "offset", 0, "number of users to skip")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			filter.Desc = order == "desc"
+			users, listErr := userService.ListUsers(app.context(), filter)
+			if listErr != nil {
+				return listErr
+			}
+			return app.printUsers(users)
+		},
+	}
+}
+
+func userUpdateCommand() *command {
+	var name, surname, username, email string
+	var version int
+	return &command{
+		name:    "update",
+		usage:   "<username> [--name N] [--surname S] [--username U] [--email E] [--version V]",
+		summary: "Update the given fields of a user. Only flags that are set are changed.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&name, "name", "", "new first name")
+			fs.StringVar(&surname, "surname", "", "new surname")
+			fs.StringVar(&username, "username", "", "new username")
+			fs.StringVar(&email, "email", "", "new email address")
+			fs.IntVar(&version, "version", 0, "expected version (defaults to the current one)")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			current, argErr := argument(fs, "username")
+			if argErr != nil {
+				return argErr
+			}
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			var patch models.UpdateUserRequest
+			fs.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "name":
+					patch.Name = &name
+				case "surname":
+					patch.Surname = &surname
+				case "username":
+					patch.Username = &username
+				case "email":
+					patch.Email = &email
+				}
+			})
+
+			if version, err = currentVersion(app, userService, current, version); err != nil {
+				return err
+			}
+			updated, updateErr := userService.UpdateUser(app.context(), current, version, patch)
+			if updateErr != nil {
+				return updateErr
+			}
+			return app.printUser(updated)
+		},
+	}
+}
+
+func userDeleteCommand() *command {
+	var version int
+	return &command{
+		name:    "delete",
+		usage:   "<username> [--version V]",
+		summary: "Soft delete a user.",
+		flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&version, "version", 0, "expected version (defaults to the current one)")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			username, argErr := argument(fs, "username")
+			if argErr != nil {
+				return argErr
+			}
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+
+			if version, err = currentVersion(app, userService, username, version); err != nil {
+				return err
+			}
+			if deleteErr := userService.DeleteUser(app.context(), username, version); deleteErr != nil {
+				return deleteErr
+			}
+			return app.printMessage(config.DeleteMessage, map[string]any{"username": username})
+		},
+	}
+}
+
+func userSetPasswordCommand() *command {
+	var password string
+	return &command{
+		name:    "set-password",
+		usage:   "<username> [--password P]",
+		summary: "Set a new password for a user. The password is read from stdin when --password is omitted.",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&password, "password", "", "new password (read from stdin when empty)")
+		},
+		run: func(app *app, fs *flag.FlagSet) error {
+			username, argErr := argument(fs, "username")
+			if argErr != nil {
+				return argErr
+			}
+			userService, err := app.services()
+			if err != nil {
+				return err
+			}
+			if password, err = readPassword(password); err != nil {
+				return err
+			}
+
+			if changeErr := userService.ChangeUserPwd(app.context(), username, password); changeErr != nil {
+				return changeErr
+			}
+			return app.printMessage(config.ChangePwdMessage, map[string]any{"username": username})
+		},
+	}
+}
+
+func currentVersion(app *app, userService *services.UserServices, username string, version int) (int, error) {
+	if version > 0 {
+		return version, nil
+	}
+	user, searchErr := userService.SearchUser(app.context(), username)
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	return user.Version, nil
+}
+
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, readErr := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		if readErr != nil {
+			return "", readErr
+		}
+		return "", fmt.Errorf("%w <password>", config.ErrMissingArgument)
+	}
+	return line, nil
+}
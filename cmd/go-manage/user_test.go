@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserCommands(t *testing.T) {
+	testApp(t)
+	exported := filepath.Join(t.TempDir(), "users.ndjson")
+
+	tests := []struct {
+		Name             string
+		Args             []string
+		ExpectedErr      string
+		ExpectedContains []string
+		ExpectedMissing  []string
+	}{
+		{
+			Name:             "Create",
+			Args:             []string{"user", "create", "--username", "johndoe", "--name", "John", "--surname", "Doe", "--email", "johndoe@example.com", "--password", "Sup3r-Secret-pass"},
+			ExpectedContains: []string{"USERNAME", "johndoe", "johndoe@example.com"},
+		},
+		{
+			Name:        "Create duplicate",
+			Args:        []string{"user", "create", "--username", "johndoe", "--name", "John", "--surname", "Doe", "--email", "johndoe@example.com", "--password", "Sup3r-Secret-pass"},
+			ExpectedErr: config.ErrUserAlreadyExists.Error(),
+		},
+		{
+			Name:        "Create with a weak password",
+			Args:        []string{"user", "create", "--username", "janedoe", "--name", "Jane", "--surname", "Doe", "--email", "janedoe@example.com", "--password", "weak"},
+			ExpectedErr: config.ErrInvalidPassword.Error(),
+		},
+		{
+			Name:             "Get as JSON",
+			Args:             []string{"user", "get", "johndoe", "--output", "json"},
+			ExpectedContains: []string{`"username": "johndoe"`},
+			ExpectedMissing:  []string{"password\":", "$2a$"},
+		},
+		{
+			Name:        "Get unknown user",
+			Args:        []string{"user", "get", "nobody"},
+			ExpectedErr: config.ErrUserNotFound.Error(),
+		},
+		{
+			Name:             "List",
+			Args:             []string{"user", "list", "--sort", "username"},
+			ExpectedContains: []string{"ID", "johndoe"},
+			ExpectedMissing:  []string{"$2a$"},
+		},
+		{
+			Name:             "Update",
+			Args:             []string{"user", "update", "johndoe", "--surname", "Smith"},
+			ExpectedContains: []string{"Smith", "VERSION"},
+		},
+		{
+			Name:        "Update with a stale version",
+			Args:        []string{"user", "update", "johndoe", "--surname", "Doe", "--version", "1"},
+			ExpectedErr: config.ErrVersionMismatch.Error(),
+		},
+		{
+			Name:             "Set password",
+			Args:             []string{"user", "set-password", "johndoe", "--password", "An0ther-Secret-pass"},
+			ExpectedContains: []string{config.ChangePwdMessage},
+		},
+		{
+			Name:        "Set password of an unknown user",
+			Args:        []string{"user", "set-password", "nobody", "--password", "An0ther-Secret-pass"},
+			ExpectedErr: config.ErrUserNotFound.Error(),
+		},
+		{
+			Name:             "Export to stdout",
+			Args:             []string{"user", "export", "--format", config.ExportFormatCSV, "--created_at_after", "2000-01-01T00:00:00Z"},
+			ExpectedContains: []string{"id,name,surname,username", "johndoe"},
+			ExpectedMissing:  []string{"$2a$"},
+		},
+		{
+			Name: "Export to a file",
+			Args: []string{"user", "export", "--format", config.ExportFormatNDJSON, "--out", exported},
+		},
+		{
+			Name:        "Export with an invalid range",
+			Args:        []string{"user", "export", "--created_at_after", "yesterday"},
+			ExpectedErr: "invalid created_at_after",
+		},
+		{
+			Name:             "Delete",
+			Args:             []string{"user", "delete", "johndoe"},
+			ExpectedContains: []string{config.DeleteMessage},
+		},
+		{
+			Name:        "Get deleted user",
+			Args:        []string{"user", "get", "johndoe"},
+			ExpectedErr: config.ErrUserNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := runCLI(tt.Args...)
+
+			if tt.ExpectedErr != "" {
+				assert.ErrorContains(t, err, tt.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, expected := range tt.ExpectedContains {
+				assert.Contains(t, out, expected)
+			}
+			for _, missing := range tt.ExpectedMissing {
+				assert.NotContains(t, out, missing)
+			}
+		})
+	}
+
+	body, readErr := os.ReadFile(exported)
+	require.NoError(t, readErr)
+	var user models.User
+	require.NoError(t, json.Unmarshal(body, &user))
+	assert.Equal(t, "johndoe", user.Username)
+	assert.Equal(t, "Smith", user.Surname)
+}
@@ -4,18 +4,23 @@ import (
 	"database/sql"
 	"fmt"
 	"go-manage/cmd/config"
+	"io"
 	"os"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func InitDatabase() (*sql.DB, error) {
+	return Open(config.EnvString(config.DBPathEnv, config.DBPath))
+}
+
+func Open(dbPath string) (*sql.DB, error) {
 	var conn *sql.DB
 	var connErr error
 
-	if !exists(config.DBPath) {
-		fmt.Println("DATABASE DOESN'T EXIST. CREATING....")
-		conn, connErr = sql.Open(config.DBDriver, config.DBPath)
+	if !exists(dbPath) {
+		fmt.Fprintln(os.Stderr, "DATABASE DOESN'T EXIST. CREATING....")
+		conn, connErr = sql.Open(config.DBDriver, dbPath)
 		if connErr != nil {
 			return nil, connErr
 		}
@@ -23,16 +28,106 @@ func InitDatabase() (*sql.DB, error) {
 			return nil, createTableErr
 		}
 	} else {
-		fmt.Println("DATABASE FOUND. USING DATABASE....")
-		conn, connErr = sql.Open(config.DBDriver, config.DBPath)
+		fmt.Fprintln(os.Stderr, "DATABASE FOUND. USING DATABASE....")
+		conn, connErr = sql.Open(config.DBDriver, dbPath)
 		if connErr != nil {
 			return nil, connErr
 		}
 	}
 
+	if migrateErr := migrate(conn, config.Migrations); migrateErr != nil {
+		return nil, migrateErr
+	}
+
 	return conn, nil
 }
 
+func OpenReadOnly(dbPath string) (*sql.DB, error) {
+	if !exists(dbPath) {
+		return nil, fmt.Errorf("database %s not found", dbPath)
+	}
+	return sql.Open(config.DBDriver, "file:"+dbPath+"?mode=ro")
+}
+
+func SchemaVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow(config.CurrentMigrationQuery).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error reading schema version. Error: %w", err)
+	}
+	return version, nil
+}
+
+func Backup(db *sql.DB, destination string) error {
+	if exists(destination) {
+		return config.ErrBackupExists
+	}
+	if _, err := db.Exec(config.BackupQuery, destination); err != nil {
+		return fmt.Errorf("error backing up database. Error: %w", err)
+	}
+	return nil
+}
+
+func CheckIntegrity(dbPath string) error {
+	conn, connErr := OpenReadOnly(dbPath)
+	if connErr != nil {
+		return connErr
+	}
+	defer conn.Close()
+
+	var result string
+	if err := conn.QueryRow(config.IntegrityCheckQuery).Scan(&result); err != nil {
+		return fmt.Errorf("%w. Error: %s", config.ErrBackupCorrupt, err.Error())
+	}
+	if result != config.IntegrityOK {
+		return fmt.Errorf("%w. Error: %s", config.ErrBackupCorrupt, result)
+	}
+	return nil
+}
+
+func Restore(source, target string) error {
+	if !exists(source) {
+		return config.ErrBackupNotFound
+	}
+	if err := CheckIntegrity(source); err != nil {
+		return err
+	}
+
+	temp := target + ".restore"
+	if err := copyFile(source, temp); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("error copying backup. Error: %w", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(target + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(temp)
+			return err
+		}
+	}
+	return os.Rename(temp, target)
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
 func exists(dbPath string) bool {
 	_, err := os.Stat(dbPath)
 	if err == nil {
@@ -51,3 +146,34 @@ func createTable(db *sql.DB, tableQuery string) error {
 	}
 	return nil
 }
+
+func migrate(db *sql.DB, migrations []string) error {
+	if _, err := db.Exec(config.CreateMigrationsTableQuery); err != nil {
+		return fmt.Errorf("error creating migrations table. Error: %w", err)
+	}
+
+	current, versionErr := SchemaVersion(db)
+	if versionErr != nil {
+		return versionErr
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, txErr := db.Begin()
+		if txErr != nil {
+			return txErr
+		}
+		if _, err := tx.Exec(migrations[version-1]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error applying migration %d. Error: %w", version, err)
+		}
+		if _, err := tx.Exec(config.SaveMigrationQuery, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error saving migration %d. Error: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
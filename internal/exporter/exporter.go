@@ -0,0 +1,135 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Writer interface {
+	Write(user models.User) error
+	Close() error
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case config.ExportFormatCSV:
+		return newCSVWriter(w)
+	case config.ExportFormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case config.ExportFormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, config.ErrInvalidExportFormat
+	}
+}
+
+func MediaType(format string) string {
+	switch format {
+	case config.ExportFormatCSV:
+		return config.CSVMediaType
+	case config.ExportFormatNDJSON:
+		return config.NDJSONMediaType
+	case config.ExportFormatXLSX:
+		return config.XLSXMediaType
+	default:
+		return ""
+	}
+}
+
+type exportedUser struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Surname           string     `json:"surname"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	LastLoginAt       *time.Time `json:"last_login_at"`
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+}
+
+func fields(user models.User) []string {
+	return []string{
+		user.ID,
+		user.Name,
+		user.Surname,
+		user.Username,
+		user.Email,
+		strconv.Itoa(user.Version),
+		formatTime(&user.CreatedAt),
+		formatTime(&user.UpdatedAt),
+		formatTime(user.LastLoginAt),
+		formatTime(user.PasswordChangedAt),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(config.ExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (cw *csvWriter) Write(user models.User) error {
+	record := fields(user)
+	for i, value := range record {
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			record[i] = "'" + value
+		}
+	}
+	return cw.writer.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+type ndjsonWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (nw *ndjsonWriter) Write(user models.User) error {
+	return nw.encoder.Encode(exportedUser{
+		ID:                user.ID,
+		Name:              user.Name,
+		Surname:           user.Surname,
+		Username:          user.Username,
+		Email:             user.Email,
+		Version:           user.Version,
+		CreatedAt:         user.CreatedAt,
+		UpdatedAt:         user.UpdatedAt,
+		LastLoginAt:       user.LastLoginAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+	})
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.buffer.Flush()
+}
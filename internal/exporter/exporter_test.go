@@ -0,0 +1,108 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportUsers(t *testing.T, format string, users ...models.User) []byte {
+	var out bytes.Buffer
+	writer, err := NewWriter(format, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range users {
+		if err := writer.Write(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func testUser() models.User {
+	return models.User{
+		ID:                "1",
+		Name:              "=HYPERLINK(\"x\")",
+		Surname:           "O'Brien & <Sons>",
+		Username:          "johndoe",
+		Email:             "johndoe@example.com",
+		Password:          "$2a$10$secrethash",
+		Version:           3,
+		CreatedAt:         config.TestTime,
+		UpdatedAt:         config.TestTime,
+		PasswordChangedAt: &config.TestTime,
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	for _, format := range config.ExportFormats {
+		_, err := NewWriter(format, io.Discard)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "", MediaType(format))
+	}
+
+	_, err := NewWriter("pdf", io.Discard)
+	assert.ErrorIs(t, err, config.ErrInvalidExportFormat)
+	assert.Equal(t, "", MediaType("pdf"))
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := exportUsers(t, config.ExportFormatCSV, testUser())
+
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, config.ExportColumns, records[0])
+	assert.Equal(t, []string{"1", "'=HYPERLINK(\"x\")", "O'Brien & <Sons>", "johndoe", "johndoe@example.com", "3",
+		"2025-01-01T12:00:00Z", "2025-01-01T12:00:00Z", "", "2025-01-01T12:00:00Z"}, records[1])
+	assert.False(t, strings.Contains(string(out), "secrethash"))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	out := exportUsers(t, config.ExportFormatNDJSON, testUser(), testUser())
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	var exported map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &exported))
+	assert.Equal(t, "=HYPERLINK(\"x\")", exported["name"])
+	assert.Equal(t, float64(3), exported["version"])
+	assert.Nil(t, exported["last_login_at"])
+	assert.NotContains(t, exported, "password")
+}
+
+func TestXLSXWriter(t *testing.T) {
+	out := exportUsers(t, config.ExportFormatXLSX, testUser())
+
+	archive, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	assert.NoError(t, err)
+
+	names := []string{}
+	var sheet string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			reader, _ := file.Open()
+			content, _ := io.ReadAll(reader)
+			sheet = string(content)
+		}
+	}
+	assert.Equal(t, []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"}, names)
+	assert.Equal(t, 2, strings.Count(sheet, "<row>"))
+	assert.Contains(t, sheet, "O&#39;Brien &amp; &lt;Sons&gt;")
+	assert.Contains(t, sheet, `<c t="n"><v>3</v></c>`)
+	assert.NotContains(t, sheet, "secrethash")
+}
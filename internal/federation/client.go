@@ -0,0 +1,239 @@
+package federation
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	discovery, discoveryErr := p.discover(ctx)
+	if discoveryErr != nil {
+		return "", discoveryErr
+	}
+
+	target, parseErr := url.Parse(discovery.AuthorizationEndpoint)
+	if parseErr != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint", config.ErrFederationFailed)
+	}
+	query := target.Query()
+	query.Set("response_type", config.OIDCResponseType)
+	query.Set("client_id", p.ClientID)
+	query.Set("redirect_uri", p.RedirectURI)
+	query.Set("scope", strings.Join(p.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", config.OIDCPKCEMethod)
+	target.RawQuery = query.Encode()
+
+	return target.String(), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	discovery, discoveryErr := p.discover(ctx)
+	if discoveryErr != nil {
+		return "", discoveryErr
+	}
+
+	form := url.Values{
+		"grant_type":    {config.OIDCGrantType},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURI},
+		"code_verifier": {verifier},
+	}
+	if p.ClientSecret == "" {
+		form.Set("client_id", p.ClientID)
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if reqErr != nil {
+		return "", fmt.Errorf("%w: %s", config.ErrFederationFailed, reqErr.Error())
+	}
+	req.Header.Set("Content-Type", config.FormMediaType)
+	req.Header.Set("Accept", config.JSONMediaType)
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	var tokens tokenResponse
+	status, callErr := p.call(req, &tokens)
+	if callErr != nil {
+		return "", callErr
+	}
+	if status != http.StatusOK || tokens.IDToken == "" {
+		return "", fmt.Errorf("%w: token endpoint answered %d %s %s", config.ErrFederationFailed, status, tokens.Error, tokens.ErrorDescription)
+	}
+	return tokens.IDToken, nil
+}
+
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string, now time.Time) (Profile, error) {
+	keys, keysErr := p.signingKeys(ctx, false)
+	if keysErr != nil {
+		return Profile{}, keysErr
+	}
+
+	var claims map[string]any
+	if verifyErr := oidc.Verify(raw, config.OIDCIDTokenType, keys, &claims); verifyErr != nil {
+		if keys, keysErr = p.signingKeys(ctx, true); keysErr != nil {
+			return Profile{}, keysErr
+		}
+		if verifyErr = oidc.Verify(raw, config.OIDCIDTokenType, keys, &claims); verifyErr != nil {
+			return Profile{}, verifyErr
+		}
+	}
+
+	expiresAt, _ := claims["exp"].(float64)
+	subject := stringClaim(claims, "sub")
+	switch {
+	case stringClaim(claims, "iss") != p.Issuer:
+		return Profile{}, fmt.Errorf("%w: unexpected issuer", config.ErrInvalidToken)
+	case !p.audienceMatches(claims):
+		return Profile{}, fmt.Errorf("%w: unexpected audience", config.ErrInvalidToken)
+	case time.Unix(int64(expiresAt), 0).Add(config.FederationClockSkew).Before(now):
+		return Profile{}, fmt.Errorf("%w: token expired", config.ErrInvalidToken)
+	case stringClaim(claims, "nonce") != nonce:
+		return Profile{}, fmt.Errorf("%w: nonce mismatch", config.ErrInvalidToken)
+	case subject == "":
+		return Profile{}, fmt.Errorf("%w: missing subject", config.ErrInvalidToken)
+	}
+
+	profile := Profile{
+		Issuer:   p.Issuer,
+		Subject:  subject,
+		Name:     stringClaim(claims, p.Claims.Name),
+		Surname:  stringClaim(claims, p.Claims.Surname),
+		Email:    stringClaim(claims, p.Claims.Email),
+		Username: stringClaim(claims, p.Claims.Username),
+	}
+	if verified, found := claims[config.ClaimEmailVerified].(bool); found && !verified {
+		profile.Email = ""
+	}
+	return profile, nil
+}
+
+func (p *Provider) audienceMatches(claims map[string]any) bool {
+	var audience []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audience = []string{aud}
+	case []any:
+		for _, value := range aud {
+			if entry, ok := value.(string); ok {
+				audience = append(audience, entry)
+			}
+		}
+	}
+
+	if !slices.Contains(audience, p.ClientID) {
+		return false
+	}
+	authorizedParty, found := claims["azp"]
+	return !found || authorizedParty == p.ClientID
+}
+
+func (p *Provider) discover(ctx context.Context) (models.OIDCDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return *p.discovery, nil
+	}
+
+	var discovery models.OIDCDiscovery
+	if fetchErr := p.getJSON(ctx, strings.TrimSuffix(p.Issuer, "/")+config.OIDCDiscoveryPath, &discovery); fetchErr != nil {
+		return models.OIDCDiscovery{}, fetchErr
+	}
+	if discovery.Issuer != p.Issuer {
+		return models.OIDCDiscovery{}, fmt.Errorf("%w: discovery document issuer %q does not match %q", config.ErrFederationFailed, discovery.Issuer, p.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return models.OIDCDiscovery{}, fmt.Errorf("%w: incomplete discovery document", config.ErrFederationFailed)
+	}
+
+	p.discovery = &discovery
+	return discovery, nil
+}
+
+func (p *Provider) signingKeys(ctx context.Context, refresh bool) (map[string]*rsa.PublicKey, error) {
+	discovery, discoveryErr := p.discover(ctx)
+	if discoveryErr != nil {
+		return nil, discoveryErr
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	var jwks models.JWKS
+	if fetchErr := p.getJSON(ctx, discovery.JWKSURI, &jwks); fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if (jwk.Use != "" && jwk.Use != "sig") || (jwk.Algorithm != "" && jwk.Algorithm != config.OIDCSigningAlg) {
+			continue
+		}
+		if key, parseErr := oidc.ParseJWK(jwk); parseErr == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+
+	p.keys = keys
+	return keys, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, out any) error {
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if reqErr != nil {
+		return fmt.Errorf("%w: %s", config.ErrFederationFailed, reqErr.Error())
+	}
+	req.Header.Set("Accept", config.JSONMediaType)
+
+	status, callErr := p.call(req, out)
+	if callErr != nil {
+		return callErr
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%w: %s answered %d", config.ErrFederationFailed, endpoint, status)
+	}
+	return nil
+}
+
+func (p *Provider) call(req *http.Request, out any) (int, error) {
+	res, callErr := p.client().Do(req)
+	if callErr != nil {
+		return 0, fmt.Errorf("%w: %s", config.ErrFederationFailed, callErr.Error())
+	}
+	defer res.Body.Close()
+
+	if decodeErr := json.NewDecoder(io.LimitReader(res.Body, config.FederationMaxBody)).Decode(out); decodeErr != nil && res.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("%w: malformed response from %s", config.ErrFederationFailed, req.URL.Host)
+	}
+	return res.StatusCode, nil
+}
+
+func stringClaim(claims map[string]any, name string) string {
+	value, _ := claims[name].(string)
+	return strings.TrimSpace(value)
+}
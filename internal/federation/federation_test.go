@@ -0,0 +1,297 @@
+package federation
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	claims   map[string]any
+	jwksHits int
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, _, err := oidc.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, kid: oidc.KeyID(&key.PublicKey)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.OIDCDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.OIDCDiscovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.jwksHits++
+		json.NewEncoder(w).Encode(models.JWKS{Keys: []models.JWK{oidc.PublicJWK(idp.kid, &idp.key.PublicKey)}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "go-manage" || secret != "s3cret" || r.PostFormValue("code") != "good-code" || r.PostFormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.OAuthError{Code: config.OAuthInvalidGrant})
+			return
+		}
+		token, _ := oidc.Sign(idp.key, idp.kid, config.OIDCIDTokenType, idp.claims)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": token, "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *mockIdP) provider() *Provider {
+	provider := &Provider{
+		Name:         "corp",
+		Issuer:       idp.server.URL,
+		ClientID:     "go-manage",
+		ClientSecret: "s3cret",
+		RedirectURI:  "http://localhost:8080/api/v1/login/corp/callback",
+	}
+	provider.normalize()
+	return provider
+}
+
+func (idp *mockIdP) validClaims() map[string]any {
+	return map[string]any{
+		"iss":                idp.server.URL,
+		"aud":                "go-manage",
+		"sub":                "248289761001",
+		"exp":                config.TestTime.Add(time.Hour).Unix(),
+		"iat":                config.TestTime.Unix(),
+		"nonce":              "nonce",
+		"given_name":         "Jane",
+		"family_name":        "Doe",
+		"email":              "janedoe@corp.example.com",
+		"email_verified":     true,
+		"preferred_username": "janedoe",
+	}
+}
+
+func TestLoadProviders(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "providers.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	t.Setenv("CORP_SECRET", "from-env")
+
+	test := []struct {
+		Name        string
+		Content     string
+		ExpectedErr error
+	}{
+		{
+			Name:    "Defaults",
+			Content: `[{"name":"corp","issuer":"https://idp.corp.example.com","client_id":"go-manage","client_secret_env":"CORP_SECRET","redirect_uri":"https://users.example.com/api/v1/login/corp/callback"}]`,
+		},
+		{
+			Name:        "Duplicate name",
+			Content:     `[{"name":"corp","issuer":"https://a.example.com","client_id":"a","redirect_uri":"https://u.example.com/cb"},{"name":"corp","issuer":"https://b.example.com","client_id":"b","redirect_uri":"https://u.example.com/cb"}]`,
+			ExpectedErr: config.ErrInvalidProvider,
+		},
+		{
+			Name:        "Missing openid scope",
+			Content:     `[{"name":"corp","issuer":"https://a.example.com","client_id":"a","redirect_uri":"https://u.example.com/cb","scopes":["email"]}]`,
+			ExpectedErr: config.ErrInvalidProvider,
+		},
+		{
+			Name:        "Invalid name",
+			Content:     `[{"name":"Corp IdP","issuer":"https://a.example.com","client_id":"a","redirect_uri":"https://u.example.com/cb"}]`,
+			ExpectedErr: config.ErrInvalidProvider,
+		},
+		{
+			Name:        "Unknown field",
+			Content:     `[{"name":"corp","issuer":"https://a.example.com","client_id":"a","redirect_uri":"https://u.example.com/cb","secret":"x"}]`,
+			ExpectedErr: config.ErrInvalidProvider,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			providers, loadErr := LoadProviders(write(tt.Content))
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, loadErr, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, loadErr)
+			corp := providers["corp"]
+			assert.Equal(t, "from-env", corp.ClientSecret)
+			assert.Equal(t, config.OIDCScopes, corp.Scopes)
+			assert.Equal(t, ClaimMapping{Name: "given_name", Surname: "family_name", Email: "email", Username: "preferred_username"}, corp.Claims)
+		})
+	}
+
+	providers, loadErr := LoadProviders("")
+	assert.NoError(t, loadErr)
+	assert.Empty(t, providers)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	redirect, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.NoError(t, err)
+
+	target, _ := url.Parse(redirect)
+	assert.Equal(t, idp.server.URL+"/authorize", target.Scheme+"://"+target.Host+target.Path)
+	assert.Equal(t, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"go-manage"},
+		"redirect_uri":          {"http://localhost:8080/api/v1/login/corp/callback"},
+		"scope":                 {"openid profile email"},
+		"state":                 {"state"},
+		"nonce":                 {"nonce"},
+		"code_challenge":        {"challenge"},
+		"code_challenge_method": {"S256"},
+	}, target.Query())
+}
+
+func TestExchangeAndVerify(t *testing.T) {
+	idp := newMockIdP(t)
+
+	test := []struct {
+		Name            string
+		Code            string
+		Claims          func(claims map[string]any)
+		ExpectedProfile Profile
+		ExpectedErr     error
+	}{
+		{
+			Name: "Success",
+			Code: "good-code",
+			ExpectedProfile: Profile{
+				Subject:  "248289761001",
+				Name:     "Jane",
+				Surname:  "Doe",
+				Email:    "janedoe@corp.example.com",
+				Username: "janedoe",
+			},
+		},
+		{
+			Name: "Audience list",
+			Code: "good-code",
+			Claims: func(claims map[string]any) {
+				claims["aud"] = []string{"other", "go-manage"}
+				claims["azp"] = "go-manage"
+			},
+			ExpectedProfile: Profile{
+				Subject:  "248289761001",
+				Name:     "Jane",
+				Surname:  "Doe",
+				Email:    "janedoe@corp.example.com",
+				Username: "janedoe",
+			},
+		},
+		{
+			Name:   "Unverified email is dropped",
+			Code:   "good-code",
+			Claims: func(claims map[string]any) { claims["email_verified"] = false },
+			ExpectedProfile: Profile{
+				Subject:  "248289761001",
+				Name:     "Jane",
+				Surname:  "Doe",
+				Username: "janedoe",
+			},
+		},
+		{
+			Name:        "Rejected code",
+			Code:        "bad-code",
+			ExpectedErr: config.ErrFederationFailed,
+		},
+		{
+			Name:        "Wrong nonce",
+			Code:        "good-code",
+			Claims:      func(claims map[string]any) { claims["nonce"] = "replayed" },
+			ExpectedErr: config.ErrInvalidToken,
+		},
+		{
+			Name:        "Wrong audience",
+			Code:        "good-code",
+			Claims:      func(claims map[string]any) { claims["aud"] = "other" },
+			ExpectedErr: config.ErrInvalidToken,
+		},
+		{
+			Name:        "Wrong issuer",
+			Code:        "good-code",
+			Claims:      func(claims map[string]any) { claims["iss"] = "https://evil.example.com" },
+			ExpectedErr: config.ErrInvalidToken,
+		},
+		{
+			Name:        "Expired",
+			Code:        "good-code",
+			Claims:      func(claims map[string]any) { claims["exp"] = config.TestTime.Add(-time.Hour).Unix() },
+			ExpectedErr: config.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			provider := idp.provider()
+			idp.claims = idp.validClaims()
+			if tt.Claims != nil {
+				tt.Claims(idp.claims)
+			}
+
+			profile, err := func() (Profile, error) {
+				idToken, exchangeErr := provider.Exchange(context.Background(), tt.Code, "verifier")
+				if exchangeErr != nil {
+					return Profile{}, exchangeErr
+				}
+				return provider.VerifyIDToken(context.Background(), idToken, "nonce", config.TestTime)
+			}()
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			tt.ExpectedProfile.Issuer = idp.server.URL
+			assert.Equal(t, tt.ExpectedProfile, profile)
+		})
+	}
+}
+
+func TestVerifyRefreshesRotatedKeys(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+	idp.claims = idp.validClaims()
+
+	first, _ := provider.Exchange(context.Background(), "good-code", "verifier")
+	_, err := provider.VerifyIDToken(context.Background(), first, "nonce", config.TestTime)
+	assert.NoError(t, err)
+
+	rotated, _, _ := oidc.GenerateKey()
+	idp.key, idp.kid = rotated, oidc.KeyID(&rotated.PublicKey)
+
+	second, _ := provider.Exchange(context.Background(), "good-code", "verifier")
+	_, err = provider.VerifyIDToken(context.Background(), second, "nonce", config.TestTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, idp.jwksHits)
+}
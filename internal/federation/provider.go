@@ -0,0 +1,143 @@
+package federation
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+type ClaimMapping struct {
+	Name     string `json:"name"`
+	Surname  string `json:"surname"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type Provider struct {
+	Name            string       `json:"name"`
+	Issuer          string       `json:"issuer"`
+	ClientID        string       `json:"client_id"`
+	ClientSecret    string       `json:"client_secret"`
+	ClientSecretEnv string       `json:"client_secret_env"`
+	RedirectURI     string       `json:"redirect_uri"`
+	Scopes          []string     `json:"scopes"`
+	Claims          ClaimMapping `json:"claims"`
+	Provision       bool         `json:"provision"`
+
+	HTTPClient *http.Client `json:"-"`
+
+	mu        sync.Mutex
+	discovery *models.OIDCDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type Profile struct {
+	Issuer   string
+	Subject  string
+	Name     string
+	Surname  string
+	Email    string
+	Username string
+}
+
+func LoadProviders(path string) (map[string]*Provider, error) {
+	providers := map[string]*Provider{}
+	if path == "" {
+		return providers, nil
+	}
+
+	raw, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var list []*Provider
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if decodeErr := decoder.Decode(&list); decodeErr != nil {
+		return nil, fmt.Errorf("%w: %s", config.ErrInvalidProvider, decodeErr.Error())
+	}
+
+	for _, provider := range list {
+		if checkErr := provider.normalize(); checkErr != nil {
+			return nil, checkErr
+		}
+		if _, found := providers[provider.Name]; found {
+			return nil, fmt.Errorf("%w: duplicate provider %q", config.ErrInvalidProvider, provider.Name)
+		}
+		providers[provider.Name] = provider
+	}
+	return providers, nil
+}
+
+func (p *Provider) normalize() error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: provider %q %s", config.ErrInvalidProvider, p.Name, reason)
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return invalid("must have a lowercase name of up to 32 letters, digits, '-' or '_'")
+	}
+	if !absoluteURL(p.Issuer) {
+		return invalid("must have an http(s) issuer")
+	}
+	if !absoluteURL(p.RedirectURI) {
+		return invalid("must have an http(s) redirect_uri")
+	}
+	if p.ClientID == "" {
+		return invalid("must have a client_id")
+	}
+	if p.ClientSecretEnv != "" {
+		p.ClientSecret = os.Getenv(p.ClientSecretEnv)
+	}
+
+	if len(p.Scopes) == 0 {
+		p.Scopes = config.OIDCScopes
+	}
+	if !slices.Contains(p.Scopes, config.ScopeOpenID) {
+		return invalid("must request the openid scope")
+	}
+
+	defaults := ClaimMapping{
+		Name:     config.ClaimGivenName,
+		Surname:  config.ClaimFamilyName,
+		Email:    config.ClaimEmail,
+		Username: config.ClaimPreferredUsername,
+	}
+	if p.Claims.Name == "" {
+		p.Claims.Name = defaults.Name
+	}
+	if p.Claims.Surname == "" {
+		p.Claims.Surname = defaults.Surname
+	}
+	if p.Claims.Email == "" {
+		p.Claims.Email = defaults.Email
+	}
+	if p.Claims.Username == "" {
+		p.Claims.Username = defaults.Username
+	}
+	return nil
+}
+
+func (p *Provider) client() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return &http.Client{Timeout: config.FederationHTTPTimeout}
+}
+
+func absoluteURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) CreateAPIKey(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createAPIKey(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.APIKeyResponse{
+		Status:  config.SuccessStatus,
+		Message: config.APIKeyMessage,
+		APIKey:  created,
+	})
+}
+
+func (h *UserV2Handler) CreateAPIKey(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createAPIKey(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.APIKeyV2Response{Data: created})
+}
+
+func (h *UserHandler) ListAPIKeys(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	keys, listErr := h.userService.ListAPIKeys(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListAPIKeysResponse{
+		Status:  config.SuccessStatus,
+		Message: config.APIKeysMessage,
+		APIKeys: keys,
+	})
+}
+
+func (h *UserV2Handler) ListAPIKeys(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	keys, listErr := h.userService.ListAPIKeys(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListAPIKeysV2Response{Data: keys})
+}
+
+func (h *UserHandler) RevokeAPIKey(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if revokeErr := h.userService.RevokeAPIKey(ctx, ctx.Param("id")); revokeErr != nil {
+		web.NewError(ctx, errorStatus(revokeErr), revokeErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) createAPIKey(ctx *gin.Context) (models.APIKey, bool) {
+	ctx.Header("Cache-Control", "no-store")
+	var request models.CreateAPIKeyRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.APIKey{}, false
+	}
+
+	created, createErr := h.userService.CreateAPIKey(ctx, request)
+	if createErr != nil {
+		web.NewError(ctx, errorStatus(createErr), createErr.Error())
+		return models.APIKey{}, false
+	}
+
+	return created, true
+}
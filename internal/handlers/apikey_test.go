@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/api-keys", handler.CreateAPIKey)
+	r.POST("/v2/api-keys", handlerV2.CreateAPIKey)
+
+	successMock := func() {
+		mock.ExpectBegin()
+		mock.ExpectExec(config.TestSaveAPIKeyQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Path:         "/v1/api-keys",
+			Body:         `{"name":"nightly export","scopes":["read"]}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedBody: `"key":"gm_`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Success v2",
+			Path:         "/v2/api-keys",
+			Body:         `{"name":"nightly export","scopes":["read","write"]}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedBody: `{"data":{"id":`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Invalid scope",
+			Path:         "/v1/api-keys",
+			Body:         `{"name":"nightly export","scopes":["root"]}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidScope.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:         "Malformed body",
+			Path:         "/v1/api-keys",
+			Body:         `{"name":`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: "",
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, bytes.NewBufferString(tt.Body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, false, strings.Contains(w.Body.String(), `"hash"`))
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.DELETE("/v1/api-keys/:id", handler.RevokeAPIKey)
+
+	tests := []struct {
+		Name         string
+		RowsAffected int64
+		ExpectedCode int
+	}{
+		{
+			Name:         "Success",
+			RowsAffected: 1,
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:         "Not found",
+			RowsAffected: 0,
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(config.TestRevokeAPIKeyQuery).
+				WithArgs(config.TestTime, "key-1", config.DefaultTenant).
+				WillReturnResult(sqlmock.NewResult(0, tt.RowsAffected))
+			if tt.RowsAffected == 1 {
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			req, _ := http.NewRequest(http.MethodDelete, "/v1/api-keys/key-1", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type AuditHandler struct {
+	auditService services.AuditServices
+}
+
+func NewAuditHandler(auditService services.AuditServices) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+func (h *AuditHandler) List(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	filter := models.AuditFilter{
+		Actor:  ctx.Query("actor"),
+		Action: ctx.Query("action"),
+		Target: ctx.Query("target"),
+	}
+
+	var err error
+	if filter.Limit, err = intQuery(ctx, "limit"); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Offset, err = intQuery(ctx, "offset"); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, listErr := h.auditService.ListEvents(ctx, filter)
+	if listErr != nil {
+		web.NewError(ctx, http.StatusInternalServerError, listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, auditResponse(config.SuccessStatus, config.AuditMessage, events))
+}
+
+func auditResponse(status string, message string, events []models.AuditEvent) *models.ListAuditResponse {
+	return &models.ListAuditResponse{
+		Status:  status,
+		Message: message,
+		Events:  events,
+	}
+}
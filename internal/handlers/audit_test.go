@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestAuditList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	auditService := services.AuditServices{Repo: repository.AuditRepository{DB: db}}
+	handler := NewAuditHandler(auditService)
+
+	r := gin.Default()
+	r.GET("/audit", handler.List)
+
+	tests := []struct {
+		Name         string
+		Query        string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Query:        "action=user.delete&limit=10",
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery+` AND action = \?`).
+					WithArgs(config.DefaultTenant, config.AuditActionDelete, 10, 0).
+					WillReturnRows(sqlmock.NewRows(config.TestAuditColumns).
+						AddRow("1", config.TestTime, "admin", config.AuditActionDelete, "johndoe", nil, "request-1", "127.0.0.1"))
+			},
+		},
+		{
+			Name:         "Invalid limit",
+			Query:        "limit=ten",
+			ExpectedCode: http.StatusBadRequest,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Error",
+			Query:        "",
+			ExpectedCode: http.StatusInternalServerError,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery).
+					WillReturnError(errors.New("list error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, "/audit?"+tt.Query, nil)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+		})
+	}
+}
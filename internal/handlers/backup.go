@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) CreateBackup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	backup, backupErr := h.userService.CreateBackup(ctx)
+	if backupErr != nil {
+		web.NewError(ctx, errorStatus(backupErr), backupErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.BackupResponse{
+		Status:  config.SuccessStatus,
+		Message: config.BackupMessage,
+		Backup:  backup,
+	})
+}
+
+func (h *UserV2Handler) CreateBackup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	backup, backupErr := h.userService.CreateBackup(ctx)
+	if backupErr != nil {
+		web.NewError(ctx, errorStatus(backupErr), backupErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.BackupV2Response{Data: backup})
+}
+
+func (h *UserHandler) ListBackups(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	backups, listErr := h.userService.ListBackups(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListBackupsResponse{
+		Status:  config.SuccessStatus,
+		Message: config.BackupsMessage,
+		Backups: backups,
+	})
+}
+
+func (h *UserV2Handler) ListBackups(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	backups, listErr := h.userService.ListBackups(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListBackupsV2Response{Data: backups})
+}
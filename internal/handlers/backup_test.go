@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestBackups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo, BackupDir: dir}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/backups", handler.CreateBackup)
+	r.GET("/v1/backups", handler.ListBackups)
+	r.GET("/v2/backups", handlerV2.ListBackups)
+
+	existing := "users-20250101T120000.000Z.db"
+	if writeErr := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	tests := []struct {
+		Name         string
+		Method       string
+		Path         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Empty list",
+			Method:       http.MethodGet,
+			Path:         "/v1/backups",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"status":"success","message":"backups listed successfully","backups":[]}`,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Backup fails",
+			Method:       http.MethodPost,
+			Path:         "/v1/backups",
+			ExpectedCode: http.StatusInternalServerError,
+			ExpectedBody: "error backing up database",
+			MockAct: func() {
+				mock.ExpectExec(config.TestBackupQuery).
+					WithArgs(filepath.Join(dir, existing)).
+					WillReturnError(errors.New("disk full"))
+			},
+		},
+		{
+			Name:         "Backup already exists",
+			Method:       http.MethodPost,
+			Path:         "/v1/backups",
+			ExpectedCode: http.StatusConflict,
+			ExpectedBody: config.ErrBackupExists.Error(),
+			MockAct: func() {
+				if writeErr := os.WriteFile(filepath.Join(dir, existing), []byte("snapshot"), 0o600); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+			},
+		},
+		{
+			Name:         "List v2",
+			Method:       http.MethodGet,
+			Path:         "/v2/backups",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"data":[{"name":"users-20250101T120000.000Z.db","size":8,"created_at":"2025-01-01T12:00:00Z"}]}`,
+			MockAct:      func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(tt.Method, tt.Path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
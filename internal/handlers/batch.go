@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+var batchStatuses = map[string]int{
+	config.BatchOpCreate: http.StatusCreated,
+	config.BatchOpUpdate: http.StatusOK,
+	config.BatchOpDelete: http.StatusNoContent,
+}
+
+func (h *UserHandler) ExecuteBatch(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	report, ok := h.executeBatch(ctx)
+	if !ok {
+		return
+	}
+
+	message := config.BatchMessage
+	if !report.Committed {
+		message = config.BatchRolledBack
+	}
+
+	ctx.JSON(http.StatusOK, &models.BatchResponse{
+		Status:  config.SuccessStatus,
+		Message: message,
+		Batch:   report,
+	})
+}
+
+func (h *UserV2Handler) ExecuteBatch(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	report, ok := h.executeBatch(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.BatchV2Response{Data: batchV2(report)})
+}
+
+func (h *UserHandler) executeBatch(ctx *gin.Context) (models.BatchReport, bool) {
+	var request models.BatchRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.BatchReport{}, false
+	}
+
+	report, batchErr := h.userService.ExecuteBatch(ctx, request)
+	if batchErr != nil {
+		web.NewError(ctx, errorStatus(batchErr), batchErr.Error())
+		return models.BatchReport{}, false
+	}
+
+	for i := range report.Results {
+		result := &report.Results[i]
+		if result.Err != nil {
+			result.Status = errorStatus(result.Err)
+		} else {
+			result.Status = batchStatuses[result.Op]
+		}
+	}
+
+	return report, true
+}
+
+func batchV2(report models.BatchReport) models.BatchReportV2 {
+	results := make([]models.BatchResultV2, 0, len(report.Results))
+	for _, result := range report.Results {
+		resultV2 := models.BatchResultV2{Index: result.Index, Op: result.Op, ID: result.ID, Status: result.Status, Error: result.Error}
+		if result.User != nil {
+			user := userV2(*result.User)
+			resultV2.User = &user
+		}
+		results = append(results, resultV2)
+	}
+	return models.BatchReportV2{
+		Mode:      report.Mode,
+		Committed: report.Committed,
+		Succeeded: report.Succeeded,
+		Failed:    report.Failed,
+		Results:   results,
+	}
+}
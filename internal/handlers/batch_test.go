@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/users/batch", handler.ExecuteBatch)
+	r.POST("/v2/users/batch", handlerV2.ExecuteBatch)
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Delete",
+			Path:         "/v1/users/batch",
+			Body:         `{"operations":[{"op":"delete","id":"1","version":1}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"batch":{"mode":"atomic","committed":true,"succeeded":1,"failed":0,"results":[{"index":0,"op":"delete","id":"1","status":204}]}`,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectExec(config.TestDeleteQuery).
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Rolled back v2",
+			Path:         "/v2/users/batch",
+			Body:         `{"operations":[{"op":"delete","id":"1","version":1},{"op":"update","id":"1","version":1}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"data":{"mode":"atomic","committed":false,"succeeded":0,"failed":2,"results":[{"index":0,"op":"delete","id":"1","status":424,"error":"not applied because another operation in the batch failed"},{"index":1,"op":"update","id":"1","status":400,"error":"invalid batch operation"}]}}`,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid body",
+			Path:         "/v1/users/batch",
+			Body:         `{"operations":[],"dry_run":true}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidBody.Error(),
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Update v1",
+			Path:         "/v1/users/batch",
+			Body:         `{"operations":[{"op":"update","id":"1","version":1,"patch":{"surname":"Smith"}}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"status":200,"user":{"id":"1","name":"John","surname":"Smith","username":"johndoe","email":"johndoe@example.com","version":2`,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Smith", "johndoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Update v2",
+			Path:         "/v2/users/batch",
+			Body:         `{"operations":[{"op":"update","id":"1","version":1,"patch":{"surname":"Smith"}}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"status":200,"user":{"id":"1","name":"John","surname":"Smith","username":"johndoe","email":"johndoe@example.com","version":2`,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Smith", "johndoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Invalid mode",
+			Path:         "/v1/users/batch",
+			Body:         `{"mode":"eventual","operations":[{"op":"delete","id":"1","version":1}]}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidBatchMode.Error(),
+			MockAct:      func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, strings.NewReader(tt.Body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assertNoPasswordHash(t, w.Body.String())
+		})
+	}
+}
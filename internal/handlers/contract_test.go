@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestResponseContracts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := NewUserHandler(userService)
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.New()
+	r.GET("/v1/users", handler.List)
+	r.GET("/v1/users/:id", handler.GetUser)
+	r.GET("/v2/users", handlerV2.List)
+	r.GET("/v2/users/:id", handlerV2.GetUser)
+
+	userV1Fields := []string{"created_at", "email", "id", "last_login_at", "name", "password_changed_at", "surname", "updated_at", "username", "version"}
+	userV2Fields := []string{"created_at", "email", "id", "last_login_at", "name", "password_changed_at", "surname", "updated_at", "username", "version"}
+
+	tests := []struct {
+		Name           string
+		Path           string
+		ExpectedFields []string
+		Item           func(body map[string]any) map[string]any
+		MockAct        func()
+	}{
+		{
+			Name:           "v1 user",
+			Path:           "/v1/users/1",
+			ExpectedFields: []string{"message", "status", "user"},
+			Item:           func(body map[string]any) map[string]any { return body["user"].(map[string]any) },
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:           "v1 list",
+			Path:           "/v1/users",
+			ExpectedFields: []string{"message", "status", "users"},
+			Item:           func(body map[string]any) map[string]any { return body["users"].([]any)[0].(map[string]any) },
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:           "v2 user",
+			Path:           "/v2/users/1",
+			ExpectedFields: []string{"data"},
+			Item:           func(body map[string]any) map[string]any { return body["data"].(map[string]any) },
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:           "v2 list",
+			Path:           "/v2/users",
+			ExpectedFields: []string{"count", "data"},
+			Item:           func(body map[string]any) map[string]any { return body["data"].([]any)[0].(map[string]any) },
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.TestTime))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, tt.Path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.ExpectedFields, fieldNames(body))
+
+			expectedUserFields := userV1Fields
+			if tt.Path[:3] == "/v2" {
+				expectedUserFields = userV2Fields
+			}
+			assert.Equal(t, expectedUserFields, fieldNames(tt.Item(body)))
+		})
+	}
+}
+
+func fieldNames(object map[string]any) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
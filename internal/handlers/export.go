@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/exporter"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) ExportUsers(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", config.ExportFormatCSV)
+
+	filter, filterErr := listFilter(ctx)
+	if filterErr != nil {
+		web.NewError(ctx, http.StatusBadRequest, filterErr.Error())
+		return
+	}
+
+	writer, writerErr := exporter.NewWriter(format, ctx.Writer)
+	if writerErr != nil {
+		web.NewError(ctx, errorStatus(writerErr), writerErr.Error())
+		return
+	}
+
+	ctx.Header("Content-Type", exporter.MediaType(format))
+	ctx.Header("Content-Disposition", `attachment; filename="`+config.ExportFileName+"."+format+`"`)
+
+	if exportErr := h.userService.ExportUsers(ctx, filter, writer.Write); exportErr != nil {
+		if !ctx.Writer.Written() {
+			ctx.Header("Content-Type", "application/json")
+			ctx.Header("Content-Disposition", "")
+			web.NewError(ctx, errorStatus(exportErr), exportErr.Error())
+			return
+		}
+		ctx.Error(exportErr)
+		ctx.Abort()
+		return
+	}
+
+	if closeErr := writer.Close(); closeErr != nil {
+		ctx.Error(closeErr)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestExportUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.GET("/v1/users/export", handler.ExportUsers)
+
+	tests := []struct {
+		Name                string
+		Query               string
+		ExpectedCode        int
+		ExpectedContentType string
+		ExpectedBody        string
+		MockAct             func()
+	}{
+		{
+			Name:                "CSV",
+			Query:               "?sort=username&created_at_after=2024-01-01T00:00:00Z",
+			ExpectedCode:        http.StatusOK,
+			ExpectedContentType: config.CSVMediaType,
+			ExpectedBody: "id,name,surname,username,email,version,created_at,updated_at,last_login_at,password_changed_at\n" +
+				"1,John,Doe,johndoe,johndoe@example.com,1,2025-01-01T12:00:00Z,2025-01-01T12:00:00Z,,2025-01-01T12:00:00Z\n",
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery+` AND created_at >= \? ORDER BY username ASC, id ASC LIMIT \? OFFSET \?;`).
+					WithArgs(config.DefaultTenant, sqlmock.AnyArg(), -1, 0).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:                "NDJSON",
+			Query:               "?format=ndjson&limit=1",
+			ExpectedCode:        http.StatusOK,
+			ExpectedContentType: config.NDJSONMediaType,
+			ExpectedBody:        `{"id":"1","name":"John","surname":"Doe","username":"johndoe","email":"johndoe@example.com","version":1,"created_at":"2025-01-01T12:00:00Z","updated_at":"2025-01-01T12:00:00Z","last_login_at":null,"password_changed_at":"2025-01-01T12:00:00Z"}` + "\n",
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WithArgs(config.DefaultTenant, 1, 0).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:                "Invalid format",
+			Query:               "?format=pdf",
+			ExpectedCode:        http.StatusBadRequest,
+			ExpectedContentType: "application/json",
+			ExpectedBody:        config.ErrInvalidExportFormat.Error(),
+			MockAct:             func() {},
+		},
+		{
+			Name:                "Invalid sort field",
+			Query:               "?sort=password",
+			ExpectedCode:        http.StatusBadRequest,
+			ExpectedContentType: "application/json",
+			ExpectedBody:        config.ErrInvalidSortField.Error(),
+			MockAct:             func() {},
+		},
+		{
+			Name:                "Error",
+			Query:               "?format=xlsx",
+			ExpectedCode:        http.StatusInternalServerError,
+			ExpectedContentType: "application/json",
+			ExpectedBody:        "error exporting users. Error: database is locked",
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WillReturnError(fmt.Errorf("database is locked"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, "/v1/users/export"+tt.Query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.HasPrefix(w.Header().Get("Content-Type"), tt.ExpectedContentType))
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, false, strings.Contains(w.Body.String(), "Password1234"))
+		})
+	}
+}
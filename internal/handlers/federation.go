@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) StartFederatedLogin(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	redirect, startErr := h.userService.StartFederation(ctx, ctx.Param("provider"), models.User{})
+	if startErr != nil {
+		web.NewError(ctx, errorStatus(startErr), startErr.Error())
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, redirect)
+}
+
+func (h *UserHandler) FederatedCallback(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	result, ok := h.federatedCallback(ctx)
+	if !ok {
+		return
+	}
+
+	switch {
+	case result.Linked != nil:
+		ctx.JSON(http.StatusOK, &models.IdentityResponse{
+			Status:   config.SuccessStatus,
+			Message:  config.IdentityLinkMsg,
+			Identity: *result.Linked,
+		})
+	case result.Login.Challenge != nil:
+		ctx.JSON(http.StatusAccepted, challengeResponse(config.SuccessStatus, config.MFARequired, *result.Login.Challenge))
+	default:
+		ctx.JSON(http.StatusOK, loginResponse(config.SuccessStatus, config.LoginMessage, result.Login.Session))
+	}
+}
+
+func (h *UserV2Handler) FederatedCallback(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	result, ok := h.federatedCallback(ctx)
+	if !ok {
+		return
+	}
+
+	switch {
+	case result.Linked != nil:
+		ctx.JSON(http.StatusOK, &models.IdentityV2Response{Data: *result.Linked})
+	case result.Login.Challenge != nil:
+		ctx.JSON(http.StatusAccepted, &models.MFAChallengeV2Response{Data: *result.Login.Challenge})
+	default:
+		ctx.JSON(http.StatusOK, &models.SessionV2Response{Data: result.Login.Session})
+	}
+}
+
+func (h *UserHandler) LinkIdentity(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return
+	}
+
+	redirect, startErr := h.userService.StartFederation(ctx, ctx.Param("provider"), user)
+	if startErr != nil {
+		web.NewError(ctx, errorStatus(startErr), startErr.Error())
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, redirect)
+}
+
+func (h *UserHandler) ListIdentities(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	identities, ok := h.listIdentities(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListIdentitiesResponse{
+		Status:     config.SuccessStatus,
+		Message:    config.IdentitiesMsg,
+		Identities: identities,
+	})
+}
+
+func (h *UserV2Handler) ListIdentities(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	identities, ok := h.listIdentities(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListIdentitiesV2Response{Data: identities})
+}
+
+func (h *UserHandler) UnlinkIdentity(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return
+	}
+
+	if unlinkErr := h.userService.UnlinkIdentity(ctx, user, ctx.Param("identity")); unlinkErr != nil {
+		web.NewError(ctx, errorStatus(unlinkErr), unlinkErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) federatedCallback(ctx *gin.Context) (models.FederationResult, bool) {
+	ctx.Header("Cache-Control", "no-store")
+	var callback models.FederationCallback
+
+	if err := ctx.ShouldBindQuery(&callback); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.FederationResult{}, false
+	}
+
+	result, callbackErr := h.userService.CompleteFederation(ctx, ctx.Param("provider"), callback)
+	if callbackErr != nil {
+		loginError(ctx, callbackErr)
+		return models.FederationResult{}, false
+	}
+
+	if result.Linked == nil && result.Login.Challenge == nil {
+		setSessionCookie(ctx, result.Login.Session)
+	}
+	return result, true
+}
+
+func (h *UserHandler) listIdentities(ctx *gin.Context) ([]models.ExternalIdentity, bool) {
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	identities, listErr := h.userService.ListIdentities(ctx, user)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return nil, false
+	}
+
+	return identities, true
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/federation"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestListIdentities(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	session := func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") != "" {
+			ctx.Set(config.SessionUserKey, models.User{ID: "1", Username: "johndoe"})
+		}
+	}
+
+	r := gin.Default()
+	r.GET("/v1/users/:id/identities", session, handler.ListIdentities)
+	r.GET("/v2/users/:id/identities", session, handlerV2.ListIdentities)
+
+	identityRows := func() {
+		mock.ExpectQuery(config.TestListIdentitiesQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(config.TestIdentityColumns).
+				AddRow("identity-1", "1", "corp", "https://idp.corp.example.com", "248289761001", "johndoe@corp.example.com", config.TestTime, nil))
+	}
+
+	tests := []struct {
+		Name          string
+		Path          string
+		Authenticated bool
+		ExpectedCode  int
+		ExpectedBody  string
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			Path:          "/v1/users/1/identities",
+			Authenticated: true,
+			ExpectedCode:  http.StatusOK,
+			ExpectedBody:  `"identities":[{"id":"identity-1","user_id":"1","provider":"corp"`,
+			MockAct:       identityRows,
+		},
+		{
+			Name:          "Success v2",
+			Path:          "/v2/users/1/identities",
+			Authenticated: true,
+			ExpectedCode:  http.StatusOK,
+			ExpectedBody:  `{"data":[{"id":"identity-1"`,
+			MockAct:       identityRows,
+		},
+		{
+			Name:          "Other user",
+			Path:          "/v1/users/2/identities",
+			Authenticated: true,
+			ExpectedCode:  http.StatusForbidden,
+			ExpectedBody:  config.ErrForbidden.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:          "No session",
+			Path:          "/v1/users/1/identities",
+			Authenticated: false,
+			ExpectedCode:  http.StatusUnauthorized,
+			ExpectedBody:  config.ErrUnauthorized.Error(),
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, tt.Path, nil)
+			if tt.Authenticated {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+		})
+	}
+}
+
+func TestFederatedCallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo, Providers: map[string]*federation.Provider{
+		"corp": {Name: "corp", Issuer: "https://idp.corp.example.com", ClientID: "go-manage"},
+	}}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.GET("/v1/login/:provider/callback", handler.FederatedCallback)
+	r.GET("/v2/login/:provider/callback", handlerV2.FederatedCallback)
+
+	tests := []struct {
+		Name         string
+		Path         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Unknown provider",
+			Path:         "/v1/login/partner/callback?code=abc&state=xyz",
+			ExpectedCode: http.StatusNotFound,
+			ExpectedBody: config.ErrUnknownProvider.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:         "Denied by provider",
+			Path:         "/v2/login/corp/callback?error=access_denied&state=xyz",
+			ExpectedCode: http.StatusUnauthorized,
+			ExpectedBody: config.ErrFederationDenied.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:         "Expired state",
+			Path:         "/v1/login/corp/callback?code=abc&state=xyz",
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidLoginState.Error(),
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchFedStateQuery).
+					WillReturnRows(sqlmock.NewRows(config.TestFedStateColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, tt.Path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
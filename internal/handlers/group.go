@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) CreateGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createGroup(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.GroupResponse{
+		Status:  config.SuccessStatus,
+		Message: config.GroupCreateMsg,
+		Group:   created,
+	})
+}
+
+func (h *UserV2Handler) CreateGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createGroup(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.GroupV2Response{Data: created})
+}
+
+func (h *UserHandler) ListGroups(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	groups, listErr := h.userService.ListGroups(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListGroupsResponse{
+		Status:  config.SuccessStatus,
+		Message: config.GroupsMessage,
+		Groups:  groups,
+	})
+}
+
+func (h *UserV2Handler) ListGroups(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	groups, listErr := h.userService.ListGroups(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListGroupsV2Response{Data: groups})
+}
+
+func (h *UserHandler) GetGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	group, searchErr := h.userService.SearchGroup(ctx, ctx.Param("id"))
+	if searchErr != nil {
+		web.NewError(ctx, errorStatus(searchErr), searchErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.GroupResponse{
+		Status:  config.SuccessStatus,
+		Message: config.GroupMessage,
+		Group:   group,
+	})
+}
+
+func (h *UserV2Handler) GetGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	group, searchErr := h.userService.SearchGroup(ctx, ctx.Param("id"))
+	if searchErr != nil {
+		web.NewError(ctx, errorStatus(searchErr), searchErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.GroupV2Response{Data: group})
+}
+
+func (h *UserHandler) UpdateGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.updateGroup(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.GroupResponse{
+		Status:  config.SuccessStatus,
+		Message: config.GroupUpdateMsg,
+		Group:   updated,
+	})
+}
+
+func (h *UserV2Handler) UpdateGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.updateGroup(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.GroupV2Response{Data: updated})
+}
+
+func (h *UserHandler) DeleteGroup(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if deleteErr := h.userService.DeleteGroup(ctx, ctx.Param("id")); deleteErr != nil {
+		web.NewError(ctx, errorStatus(deleteErr), deleteErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) ListGroupMembers(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	members, listErr := h.userService.ListGroupMembers(ctx, ctx.Param("id"), ctx.Query("nested") == "true")
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListGroupMembersResponse{
+		Status:  config.SuccessStatus,
+		Message: config.MembersMessage,
+		Members: members,
+	})
+}
+
+func (h *UserV2Handler) ListGroupMembers(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	members, listErr := h.userService.ListGroupMembers(ctx, ctx.Param("id"), ctx.Query("nested") == "true")
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListGroupMembersV2Response{Data: members})
+}
+
+func (h *UserHandler) SetGroupMember(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	member, ok := h.setGroupMember(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.GroupMemberResponse{
+		Status:  config.SuccessStatus,
+		Message: config.MemberMessage,
+		Member:  member,
+	})
+}
+
+func (h *UserV2Handler) SetGroupMember(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	member, ok := h.setGroupMember(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.GroupMemberV2Response{Data: member})
+}
+
+func (h *UserHandler) RemoveGroupMember(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if removeErr := h.userService.RemoveGroupMember(ctx, ctx.Param("id"), ctx.Param("user")); removeErr != nil {
+		web.NewError(ctx, errorStatus(removeErr), removeErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) ListUserGroups(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	memberships, listErr := h.userService.ListUserGroups(ctx, ctx.Param("id"), ctx.Query("nested") == "true")
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListMembershipsResponse{
+		Status:  config.SuccessStatus,
+		Message: config.GroupsMessage,
+		Groups:  memberships,
+	})
+}
+
+func (h *UserV2Handler) ListUserGroups(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	memberships, listErr := h.userService.ListUserGroups(ctx, ctx.Param("id"), ctx.Query("nested") == "true")
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListMembershipsV2Response{Data: memberships})
+}
+
+func (h *UserHandler) createGroup(ctx *gin.Context) (models.Group, bool) {
+	var request models.CreateGroupRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.Group{}, false
+	}
+
+	created, createErr := h.userService.CreateGroup(ctx, request)
+	if createErr != nil {
+		web.NewError(ctx, errorStatus(createErr), createErr.Error())
+		return models.Group{}, false
+	}
+
+	return created, true
+}
+
+func (h *UserHandler) updateGroup(ctx *gin.Context) (models.Group, bool) {
+	var request models.UpdateGroupRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.Group{}, false
+	}
+
+	updated, updateErr := h.userService.UpdateGroup(ctx, ctx.Param("id"), request)
+	if updateErr != nil {
+		web.NewError(ctx, errorStatus(updateErr), updateErr.Error())
+		return models.Group{}, false
+	}
+
+	return updated, true
+}
+
+func (h *UserHandler) setGroupMember(ctx *gin.Context) (models.GroupMember, bool) {
+	var request models.GroupMemberRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.GroupMember{}, false
+	}
+
+	member, setErr := h.userService.SetGroupMember(ctx, ctx.Param("id"), ctx.Param("user"), request)
+	if setErr != nil {
+		web.NewError(ctx, errorStatus(setErr), setErr.Error())
+		return models.GroupMember{}, false
+	}
+
+	return member, true
+}
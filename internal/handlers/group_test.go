@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestSetGroupMember(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.PUT("/v1/groups/:id/members/:user", handler.SetGroupMember)
+	r.PUT("/v2/groups/:id/members/:user", handlerV2.SetGroupMember)
+
+	newMember := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(config.TestSearchGroupQuery).
+			WithArgs("group-1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestGroupColumns).
+				AddRow("group-1", "Engineering", "", nil, config.TestTime, config.TestTime))
+		mock.ExpectQuery(config.TestSearchByIDQuery).
+			WithArgs("1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+		mock.ExpectQuery(config.TestSearchGroupMemberQuery).
+			WithArgs("group-1", "1").
+			WillReturnRows(sqlmock.NewRows(config.TestGroupMemberColumns))
+		mock.ExpectExec(config.TestSaveGroupMemberQuery).
+			WithArgs("group-1", "1", "manager", config.TestTime).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Path:         "/v1/groups/group-1/members/1",
+			Body:         `{"role":"manager"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"member":{"group_id":"group-1","user_id":"1","username":"johndoe","role":"manager"`,
+			MockAct:      newMember,
+		},
+		{
+			Name:         "Success v2",
+			Path:         "/v2/groups/group-1/members/1",
+			Body:         `{"role":"manager"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"data":{"group_id":"group-1","user_id":"1"`,
+			MockAct:      newMember,
+		},
+		{
+			Name:         "Unknown role",
+			Path:         "/v1/groups/group-1/members/1",
+			Body:         `{"role":"admin"}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidGroupRole.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:         "Group not found",
+			Path:         "/v1/groups/group-2/members/1",
+			Body:         `{"role":"member"}`,
+			ExpectedCode: http.StatusNotFound,
+			ExpectedBody: config.ErrGroupNotFound.Error(),
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WithArgs("group-2", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestGroupColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPut, tt.Path, strings.NewReader(tt.Body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+		})
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.DELETE("/v1/groups/:id", handler.DeleteGroup)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(config.TestSearchGroupQuery).
+		WithArgs("group-1", config.DefaultTenant).
+		WillReturnRows(sqlmock.NewRows(config.TestGroupColumns).
+			AddRow("group-1", "Acme", "", nil, config.TestTime, config.TestTime))
+	mock.ExpectExec(config.TestDeleteGroupQuery).
+		WithArgs("group-1", "group-1", config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	req, _ := http.NewRequest(http.MethodDelete, "/v1/groups/group-1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), config.ErrGroupHasSubgroups.Error()))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/importer"
+	"go-manage/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) ImportUsers(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	report, ok := h.importUsers(ctx)
+	if !ok {
+		return
+	}
+
+	message := config.ImportMessage
+	if report.DryRun {
+		message = config.ImportDryRunMsg
+	}
+
+	ctx.JSON(http.StatusOK, &models.ImportResponse{
+		Status:  config.SuccessStatus,
+		Message: message,
+		Report:  report,
+	})
+}
+
+func (h *UserV2Handler) ImportUsers(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	report, ok := h.importUsers(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ImportV2Response{Data: report})
+}
+
+func (h *UserHandler) importUsers(ctx *gin.Context) (models.ImportReport, bool) {
+	options := models.ImportOptions{OnConflict: ctx.Query("on_conflict")}
+
+	if raw := ctx.Query("dry_run"); raw != "" {
+		dryRun, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			web.NewError(ctx, http.StatusBadRequest, config.ErrInvalidFilter.Error()+": dry_run")
+			return models.ImportReport{}, false
+		}
+		options.DryRun = dryRun
+	}
+
+	reader, readerErr := importer.NewReader(ctx.ContentType(), ctx.Request.Body)
+	if readerErr != nil {
+		web.NewError(ctx, errorStatus(readerErr), readerErr.Error())
+		return models.ImportReport{}, false
+	}
+
+	report, importErr := h.userService.ImportUsers(ctx, reader, options)
+	if importErr != nil {
+		web.NewError(ctx, errorStatus(importErr), importErr.Error())
+		return models.ImportReport{}, false
+	}
+
+	return report, true
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestImportUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/users/import", handler.ImportUsers)
+	r.POST("/v2/users/import", handlerV2.ImportUsers)
+
+	csvBody := "name,surname,username,email,password\nJohn,Doe,johndoe,johndoe@example.com,Sup3r-Secret-pass\n"
+
+	tests := []struct {
+		Name         string
+		Path         string
+		MediaType    string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Skip existing",
+			Path:         "/v1/users/import",
+			MediaType:    config.CSVMediaType,
+			Body:         csvBody,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"report":{"dry_run":false,"on_conflict":"skip","total":1,"created":0,"updated":0,"skipped":1,"failed":0,"passwords_ignored":0,"aborted":false,"errors":[]}`,
+			MockAct: func() {
+				existing := func() {
+					mock.ExpectQuery(config.TestSearchQuery).
+						WithArgs("johndoe", config.DefaultTenant).
+						WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+							AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				}
+				existing()
+				mock.ExpectBegin()
+				existing()
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Row errors v2",
+			Path:         "/v2/users/import?dry_run=true",
+			MediaType:    config.NDJSONMediaType,
+			Body:         `{"name":"John","surname":"Doe","username":"jd","email":"johndoe@example.com","password":"Sup3r-Secret-pass"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"data":{"dry_run":true,"on_conflict":"skip","total":1,"created":0,"updated":0,"skipped":0,"failed":1,"passwords_ignored":0,"aborted":false,"errors":[{"row":1,"username":"jd","error":"field length out of range: username"}]}}`,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Unsupported media type",
+			Path:         "/v1/users/import",
+			MediaType:    config.JSONMediaType,
+			Body:         `[]`,
+			ExpectedCode: http.StatusUnsupportedMediaType,
+			ExpectedBody: config.ErrUnsupportedMediaType.Error(),
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid header",
+			Path:         "/v1/users/import",
+			MediaType:    config.CSVMediaType,
+			Body:         "username,email\n",
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidImportHeader.Error(),
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid conflict mode",
+			Path:         "/v1/users/import?on_conflict=replace",
+			MediaType:    config.CSVMediaType,
+			Body:         csvBody,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidConflictMode.Error(),
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid dry run",
+			Path:         "/v1/users/import?dry_run=maybe",
+			MediaType:    config.CSVMediaType,
+			Body:         csvBody,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidFilter.Error(),
+			MockAct:      func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, strings.NewReader(tt.Body))
+			req.Header.Set("Content-Type", tt.MediaType+"; charset=utf-8")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+		})
+	}
+}
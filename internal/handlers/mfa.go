@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) EnrollTOTP(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	enrollment, ok := h.enrollTOTP(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.TOTPEnrollmentResponse{
+		Status:     config.SuccessStatus,
+		Message:    config.TOTPEnrollMsg,
+		Enrollment: enrollment,
+	})
+}
+
+func (h *UserV2Handler) EnrollTOTP(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	enrollment, ok := h.enrollTOTP(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.TOTPEnrollmentV2Response{Data: enrollment})
+}
+
+func (h *UserHandler) ConfirmTOTP(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	codes, ok := h.confirmTOTP(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse(codes))
+}
+
+func (h *UserV2Handler) ConfirmTOTP(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	codes, ok := h.confirmTOTP(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.RecoveryCodesV2Response{Data: codes})
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	codes, ok := h.regenerateRecoveryCodes(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse(codes))
+}
+
+func (h *UserV2Handler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	codes, ok := h.regenerateRecoveryCodes(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.RecoveryCodesV2Response{Data: codes})
+}
+
+func (h *UserHandler) DisableTOTP(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	user, request, ok := h.mfaRequest(ctx)
+	if !ok {
+		return
+	}
+
+	if disableErr := h.userService.DisableTOTP(ctx, user, request); disableErr != nil {
+		web.NewError(ctx, errorStatus(disableErr), disableErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) enrollTOTP(ctx *gin.Context) (models.TOTPEnrollment, bool) {
+	ctx.Header("Cache-Control", "no-store")
+
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return models.TOTPEnrollment{}, false
+	}
+
+	enrollment, enrollErr := h.userService.EnrollTOTP(ctx, user)
+	if enrollErr != nil {
+		web.NewError(ctx, errorStatus(enrollErr), enrollErr.Error())
+		return models.TOTPEnrollment{}, false
+	}
+
+	return enrollment, true
+}
+
+func (h *UserHandler) confirmTOTP(ctx *gin.Context) (models.RecoveryCodes, bool) {
+	user, request, ok := h.mfaRequest(ctx)
+	if !ok {
+		return models.RecoveryCodes{}, false
+	}
+
+	codes, confirmErr := h.userService.ConfirmTOTP(ctx, user, request)
+	if confirmErr != nil {
+		web.NewError(ctx, errorStatus(confirmErr), confirmErr.Error())
+		return models.RecoveryCodes{}, false
+	}
+
+	return codes, true
+}
+
+func (h *UserHandler) regenerateRecoveryCodes(ctx *gin.Context) (models.RecoveryCodes, bool) {
+	user, request, ok := h.mfaRequest(ctx)
+	if !ok {
+		return models.RecoveryCodes{}, false
+	}
+
+	codes, regenerateErr := h.userService.RegenerateRecoveryCodes(ctx, user, request)
+	if regenerateErr != nil {
+		web.NewError(ctx, errorStatus(regenerateErr), regenerateErr.Error())
+		return models.RecoveryCodes{}, false
+	}
+
+	return codes, true
+}
+
+func (h *UserHandler) mfaRequest(ctx *gin.Context) (models.User, models.MFACodeRequest, bool) {
+	ctx.Header("Cache-Control", "no-store")
+	var request models.MFACodeRequest
+
+	user, ok := sessionUser(ctx)
+	if !ok {
+		return models.User{}, models.MFACodeRequest{}, false
+	}
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.User{}, models.MFACodeRequest{}, false
+	}
+
+	return user, request, true
+}
+
+func sessionUser(ctx *gin.Context) (models.User, bool) {
+	user, found := ctx.Value(config.SessionUserKey).(models.User)
+	if !found {
+		web.NewError(ctx, http.StatusUnauthorized, config.ErrUnauthorized.Error())
+		return models.User{}, false
+	}
+	if user.ID != ctx.Param("id") {
+		web.NewError(ctx, http.StatusForbidden, config.ErrForbidden.Error())
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func recoveryCodesResponse(codes models.RecoveryCodes) *models.RecoveryCodesResponse {
+	return &models.RecoveryCodesResponse{
+		Status:        config.SuccessStatus,
+		Message:       config.RecoveryCodesMsg,
+		RecoveryCodes: codes.Codes,
+	}
+}
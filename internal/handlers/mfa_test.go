@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"go-manage/internal/totp"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestEnrollTOTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo, TOTPIssuer: "Go-Manage"}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	session := func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") != "" {
+			ctx.Set(config.SessionUserKey, models.User{ID: "1", Username: "johndoe"})
+		}
+	}
+
+	r := gin.Default()
+	r.POST("/v1/users/:id/mfa/totp", session, handler.EnrollTOTP)
+	r.POST("/v2/users/:id/mfa/totp", session, handlerV2.EnrollTOTP)
+
+	tests := []struct {
+		Name          string
+		Path          string
+		Authenticated bool
+		ExpectedCode  int
+		ExpectedBody  string
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			Path:          "/v1/users/1/mfa/totp",
+			Authenticated: true,
+			ExpectedCode:  http.StatusCreated,
+			ExpectedBody:  `"provisioning_uri":"otpauth://totp/Go-Manage:johndoe?`,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns))
+				mock.ExpectExec(config.TestSaveTOTPQuery).
+					WithArgs("1", sqlmock.AnyArg(), nil, 0, config.TestTime).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:          "Success v2",
+			Path:          "/v2/users/1/mfa/totp",
+			Authenticated: true,
+			ExpectedCode:  http.StatusCreated,
+			ExpectedBody:  `{"data":{"secret":`,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns))
+				mock.ExpectExec(config.TestSaveTOTPQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:          "Already enabled",
+			Path:          "/v1/users/1/mfa/totp",
+			Authenticated: true,
+			ExpectedCode:  http.StatusConflict,
+			ExpectedBody:  config.ErrMFAAlreadyEnabled.Error(),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+						AddRow("1", "JBSWY3DPEHPK3PXP", config.TestTime, 0, config.TestTime))
+			},
+		},
+		{
+			Name:          "Other user",
+			Path:          "/v1/users/2/mfa/totp",
+			Authenticated: true,
+			ExpectedCode:  http.StatusForbidden,
+			ExpectedBody:  config.ErrForbidden.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:          "No session",
+			Path:          "/v1/users/1/mfa/totp",
+			Authenticated: false,
+			ExpectedCode:  http.StatusUnauthorized,
+			ExpectedBody:  config.ErrUnauthorized.Error(),
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, nil)
+			if tt.Authenticated {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestLoginMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/login/mfa", handler.LoginMFA)
+	r.POST("/v2/login/mfa", handlerV2.LoginMFA)
+
+	code, err := totp.Code("JBSWY3DPEHPK3PXP", totp.Step(config.TestTime))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	challengeMock := func(rows *sqlmock.Rows) {
+		mock.ExpectQuery(config.TestSearchChallengeQuery).
+			WillReturnRows(rows)
+	}
+	successMock := func() {
+		challengeMock(sqlmock.NewRows([]string{"user_id"}).AddRow("1"))
+		mock.ExpectQuery(config.TestSearchByIDQuery).
+			WithArgs("1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+		mock.ExpectQuery(config.TestSearchThrottleQuery).
+			WithArgs("user:1").
+			WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns))
+		mock.ExpectBegin()
+		mock.ExpectQuery(config.TestSearchTOTPQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+				AddRow("1", "JBSWY3DPEHPK3PXP", config.TestTime, 0, config.TestTime))
+		mock.ExpectExec(config.TestUseTOTPStepQuery).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(config.TestDeleteChallengeQuery).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(config.TestLoginQuery).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(config.TestSaveSessionQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Path:         "/v1/login/mfa",
+			Body:         `{"challenge_token":"challenge","code":"` + code + `"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"session":{"token":`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Success v2",
+			Path:         "/v2/login/mfa",
+			Body:         `{"challenge_token":"challenge","code":"` + code + `"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"data":{"token":`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Expired challenge",
+			Path:         "/v1/login/mfa",
+			Body:         `{"challenge_token":"challenge","code":"` + code + `"}`,
+			ExpectedCode: http.StatusUnauthorized,
+			ExpectedBody: config.ErrInvalidChallenge.Error(),
+			MockAct: func() {
+				challengeMock(sqlmock.NewRows([]string{"user_id"}))
+			},
+		},
+		{
+			Name:         "Missing code",
+			Path:         "/v1/login/mfa",
+			Body:         `{"challenge_token":"challenge"}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrAllFieldsAreRequired.Error(),
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, bytes.NewBufferString(tt.Body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		})
+	}
+}
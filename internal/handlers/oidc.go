@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/validation"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) CreateOAuthClient(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createOAuthClient(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.OAuthClientResponse{
+		Status:  config.SuccessStatus,
+		Message: config.OAuthClientMsg,
+		Client:  created,
+	})
+}
+
+func (h *UserV2Handler) CreateOAuthClient(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createOAuthClient(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.OAuthClientV2Response{Data: created})
+}
+
+func (h *UserHandler) ListOAuthClients(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	clients, listErr := h.userService.ListOAuthClients(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListOAuthClientsResponse{
+		Status:  config.SuccessStatus,
+		Message: config.OAuthClientsMsg,
+		Clients: clients,
+	})
+}
+
+func (h *UserV2Handler) ListOAuthClients(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	clients, listErr := h.userService.ListOAuthClients(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListOAuthClientsV2Response{Data: clients})
+}
+
+func (h *UserHandler) DeleteOAuthClient(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if deleteErr := h.userService.DeleteOAuthClient(ctx, ctx.Param("id")); deleteErr != nil {
+		web.NewError(ctx, errorStatus(deleteErr), deleteErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) RotateSigningKey(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if rotateErr := h.userService.RotateSigningKey(ctx); rotateErr != nil {
+		web.NewError(ctx, errorStatus(rotateErr), rotateErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) Discovery(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.userService.Discovery())
+}
+
+func (h *UserHandler) JWKS(ctx *gin.Context) {
+	jwks, jwksErr := h.userService.JWKS(ctx)
+	if jwksErr != nil {
+		oauthError(ctx, jwksErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jwks)
+}
+
+func (h *UserHandler) Authorize(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	user, found := ctx.Value(config.SessionUserKey).(models.User)
+	if !found {
+		web.NewError(ctx, http.StatusUnauthorized, config.ErrUnauthorized.Error())
+		return
+	}
+
+	var request models.AuthorizeRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		oauthError(ctx, &models.OAuthError{Code: config.OAuthInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	redirect, authorizeErr := h.userService.Authorize(ctx, user, request)
+	if authorizeErr != nil {
+		oauthError(ctx, authorizeErr)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, redirect)
+}
+
+func (h *UserHandler) Token(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var request models.TokenRequest
+	if err := ctx.ShouldBindWith(&request, binding.Form); err != nil {
+		oauthError(ctx, &models.OAuthError{Code: config.OAuthInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	if id, secret, found := ctx.Request.BasicAuth(); found {
+		request.ClientID, _ = url.QueryUnescape(id)
+		request.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	tokens, exchangeErr := h.userService.ExchangeCode(ctx, request)
+	if exchangeErr != nil {
+		oauthError(ctx, exchangeErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *UserHandler) UserInfo(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !found || token == "" {
+		ctx.Header("WWW-Authenticate", `Bearer realm="`+config.OIDCUserInfoPath+`"`)
+		ctx.JSON(http.StatusUnauthorized, &models.OAuthError{Code: config.OAuthInvalidToken, Description: "missing bearer token"})
+		return
+	}
+
+	info, infoErr := h.userService.UserInfo(ctx, token)
+	if errors.Is(infoErr, config.ErrInvalidToken) {
+		ctx.Header("WWW-Authenticate", `Bearer error="`+config.OAuthInvalidToken+`"`)
+		ctx.JSON(http.StatusUnauthorized, &models.OAuthError{Code: config.OAuthInvalidToken, Description: infoErr.Error()})
+		return
+	}
+	if infoErr != nil {
+		oauthError(ctx, infoErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func (h *UserHandler) createOAuthClient(ctx *gin.Context) (models.OAuthClient, bool) {
+	ctx.Header("Cache-Control", "no-store")
+	var request models.CreateOAuthClientRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.OAuthClient{}, false
+	}
+
+	created, createErr := h.userService.CreateOAuthClient(ctx, request)
+	if createErr != nil {
+		web.NewError(ctx, errorStatus(createErr), createErr.Error())
+		return models.OAuthClient{}, false
+	}
+
+	return created, true
+}
+
+func oauthError(ctx *gin.Context, err error) {
+	var protocolErr *models.OAuthError
+	if !errors.As(err, &protocolErr) {
+		ctx.JSON(http.StatusInternalServerError, &models.OAuthError{Code: config.OAuthServerError, Description: err.Error()})
+		return
+	}
+
+	if protocolErr.Code == config.OAuthInvalidClient {
+		ctx.Header("WWW-Authenticate", `Basic realm="`+config.OIDCTokenPath+`"`)
+		ctx.JSON(http.StatusUnauthorized, protocolErr)
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, protocolErr)
+}
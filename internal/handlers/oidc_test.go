@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestCreateOAuthClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/oauth/clients", handler.CreateOAuthClient)
+	r.POST("/v2/oauth/clients", handlerV2.CreateOAuthClient)
+
+	successMock := func() {
+		mock.ExpectBegin()
+		mock.ExpectExec(config.TestSaveOAuthClientQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Confidential client gets a secret",
+			Path:         "/v1/oauth/clients",
+			Body:         `{"name":"wiki","redirect_uris":["https://wiki.example.com/callback"]}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedBody: `"client_secret":"`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Public client v2",
+			Path:         "/v2/oauth/clients",
+			Body:         `{"name":"cli","redirect_uris":["http://127.0.0.1:8400/callback"],"public":true}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedBody: `"public":true`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Plain http redirect",
+			Path:         "/v1/oauth/clients",
+			Body:         `{"name":"wiki","redirect_uris":["http://wiki.example.com/callback"]}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidRedirectURI.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:         "Missing redirect uris",
+			Path:         "/v1/oauth/clients",
+			Body:         `{"name":"wiki"}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: "",
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, bytes.NewBufferString(tt.Body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, false, strings.Contains(w.Body.String(), "secret_hash"))
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTokenHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.POST(config.OIDCTokenPath, handler.Token)
+	r.GET(config.OIDCUserInfoPath, handler.UserInfo)
+
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {"code"}, "code_verifier": {"verifier"}}
+
+	t.Run("Unknown client", func(t *testing.T) {
+		mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+			WithArgs("client-1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestOAuthClientColumns))
+
+		req, _ := http.NewRequest(http.MethodPost, config.OIDCTokenPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", config.FormMediaType)
+		req.SetBasicAuth("client-1", "secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, true, strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic"))
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Equal(t, true, strings.Contains(w.Body.String(), `"error":"invalid_client"`))
+		assert.Equal(t, nil, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Userinfo without token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, config.OIDCUserInfoPath, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, true, strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Bearer"))
+		assert.Equal(t, true, strings.Contains(w.Body.String(), `"error":"invalid_token"`))
+	})
+}
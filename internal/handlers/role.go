@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/policy"
+	"go-manage/internal/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) CreateRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createRole(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.RoleResponse{
+		Status:  config.SuccessStatus,
+		Message: config.RoleCreateMsg,
+		Role:    created,
+	})
+}
+
+func (h *UserV2Handler) CreateRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createRole(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &models.RoleV2Response{Data: created})
+}
+
+func (h *UserHandler) ListRoles(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	roles, listErr := h.userService.ListRoles(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListRolesResponse{
+		Status:  config.SuccessStatus,
+		Message: config.RolesMessage,
+		Roles:   roles,
+	})
+}
+
+func (h *UserV2Handler) ListRoles(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	roles, listErr := h.userService.ListRoles(ctx)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListRolesV2Response{Data: roles})
+}
+
+func (h *UserHandler) GetRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	role, searchErr := h.userService.SearchRole(ctx, ctx.Param("id"))
+	if searchErr != nil {
+		web.NewError(ctx, errorStatus(searchErr), searchErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.RoleResponse{
+		Status:  config.SuccessStatus,
+		Message: config.RoleMessage,
+		Role:    role,
+	})
+}
+
+func (h *UserV2Handler) GetRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	role, searchErr := h.userService.SearchRole(ctx, ctx.Param("id"))
+	if searchErr != nil {
+		web.NewError(ctx, errorStatus(searchErr), searchErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.RoleV2Response{Data: role})
+}
+
+func (h *UserHandler) UpdateRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.updateRole(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.RoleResponse{
+		Status:  config.SuccessStatus,
+		Message: config.RoleUpdateMsg,
+		Role:    updated,
+	})
+}
+
+func (h *UserV2Handler) UpdateRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.updateRole(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.RoleV2Response{Data: updated})
+}
+
+func (h *UserHandler) DeleteRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if deleteErr := h.userService.DeleteRole(ctx, ctx.Param("id")); deleteErr != nil {
+		web.NewError(ctx, errorStatus(deleteErr), deleteErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) ListPermissions(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	ctx.JSON(http.StatusOK, &models.ListPermissionsResponse{
+		Status:      config.SuccessStatus,
+		Message:     config.PermissionsMsg,
+		Permissions: policy.Catalog(),
+	})
+}
+
+func (h *UserV2Handler) ListPermissions(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	ctx.JSON(http.StatusOK, &models.ListPermissionsV2Response{Data: policy.Catalog()})
+}
+
+func (h *UserHandler) AssignRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if assignErr := h.userService.AssignRole(ctx, ctx.Param("id"), ctx.Param("role")); assignErr != nil {
+		web.NewError(ctx, errorStatus(assignErr), assignErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) UnassignRole(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	if unassignErr := h.userService.UnassignRole(ctx, ctx.Param("id"), ctx.Param("role")); unassignErr != nil {
+		web.NewError(ctx, errorStatus(unassignErr), unassignErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) ListUserRoles(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	roles, listErr := h.userService.ListUserRoles(ctx, ctx.Param("id"))
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListRolesResponse{
+		Status:  config.SuccessStatus,
+		Message: config.RolesMessage,
+		Roles:   roles,
+	})
+}
+
+func (h *UserV2Handler) ListUserRoles(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	roles, listErr := h.userService.ListUserRoles(ctx, ctx.Param("id"))
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.ListRolesV2Response{Data: roles})
+}
+
+func (h *UserHandler) CheckPermission(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	decision, checkErr := h.userService.CheckPermission(ctx, ctx.Param("id"), ctx.Query("action"), ctx.Query("resource"))
+	if checkErr != nil {
+		web.NewError(ctx, errorStatus(checkErr), checkErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.PermissionDecisionResponse{
+		Status:   config.SuccessStatus,
+		Message:  config.DecisionMessage,
+		Decision: decision,
+	})
+}
+
+func (h *UserV2Handler) CheckPermission(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	decision, checkErr := h.userService.CheckPermission(ctx, ctx.Param("id"), ctx.Query("action"), ctx.Query("resource"))
+	if checkErr != nil {
+		web.NewError(ctx, errorStatus(checkErr), checkErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.PermissionDecisionV2Response{Data: decision})
+}
+
+func (h *UserHandler) createRole(ctx *gin.Context) (models.Role, bool) {
+	var request models.CreateRoleRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.Role{}, false
+	}
+
+	created, createErr := h.userService.CreateRole(ctx, request)
+	if createErr != nil {
+		web.NewError(ctx, errorStatus(createErr), createErr.Error())
+		return models.Role{}, false
+	}
+
+	return created, true
+}
+
+func (h *UserHandler) updateRole(ctx *gin.Context) (models.Role, bool) {
+	var request models.UpdateRoleRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.Role{}, false
+	}
+
+	updated, updateErr := h.userService.UpdateRole(ctx, ctx.Param("id"), request)
+	if updateErr != nil {
+		web.NewError(ctx, errorStatus(updateErr), updateErr.Error())
+		return models.Role{}, false
+	}
+
+	return updated, true
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestCreateRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/roles", handler.CreateRole)
+	r.POST("/v2/roles", handlerV2.CreateRole)
+
+	newRole := func() {
+		mock.ExpectBegin()
+		mock.ExpectExec(config.TestSaveRoleQuery).
+			WithArgs(sqlmock.AnyArg(), "Support", "", "users:delete,users:read", config.TestTime, config.TestTime, config.DefaultTenant).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Path:         "/v1/roles",
+			Body:         `{"name":"Support","permissions":["users:read","users:delete","users:read"]}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedBody: `"name":"Support","description":"","permissions":["users:delete","users:read"]`,
+			MockAct:      newRole,
+		},
+		{
+			Name:         "Success v2",
+			Path:         "/v2/roles",
+			Body:         `{"name":"Support","permissions":["users:delete","users:read"]}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedBody: `{"data":{"id":`,
+			MockAct:      newRole,
+		},
+		{
+			Name:         "Unknown permission",
+			Path:         "/v1/roles",
+			Body:         `{"name":"Support","permissions":["users:impersonate"]}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidPermission.Error(),
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Already exists",
+			Path:         "/v1/roles",
+			Body:         `{"name":"Support","permissions":["users:read"]}`,
+			ExpectedCode: http.StatusConflict,
+			ExpectedBody: config.ErrRoleAlreadyExists.Error(),
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestSaveRoleQuery).
+					WillReturnError(config.ErrRoleAlreadyExists)
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, strings.NewReader(tt.Body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+		})
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.GET("/v1/users/:id/permissions/check", handler.CheckPermission)
+
+	userRow := func() {
+		mock.ExpectQuery(config.TestSearchByIDQuery).
+			WithArgs("1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+	}
+
+	tests := []struct {
+		Name         string
+		Query        string
+		ExpectedCode int
+		ExpectedBody string
+		MockAct      func()
+	}{
+		{
+			Name:         "Granted by role",
+			Query:        "?action=users:delete&resource=2",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"decision":{"user_id":"1","action":"users:delete","resource":"2","allowed":true,"role":"Support","permission":"users:delete"}`,
+			MockAct: func() {
+				userRow()
+				mock.ExpectQuery(config.TestListUserRolesQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestRoleColumns).
+						AddRow("role-1", "Support", "", "users:delete", config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:         "Baseline",
+			Query:        "?action=users:read&resource=1",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"allowed":true,"role":"baseline","permission":"users:read:self"`,
+			MockAct:      userRow,
+		},
+		{
+			Name:         "Denied",
+			Query:        "?action=audit:read",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"decision":{"user_id":"1","action":"audit:read","allowed":false}`,
+			MockAct: func() {
+				userRow()
+				mock.ExpectQuery(config.TestListUserRolesQuery).
+					WillReturnRows(sqlmock.NewRows(config.TestRoleColumns))
+			},
+		},
+		{
+			Name:         "Unknown action",
+			Query:        "?action=users:impersonate",
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidPermission.Error(),
+			MockAct:      func() {},
+		},
+		{
+			Name:         "User not found",
+			Query:        "?action=users:read",
+			ExpectedCode: http.StatusNotFound,
+			ExpectedBody: config.ErrUserNotFound.Error(),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, "/v1/users/1/permissions/check"+tt.Query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+		})
+	}
+}
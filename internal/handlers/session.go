@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/services"
+	"go-manage/internal/validation"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func (h *UserHandler) Login(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	result, ok := h.login(ctx)
+	if !ok {
+		return
+	}
+
+	if result.Challenge != nil {
+		ctx.JSON(http.StatusAccepted, challengeResponse(config.SuccessStatus, config.MFARequired, *result.Challenge))
+		return
+	}
+	ctx.JSON(http.StatusOK, loginResponse(config.SuccessStatus, config.LoginMessage, result.Session))
+}
+
+func (h *UserV2Handler) Login(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	result, ok := h.login(ctx)
+	if !ok {
+		return
+	}
+
+	if result.Challenge != nil {
+		ctx.JSON(http.StatusAccepted, &models.MFAChallengeV2Response{Data: *result.Challenge})
+		return
+	}
+	ctx.JSON(http.StatusOK, &models.SessionV2Response{Data: result.Session})
+}
+
+func (h *UserHandler) LoginMFA(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	session, ok := h.loginMFA(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginResponse(config.SuccessStatus, config.LoginMessage, session))
+}
+
+func (h *UserV2Handler) LoginMFA(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	session, ok := h.loginMFA(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &models.SessionV2Response{Data: session})
+}
+
+func (h *UserHandler) login(ctx *gin.Context) (models.LoginResult, bool) {
+	ctx.Header("Cache-Control", "no-store")
+	var request models.LoginRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.LoginResult{}, false
+	}
+
+	result, loginErr := h.userService.Login(ctx, request)
+	if loginErr != nil {
+		loginError(ctx, loginErr)
+		return models.LoginResult{}, false
+	}
+
+	if result.Challenge == nil {
+		setSessionCookie(ctx, result.Session)
+	}
+	return result, true
+}
+
+func (h *UserHandler) loginMFA(ctx *gin.Context) (models.Session, bool) {
+	ctx.Header("Cache-Control", "no-store")
+	var request models.MFALoginRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.Session{}, false
+	}
+
+	session, loginErr := h.userService.CompleteMFALogin(ctx, request)
+	if loginErr != nil {
+		loginError(ctx, loginErr)
+		return models.Session{}, false
+	}
+
+	setSessionCookie(ctx, session)
+	return session, true
+}
+
+func loginError(ctx *gin.Context, loginErr error) {
+	var retry *services.RetryError
+	if errors.As(loginErr, &retry) {
+		ctx.Header(config.RetryAfterHeader, retryAfter(retry.RetryAfter))
+	}
+	web.NewError(ctx, errorStatus(loginErr), loginErr.Error())
+}
+
+func (h *UserHandler) Unlock(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	username := ctx.Param("username")
+	if username == "" {
+		web.NewError(ctx, http.StatusBadRequest, config.ErrEmptyQueryParam.Error())
+		return
+	}
+
+	if unlockErr := h.userService.UnlockUser(ctx, username); unlockErr != nil {
+		web.NewError(ctx, errorStatus(unlockErr), unlockErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func setSessionCookie(ctx *gin.Context, session models.Session) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(config.SessionCookieName, session.Token, int(session.ExpiresAt.Sub(session.CreatedAt).Seconds()), "/", "", ctx.Request.TLS != nil, true)
+}
+
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+func loginResponse(status string, message string, session models.Session) *models.LoginResponse {
+	return &models.LoginResponse{
+		Status:  status,
+		Message: message,
+		Session: session,
+	}
+}
+
+func challengeResponse(status string, message string, challenge models.MFAChallenge) *models.MFAChallengeResponse {
+	return &models.MFAChallengeResponse{
+		Status:    status,
+		Message:   message,
+		Challenge: challenge,
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"go-manage/cmd/config"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Password1234"), bcrypt.MinCost)
+	if err != nil {
+		log.Fatal(err)
+	}
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: bcrypt.MinCost}
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo, Hasher: &hasher}
+	handler := &UserHandler{userService: userService}
+	handlerV2 := NewUserV2Handler(handler)
+
+	r := gin.Default()
+	r.POST("/v1/login", handler.Login)
+	r.POST("/v2/login", handlerV2.Login)
+
+	userMock := func() {
+		mock.ExpectQuery(config.TestSearchQuery).
+			WithArgs("johndoe", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", string(hash), 1, config.TestTime, config.TestTime, nil, config.TestTime))
+	}
+	throttleMock := func(failures int, lockedUntil any) {
+		rows := sqlmock.NewRows(config.TestThrottleColumns)
+		if failures > 0 {
+			rows.AddRow("user:1", failures, config.TestTime, lockedUntil)
+		}
+		mock.ExpectQuery(config.TestSearchThrottleQuery).
+			WithArgs("user:1").
+			WillReturnRows(rows)
+	}
+	totpMock := func(confirmedAt any) {
+		rows := sqlmock.NewRows(config.TestTOTPColumns)
+		if confirmedAt != nil {
+			rows.AddRow("1", "JBSWY3DPEHPK3PXP", confirmedAt, 0, config.TestTime)
+		}
+		mock.ExpectQuery(config.TestSearchTOTPQuery).
+			WithArgs("1").
+			WillReturnRows(rows)
+	}
+	successMock := func() {
+		userMock()
+		throttleMock(0, nil)
+		totpMock(nil)
+		mock.ExpectBegin()
+		mock.ExpectExec(config.TestLoginQuery).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(config.TestSaveSessionQuery).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		Name          string
+		Path          string
+		Body          string
+		ExpectedCode  int
+		ExpectedBody  string
+		ExpectedRetry string
+		MockAct       func()
+	}{
+		{
+			Name:         "Success",
+			Path:         "/v1/login",
+			Body:         `{"username":"johndoe","password":"Password1234"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"session":{"token":`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Success v2",
+			Path:         "/v2/login",
+			Body:         `{"username":"johndoe","password":"Password1234"}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"data":{"token":`,
+			MockAct:      successMock,
+		},
+		{
+			Name:         "Second factor required",
+			Path:         "/v1/login",
+			Body:         `{"username":"johndoe","password":"Password1234"}`,
+			ExpectedCode: http.StatusAccepted,
+			ExpectedBody: `"challenge":{"challenge_token":`,
+			MockAct: func() {
+				userMock()
+				throttleMock(0, nil)
+				totpMock(config.TestTime)
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestSaveChallengeQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Wrong password",
+			Path:         "/v1/login",
+			Body:         `{"username":"johndoe","password":"Password5678"}`,
+			ExpectedCode: http.StatusUnauthorized,
+			ExpectedBody: config.ErrInvalidCredentials.Error(),
+			MockAct: func() {
+				userMock()
+				throttleMock(0, nil)
+				mock.ExpectBegin()
+				throttleMock(0, nil)
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("user:1", 1, config.TestTime, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:          "Backoff",
+			Path:          "/v1/login",
+			Body:          `{"username":"johndoe","password":"Password1234"}`,
+			ExpectedCode:  http.StatusTooManyRequests,
+			ExpectedBody:  config.ErrLoginThrottled.Error(),
+			ExpectedRetry: "3",
+			MockAct: func() {
+				userMock()
+				throttleMock(5, config.TestTime.Add(2500*time.Millisecond))
+			},
+		},
+		{
+			Name:          "Locked",
+			Path:          "/v1/login",
+			Body:          `{"username":"johndoe","password":"Password1234"}`,
+			ExpectedCode:  http.StatusLocked,
+			ExpectedBody:  config.ErrAccountLocked.Error(),
+			ExpectedRetry: "900",
+			MockAct: func() {
+				userMock()
+				throttleMock(config.DefaultLoginLockoutThreshold, config.TestTime.Add(15*time.Minute))
+			},
+		},
+		{
+			Name:         "Unknown user",
+			Path:         "/v1/login",
+			Body:         `{"username":"nonexistentuser","password":"Password1234"}`,
+			ExpectedCode: http.StatusUnauthorized,
+			ExpectedBody: config.ErrInvalidCredentials.Error(),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+		{
+			Name:         "Missing password",
+			Path:         "/v1/login",
+			Body:         `{"username":"johndoe"}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrAllFieldsAreRequired.Error(),
+			MockAct: func() {
+			},
+		},
+		{
+			Name:         "Unknown field",
+			Path:         "/v1/login",
+			Body:         `{"username":"johndoe","password":"Password1234","remember":true}`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: config.ErrInvalidBody.Error(),
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, tt.Path, bytes.NewBufferString(tt.Body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, true, strings.Contains(w.Body.String(), tt.ExpectedBody))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.ExpectedRetry, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := &UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.POST("/users/by-username/:username/unlock", handler.Unlock)
+
+	tests := []struct {
+		Name         string
+		Username     string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Username:     "johndoe",
+			ExpectedCode: http.StatusNoContent,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchThrottleQuery).
+					WithArgs("user:1").
+					WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns))
+				mock.ExpectExec(config.TestDeleteThrottleQuery).
+					WithArgs("user:1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "User not found",
+			Username:     "nonexistentuser",
+			ExpectedCode: http.StatusNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, "/users/by-username/"+tt.Username+"/unlock", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+		})
+	}
+}
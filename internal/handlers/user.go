@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"go-manage/cmd/config"
 	"go-manage/internal/models"
 	"go-manage/internal/services"
+	"go-manage/internal/validation"
 	"net/http"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gustyaguero21/go-core/pkg/web"
@@ -41,22 +48,41 @@ func (h *UserHandler) Search(ctx *gin.Context) {
 		}
 	}
 
+	ctx.Header("ETag", etag(search.Version))
 	ctx.JSON(http.StatusOK, searchResponse(config.SuccessStatus, config.SearchMessage, search))
 
 }
 
+func (h *UserHandler) List(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	filter, filterErr := listFilter(ctx)
+	if filterErr != nil {
+		web.NewError(ctx, http.StatusBadRequest, filterErr.Error())
+		return
+	}
+
+	users, listErr := h.userService.ListUsers(ctx, filter)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listResponse(config.SuccessStatus, config.ListMessage, users))
+}
+
 func (h *UserHandler) Create(ctx *gin.Context) {
 	ctx.Header("Content-Type", "application/json")
-	var user models.User
+	var request models.CreateUserRequest
 
-	if err := ctx.ShouldBindJSON(&user); err != nil {
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
 		web.NewError(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	created, createErr := h.userService.CreateUser(ctx, user)
+	created, createErr := h.userService.CreateUser(ctx, request)
 	if createErr != nil {
-		web.NewError(ctx, http.StatusInternalServerError, createErr.Error())
+		web.NewError(ctx, errorStatus(createErr), createErr.Error())
 		return
 	}
 
@@ -72,14 +98,39 @@ func (h *UserHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if deleteErr := h.userService.DeleteUser(ctx, username); deleteErr != nil {
-		web.NewError(ctx, http.StatusInternalServerError, deleteErr.Error())
+	version, versionErr := ifMatchVersion(ctx)
+	if versionErr != nil {
+		web.NewError(ctx, errorStatus(versionErr), versionErr.Error())
+		return
+	}
+
+	if deleteErr := h.userService.DeleteUser(ctx, username, version); deleteErr != nil {
+		web.NewError(ctx, errorStatus(deleteErr), deleteErr.Error())
 		return
 	}
 
 	ctx.JSON(http.StatusOK, deleteResponse(config.SuccessStatus, config.DeleteMessage))
 }
 
+func (h *UserHandler) Restore(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	username := ctx.Param("username")
+	if username == "" {
+		web.NewError(ctx, http.StatusBadRequest, config.ErrEmptyQueryParam.Error())
+		return
+	}
+
+	restored, restoreErr := h.userService.RestoreUser(ctx, username)
+	if restoreErr != nil {
+		web.NewError(ctx, errorStatus(restoreErr), restoreErr.Error())
+		return
+	}
+
+	ctx.Header("ETag", etag(restored.Version))
+	ctx.JSON(http.StatusOK, restoreResponse(config.SuccessStatus, config.RestoreMessage, restored))
+}
+
 func (h *UserHandler) Update(ctx *gin.Context) {
 	ctx.Header("Content-Type", "application/json")
 
@@ -89,18 +140,26 @@ func (h *UserHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	var user models.User
+	version, versionErr := ifMatchVersion(ctx)
+	if versionErr != nil {
+		web.NewError(ctx, errorStatus(versionErr), versionErr.Error())
+		return
+	}
 
-	if err := ctx.ShouldBindJSON(&user); err != nil {
-		web.NewError(ctx, http.StatusBadRequest, err.Error())
+	patch, bindErr := bindMergePatch(ctx)
+	if bindErr != nil {
+		web.NewError(ctx, errorStatus(bindErr), bindErr.Error())
 		return
 	}
 
-	if updateErr := h.userService.UpdateUser(ctx, username, user); updateErr != nil {
-		web.NewError(ctx, http.StatusInternalServerError, updateErr.Error())
+	updated, updateErr := h.userService.UpdateUser(ctx, username, version, patch)
+	if updateErr != nil {
+		web.NewError(ctx, errorStatus(updateErr), updateErr.Error())
 		return
 	}
-	ctx.JSON(http.StatusOK, updateResponse(config.SuccessStatus, config.UpdateMessage))
+
+	ctx.Header("ETag", etag(updated.Version))
+	ctx.JSON(http.StatusOK, updateResponse(config.SuccessStatus, config.UpdateMessage, updated))
 }
 
 func (h *UserHandler) ChangePwd(ctx *gin.Context) {
@@ -114,13 +173,406 @@ func (h *UserHandler) ChangePwd(ctx *gin.Context) {
 	}
 
 	if changeErr := h.userService.ChangeUserPwd(ctx, username, newPassword); changeErr != nil {
-		web.NewError(ctx, http.StatusInternalServerError, changeErr.Error())
+		web.NewError(ctx, errorStatus(changeErr), changeErr.Error())
 		return
 	}
 
 	ctx.JSON(http.StatusOK, changePwdResponse(config.SuccessStatus, config.ChangePwdMessage))
 }
 
+func (h *UserHandler) CreateUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createResponse(config.SuccessStatus, config.CreateMessage, created))
+}
+
+func (h *UserHandler) GetUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	user, found := h.userByID(ctx)
+	if !found {
+		return
+	}
+
+	ctx.Header("ETag", etag(user.Version))
+	ctx.JSON(http.StatusOK, searchResponse(config.SuccessStatus, config.SearchMessage, user))
+}
+
+func (h *UserHandler) GetUserByUsername(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	user, found := h.userByUsername(ctx)
+	if !found {
+		return
+	}
+
+	ctx.Header("ETag", etag(user.Version))
+	ctx.JSON(http.StatusOK, searchResponse(config.SuccessStatus, config.SearchMessage, user))
+}
+
+func (h *UserHandler) PatchUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.modifyUser(ctx, bindMergePatch)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updateResponse(config.SuccessStatus, config.UpdateMessage, updated))
+}
+
+func (h *UserHandler) ReplaceUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.modifyUser(ctx, bindReplacement)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updateResponse(config.SuccessStatus, config.UpdateMessage, updated))
+}
+
+func (h *UserHandler) DeleteUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	version, versionErr := ifMatchVersion(ctx)
+	if versionErr != nil {
+		web.NewError(ctx, errorStatus(versionErr), versionErr.Error())
+		return
+	}
+
+	user, found := h.userByID(ctx)
+	if !found {
+		return
+	}
+
+	if deleteErr := h.userService.DeleteUser(ctx, user.Username, version); deleteErr != nil {
+		web.NewError(ctx, errorStatus(deleteErr), deleteErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) SetPassword(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+	var request models.ChangePwdRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validation.Struct(request); err != nil {
+		web.NewError(ctx, errorStatus(err), err.Error())
+		return
+	}
+
+	user, found := h.userByID(ctx)
+	if !found {
+		return
+	}
+
+	if changeErr := h.userService.ChangeUserPwd(ctx, user.Username, request.Password); changeErr != nil {
+		web.NewError(ctx, errorStatus(changeErr), changeErr.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) createUser(ctx *gin.Context) (models.User, bool) {
+	var request models.CreateUserRequest
+
+	if err := validation.DecodeJSON(ctx.Request.Body, &request); err != nil {
+		web.NewError(ctx, http.StatusBadRequest, err.Error())
+		return models.User{}, false
+	}
+
+	created, createErr := h.userService.CreateUser(ctx, request)
+	if createErr != nil {
+		web.NewError(ctx, errorStatus(createErr), createErr.Error())
+		return models.User{}, false
+	}
+
+	ctx.Header("Location", path.Join(ctx.Request.URL.Path, created.ID))
+	ctx.Header("ETag", etag(created.Version))
+	return created, true
+}
+
+func (h *UserHandler) modifyUser(ctx *gin.Context, bind func(ctx *gin.Context) (models.UpdateUserRequest, error)) (models.User, bool) {
+	version, versionErr := ifMatchVersion(ctx)
+	if versionErr != nil {
+		web.NewError(ctx, errorStatus(versionErr), versionErr.Error())
+		return models.User{}, false
+	}
+
+	patch, bindErr := bind(ctx)
+	if bindErr != nil {
+		web.NewError(ctx, errorStatus(bindErr), bindErr.Error())
+		return models.User{}, false
+	}
+
+	user, found := h.userByID(ctx)
+	if !found {
+		return models.User{}, false
+	}
+
+	updated, updateErr := h.userService.UpdateUser(ctx, user.Username, version, patch)
+	if updateErr != nil {
+		web.NewError(ctx, errorStatus(updateErr), updateErr.Error())
+		return models.User{}, false
+	}
+
+	ctx.Header("ETag", etag(updated.Version))
+	return updated, true
+}
+
+func (h *UserHandler) userByID(ctx *gin.Context) (models.User, bool) {
+	user, searchErr := h.userService.SearchUserByID(ctx, ctx.Param("id"))
+	if searchErr != nil {
+		web.NewError(ctx, errorStatus(searchErr), searchErr.Error())
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func (h *UserHandler) userByUsername(ctx *gin.Context) (models.User, bool) {
+	user, searchErr := h.userService.SearchUser(ctx, ctx.Param("username"))
+	if searchErr != nil {
+		web.NewError(ctx, errorStatus(searchErr), searchErr.Error())
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func ifMatchVersion(ctx *gin.Context) (int, error) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		return 0, config.ErrPreconditionRequired
+	}
+
+	if strings.HasPrefix(ifMatch, "W/") {
+		return 0, config.ErrWeakETag
+	}
+
+	version, err := strconv.Atoi(strings.Trim(ifMatch, `"`))
+	if err != nil {
+		return 0, config.ErrInvalidVersion
+	}
+
+	return version, nil
+}
+
+func listFilter(ctx *gin.Context) (models.UserFilter, error) {
+	filter := models.UserFilter{
+		Sort: ctx.Query("sort"),
+		Desc: ctx.Query("order") == "desc",
+	}
+
+	var err error
+	if filter.Limit, err = intQuery(ctx, "limit"); err != nil {
+		return models.UserFilter{}, err
+	}
+	if filter.Offset, err = intQuery(ctx, "offset"); err != nil {
+		return models.UserFilter{}, err
+	}
+
+	for _, field := range config.UserTimestampFields {
+		timeRange := models.TimeRange{Field: field}
+		if timeRange.After, err = timeQuery(ctx, field+"_after"); err != nil {
+			return models.UserFilter{}, err
+		}
+		if timeRange.Before, err = timeQuery(ctx, field+"_before"); err != nil {
+			return models.UserFilter{}, err
+		}
+		if timeRange.After != nil || timeRange.Before != nil {
+			filter.Ranges = append(filter.Ranges, timeRange)
+		}
+	}
+
+	return filter, nil
+}
+
+func intQuery(ctx *gin.Context, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", config.ErrInvalidFilter, key)
+	}
+	return parsed, nil
+}
+
+func timeQuery(ctx *gin.Context, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", config.ErrInvalidFilter, key)
+	}
+	return &parsed, nil
+}
+
+func bindMergePatch(ctx *gin.Context) (models.UpdateUserRequest, error) {
+	contentType := ctx.ContentType()
+	if contentType != config.MergePatchMediaType && contentType != config.JSONMediaType {
+		return models.UpdateUserRequest{}, config.ErrUnsupportedMediaType
+	}
+
+	return decodeUserPatch(ctx)
+}
+
+func bindReplacement(ctx *gin.Context) (models.UpdateUserRequest, error) {
+	if ctx.ContentType() != config.JSONMediaType {
+		return models.UpdateUserRequest{}, config.ErrUnsupportedMediaType
+	}
+
+	patch, err := decodeUserPatch(ctx)
+	if err != nil {
+		return models.UpdateUserRequest{}, err
+	}
+
+	required := map[string]*string{
+		"name":     patch.Name,
+		"surname":  patch.Surname,
+		"username": patch.Username,
+		"email":    patch.Email,
+	}
+	for _, field := range []string{"name", "surname", "username", "email"} {
+		if required[field] == nil {
+			return models.UpdateUserRequest{}, fmt.Errorf("%w: %s", config.ErrFieldRequired, field)
+		}
+	}
+
+	return patch, nil
+}
+
+func decodeUserPatch(ctx *gin.Context) (models.UpdateUserRequest, error) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&fields); err != nil {
+		return models.UpdateUserRequest{}, fmt.Errorf("%w: %s", config.ErrInvalidBody, err.Error())
+	}
+
+	patch := models.UpdateUserRequest{}
+	targets := map[string]**string{
+		"name":     &patch.Name,
+		"surname":  &patch.Surname,
+		"username": &patch.Username,
+		"email":    &patch.Email,
+	}
+
+	for field, raw := range fields {
+		target, ok := targets[field]
+		if !ok {
+			return models.UpdateUserRequest{}, fmt.Errorf("%w: %s", config.ErrFieldNotPatchable, field)
+		}
+		if string(raw) == "null" {
+			return models.UpdateUserRequest{}, fmt.Errorf("%w: %s", config.ErrFieldRequired, field)
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return models.UpdateUserRequest{}, fmt.Errorf("%w: %s", config.ErrInvalidBody, field)
+		}
+		*target = &value
+	}
+
+	return patch, nil
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, config.ErrPreconditionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, config.ErrInvalidVersion):
+		return http.StatusBadRequest
+	case errors.Is(err, config.ErrVersionMismatch), errors.Is(err, config.ErrWeakETag):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, config.ErrInvalidCredentials),
+		errors.Is(err, config.ErrInvalidChallenge),
+		errors.Is(err, config.ErrInvalidToken),
+		errors.Is(err, config.ErrFederationDenied),
+		errors.Is(err, config.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, config.ErrForbidden),
+		errors.Is(err, config.ErrIdentityNotLinked):
+		return http.StatusForbidden
+	case errors.Is(err, config.ErrLoginThrottled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, config.ErrAccountLocked):
+		return http.StatusLocked
+	case errors.Is(err, config.ErrUserNotFound),
+		errors.Is(err, config.ErrAPIKeyNotFound),
+		errors.Is(err, config.ErrOAuthClientNotFound),
+		errors.Is(err, config.ErrUnknownProvider),
+		errors.Is(err, config.ErrIdentityNotFound),
+		errors.Is(err, config.ErrGroupNotFound),
+		errors.Is(err, config.ErrMemberNotFound),
+		errors.Is(err, config.ErrRoleNotFound),
+		errors.Is(err, config.ErrRoleNotAssigned),
+		errors.Is(err, config.ErrBackupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrUserAlreadyExists),
+		errors.Is(err, config.ErrMFAAlreadyEnabled),
+		errors.Is(err, config.ErrMFANotEnabled),
+		errors.Is(err, config.ErrMFANotEnrolled),
+		errors.Is(err, config.ErrIdentityLinked),
+		errors.Is(err, config.ErrGroupAlreadyExists),
+		errors.Is(err, config.ErrGroupHasSubgroups),
+		errors.Is(err, config.ErrGroupCycle),
+		errors.Is(err, config.ErrRoleAlreadyExists),
+		errors.Is(err, config.ErrBackupExists):
+		return http.StatusConflict
+	case errors.Is(err, config.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, config.ErrInvalidBody),
+		errors.Is(err, config.ErrInvalidSortField),
+		errors.Is(err, config.ErrInvalidFilter),
+		errors.Is(err, config.ErrFieldNotPatchable),
+		errors.Is(err, config.ErrFieldRequired),
+		errors.Is(err, config.ErrFieldLength),
+		errors.Is(err, config.ErrInvalidUsername),
+		errors.Is(err, config.ErrValidationFailed),
+		errors.Is(err, config.ErrInvalidEmail),
+		errors.Is(err, config.ErrInvalidPassword),
+		errors.Is(err, config.ErrBreachedPassword),
+		errors.Is(err, config.ErrPasswordReused),
+		errors.Is(err, config.ErrInvalidMFACode),
+		errors.Is(err, config.ErrInvalidScope),
+		errors.Is(err, config.ErrInvalidExpiry),
+		errors.Is(err, config.ErrInvalidRedirectURI),
+		errors.Is(err, config.ErrInvalidLoginState),
+		errors.Is(err, config.ErrInvalidGroupRole),
+		errors.Is(err, config.ErrInvalidPermission),
+		errors.Is(err, config.ErrInvalidConflictMode),
+		errors.Is(err, config.ErrInvalidImportHeader),
+		errors.Is(err, config.ErrInvalidExportFormat),
+		errors.Is(err, config.ErrInvalidBatchMode),
+		errors.Is(err, config.ErrInvalidBatchOp),
+		errors.Is(err, config.ErrAllFieldsAreRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, config.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, config.ErrFederationFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func searchResponse(status string, message string, user models.User) *models.SearchResponse {
 	return &models.SearchResponse{
 		Status:  status,
@@ -129,6 +581,14 @@ func searchResponse(status string, message string, user models.User) *models.Sea
 	}
 }
 
+func listResponse(status string, message string, users []models.User) *models.ListUsersResponse {
+	return &models.ListUsersResponse{
+		Status:  status,
+		Message: message,
+		Users:   users,
+	}
+}
+
 func createResponse(status string, message string, created models.User) *models.CreateUserResponse {
 	return &models.CreateUserResponse{
 		Status:  status,
@@ -144,10 +604,19 @@ func deleteResponse(status string, message string) *models.DeleteUserResponse {
 	}
 }
 
-func updateResponse(status string, message string) *models.UpdateUserResponse {
+func restoreResponse(status string, message string, restored models.User) *models.RestoreUserResponse {
+	return &models.RestoreUserResponse{
+		Status:  status,
+		Message: message,
+		User:    restored,
+	}
+}
+
+func updateResponse(status string, message string, updated models.User) *models.UpdateUserResponse {
 	return &models.UpdateUserResponse{
 		Status:  status,
 		Message: message,
+		User:    updated,
 	}
 }
 
@@ -9,7 +9,9 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
@@ -25,7 +27,7 @@ func TestSearch(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := services.UserServices{DB: db, Repo: repo}
 	handler := UserHandler{userService: userService}
 
@@ -44,9 +46,9 @@ func TestSearch(t *testing.T) {
 			ExpectedCode: http.StatusOK,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 		},
 		{
@@ -55,7 +57,7 @@ func TestSearch(t *testing.T) {
 			ExpectedCode: http.StatusInternalServerError,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
+					WithArgs("johndoe", config.DefaultTenant).
 					WillReturnError(err)
 			},
 		},
@@ -72,7 +74,7 @@ func TestSearch(t *testing.T) {
 			ExpectedCode: http.StatusNotFound,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
+					WithArgs("johndoe", config.DefaultTenant).
 					WillReturnError(config.ErrUserNotFound)
 			},
 		},
@@ -90,6 +92,82 @@ func TestSearch(t *testing.T) {
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
+			if w.Code == http.StatusOK {
+				assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.GET("/list", handler.List)
+
+	tests := []struct {
+		Name         string
+		Query        string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Query:        "sort=updated_at&order=desc&created_at_after=2024-12-01T00:00:00Z",
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery+` AND created_at >= \? ORDER BY updated_at DESC`).
+					WithArgs(config.DefaultTenant, time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), config.DefaultListLimit, 0).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:         "Invalid timestamp",
+			Query:        "created_at_after=yesterday",
+			ExpectedCode: http.StatusBadRequest,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid sort field",
+			Query:        "sort=password",
+			ExpectedCode: http.StatusBadRequest,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Error",
+			Query:        "",
+			ExpectedCode: http.StatusInternalServerError,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WillReturnError(errors.New("list error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, "/list?"+tt.Query, nil)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
 		})
 	}
 }
@@ -103,7 +181,7 @@ func TestCreate(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := services.UserServices{DB: db, Repo: repo}
 	handler := UserHandler{userService: userService}
 
@@ -120,7 +198,6 @@ func TestCreate(t *testing.T) {
 		{
 			Name: "Success",
 			Body: `{
-				"id": "1",
 				"name": "John",
 				"surname": "Doe",
 				"username": "johndoe",
@@ -130,15 +207,35 @@ func TestCreate(t *testing.T) {
 			ExpectedCode: http.StatusOK,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestSaveQuery).
 					WithArgs().
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveHistoryQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
+		{
+			Name:         "Unknown field",
+			Body:         `{"id": "1", "name": "John", "surname": "Doe", "username": "johndoe", "email": "johndoe@example.com", "password": "Password1234"}`,
+			ExpectedCode: http.StatusBadRequest,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid username",
+			Body:         `{"name": "John", "surname": "Doe", "username": "john doe", "email": "johndoe@example.com", "password": "Password1234"}`,
+			ExpectedCode: http.StatusBadRequest,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
 		{
 			Name:         "Invalid JSON",
 			Body:         `{"id": "1", "name": "John", "surname": "Doe", "username": "johndoe", "email": "johndoe@example.com", "password": }`,
@@ -149,7 +246,6 @@ func TestCreate(t *testing.T) {
 		{
 			Name: "Error",
 			Body: `{
-				"id": "1",
 				"name": "John",
 				"surname": "Doe",
 				"username": "johndoe",
@@ -174,6 +270,7 @@ func TestCreate(t *testing.T) {
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
 		})
 	}
 }
@@ -187,7 +284,7 @@ func TestDelete(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := services.UserServices{DB: db, Repo: repo}
 	handler := UserHandler{userService: userService}
 
@@ -197,6 +294,7 @@ func TestDelete(t *testing.T) {
 	tests := []struct {
 		Name         string
 		Username     string
+		IfMatch      string
 		ExpectedCode int
 		SearchMock   func()
 		MockAct      func()
@@ -204,17 +302,22 @@ func TestDelete(t *testing.T) {
 		{
 			Name:         "Success",
 			Username:     "johndoe",
+			IfMatch:      `"1"`,
 			ExpectedCode: http.StatusOK,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestDeleteQuery).
-					WithArgs("johndoe").
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
@@ -227,17 +330,46 @@ func TestDelete(t *testing.T) {
 		{
 			Name:         "Error",
 			Username:     "johndoe",
+			IfMatch:      `"1"`,
 			ExpectedCode: http.StatusInternalServerError,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestDeleteQuery).
-					WithArgs("johndoe").
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnError(err)
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:         "Missing If-Match",
+			Username:     "johndoe",
+			ExpectedCode: http.StatusPreconditionRequired,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Stale version",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusPreconditionFailed,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 2, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteQuery).
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 			},
 		},
 	}
@@ -250,6 +382,9 @@ func TestDelete(t *testing.T) {
 			url := "/delete?username=" + tt.Username
 
 			req, _ := http.NewRequest(http.MethodDelete, url, nil)
+			if tt.IfMatch != "" {
+				req.Header.Set("If-Match", tt.IfMatch)
+			}
 
 			w := httptest.NewRecorder()
 
@@ -269,7 +404,7 @@ func TestUpdate(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := services.UserServices{DB: db, Repo: repo}
 	handler := UserHandler{userService: userService}
 
@@ -279,6 +414,8 @@ func TestUpdate(t *testing.T) {
 	tests := []struct {
 		Name         string
 		Username     string
+		IfMatch      string
+		ContentType  string
 		Body         string
 		ExpectedCode int
 		SearchMock   func()
@@ -287,25 +424,27 @@ func TestUpdate(t *testing.T) {
 		{
 			Name:     "Success",
 			Username: "johndoe",
+			IfMatch:  `"1"`,
 			Body: `{
-				"id": "1",
 				"name": "Johncito",
 				"surname": "Doecito",
-				"username": "johndoe",
-				"email": "johndoe2024@example.com",
-				"password": "NewPassword1234"
+				"email": "johndoe2024@example.com"
 			}`,
 			ExpectedCode: http.StatusOK,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestUpdateQuery).
-					WithArgs("Johncito", "Doecito", "johndoe2024@example.com", "johndoe").
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
@@ -319,6 +458,7 @@ func TestUpdate(t *testing.T) {
 		{
 			Name:         "Invalid JSON",
 			Username:     "johndoe",
+			IfMatch:      `"1"`,
 			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com", "password":}`,
 			ExpectedCode: http.StatusBadRequest,
 			SearchMock:   func() {},
@@ -327,18 +467,146 @@ func TestUpdate(t *testing.T) {
 		{
 			Name:         "Error",
 			Username:     "johndoe",
-			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com", "password": "NewPassword1234"}`,
+			IfMatch:      `"1"`,
+			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com"}`,
 			ExpectedCode: http.StatusInternalServerError,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestUpdateQuery).
-					WithArgs("Johncito", "Doecito", "johndoe2024@example.com", "johndoe").
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnError(errors.New("update error"))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:         "Partial patch",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			ContentType:  config.MergePatchMediaType,
+			Body:         `{"surname": "Doecito"}`,
+			ExpectedCode: http.StatusOK,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Doecito", "johndoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Username taken",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			Body:         `{"username": "janedoe"}`,
+			ExpectedCode: http.StatusConflict,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("janedoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(2, "Jane", "Doe", "janedoe", "janedoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:         "Field not patchable",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			Body:         `{"password": "NewPassword1234"}`,
+			ExpectedCode: http.StatusBadRequest,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Required field removed",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			Body:         `{"email": null}`,
+			ExpectedCode: http.StatusBadRequest,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid email",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			Body:         `{"email": "invalid-email"}`,
+			ExpectedCode: http.StatusBadRequest,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Unsupported media type",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			ContentType:  "text/plain",
+			Body:         `{"surname": "Doecito"}`,
+			ExpectedCode: http.StatusUnsupportedMediaType,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Missing If-Match",
+			Username:     "johndoe",
+			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com"}`,
+			ExpectedCode: http.StatusPreconditionRequired,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Invalid If-Match",
+			Username:     "johndoe",
+			IfMatch:      `"abc"`,
+			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com"}`,
+			ExpectedCode: http.StatusBadRequest,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Weak If-Match",
+			Username:     "johndoe",
+			IfMatch:      `W/"2"`,
+			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com"}`,
+			ExpectedCode: http.StatusPreconditionFailed,
+			SearchMock:   func() {},
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Stale version",
+			Username:     "johndoe",
+			IfMatch:      `"1"`,
+			Body:         `{"name": "Johncito", "surname": "Doecito", "email": "johndoe2024@example.com"}`,
+			ExpectedCode: http.StatusPreconditionFailed,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 2, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 			},
 		},
 	}
@@ -351,13 +619,21 @@ func TestUpdate(t *testing.T) {
 			body := bytes.NewBufferString(tt.Body)
 			url := "/update?username=" + tt.Username
 			req, _ := http.NewRequest(http.MethodPatch, url, body)
-			req.Header.Set("Content-Type", "application/json")
+			contentType := tt.ContentType
+			if contentType == "" {
+				contentType = config.JSONMediaType
+			}
+			req.Header.Set("Content-Type", contentType)
+			if tt.IfMatch != "" {
+				req.Header.Set("If-Match", tt.IfMatch)
+			}
 
 			w := httptest.NewRecorder()
 
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
 		})
 	}
 }
@@ -371,7 +647,7 @@ func TestChangePwd(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := services.UserServices{DB: db, Repo: repo}
 	handler := UserHandler{userService: userService}
 
@@ -393,14 +669,22 @@ func TestChangePwd(t *testing.T) {
 			ExpectedCode: http.StatusOK,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}))
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestChangePwdQuery).
-					WithArgs(sqlmock.AnyArg(), "johndoe").
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveHistoryQuery).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
@@ -419,14 +703,18 @@ func TestChangePwd(t *testing.T) {
 			ExpectedCode: http.StatusInternalServerError,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}))
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestChangePwdQuery).
-					WithArgs(sqlmock.AnyArg(), "johndoe").
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.TestTime, "johndoe", config.DefaultTenant).
 					WillReturnError(err)
+				mock.ExpectRollback()
 			},
 		},
 	}
@@ -448,3 +736,541 @@ func TestChangePwd(t *testing.T) {
 		})
 	}
 }
+
+func TestRestore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.POST("/users/:username/restore", handler.Restore)
+
+	tests := []struct {
+		Name         string
+		Username     string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Username:     "johndoe",
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 3, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:         "User not deleted",
+			Username:     "johndoe",
+			ExpectedCode: http.StatusNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:         "Error",
+			Username:     "johndoe",
+			ExpectedCode: http.StatusInternalServerError,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnError(errors.New("restore error"))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, "/users/"+tt.Username+"/restore", nil)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.POST("/api/v1/users", handler.CreateUser)
+
+	tests := []struct {
+		Name         string
+		Body         string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Body:         `{"name": "John", "surname": "Doe", "username": "johndoe", "email": "johndoe@example.com", "password": "Password1234"}`,
+			ExpectedCode: http.StatusCreated,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestSaveQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveHistoryQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Already exists",
+			Body:         `{"name": "John", "surname": "Doe", "username": "johndoe", "email": "johndoe@example.com", "password": "Password1234"}`,
+			ExpectedCode: http.StatusConflict,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:         "Missing fields",
+			Body:         `{"name": "John"}`,
+			ExpectedCode: http.StatusBadRequest,
+			MockAct:      func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBufferString(tt.Body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
+			if tt.ExpectedCode == http.StatusCreated {
+				assert.Equal(t, true, strings.HasPrefix(w.Header().Get("Location"), "/api/v1/users/"))
+				assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.GET("/users/:id", handler.GetUser)
+	r.GET("/users/by-username/:username", handler.GetUserByUsername)
+
+	tests := []struct {
+		Name         string
+		URL          string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "By id",
+			URL:          "/users/1",
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:         "By id not found",
+			URL:          "/users/2",
+			ExpectedCode: http.StatusNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("2", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+		{
+			Name:         "By username",
+			URL:          "/users/by-username/johndoe",
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:         "By username not found",
+			URL:          "/users/by-username/janedoe",
+			ExpectedCode: http.StatusNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("janedoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodGet, tt.URL, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
+		})
+	}
+}
+
+func TestModifyUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.PATCH("/users/:id", handler.PatchUser)
+	r.PUT("/users/:id", handler.ReplaceUser)
+
+	tests := []struct {
+		Name         string
+		Method       string
+		Body         string
+		ContentType  string
+		IfMatch      string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Patch",
+			Method:       http.MethodPatch,
+			Body:         `{"surname": "Doecito"}`,
+			ContentType:  config.MergePatchMediaType,
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Doecito", "johndoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Replace",
+			Method:       http.MethodPut,
+			Body:         `{"name": "Jane", "surname": "Doe", "username": "johndoe", "email": "jane@example.com"}`,
+			ContentType:  config.JSONMediaType,
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusOK,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("Jane", "Doe", "johndoe", "jane@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Replace missing field",
+			Method:       http.MethodPut,
+			Body:         `{"name": "Jane", "surname": "Doe", "username": "johndoe"}`,
+			ContentType:  config.JSONMediaType,
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusBadRequest,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Replace with merge patch media type",
+			Method:       http.MethodPut,
+			Body:         `{"name": "Jane", "surname": "Doe", "username": "johndoe", "email": "jane@example.com"}`,
+			ContentType:  config.MergePatchMediaType,
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusUnsupportedMediaType,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Missing If-Match",
+			Method:       http.MethodPatch,
+			Body:         `{"surname": "Doecito"}`,
+			ContentType:  config.MergePatchMediaType,
+			ExpectedCode: http.StatusPreconditionRequired,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Not found",
+			Method:       http.MethodPatch,
+			Body:         `{"surname": "Doecito"}`,
+			ContentType:  config.MergePatchMediaType,
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(tt.Method, "/users/1", bytes.NewBufferString(tt.Body))
+			req.Header.Set("Content-Type", tt.ContentType)
+			if tt.IfMatch != "" {
+				req.Header.Set("If-Match", tt.IfMatch)
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assertNoPasswordHash(t, w.Body.String())
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.DELETE("/users/:id", handler.DeleteUser)
+
+	tests := []struct {
+		Name         string
+		IfMatch      string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusNoContent,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteQuery).
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Missing If-Match",
+			ExpectedCode: http.StatusPreconditionRequired,
+			MockAct:      func() {},
+		},
+		{
+			Name:         "Not found",
+			IfMatch:      `"1"`,
+			ExpectedCode: http.StatusNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodDelete, "/users/1", nil)
+			if tt.IfMatch != "" {
+				req.Header.Set("If-Match", tt.IfMatch)
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+		})
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := services.UserServices{DB: db, Repo: repo}
+	handler := UserHandler{userService: userService}
+
+	r := gin.Default()
+	r.PUT("/users/:id/password", handler.SetPassword)
+
+	tests := []struct {
+		Name         string
+		Body         string
+		ExpectedCode int
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			Body:         `{"password": "NewPassword1234"}`,
+			ExpectedCode: http.StatusNoContent,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestChangePwdQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveHistoryQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:         "Empty password",
+			Body:         `{"password": ""}`,
+			ExpectedCode: http.StatusBadRequest,
+			MockAct:      func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			req, _ := http.NewRequest(http.MethodPut, "/users/1/password", bytes.NewBufferString(tt.Body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+		})
+	}
+}
+
+func assertNoPasswordHash(t *testing.T, body string) {
+	t.Helper()
+	assert.Equal(t, false, strings.Contains(body, `"password"`))
+	assert.Equal(t, false, strings.Contains(body, "Password1234"))
+	assert.Equal(t, false, strings.Contains(body, "$argon2id$"))
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"go-manage/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type UserV2Handler struct {
+	*UserHandler
+}
+
+func NewUserV2Handler(userHandler *UserHandler) *UserV2Handler {
+	return &UserV2Handler{
+		UserHandler: userHandler,
+	}
+}
+
+func (h *UserV2Handler) List(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	filter, filterErr := listFilter(ctx)
+	if filterErr != nil {
+		web.NewError(ctx, http.StatusBadRequest, filterErr.Error())
+		return
+	}
+
+	users, listErr := h.userService.ListUsers(ctx, filter)
+	if listErr != nil {
+		web.NewError(ctx, errorStatus(listErr), listErr.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listV2Response(users))
+}
+
+func (h *UserV2Handler) CreateUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	created, ok := h.createUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, userV2Response(created))
+}
+
+func (h *UserV2Handler) GetUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	user, found := h.userByID(ctx)
+	if !found {
+		return
+	}
+
+	ctx.Header("ETag", etag(user.Version))
+	ctx.JSON(http.StatusOK, userV2Response(user))
+}
+
+func (h *UserV2Handler) GetUserByUsername(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	user, found := h.userByUsername(ctx)
+	if !found {
+		return
+	}
+
+	ctx.Header("ETag", etag(user.Version))
+	ctx.JSON(http.StatusOK, userV2Response(user))
+}
+
+func (h *UserV2Handler) PatchUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.modifyUser(ctx, bindMergePatch)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userV2Response(updated))
+}
+
+func (h *UserV2Handler) ReplaceUser(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	updated, ok := h.modifyUser(ctx, bindReplacement)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userV2Response(updated))
+}
+
+func (h *UserV2Handler) Restore(ctx *gin.Context) {
+	ctx.Header("Content-Type", "application/json")
+
+	restored, restoreErr := h.userService.RestoreUser(ctx, ctx.Param("username"))
+	if restoreErr != nil {
+		web.NewError(ctx, errorStatus(restoreErr), restoreErr.Error())
+		return
+	}
+
+	ctx.Header("ETag", etag(restored.Version))
+	ctx.JSON(http.StatusOK, userV2Response(restored))
+}
+
+func userV2(user models.User) models.UserV2 {
+	return models.UserV2{
+		ID:                user.ID,
+		Name:              user.Name,
+		Surname:           user.Surname,
+		Username:          user.Username,
+		Email:             user.Email,
+		Version:           user.Version,
+		CreatedAt:         user.CreatedAt,
+		UpdatedAt:         user.UpdatedAt,
+		LastLoginAt:       user.LastLoginAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+	}
+}
+
+func userV2Response(user models.User) *models.UserV2Response {
+	return &models.UserV2Response{
+		Data: userV2(user),
+	}
+}
+
+func listV2Response(users []models.User) *models.ListUsersV2Response {
+	data := make([]models.UserV2, 0, len(users))
+	for _, user := range users {
+		data = append(data, userV2(user))
+	}
+	return &models.ListUsersV2Response{
+		Data:  data,
+		Count: len(data),
+	}
+}
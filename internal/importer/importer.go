@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/validation"
+	"io"
+	"slices"
+	"strings"
+)
+
+type Record struct {
+	Row     int
+	Request models.CreateUserRequest
+	Err     error
+}
+
+type Reader interface {
+	Next() (Record, error)
+}
+
+func NewReader(mediaType string, body io.Reader) (Reader, error) {
+	switch mediaType {
+	case config.CSVMediaType:
+		return newCSVReader(body)
+	case config.NDJSONMediaType:
+		return newNDJSONReader(body), nil
+	default:
+		return nil, config.ErrUnsupportedMediaType
+	}
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns []string
+	row     int
+}
+
+func newCSVReader(body io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file", config.ErrInvalidImportHeader)
+		}
+		return nil, fmt.Errorf("%w: %s", config.ErrInvalidImportHeader, err.Error())
+	}
+
+	columns := make([]string, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !slices.Contains(config.ImportColumns, column) {
+			return nil, fmt.Errorf("%w: unknown column %q", config.ErrInvalidImportHeader, column)
+		}
+		if slices.Contains(columns[:i], column) {
+			return nil, fmt.Errorf("%w: duplicated column %q", config.ErrInvalidImportHeader, column)
+		}
+		columns[i] = column
+	}
+	for _, column := range config.ImportColumns {
+		if !slices.Contains(columns, column) {
+			return nil, fmt.Errorf("%w: missing column %q", config.ErrInvalidImportHeader, column)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns, row: 1}, nil
+}
+
+func (r *csvReader) Next() (Record, error) {
+	fields, err := r.reader.Read()
+	r.row++
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{Row: r.row, Err: fmt.Errorf("%w: %s", config.ErrInvalidBody, parseErr.Err.Error())}, nil
+		}
+		return Record{}, err
+	}
+	if len(fields) != len(r.columns) {
+		return Record{Row: r.row, Err: fmt.Errorf("%w: expected %d fields, got %d", config.ErrInvalidBody, len(r.columns), len(fields))}, nil
+	}
+
+	values := make(map[string]string, len(fields))
+	for i, field := range fields {
+		values[r.columns[i]] = field
+	}
+
+	return Record{Row: r.row, Request: models.CreateUserRequest{
+		Name:     values["name"],
+		Surname:  values["surname"],
+		Username: values["username"],
+		Email:    values["email"],
+		Password: values["password"],
+	}}, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func newNDJSONReader(body io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), config.ImportMaxLineSize)
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (r *ndjsonReader) Next() (Record, error) {
+	for r.scanner.Scan() {
+		r.row++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var request models.CreateUserRequest
+		if err := validation.DecodeJSON(bytes.NewReader(line), &request); err != nil {
+			return Record{Row: r.row, Err: err}, nil
+		}
+		return Record{Row: r.row, Request: request}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return Record{}, fmt.Errorf("%w: line %d exceeds %d bytes", config.ErrInvalidBody, r.row+1, config.ImportMaxLineSize)
+		}
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
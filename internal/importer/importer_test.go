@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, reader Reader) []Record {
+	records := []Record{}
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	test := []struct {
+		Name        string
+		MediaType   string
+		Body        string
+		ExpectedErr error
+	}{
+		{Name: "CSV", MediaType: config.CSVMediaType, Body: "name,surname,username,email,password\n"},
+		{Name: "NDJSON", MediaType: config.NDJSONMediaType, Body: ""},
+		{Name: "Unsupported", MediaType: config.JSONMediaType, Body: "[]", ExpectedErr: config.ErrUnsupportedMediaType},
+		{Name: "Empty CSV", MediaType: config.CSVMediaType, Body: "", ExpectedErr: config.ErrInvalidImportHeader},
+		{Name: "Missing column", MediaType: config.CSVMediaType, Body: "name,surname,username,email\n", ExpectedErr: config.ErrInvalidImportHeader},
+		{Name: "Unknown column", MediaType: config.CSVMediaType, Body: "name,surname,username,email,password,role\n", ExpectedErr: config.ErrInvalidImportHeader},
+		{Name: "Duplicated column", MediaType: config.CSVMediaType, Body: "name,name,surname,username,email,password\n", ExpectedErr: config.ErrInvalidImportHeader},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := NewReader(tt.MediaType, strings.NewReader(tt.Body))
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	body := "\ufeffEmail, Username,Name,Surname,Password\n" +
+		"john@example.com,johndoe,John,Doe,Sup3r-Secret-pass\n" +
+		"jane@example.com,janedoe,Jane\n" +
+		"\"broken,jane@example.com\n"
+
+	reader, err := NewReader(config.CSVMediaType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := readAll(t, reader)
+
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, 2, records[0].Row)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, "johndoe", records[0].Request.Username)
+	assert.Equal(t, "john@example.com", records[0].Request.Email)
+	assert.Equal(t, "Sup3r-Secret-pass", records[0].Request.Password)
+	assert.Equal(t, 3, records[1].Row)
+	assert.ErrorIs(t, records[1].Err, config.ErrInvalidBody)
+	assert.ErrorIs(t, records[2].Err, config.ErrInvalidBody)
+}
+
+func TestNDJSONReader(t *testing.T) {
+	body := `{"name":"John","surname":"Doe","username":"johndoe","email":"john@example.com","password":"Sup3r-Secret-pass"}` + "\n" +
+		"\n" +
+		`{"name":"Jane","role":"admin"}` + "\n" +
+		`not json`
+
+	reader, err := NewReader(config.NDJSONMediaType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := readAll(t, reader)
+
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, 1, records[0].Row)
+	assert.Equal(t, "johndoe", records[0].Request.Username)
+	assert.Equal(t, 3, records[1].Row)
+	assert.ErrorIs(t, records[1].Err, config.ErrInvalidBody)
+	assert.Equal(t, 4, records[2].Row)
+	assert.ErrorIs(t, records[2].Err, config.ErrInvalidBody)
+}
+
+func TestNDJSONReaderLineTooLong(t *testing.T) {
+	reader, err := NewReader(config.NDJSONMediaType, strings.NewReader(strings.Repeat("x", config.ImportMaxLineSize+1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, nextErr := reader.Next()
+	assert.ErrorIs(t, nextErr, config.ErrInvalidBody)
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func AdminAuth(adminToken string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if key, found := ctx.Value(config.APIKeyContextKey).(models.APIKey); found {
+			if !key.HasScope(config.ScopeAdmin) {
+				web.NewError(ctx, http.StatusForbidden, config.ErrForbidden.Error())
+				ctx.Abort()
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		if !isAdminToken(ctx.GetHeader("Authorization"), adminToken) {
+			web.NewError(ctx, http.StatusUnauthorized, config.ErrUnauthorized.Error())
+			ctx.Abort()
+			return
+		}
+
+		SetActor(ctx, config.AdminActor)
+		ctx.Next()
+	}
+}
+
+func isAdminToken(authorization, adminToken string) bool {
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	return adminToken != "" && found && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		Name          string
+		AdminToken    string
+		Authorization string
+		APIKey        *models.APIKey
+		ExpectedCode  int
+	}{
+		{
+			Name:          "Success",
+			AdminToken:    "secret",
+			Authorization: "Bearer secret",
+			ExpectedCode:  http.StatusOK,
+		},
+		{
+			Name:          "Wrong token",
+			AdminToken:    "secret",
+			Authorization: "Bearer other",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+		{
+			Name:          "Missing header",
+			AdminToken:    "secret",
+			Authorization: "",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+		{
+			Name:          "Admin token not configured",
+			AdminToken:    "",
+			Authorization: "Bearer ",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+		{
+			Name:         "API key with admin scope",
+			AdminToken:   "secret",
+			APIKey:       &models.APIKey{Scopes: []string{config.ScopeAdmin}},
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "API key without admin scope",
+			AdminToken:   "secret",
+			APIKey:       &models.APIKey{Scopes: []string{config.ScopeRead, config.ScopeWrite}},
+			ExpectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := gin.New()
+			if tt.APIKey != nil {
+				r.Use(func(ctx *gin.Context) {
+					ctx.Set(config.APIKeyContextKey, *tt.APIKey)
+				})
+			}
+			r.GET("/admin", AdminAuth(tt.AdminToken), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.Authorization != "" {
+				req.Header.Set("Authorization", tt.Authorization)
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+		})
+	}
+}
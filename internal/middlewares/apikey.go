@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type APIKeyAuthenticator func(ctx context.Context, key string) (models.APIKey, error)
+
+func APIKeyAuth(authenticate APIKeyAuthenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		raw, found := strings.CutPrefix(ctx.GetHeader("Authorization"), config.APIKeyScheme)
+		if !found {
+			ctx.Next()
+			return
+		}
+
+		key, authErr := authenticate(ctx, raw)
+		if authErr != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(authErr, config.ErrUnauthorized) {
+				status = http.StatusUnauthorized
+			}
+			web.NewError(ctx, status, authErr.Error())
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(config.APIKeyContextKey, key)
+		SetActor(ctx, config.APIKeyActorPrefix+key.Prefix)
+		ctx.Next()
+	}
+}
+
+func methodScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return config.ScopeRead
+	default:
+		return config.ScopeWrite
+	}
+}
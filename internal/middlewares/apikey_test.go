@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticate := func(ctx context.Context, key string) (models.APIKey, error) {
+		switch key {
+		case "gm_readonly_secret":
+			return models.APIKey{ID: "1", Prefix: "gm_readonly", Scopes: []string{config.ScopeRead}}, nil
+		case "gm_adminkey_secret":
+			return models.APIKey{ID: "2", Prefix: "gm_adminkey", Scopes: []string{config.ScopeAdmin}}, nil
+		case "gm_broken_secret":
+			return models.APIKey{}, errors.New("database is locked")
+		default:
+			return models.APIKey{}, config.ErrUnauthorized
+		}
+	}
+
+	tests := []struct {
+		Name          string
+		Method        string
+		Authorization string
+		ExpectedCode  int
+		ExpectedActor string
+	}{
+		{
+			Name:          "Read scope on GET",
+			Method:        http.MethodGet,
+			Authorization: "ApiKey gm_readonly_secret",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "apikey:gm_readonly",
+		},
+		{
+			Name:          "Read scope on POST is left to the permission check",
+			Method:        http.MethodPost,
+			Authorization: "ApiKey gm_readonly_secret",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "apikey:gm_readonly",
+		},
+		{
+			Name:          "Admin scope on POST",
+			Method:        http.MethodPost,
+			Authorization: "ApiKey gm_adminkey_secret",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "apikey:gm_adminkey",
+		},
+		{
+			Name:          "Unknown key",
+			Method:        http.MethodGet,
+			Authorization: "ApiKey gm_other_secret",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+		{
+			Name:          "Lookup error",
+			Method:        http.MethodGet,
+			Authorization: "ApiKey gm_broken_secret",
+			ExpectedCode:  http.StatusInternalServerError,
+		},
+		{
+			Name:          "Other scheme passes through",
+			Method:        http.MethodGet,
+			Authorization: "Bearer token",
+			ExpectedCode:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var actor string
+
+			r := gin.New()
+			r.Handle(tt.Method, "/users", APIKeyAuth(authenticate), func(ctx *gin.Context) {
+				if meta, found := ctx.Value(config.AuditContextKey).(models.AuditContext); found {
+					actor = meta.Actor
+				}
+				ctx.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(tt.Method, "/users", nil)
+			req.Header.Set("Authorization", tt.Authorization)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedActor, actor)
+		})
+	}
+}
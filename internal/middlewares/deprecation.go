@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", "@"+strconv.FormatInt(config.LegacyDeprecatedAt.Unix(), 10))
+		ctx.Header("Sunset", config.LegacySunsetAt.Format(http.TimeFormat))
+		if successor != "" {
+			ctx.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestDeprecated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		Name         string
+		Successor    string
+		ExpectedLink string
+	}{
+		{
+			Name:         "With successor",
+			Successor:    config.UsersResourcePath,
+			ExpectedLink: `</api/v1/users>; rel="successor-version"`,
+		},
+		{
+			Name:         "Without successor",
+			Successor:    "",
+			ExpectedLink: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/legacy", Deprecated(tt.Successor), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/legacy", nil)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+			assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			assert.Equal(t, tt.ExpectedLink, w.Header().Get("Link"))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type Authorizer func(ctx context.Context, actor models.User, action, resource string) (bool, error)
+
+type KeyAuthorizer func(ctx context.Context, key models.APIKey, action, resource string) (bool, error)
+
+type PermissionCheck func(action string) gin.HandlerFunc
+
+func Permission(adminToken string, authenticate Authenticator, can Authorizer, canKey KeyAuthorizer) PermissionCheck {
+	return func(action string) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			if key, found := ctx.Value(config.APIKeyContextKey).(models.APIKey); found {
+				allowed, canErr := canKey(ctx, key, action, ctx.Param("id"))
+				if !authorized(ctx, allowed, canErr) {
+					return
+				}
+				ctx.Next()
+				return
+			}
+			if isAdminToken(ctx.GetHeader("Authorization"), adminToken) {
+				SetActor(ctx, config.AdminActor)
+				ctx.Next()
+				return
+			}
+
+			user, ok := sessionUser(ctx, authenticate)
+			if !ok {
+				ctx.Abort()
+				return
+			}
+
+			allowed, canErr := can(ctx, user, action, targetUser(ctx, user))
+			if !authorized(ctx, allowed, canErr) {
+				return
+			}
+
+			ctx.Set(config.SessionUserKey, user)
+			SetActor(ctx, user.Username)
+			ctx.Next()
+		}
+	}
+}
+
+func authorized(ctx *gin.Context, allowed bool, canErr error) bool {
+	if canErr != nil {
+		web.NewError(ctx, http.StatusInternalServerError, canErr.Error())
+		ctx.Abort()
+		return false
+	}
+	if !allowed {
+		web.NewError(ctx, http.StatusForbidden, config.ErrForbidden.Error())
+		ctx.Abort()
+		return false
+	}
+	return true
+}
+
+func targetUser(ctx *gin.Context, actor models.User) string {
+	if id := ctx.Param("id"); id != "" {
+		return id
+	}
+	username := ctx.Param("username")
+	if username == "" {
+		username = ctx.Query("username")
+	}
+	if username != "" && username == actor.Username {
+		return actor.ID
+	}
+	return username
+}
@@ -0,0 +1,151 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticate := func(ctx context.Context, token string) (models.User, error) {
+		if token == "valid" {
+			return models.User{ID: "1", Username: "johndoe"}, nil
+		}
+		return models.User{}, config.ErrUnauthorized
+	}
+	can := func(ctx context.Context, actor models.User, action, resource string) (bool, error) {
+		switch action {
+		case "broken":
+			return false, errors.New("database is locked")
+		case config.PermUsersRead:
+			return resource == actor.ID, nil
+		default:
+			return false, nil
+		}
+	}
+
+	canKey := func(ctx context.Context, key models.APIKey, action, resource string) (bool, error) {
+		return slices.Contains(key.Scopes, action), nil
+	}
+
+	tests := []struct {
+		Name          string
+		Action        string
+		Path          string
+		Authorization string
+		APIKey        []string
+		ExpectedCode  int
+		ExpectedActor string
+	}{
+		{
+			Name:          "Self by id",
+			Action:        config.PermUsersRead,
+			Path:          "/users/1",
+			Authorization: "Bearer valid",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "johndoe",
+		},
+		{
+			Name:          "Self by username",
+			Action:        config.PermUsersRead,
+			Path:          "/search?username=johndoe",
+			Authorization: "Bearer valid",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "johndoe",
+		},
+		{
+			Name:          "Another user",
+			Action:        config.PermUsersRead,
+			Path:          "/users/2",
+			Authorization: "Bearer valid",
+			ExpectedCode:  http.StatusForbidden,
+		},
+		{
+			Name:          "Permission not granted",
+			Action:        config.PermUsersDelete,
+			Path:          "/users/1",
+			Authorization: "Bearer valid",
+			ExpectedCode:  http.StatusForbidden,
+		},
+		{
+			Name:          "Authorizer error",
+			Action:        "broken",
+			Path:          "/users/1",
+			Authorization: "Bearer valid",
+			ExpectedCode:  http.StatusInternalServerError,
+		},
+		{
+			Name:         "Missing credential",
+			Action:       config.PermUsersRead,
+			Path:         "/users/1",
+			ExpectedCode: http.StatusUnauthorized,
+		},
+		{
+			Name:          "Admin token",
+			Action:        config.PermUsersDelete,
+			Path:          "/users/2",
+			Authorization: "Bearer secret",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: config.AdminActor,
+		},
+		{
+			Name:         "API key with the permission",
+			Action:       config.PermUsersDelete,
+			Path:         "/users/2",
+			APIKey:       []string{config.PermUsersDelete},
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "API key without the permission",
+			Action:       config.PermUsersDelete,
+			Path:         "/users/2",
+			APIKey:       []string{config.PermUsersWrite},
+			ExpectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var actor string
+
+			apiKey := func(ctx *gin.Context) {
+				if tt.APIKey != nil {
+					ctx.Set(config.APIKeyContextKey, models.APIKey{Prefix: "abc", Scopes: tt.APIKey})
+				}
+			}
+			handler := func(ctx *gin.Context) {
+				if meta, found := ctx.Value(config.AuditContextKey).(models.AuditContext); found {
+					actor = meta.Actor
+				}
+				ctx.Status(http.StatusOK)
+			}
+			permit := Permission("secret", authenticate, can, canKey)(tt.Action)
+
+			r := gin.New()
+			r.GET("/users/:id", apiKey, permit, handler)
+			r.GET("/search", apiKey, permit, handler)
+
+			req, _ := http.NewRequest(http.MethodGet, tt.Path, nil)
+			if tt.Authorization != "" {
+				req.Header.Set("Authorization", tt.Authorization)
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedActor, actor)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/ratelimit"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type KeyFunc func(ctx *gin.Context) string
+
+type RateLimiter struct {
+	Store  ratelimit.Store
+	Limits map[string]ratelimit.Limit
+	Key    KeyFunc
+}
+
+func (rl *RateLimiter) Group(name string) gin.HandlerFunc {
+	if rl == nil || rl.Store == nil {
+		return passThrough
+	}
+	limit, found := rl.Limits[name]
+	if !found {
+		return passThrough
+	}
+
+	key := rl.Key
+	if key == nil {
+		key = ClientKey
+	}
+
+	return func(ctx *gin.Context) {
+		decision, takeErr := rl.Store.Take(name+":"+key(ctx), limit)
+		if takeErr != nil {
+			log.Println("rate limiter unavailable. Error: " + takeErr.Error())
+			ctx.Next()
+			return
+		}
+
+		ctx.Header(config.RateLimitPolicyHeader, limit.Policy())
+		ctx.Header(config.RateLimitLimitHeader, strconv.Itoa(decision.Limit))
+		ctx.Header(config.RateLimitRemainingHeader, strconv.Itoa(decision.Remaining))
+		ctx.Header(config.RateLimitResetHeader, ceilSeconds(decision.Reset))
+
+		if !decision.Allowed {
+			ctx.Header(config.RetryAfterHeader, ceilSeconds(decision.RetryAfter))
+			web.NewError(ctx, http.StatusTooManyRequests, config.ErrRateLimited.Error())
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func ClientKey(ctx *gin.Context) string {
+	meta, _ := ctx.Value(config.AuditContextKey).(models.AuditContext)
+	if meta.Actor != "" && meta.Actor != config.AnonymousActor {
+		return actorKey(ctx, meta.Actor)
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+func CredentialKey(adminToken string, authenticate Authenticator) KeyFunc {
+	return func(ctx *gin.Context) string {
+		if _, found := ctx.Value(config.APIKeyContextKey).(models.APIKey); found {
+			return ClientKey(ctx)
+		}
+		if isAdminToken(ctx.GetHeader("Authorization"), adminToken) {
+			return actorKey(ctx, config.AdminActor)
+		}
+		if token, found := sessionToken(ctx); found && authenticate != nil {
+			if user, authErr := authenticate(ctx, token); authErr == nil {
+				return actorKey(ctx, "user:"+user.ID)
+			}
+		}
+		return ClientKey(ctx)
+	}
+}
+
+func actorKey(ctx *gin.Context, actor string) string {
+	return "actor:" + ctx.GetString(config.TenantContextKey) + ":" + actor
+}
+
+func passThrough(ctx *gin.Context) {
+	ctx.Next()
+}
+
+func ceilSeconds(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
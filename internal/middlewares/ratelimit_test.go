@@ -0,0 +1,221 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("store unavailable")
+}
+
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := config.TestTime
+	store := ratelimit.NewMemoryStore()
+	store.Clock = func() time.Time { return now }
+
+	authenticate := func(ctx context.Context, token string) (models.User, error) {
+		if token == "jane" || token == "john" {
+			return models.User{ID: token, Username: token}, nil
+		}
+		return models.User{}, config.ErrUnauthorized
+	}
+	limiter := &RateLimiter{
+		Store: store,
+		Limits: map[string]ratelimit.Limit{
+			config.RateLimitWrite: {Requests: 2, Period: time.Minute, Burst: 2},
+		},
+		Key: CredentialKey("secret", authenticate),
+	}
+
+	r := gin.New()
+	r.Use(RequestContext(), func(ctx *gin.Context) {
+		if tenant := ctx.GetHeader(config.TenantHeader); tenant != "" {
+			ctx.Set(config.TenantContextKey, tenant)
+		}
+	})
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	r.POST("/users", limiter.Group(config.RateLimitWrite), ok)
+	r.GET("/users", limiter.Group(config.RateLimitRead), ok)
+	r.POST("/admin", limiter.Group(config.RateLimitWrite), AdminAuth("secret"), ok)
+	r.POST("/session", limiter.Group(config.RateLimitWrite), SessionAuth(authenticate), ok)
+	r.POST("/failing", (&RateLimiter{Store: failingStore{}, Limits: limiter.Limits}).Group(config.RateLimitWrite), ok)
+
+	tests := []struct {
+		Name              string
+		Method            string
+		Path              string
+		RemoteAddr        string
+		Authorization     string
+		Tenant            string
+		ExpectedCode      int
+		ExpectedRemaining string
+		ExpectedRetry     string
+	}{
+		{
+			Name:              "First request",
+			Method:            http.MethodPost,
+			Path:              "/users",
+			RemoteAddr:        "10.0.0.1:1234",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "1",
+		},
+		{
+			Name:              "Last token",
+			Method:            http.MethodPost,
+			Path:              "/users",
+			RemoteAddr:        "10.0.0.1:1234",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "0",
+		},
+		{
+			Name:              "Limited",
+			Method:            http.MethodPost,
+			Path:              "/users",
+			RemoteAddr:        "10.0.0.1:1234",
+			ExpectedCode:      http.StatusTooManyRequests,
+			ExpectedRemaining: "0",
+			ExpectedRetry:     "30",
+		},
+		{
+			Name:              "Other client",
+			Method:            http.MethodPost,
+			Path:              "/users",
+			RemoteAddr:        "10.0.0.2:1234",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "1",
+		},
+		{
+			Name:              "Authenticated actor has its own bucket",
+			Method:            http.MethodPost,
+			Path:              "/admin",
+			RemoteAddr:        "10.0.0.1:1234",
+			Authorization:     "Bearer secret",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "1",
+		},
+		{
+			Name:              "Session user has its own bucket",
+			Method:            http.MethodPost,
+			Path:              "/session",
+			RemoteAddr:        "10.0.0.1:1234",
+			Authorization:     "Bearer jane",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "1",
+		},
+		{
+			Name:              "Another session user behind the same address",
+			Method:            http.MethodPost,
+			Path:              "/session",
+			RemoteAddr:        "10.0.0.1:1234",
+			Authorization:     "Bearer john",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "1",
+		},
+		{
+			Name:              "Same session user",
+			Method:            http.MethodPost,
+			Path:              "/session",
+			RemoteAddr:        "10.0.0.3:1234",
+			Authorization:     "Bearer jane",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "0",
+		},
+		{
+			Name:              "Admin in another tenant",
+			Method:            http.MethodPost,
+			Path:              "/admin",
+			RemoteAddr:        "10.0.0.1:1234",
+			Authorization:     "Bearer secret",
+			Tenant:            "globex",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "1",
+		},
+		{
+			Name:              "Unknown credential shares the address bucket",
+			Method:            http.MethodPost,
+			Path:              "/session",
+			RemoteAddr:        "10.0.0.2:1234",
+			Authorization:     "Bearer guess",
+			ExpectedCode:      http.StatusUnauthorized,
+			ExpectedRemaining: "0",
+		},
+		{
+			Name:              "Unconfigured group is not limited",
+			Method:            http.MethodGet,
+			Path:              "/users",
+			RemoteAddr:        "10.0.0.1:1234",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "",
+		},
+		{
+			Name:              "Store errors fail open",
+			Method:            http.MethodPost,
+			Path:              "/failing",
+			RemoteAddr:        "10.0.0.1:1234",
+			ExpectedCode:      http.StatusOK,
+			ExpectedRemaining: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.Method, tt.Path, nil)
+			req.RemoteAddr = tt.RemoteAddr
+			if tt.Authorization != "" {
+				req.Header.Set("Authorization", tt.Authorization)
+			}
+			if tt.Tenant != "" {
+				req.Header.Set(config.TenantHeader, tt.Tenant)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedRemaining, w.Header().Get(config.RateLimitRemainingHeader))
+			assert.Equal(t, tt.ExpectedRetry, w.Header().Get(config.RetryAfterHeader))
+		})
+	}
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := &RateLimiter{
+		Store:  ratelimit.NewMemoryStore(),
+		Limits: map[string]ratelimit.Limit{config.RateLimitRead: {Requests: 60, Period: time.Minute, Burst: 60}},
+	}
+
+	r := gin.New()
+	r.GET("/users", limiter.Group(config.RateLimitRead), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "60;w=60", w.Header().Get(config.RateLimitPolicyHeader))
+	assert.Equal(t, "60", w.Header().Get(config.RateLimitLimitHeader))
+	assert.Equal(t, "59", w.Header().Get(config.RateLimitRemainingHeader))
+	assert.Equal(t, "1", w.Header().Get(config.RateLimitResetHeader))
+
+	var nilLimiter *RateLimiter
+	r.GET("/open", nilLimiter.Group(config.RateLimitRead), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	open := httptest.NewRecorder()
+	openReq, _ := http.NewRequest(http.MethodGet, "/open", nil)
+	r.ServeHTTP(open, openReq)
+	assert.Equal(t, http.StatusOK, open.Code)
+}
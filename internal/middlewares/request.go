@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func RequestContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(config.RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		ctx.Header(config.RequestIDHeader, requestID)
+
+		ctx.Set(config.AuditContextKey, models.AuditContext{
+			Actor:     config.AnonymousActor,
+			RequestID: requestID,
+			IP:        ctx.ClientIP(),
+		})
+
+		ctx.Next()
+	}
+}
+
+func SetActor(ctx *gin.Context, actor string) {
+	meta, _ := ctx.Value(config.AuditContextKey).(models.AuditContext)
+	meta.Actor = actor
+	if meta.IP == "" {
+		meta.IP = ctx.ClientIP()
+	}
+	ctx.Set(config.AuditContextKey, meta)
+}
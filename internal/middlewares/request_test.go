@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		Name          string
+		RequestID     string
+		Authorization string
+		ExpectedActor string
+	}{
+		{
+			Name:          "Anonymous with given request id",
+			RequestID:     "request-1",
+			ExpectedActor: config.AnonymousActor,
+		},
+		{
+			Name:          "Admin with generated request id",
+			Authorization: "Bearer secret",
+			ExpectedActor: config.AdminActor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var meta models.AuditContext
+
+			r := gin.New()
+			r.Use(RequestContext())
+			handler := func(ctx *gin.Context) {
+				meta = ctx.Value(config.AuditContextKey).(models.AuditContext)
+				ctx.Status(http.StatusOK)
+			}
+			if tt.Authorization != "" {
+				r.GET("/", AdminAuth("secret"), handler)
+			} else {
+				r.GET("/", handler)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			if tt.RequestID != "" {
+				req.Header.Set(config.RequestIDHeader, tt.RequestID)
+			}
+			if tt.Authorization != "" {
+				req.Header.Set("Authorization", tt.Authorization)
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedActor, meta.Actor)
+			assert.Equal(t, "10.0.0.1", meta.IP)
+			assert.Equal(t, w.Header().Get(config.RequestIDHeader), meta.RequestID)
+			if tt.RequestID != "" {
+				assert.Equal(t, tt.RequestID, meta.RequestID)
+			}
+		})
+	}
+}
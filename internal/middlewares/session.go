@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type Authenticator func(ctx context.Context, token string) (models.User, error)
+
+func SessionAuth(authenticate Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := sessionUser(ctx, authenticate)
+		if !ok {
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(config.SessionUserKey, user)
+		SetActor(ctx, user.Username)
+		ctx.Next()
+	}
+}
+
+func sessionUser(ctx *gin.Context, authenticate Authenticator) (models.User, bool) {
+	token, found := sessionToken(ctx)
+	if !found {
+		web.NewError(ctx, http.StatusUnauthorized, config.ErrUnauthorized.Error())
+		return models.User{}, false
+	}
+
+	user, authErr := authenticate(ctx, token)
+	if authErr != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(authErr, config.ErrUnauthorized) {
+			status = http.StatusUnauthorized
+		}
+		web.NewError(ctx, status, authErr.Error())
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func sessionToken(ctx *gin.Context) (string, bool) {
+	authorization := ctx.GetHeader("Authorization")
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	if authorization == "" && methodScope(ctx.Request.Method) == config.ScopeRead {
+		cookie, cookieErr := ctx.Cookie(config.SessionCookieName)
+		token, found = cookie, cookieErr == nil
+	}
+	return token, found && token != ""
+}
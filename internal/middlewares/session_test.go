@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestSessionAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticate := func(ctx context.Context, token string) (models.User, error) {
+		switch token {
+		case "valid":
+			return models.User{ID: "1", Username: "johndoe"}, nil
+		case "broken":
+			return models.User{}, errors.New("database is locked")
+		default:
+			return models.User{}, config.ErrUnauthorized
+		}
+	}
+
+	tests := []struct {
+		Name          string
+		Method        string
+		Authorization string
+		Cookie        string
+		ExpectedCode  int
+		ExpectedActor string
+	}{
+		{
+			Name:          "Success",
+			Authorization: "Bearer valid",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "johndoe",
+		},
+		{
+			Name:          "Unknown token",
+			Authorization: "Bearer other",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+		{
+			Name:          "Missing header",
+			Authorization: "",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+		{
+			Name:          "Lookup error",
+			Authorization: "Bearer broken",
+			ExpectedCode:  http.StatusInternalServerError,
+		},
+		{
+			Name:          "Session cookie",
+			Cookie:        "valid",
+			ExpectedCode:  http.StatusOK,
+			ExpectedActor: "johndoe",
+		},
+		{
+			Name:         "Session cookie on unsafe method",
+			Method:       http.MethodPost,
+			Cookie:       "valid",
+			ExpectedCode: http.StatusUnauthorized,
+		},
+		{
+			Name:          "Header takes precedence over cookie",
+			Authorization: "Bearer other",
+			Cookie:        "valid",
+			ExpectedCode:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var actor string
+
+			r := gin.New()
+			r.Match([]string{http.MethodGet, http.MethodPost}, "/me", SessionAuth(authenticate), func(ctx *gin.Context) {
+				user := ctx.MustGet(config.SessionUserKey).(models.User)
+				meta := ctx.MustGet(config.AuditContextKey).(models.AuditContext)
+				assert.Equal(t, user.Username, meta.Actor)
+				actor = meta.Actor
+				ctx.Status(http.StatusOK)
+			})
+
+			method := http.MethodGet
+			if tt.Method != "" {
+				method = tt.Method
+			}
+
+			req, _ := http.NewRequest(method, "/me", nil)
+			if tt.Authorization != "" {
+				req.Header.Set("Authorization", tt.Authorization)
+			}
+			if tt.Cookie != "" {
+				req.AddCookie(&http.Cookie{Name: config.SessionCookieName, Value: tt.Cookie})
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedActor, actor)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type TenantLookup func(ctx context.Context, authorization string) (string, error)
+
+func Tenant(tenants []string, domain, adminToken string, lookup TenantLookup) gin.HandlerFunc {
+	known := map[string]bool{config.DefaultTenant: true}
+	for _, tenant := range tenants {
+		known[strings.ToLower(tenant)] = true
+	}
+
+	return func(ctx *gin.Context) {
+		selected := strings.ToLower(strings.TrimSpace(ctx.GetHeader(config.TenantHeader)))
+		if selected == "" {
+			selected = subdomainTenant(ctx.Request.Host, domain)
+		}
+
+		bound := ""
+		if credential := requestCredential(ctx); credential != "" && lookup != nil {
+			found, lookupErr := lookup(ctx, credential)
+			if lookupErr != nil {
+				web.NewError(ctx, http.StatusInternalServerError, lookupErr.Error())
+				ctx.Abort()
+				return
+			}
+			bound = found
+		}
+
+		tenant := bound
+		switch {
+		case bound != "" && selected != "" && selected != bound:
+			web.NewError(ctx, http.StatusForbidden, config.ErrTenantMismatch.Error())
+			ctx.Abort()
+			return
+		case bound == "" && selected != "":
+			if !isAdminToken(ctx.GetHeader("Authorization"), adminToken) && !entryRoute(ctx.FullPath()) {
+				web.NewError(ctx, http.StatusForbidden, config.ErrTenantSelection.Error())
+				ctx.Abort()
+				return
+			}
+			tenant = selected
+		case bound == "":
+			tenant = config.DefaultTenant
+		}
+
+		if !known[tenant] {
+			web.NewError(ctx, http.StatusNotFound, config.ErrTenantNotFound.Error())
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(config.TenantContextKey, tenant)
+		ctx.Next()
+	}
+}
+
+func subdomainTenant(host, domain string) string {
+	if domain == "" {
+		return ""
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(domain))
+	if !found || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+func requestCredential(ctx *gin.Context) string {
+	if authorization := ctx.GetHeader("Authorization"); authorization != "" {
+		return authorization
+	}
+	if methodScope(ctx.Request.Method) == config.ScopeRead {
+		if cookie, cookieErr := ctx.Cookie(config.SessionCookieName); cookieErr == nil && cookie != "" {
+			return "Bearer " + cookie
+		}
+	}
+	return ""
+}
+
+func entryRoute(path string) bool {
+	if rest, found := strings.CutPrefix(path, "/api/"); found {
+		_, route, _ := strings.Cut(rest, "/")
+		path = "/" + route
+	}
+	return slices.Contains(config.TenantEntryRoutes, path)
+}
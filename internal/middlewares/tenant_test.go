@@ -0,0 +1,214 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lookup := func(ctx context.Context, authorization string) (string, error) {
+		switch authorization {
+		case "Bearer acme-session", "ApiKey gm_acmekey_secret":
+			return "acme", nil
+		case "Bearer broken":
+			return "", errors.New("database is locked")
+		default:
+			return "", nil
+		}
+	}
+
+	tests := []struct {
+		Name           string
+		Method         string
+		Path           string
+		Host           string
+		Header         string
+		Authorization  string
+		Cookie         string
+		ExpectedCode   int
+		ExpectedTenant string
+	}{
+		{
+			Name:           "Default tenant",
+			Method:         http.MethodGet,
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: config.DefaultTenant,
+		},
+		{
+			Name:         "Anonymous header",
+			Method:       http.MethodGet,
+			Header:       "Globex",
+			ExpectedCode: http.StatusForbidden,
+		},
+		{
+			Name:         "Anonymous subdomain",
+			Method:       http.MethodGet,
+			Host:         "acme.example.com:8080",
+			ExpectedCode: http.StatusForbidden,
+		},
+		{
+			Name:           "Header on login",
+			Method:         http.MethodPost,
+			Path:           "/api/v1/login",
+			Header:         "Globex",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "globex",
+		},
+		{
+			Name:           "Header wins over subdomain on login",
+			Method:         http.MethodPost,
+			Path:           "/api/v2/login",
+			Host:           "acme.example.com",
+			Header:         "globex",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "globex",
+		},
+		{
+			Name:           "Subdomain on OIDC token",
+			Method:         http.MethodPost,
+			Path:           config.OIDCTokenPath,
+			Host:           "acme.example.com:8080",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "acme",
+		},
+		{
+			Name:           "Admin token selects tenant",
+			Method:         http.MethodGet,
+			Header:         "globex",
+			Authorization:  "Bearer admin-secret",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "globex",
+		},
+		{
+			Name:          "Wrong admin token",
+			Method:        http.MethodGet,
+			Header:        "globex",
+			Authorization: "Bearer not-the-secret",
+			ExpectedCode:  http.StatusForbidden,
+		},
+		{
+			Name:           "Nested subdomain is ignored",
+			Method:         http.MethodGet,
+			Host:           "api.acme.example.com",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: config.DefaultTenant,
+		},
+		{
+			Name:           "Session token with matching header",
+			Method:         http.MethodGet,
+			Header:         "acme",
+			Authorization:  "Bearer acme-session",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "acme",
+		},
+		{
+			Name:          "Session token with conflicting header",
+			Method:        http.MethodGet,
+			Header:        "globex",
+			Authorization: "Bearer acme-session",
+			ExpectedCode:  http.StatusForbidden,
+		},
+		{
+			Name:          "API key with conflicting subdomain",
+			Method:        http.MethodGet,
+			Host:          "globex.example.com",
+			Authorization: "ApiKey gm_acmekey_secret",
+			ExpectedCode:  http.StatusForbidden,
+		},
+		{
+			Name:         "Session cookie with conflicting header on login",
+			Method:       http.MethodGet,
+			Path:         "/api/v1/login/corp",
+			Header:       "globex",
+			Cookie:       "acme-session",
+			ExpectedCode: http.StatusForbidden,
+		},
+		{
+			Name:           "Session token",
+			Method:         http.MethodPost,
+			Authorization:  "Bearer acme-session",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "acme",
+		},
+		{
+			Name:           "API key",
+			Method:         http.MethodDelete,
+			Authorization:  "ApiKey gm_acmekey_secret",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "acme",
+		},
+		{
+			Name:           "Session cookie on GET",
+			Method:         http.MethodGet,
+			Cookie:         "acme-session",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: "acme",
+		},
+		{
+			Name:           "Session cookie on POST",
+			Method:         http.MethodPost,
+			Cookie:         "acme-session",
+			ExpectedCode:   http.StatusOK,
+			ExpectedTenant: config.DefaultTenant,
+		},
+		{
+			Name:         "Unknown tenant",
+			Method:       http.MethodGet,
+			Path:         "/api/v1/login/:provider",
+			Header:       "initech",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Name:          "Lookup error",
+			Method:        http.MethodGet,
+			Authorization: "Bearer broken",
+			ExpectedCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var tenant string
+			if tt.Path == "" {
+				tt.Path = "/api/v1/users"
+			}
+
+			r := gin.New()
+			r.Handle(tt.Method, tt.Path, Tenant([]string{"acme", "Globex"}, "example.com", "admin-secret", lookup), func(ctx *gin.Context) {
+				tenant = ctx.GetString(config.TenantContextKey)
+				ctx.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(tt.Method, strings.ReplaceAll(tt.Path, ":provider", "corp"), nil)
+			if tt.Host != "" {
+				req.Host = tt.Host
+			}
+			if tt.Header != "" {
+				req.Header.Set(config.TenantHeader, tt.Header)
+			}
+			if tt.Authorization != "" {
+				req.Header.Set("Authorization", tt.Authorization)
+			}
+			if tt.Cookie != "" {
+				req.AddCookie(&http.Cookie{Name: config.SessionCookieName, Value: tt.Cookie})
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedTenant, tenant)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+func APIVersion(version string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requested := AcceptedVersion(ctx.GetHeader("Accept"))
+		if requested != "" && requested != version {
+			web.NewError(ctx, http.StatusNotAcceptable, config.ErrVersionNotAcceptable.Error())
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(config.APIVersionKey, version)
+		ctx.Header(config.APIVersionHeader, version)
+		ctx.Next()
+	}
+}
+
+func AcceptedVersion(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+		version, found := strings.CutPrefix(strings.TrimSpace(mediaType), config.VendorMediaTypePrefix)
+		if !found {
+			continue
+		}
+		return strings.TrimSuffix(version, config.VendorMediaTypeSuffix)
+	}
+	return ""
+}
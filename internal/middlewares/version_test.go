@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"go-manage/cmd/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestAPIVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		Name            string
+		Accept          string
+		ExpectedCode    int
+		ExpectedVersion string
+	}{
+		{
+			Name:            "No vendor media type",
+			Accept:          "application/json",
+			ExpectedCode:    http.StatusOK,
+			ExpectedVersion: "v2",
+		},
+		{
+			Name:            "Matching vendor media type",
+			Accept:          "application/vnd.go-manage.v2+json",
+			ExpectedCode:    http.StatusOK,
+			ExpectedVersion: "v2",
+		},
+		{
+			Name:            "Conflicting vendor media type",
+			Accept:          "application/vnd.go-manage.v1+json",
+			ExpectedCode:    http.StatusNotAcceptable,
+			ExpectedVersion: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var version string
+
+			r := gin.New()
+			r.GET("/", APIVersion("v2"), func(ctx *gin.Context) {
+				version = ctx.GetString(config.APIVersionKey)
+				ctx.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.Accept)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedVersion, version)
+			assert.Equal(t, tt.ExpectedVersion, w.Header().Get(config.APIVersionHeader))
+		})
+	}
+}
+
+func TestAcceptedVersion(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Accept   string
+		Expected string
+	}{
+		{
+			Name:     "Empty",
+			Accept:   "",
+			Expected: "",
+		},
+		{
+			Name:     "Generic JSON",
+			Accept:   "application/json, */*;q=0.8",
+			Expected: "",
+		},
+		{
+			Name:     "Vendor media type",
+			Accept:   "application/vnd.go-manage.v2+json",
+			Expected: "v2",
+		},
+		{
+			Name:     "Vendor media type with parameters",
+			Accept:   "text/html, application/vnd.go-manage.v1+json; q=0.9",
+			Expected: "v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, AcceptedVersion(tt.Accept))
+		})
+	}
+}
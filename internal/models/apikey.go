@@ -0,0 +1,55 @@
+package models
+
+import (
+	"go-manage/cmd/config"
+	"slices"
+	"time"
+)
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, config.ScopeAdmin) || slices.Contains(k.Scopes, scope)
+}
+
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,notblank,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,api_scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	APIKey  APIKey `json:"api_key"`
+}
+
+type ListAPIKeysResponse struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+type APIKeyV2Response struct {
+	Data APIKey `json:"data"`
+}
+
+type ListAPIKeysV2Response struct {
+	Data []APIKey `json:"data"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type AuditEvent struct {
+	ID         string                 `json:"id"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Actor      string                 `json:"actor"`
+	Action     string                 `json:"action"`
+	Target     string                 `json:"target"`
+	Changes    map[string]FieldChange `json:"changes,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	TenantID   string                 `json:"-"`
+}
+
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditContext struct {
+	Actor     string
+	RequestID string
+	IP        string
+}
+
+type AuditFilter struct {
+	TenantID string
+	Actor    string
+	Action   string
+	Target   string
+	Limit    int
+	Offset   int
+}
+
+type ListAuditResponse struct {
+	Status  string       `json:"status"`
+	Message string       `json:"message"`
+	Events  []AuditEvent `json:"events"`
+}
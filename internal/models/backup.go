@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BackupResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Backup  Backup `json:"backup"`
+}
+
+type ListBackupsResponse struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Backups []Backup `json:"backups"`
+}
+
+type BackupV2Response struct {
+	Data Backup `json:"data"`
+}
+
+type ListBackupsV2Response struct {
+	Data []Backup `json:"data"`
+}
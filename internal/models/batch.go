@@ -0,0 +1,59 @@
+package models
+
+type BatchRequest struct {
+	Mode       string           `json:"mode,omitempty"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,max=100"`
+}
+
+type BatchOperation struct {
+	Op      string             `json:"op"`
+	ID      string             `json:"id,omitempty"`
+	Version int                `json:"version,omitempty"`
+	User    *CreateUserRequest `json:"user,omitempty"`
+	Patch   *UpdateUserRequest `json:"patch,omitempty"`
+}
+
+type BatchReport struct {
+	Mode      string        `json:"mode"`
+	Committed bool          `json:"committed"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+	User   *User  `json:"user,omitempty"`
+	Err    error  `json:"-"`
+}
+
+type BatchResponse struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Batch   BatchReport `json:"batch"`
+}
+
+type BatchReportV2 struct {
+	Mode      string          `json:"mode"`
+	Committed bool            `json:"committed"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Results   []BatchResultV2 `json:"results"`
+}
+
+type BatchResultV2 struct {
+	Index  int     `json:"index"`
+	Op     string  `json:"op"`
+	ID     string  `json:"id,omitempty"`
+	Status int     `json:"status"`
+	Error  string  `json:"error,omitempty"`
+	User   *UserV2 `json:"user,omitempty"`
+}
+
+type BatchV2Response struct {
+	Data BatchReportV2 `json:"data"`
+}
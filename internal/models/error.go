@@ -0,0 +1,6 @@
+package models
+
+type ErrorResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+type ExternalIdentity struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Issuer      string     `json:"issuer"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+type FederationState struct {
+	Hash         string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	UserID       string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+type FederationCallback struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+type FederationResult struct {
+	Login  LoginResult
+	Linked *ExternalIdentity
+}
+
+type IdentityResponse struct {
+	Status   string           `json:"status"`
+	Message  string           `json:"message"`
+	Identity ExternalIdentity `json:"identity"`
+}
+
+type ListIdentitiesResponse struct {
+	Status     string             `json:"status"`
+	Message    string             `json:"message"`
+	Identities []ExternalIdentity `json:"identities"`
+}
+
+type IdentityV2Response struct {
+	Data ExternalIdentity `json:"data"`
+}
+
+type ListIdentitiesV2Response struct {
+	Data []ExternalIdentity `json:"data"`
+}
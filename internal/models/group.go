@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+type Group struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ParentID    *string   `json:"parent_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type GroupMember struct {
+	GroupID   string    `json:"group_id"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Membership struct {
+	Group      Group     `json:"group"`
+	Role       string    `json:"role"`
+	ViaGroupID *string   `json:"via_group_id"`
+	CreatedAt  time.Time `json:"member_since"`
+}
+
+type CreateGroupRequest struct {
+	Name        string  `json:"name" binding:"required,notblank,max=100"`
+	Description string  `json:"description" binding:"max=500"`
+	ParentID    *string `json:"parent_id"`
+}
+
+type UpdateGroupRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,notblank,max=100"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=500"`
+	ParentID    *string `json:"parent_id,omitempty"`
+}
+
+type GroupMemberRequest struct {
+	Role string `json:"role" binding:"required,group_role"`
+}
+
+type GroupResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Group   Group  `json:"group"`
+}
+
+type ListGroupsResponse struct {
+	Status  string  `json:"status"`
+	Message string  `json:"message"`
+	Groups  []Group `json:"groups"`
+}
+
+type GroupMemberResponse struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Member  GroupMember `json:"member"`
+}
+
+type ListGroupMembersResponse struct {
+	Status  string        `json:"status"`
+	Message string        `json:"message"`
+	Members []GroupMember `json:"members"`
+}
+
+type ListMembershipsResponse struct {
+	Status  string       `json:"status"`
+	Message string       `json:"message"`
+	Groups  []Membership `json:"groups"`
+}
+
+type GroupV2Response struct {
+	Data Group `json:"data"`
+}
+
+type ListGroupsV2Response struct {
+	Data []Group `json:"data"`
+}
+
+type GroupMemberV2Response struct {
+	Data GroupMember `json:"data"`
+}
+
+type ListGroupMembersV2Response struct {
+	Data []GroupMember `json:"data"`
+}
+
+type ListMembershipsV2Response struct {
+	Data []Membership `json:"data"`
+}
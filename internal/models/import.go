@@ -0,0 +1,35 @@
+package models
+
+type ImportOptions struct {
+	DryRun     bool
+	OnConflict string
+}
+
+type ImportReport struct {
+	DryRun           bool          `json:"dry_run"`
+	OnConflict       string        `json:"on_conflict"`
+	Total            int           `json:"total"`
+	Created          int           `json:"created"`
+	Updated          int           `json:"updated"`
+	Skipped          int           `json:"skipped"`
+	Failed           int           `json:"failed"`
+	PasswordsIgnored int           `json:"passwords_ignored"`
+	Aborted          bool          `json:"aborted"`
+	Errors           []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Row      int    `json:"row"`
+	Username string `json:"username,omitempty"`
+	Error    string `json:"error"`
+}
+
+type ImportResponse struct {
+	Status  string       `json:"status"`
+	Message string       `json:"message"`
+	Report  ImportReport `json:"report"`
+}
+
+type ImportV2Response struct {
+	Data ImportReport `json:"data"`
+}
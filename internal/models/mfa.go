@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+type TOTPSecret struct {
+	UserID       string
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+type MFAChallenge struct {
+	Token     string    `json:"challenge_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type LoginResult struct {
+	Session   Session
+	Challenge *MFAChallenge
+}
+
+type MFALoginRequest struct {
+	Challenge string `json:"challenge_token" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type MFAChallengeResponse struct {
+	Status    string       `json:"status"`
+	Message   string       `json:"message"`
+	Challenge MFAChallenge `json:"challenge"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Status     string         `json:"status"`
+	Message    string         `json:"message"`
+	Enrollment TOTPEnrollment `json:"enrollment"`
+}
+
+type RecoveryCodesResponse struct {
+	Status        string   `json:"status"`
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAChallengeV2Response struct {
+	Data MFAChallenge `json:"data"`
+}
+
+type TOTPEnrollmentV2Response struct {
+	Data TOTPEnrollment `json:"data"`
+}
+
+type RecoveryCodesV2Response struct {
+	Data RecoveryCodes `json:"data"`
+}
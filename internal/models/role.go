@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+type Role struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type PermissionDecision struct {
+	UserID     string `json:"user_id"`
+	Action     string `json:"action"`
+	Resource   string `json:"resource,omitempty"`
+	Allowed    bool   `json:"allowed"`
+	Role       string `json:"role,omitempty"`
+	Permission string `json:"permission,omitempty"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,notblank,max=100"`
+	Description string   `json:"description" binding:"max=500"`
+	Permissions []string `json:"permissions" binding:"required,min=1,dive,permission"`
+}
+
+type UpdateRoleRequest struct {
+	Name        *string   `json:"name,omitempty" binding:"omitempty,notblank,max=100"`
+	Description *string   `json:"description,omitempty" binding:"omitempty,max=500"`
+	Permissions *[]string `json:"permissions,omitempty" binding:"omitempty,min=1,dive,permission"`
+}
+
+type RoleResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Role    Role   `json:"role"`
+}
+
+type ListRolesResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Roles   []Role `json:"roles"`
+}
+
+type ListPermissionsResponse struct {
+	Status      string   `json:"status"`
+	Message     string   `json:"message"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionDecisionResponse struct {
+	Status   string             `json:"status"`
+	Message  string             `json:"message"`
+	Decision PermissionDecision `json:"decision"`
+}
+
+type RoleV2Response struct {
+	Data Role `json:"data"`
+}
+
+type ListRolesV2Response struct {
+	Data []Role `json:"data"`
+}
+
+type ListPermissionsV2Response struct {
+	Data []string `json:"data"`
+}
+
+type PermissionDecisionV2Response struct {
+	Data PermissionDecision `json:"data"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type Session struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginResponse struct {
+	Status  string  `json:"status"`
+	Message string  `json:"message"`
+	Session Session `json:"session"`
+}
+
+type SessionV2Response struct {
+	Data Session `json:"data"`
+}
@@ -1,12 +1,52 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Surname           string     `json:"surname"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Password          string     `json:"-"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+}
+
+type CreateUserRequest struct {
+	Name     string `json:"name" binding:"required,notblank,max=100"`
+	Surname  string `json:"surname" binding:"required,notblank,max=100"`
+	Username string `json:"username" binding:"required,min=3,max=32,username"`
+	Email    string `json:"email" binding:"required,max=254,user_email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type UpdateUserRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,notblank,max=100"`
+	Surname  *string `json:"surname,omitempty" binding:"omitempty,notblank,max=100"`
+	Username *string `json:"username,omitempty" binding:"omitempty,notblank,min=3,max=32,username"`
+	Email    *string `json:"email,omitempty" binding:"omitempty,notblank,max=254,user_email"`
+}
+
+type ChangePwdRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type UserFilter struct {
+	Sort   string
+	Desc   bool
+	Ranges []TimeRange
+	Limit  int
+	Offset int
+}
+
+type TimeRange struct {
+	Field  string
+	After  *time.Time
+	Before *time.Time
 }
 
 type CreateUserResponse struct {
@@ -20,17 +60,52 @@ type SearchResponse struct {
 	User    User   `json:"user"`
 }
 
+type ListUsersResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Users   []User `json:"users"`
+}
+
 type DeleteUserResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
 
+type RestoreUserResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	User    User   `json:"user"`
+}
+
 type UpdateUserResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
+	User    User   `json:"user"`
 }
 
 type ChangePwdResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
+
+type UserV2 struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Surname           string     `json:"surname"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+}
+
+type UserV2Response struct {
+	Data UserV2 `json:"data"`
+}
+
+type ListUsersV2Response struct {
+	Data  []UserV2 `json:"data"`
+	Count int      `json:"count"`
+}
@@ -0,0 +1,83 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go-manage/cmd/config"
+	"strings"
+)
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+var encoding = base64.RawURLEncoding
+
+func Sign(key *rsa.PrivateKey, kid, typ string, claims any) (string, error) {
+	rawHeader, headerErr := json.Marshal(header{Algorithm: config.OIDCSigningAlg, Type: typ, KeyID: kid})
+	if headerErr != nil {
+		return "", headerErr
+	}
+	rawClaims, claimsErr := json.Marshal(claims)
+	if claimsErr != nil {
+		return "", claimsErr
+	}
+
+	signingInput := encoding.EncodeToString(rawHeader) + "." + encoding.EncodeToString(rawClaims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, signErr := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if signErr != nil {
+		return "", signErr
+	}
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+func Verify(token, typ string, keys map[string]*rsa.PublicKey, claims any) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", config.ErrInvalidToken)
+	}
+
+	rawHeader, headerErr := encoding.DecodeString(parts[0])
+	if headerErr != nil {
+		return fmt.Errorf("%w: malformed header", config.ErrInvalidToken)
+	}
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return fmt.Errorf("%w: malformed header", config.ErrInvalidToken)
+	}
+	typed := h.Type == typ || (h.Type == "" && typ == config.OIDCIDTokenType)
+	if h.Algorithm != config.OIDCSigningAlg || !typed {
+		return fmt.Errorf("%w: unexpected %s token signed with %s", config.ErrInvalidToken, h.Type, h.Algorithm)
+	}
+
+	key, found := keys[h.KeyID]
+	if !found {
+		return fmt.Errorf("%w: unknown signing key", config.ErrInvalidToken)
+	}
+	signature, signatureErr := encoding.DecodeString(parts[2])
+	if signatureErr != nil {
+		return fmt.Errorf("%w: malformed signature", config.ErrInvalidToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("%w: bad signature", config.ErrInvalidToken)
+	}
+
+	rawClaims, claimsErr := encoding.DecodeString(parts[1])
+	if claimsErr != nil {
+		return fmt.Errorf("%w: malformed claims", config.ErrInvalidToken)
+	}
+	if err := json.Unmarshal(rawClaims, claims); err != nil {
+		return fmt.Errorf("%w: malformed claims", config.ErrInvalidToken)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"math/big"
+)
+
+func GenerateKey() (*rsa.PrivateKey, string, error) {
+	key, keyErr := rsa.GenerateKey(rand.Reader, config.OIDCSigningKeyBits)
+	if keyErr != nil {
+		return nil, "", keyErr
+	}
+	der, derErr := x509.MarshalPKCS8PrivateKey(key)
+	if derErr != nil {
+		return nil, "", derErr
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func ParseKey(encoded string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("invalid signing key PEM")
+	}
+	parsed, parseErr := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key is not an RSA key")
+	}
+	return key, nil
+}
+
+func KeyID(key *rsa.PublicKey) string {
+	sum := sha256.Sum256(key.N.Bytes())
+	return encoding.EncodeToString(sum[:12])
+}
+
+func PublicJWK(kid string, key *rsa.PublicKey) models.JWK {
+	return models.JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: config.OIDCSigningAlg,
+		KeyID:     kid,
+		Modulus:   encoding.EncodeToString(key.N.Bytes()),
+		Exponent:  encoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ParseJWK(jwk models.JWK) (*rsa.PublicKey, error) {
+	if jwk.KeyType != "RSA" {
+		return nil, errors.New("unsupported key type " + jwk.KeyType)
+	}
+	modulus, modulusErr := encoding.DecodeString(jwk.Modulus)
+	exponent, exponentErr := encoding.DecodeString(jwk.Exponent)
+	if modulusErr != nil || exponentErr != nil || len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("malformed RSA key " + jwk.KeyID)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}, nil
+}
@@ -0,0 +1,102 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	generated, encoded, err := GenerateKey()
+	assert.NoError(t, err)
+
+	key, err := ParseKey(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, generated, key)
+	kid := KeyID(&key.PublicKey)
+
+	otherKey, _, err := GenerateKey()
+	assert.NoError(t, err)
+
+	claims := models.TokenClaims{UserInfo: models.UserInfo{Subject: "1"}, Issuer: "https://id.example.com", Audience: "client"}
+	token, err := Sign(key, kid, config.OIDCIDTokenType, claims)
+	assert.NoError(t, err)
+
+	untyped, err := Sign(key, kid, "", claims)
+	assert.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + encoding.EncodeToString([]byte(`{"sub":"2"}`)) + "." + parts[2]
+
+	test := []struct {
+		Name    string
+		Token   string
+		Type    string
+		Keys    map[string]*rsa.PublicKey
+		Invalid bool
+	}{
+		{Name: "Valid", Token: token, Type: config.OIDCIDTokenType, Keys: map[string]*rsa.PublicKey{kid: &key.PublicKey}},
+		{Name: "Wrong type", Token: token, Type: config.OIDCAccessTokenType, Keys: map[string]*rsa.PublicKey{kid: &key.PublicKey}, Invalid: true},
+		{Name: "Untyped ID token", Token: untyped, Type: config.OIDCIDTokenType, Keys: map[string]*rsa.PublicKey{kid: &key.PublicKey}},
+		{Name: "Untyped access token", Token: untyped, Type: config.OIDCAccessTokenType, Keys: map[string]*rsa.PublicKey{kid: &key.PublicKey}, Invalid: true},
+		{Name: "Unknown key", Token: token, Type: config.OIDCIDTokenType, Keys: map[string]*rsa.PublicKey{}, Invalid: true},
+		{Name: "Other key under same kid", Token: token, Type: config.OIDCIDTokenType, Keys: map[string]*rsa.PublicKey{kid: &otherKey.PublicKey}, Invalid: true},
+		{Name: "Tampered claims", Token: tampered, Type: config.OIDCIDTokenType, Keys: map[string]*rsa.PublicKey{kid: &key.PublicKey}, Invalid: true},
+		{Name: "Malformed", Token: "abc", Type: config.OIDCIDTokenType, Keys: map[string]*rsa.PublicKey{kid: &key.PublicKey}, Invalid: true},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var verified models.TokenClaims
+			verifyErr := Verify(tt.Token, tt.Type, tt.Keys, &verified)
+
+			if tt.Invalid {
+				assert.ErrorIs(t, verifyErr, config.ErrInvalidToken)
+			} else {
+				assert.NoError(t, verifyErr)
+				assert.Equal(t, claims, verified)
+			}
+		})
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.Equal(t, challenge, Challenge(verifier))
+	assert.True(t, ValidChallenge(challenge, "S256"))
+	assert.False(t, ValidChallenge(challenge, "plain"))
+	assert.False(t, ValidChallenge("short", "S256"))
+
+	assert.True(t, VerifyPKCE(verifier, challenge))
+	assert.False(t, VerifyPKCE(verifier+"x", challenge))
+	assert.False(t, VerifyPKCE("short", Challenge("short")))
+}
+
+func TestPublicJWK(t *testing.T) {
+	key, _, err := GenerateKey()
+	assert.NoError(t, err)
+	kid := KeyID(&key.PublicKey)
+
+	jwk := PublicJWK(kid, &key.PublicKey)
+
+	assert.Equal(t, "RSA", jwk.KeyType)
+	assert.Equal(t, "AQAB", jwk.Exponent)
+	assert.Equal(t, kid, jwk.KeyID)
+
+	parsed, parseErr := ParseJWK(jwk)
+	assert.NoError(t, parseErr)
+	assert.True(t, key.PublicKey.Equal(parsed))
+
+	jwk.KeyType = "EC"
+	_, parseErr = ParseJWK(jwk)
+	assert.Error(t, parseErr)
+
+	_, err = ParseKey("not a key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"go-manage/cmd/config"
+	"regexp"
+)
+
+var verifierPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+
+func ValidChallenge(challenge, method string) bool {
+	return method == config.OIDCPKCEMethod && len(challenge) == encoding.EncodedLen(sha256.Size) && verifierPattern.MatchString(challenge)
+}
+
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return encoding.EncodeToString(sum[:])
+}
+
+func VerifyPKCE(verifier, challenge string) bool {
+	if !verifierPattern.MatchString(verifier) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(Challenge(verifier)), []byte(challenge)) == 1
+}
@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+type Docs struct {
+	document Document
+}
+
+func (d *Docs) Load(routes gin.RoutesInfo, operations []Operation, errorModel any) error {
+	document, err := Build(routes, operations, errorModel)
+	if err != nil {
+		return err
+	}
+	d.document = document
+	return nil
+}
+
+func (d *Docs) Spec(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, d.document)
+}
+
+func (d *Docs) UI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUI)
+}
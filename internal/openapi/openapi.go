@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Operation struct {
+	Method     string
+	Path       string
+	Summary    string
+	Tag        string
+	Params     []Parameter
+	Body       any
+	BodyTypes  []string
+	Responses  map[int]any
+	Errors     []int
+	Admin      bool
+	Session    bool
+	Permission string
+	Deprecated bool
+}
+
+type Parameter struct {
+	Name     string
+	In       string
+	Type     string
+	Required bool
+}
+
+type Document map[string]any
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func Build(routes gin.RoutesInfo, operations []Operation, errorModel any) (Document, error) {
+	documented := make(map[string]Operation, len(operations))
+	for _, operation := range operations {
+		documented[operation.Method+" "+operation.Path] = operation
+	}
+
+	g := generator{schemas: map[string]any{}}
+	errorSchema := g.schema(reflect.TypeOf(errorModel))
+
+	var drift []string
+	paths := map[string]any{}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		operation, found := documented[key]
+		if !found {
+			drift = append(drift, "undocumented route "+key)
+			continue
+		}
+		delete(documented, key)
+
+		path, pathParams := templatePath(route.Path)
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = g.operation(operation, pathParams, errorSchema)
+	}
+	for key := range documented {
+		drift = append(drift, "documented operation without route "+key)
+	}
+	if len(drift) > 0 {
+		slices.Sort(drift)
+		return nil, errors.New("openapi spec and routes drifted. Error: " + strings.Join(drift, "; "))
+	}
+
+	return Document{
+		"openapi": config.OpenAPIVersion,
+		"info": map[string]any{
+			"title":       config.APITitle,
+			"version":     config.APIDocVersion,
+			"description": config.APIDescription,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				config.AdminSecurityScheme:   map[string]any{"type": "http", "scheme": "bearer"},
+				config.SessionSecurityScheme: map[string]any{"type": "http", "scheme": "bearer"},
+				config.APIKeySecurityScheme:  map[string]any{"type": "apiKey", "in": "header", "name": "Authorization"},
+			},
+		},
+	}, nil
+}
+
+func templatePath(ginPath string) (string, []string) {
+	var params []string
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if name, found := strings.CutPrefix(segment, ":"); found {
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+type generator struct {
+	schemas map[string]any
+}
+
+func (g *generator) operation(operation Operation, pathParams []string, errorSchema map[string]any) map[string]any {
+	result := map[string]any{
+		"operationId": operationID(operation),
+		"summary":     operation.Summary,
+		"tags":        []string{operation.Tag},
+	}
+	if operation.Deprecated {
+		result["deprecated"] = true
+	}
+	if operation.Admin {
+		result["security"] = []map[string][]string{{config.AdminSecurityScheme: {}}, {config.APIKeySecurityScheme: {}}}
+	}
+	if operation.Session {
+		result["security"] = []map[string][]string{{config.SessionSecurityScheme: {}}}
+	}
+	if operation.Permission != "" {
+		result["security"] = []map[string][]string{{config.AdminSecurityScheme: {}}, {config.APIKeySecurityScheme: {}}, {config.SessionSecurityScheme: {}}}
+		result["x-permission"] = operation.Permission
+	}
+
+	var params []map[string]any
+	for _, name := range pathParams {
+		params = append(params, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, param := range operation.Params {
+		params = append(params, map[string]any{
+			"name": param.Name, "in": param.In, "required": param.Required, "schema": map[string]any{"type": param.Type},
+		})
+	}
+	if params != nil {
+		result["parameters"] = params
+	}
+
+	if operation.Body != nil {
+		bodyTypes := operation.BodyTypes
+		if bodyTypes == nil {
+			bodyTypes = []string{config.JSONMediaType}
+		}
+		content := map[string]any{}
+		schema := g.schema(reflect.TypeOf(operation.Body))
+		for _, bodyType := range bodyTypes {
+			content[bodyType] = map[string]any{"schema": schema}
+		}
+		result["requestBody"] = map[string]any{"required": true, "content": content}
+	}
+
+	responses := map[string]any{}
+	for status, model := range operation.Responses {
+		response := map[string]any{"description": http.StatusText(status)}
+		if model != nil {
+			response["content"] = map[string]any{
+				config.JSONMediaType: map[string]any{"schema": g.schema(reflect.TypeOf(model))},
+			}
+		}
+		responses[strconv.Itoa(status)] = response
+	}
+	for _, status := range operation.Errors {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     map[string]any{config.JSONMediaType: map[string]any{"schema": errorSchema}},
+		}
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+func operationID(operation Operation) string {
+	id := strings.ToLower(operation.Method)
+	for _, segment := range strings.Split(operation.Path, "/") {
+		segment = strings.NewReplacer(":", "by_", "-", "_", ".", "_").Replace(segment)
+		if segment != "" {
+			id += "_" + segment
+		}
+	}
+	return id
+}
+
+func (g *generator) schema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, found := g.schemas[t.Name()]; !found {
+			g.schemas[t.Name()] = map[string]any{}
+			g.schemas[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *generator) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = constrain(g.schema(field.Type), field.Tag.Get("binding"))
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+func constrain(schema map[string]any, binding string) map[string]any {
+	if binding == "" || schema["type"] != "string" {
+		return schema
+	}
+
+	for _, rule := range strings.Split(binding, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			length, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			if name == "min" {
+				schema["minLength"] = length
+			} else {
+				schema["maxLength"] = length
+			}
+		case "notblank":
+			if _, found := schema["minLength"]; !found {
+				schema["minLength"] = 1
+			}
+		case "user_email":
+			schema["format"] = "email"
+		case "username":
+			schema["pattern"] = config.UsernamePattern
+		}
+	}
+	return schema
+}
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/users/:id"},
+		{Method: http.MethodPost, Path: "/users"},
+		{Method: http.MethodDelete, Path: "/users/:id"},
+	}
+	getUser := Operation{Method: http.MethodGet, Path: "/users/:id", Summary: "Get a user", Tag: "users",
+		Responses: map[int]any{http.StatusOK: models.SearchResponse{}}, Errors: []int{http.StatusNotFound}, Session: true}
+	createUser := Operation{Method: http.MethodPost, Path: "/users", Summary: "Create a user", Tag: "users",
+		Body: models.ChangePwdRequest{}, Responses: map[int]any{http.StatusCreated: nil}, Admin: true}
+	deleteUser := Operation{Method: http.MethodDelete, Path: "/users/:id", Summary: "Delete a user", Tag: "users",
+		Responses: map[int]any{http.StatusNoContent: nil}, Permission: config.PermUsersDelete}
+
+	test := []struct {
+		Name        string
+		Routes      gin.RoutesInfo
+		Operations  []Operation
+		ExpectedErr string
+	}{
+		{
+			Name:       "Success",
+			Routes:     routes,
+			Operations: []Operation{getUser, createUser, deleteUser},
+		},
+		{
+			Name:        "Undocumented route",
+			Routes:      routes,
+			Operations:  []Operation{getUser},
+			ExpectedErr: "undocumented route POST /users",
+		},
+		{
+			Name:        "Documented operation without route",
+			Routes:      routes[:1],
+			Operations:  []Operation{getUser, createUser},
+			ExpectedErr: "documented operation without route POST /users",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			document, buildErr := Build(tt.Routes, tt.Operations, models.ErrorResponse{})
+
+			if tt.ExpectedErr != "" {
+				assert.ErrorContains(t, buildErr, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, buildErr)
+
+			paths := document["paths"].(map[string]any)
+			get := paths["/users/{id}"].(map[string]any)["get"].(map[string]any)
+			assert.Equal(t, "get_users_by_id", get["operationId"])
+			assert.Equal(t, "id", get["parameters"].([]map[string]any)[0]["name"])
+			assert.Contains(t, get["responses"], "404")
+			assert.Equal(t, []map[string][]string{{config.SessionSecurityScheme: {}}}, get["security"])
+
+			post := paths["/users"].(map[string]any)["post"].(map[string]any)
+			assert.Contains(t, post, "requestBody")
+			assert.Contains(t, post, "security")
+
+			remove := paths["/users/{id}"].(map[string]any)["delete"].(map[string]any)
+			assert.Len(t, remove["security"], 3)
+			assert.Equal(t, config.PermUsersDelete, remove["x-permission"])
+
+			schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+			for _, name := range []string{"SearchResponse", "User", "ChangePwdRequest", "ErrorResponse"} {
+				assert.Contains(t, schemas, name)
+			}
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	g := generator{schemas: map[string]any{}}
+
+	ref := g.schema(reflect.TypeOf(models.User{}))
+
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/User"}, ref)
+
+	user := g.schemas["User"].(map[string]any)
+	properties := user["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, properties["created_at"])
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["version"])
+	assert.Contains(t, user["required"], "username")
+	assert.NotContains(t, user["required"], "last_login_at")
+}
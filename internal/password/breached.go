@@ -0,0 +1,114 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+const prefixLength = 5
+
+type BreachedList struct {
+	Path string
+}
+
+func OpenBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("error opening breached passwords file. Error: " + err.Error())
+	}
+	file.Close()
+
+	return &BreachedList{Path: path}, nil
+}
+
+func (b *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix := hash[:prefixLength]
+
+	file, err := os.Open(b.Path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	low, high := int64(0), info.Size()
+	for low < high {
+		middle := low + (high-low)/2
+		line, found, lineErr := lineFrom(file, middle, info.Size())
+		if lineErr != nil {
+			return false, lineErr
+		}
+		if !found || linePrefix(line) >= prefix {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+
+	reader, err := readerFrom(file, low, info.Size())
+	if err != nil {
+		return false, err
+	}
+	for {
+		line, readErr := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line != "" {
+			if linePrefix(line) > prefix {
+				return false, nil
+			}
+			candidate, _, _ := strings.Cut(line, ":")
+			if strings.EqualFold(candidate, hash) {
+				return true, nil
+			}
+		}
+		if readErr == io.EOF {
+			return false, nil
+		}
+		if readErr != nil {
+			return false, readErr
+		}
+	}
+}
+
+func lineFrom(file *os.File, offset, size int64) (string, bool, error) {
+	reader, err := readerFrom(file, offset, size)
+	if err != nil {
+		return "", false, err
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+	line = strings.TrimSpace(line)
+	return line, line != "", nil
+}
+
+func readerFrom(file *os.File, offset, size int64) (*bufio.Reader, error) {
+	if offset == 0 {
+		return bufio.NewReader(io.NewSectionReader(file, 0, size)), nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(file, offset-1, size-offset+1))
+	if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return reader, nil
+}
+
+func linePrefix(line string) string {
+	if len(line) < prefixLength {
+		return strings.ToUpper(line)
+	}
+	return strings.ToUpper(line[:prefixLength])
+}
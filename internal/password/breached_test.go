@@ -0,0 +1,104 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"go-manage/cmd/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeBreachedFile(t *testing.T, passwords []string, lineEnding string) string {
+	t.Helper()
+
+	var lines []string
+	for _, password := range passwords {
+		lines = append(lines, sha1Hex(password)+":"+fmt.Sprint(len(password)))
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, lineEnding)+lineEnding), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBreachedListContains(t *testing.T) {
+	var passwords []string
+	for i := 0; i < 500; i++ {
+		passwords = append(passwords, fmt.Sprintf("Leaked%dPassword", i))
+	}
+	passwords = append(passwords, "Password1234")
+
+	hashes := make([]string, len(passwords))
+	for i, password := range passwords {
+		hashes[i] = sha1Hex(password)
+	}
+	sorted := slices.Clone(passwords)
+	slices.SortFunc(sorted, func(a, b string) int { return strings.Compare(sha1Hex(a), sha1Hex(b)) })
+
+	for _, lineEnding := range []string{"\n", "\r\n"} {
+		list := BreachedList{Path: writeBreachedFile(t, passwords, lineEnding)}
+
+		test := []struct {
+			Name     string
+			Password string
+			Expected bool
+		}{
+			{Name: "Breached", Password: "Password1234", Expected: true},
+			{Name: "First entry", Password: sorted[0], Expected: true},
+			{Name: "Last entry", Password: sorted[len(sorted)-1], Expected: true},
+			{Name: "Middle entry", Password: sorted[len(sorted)/2], Expected: true},
+			{Name: "Not breached", Password: "Correct-Horse-Battery-Staple-9", Expected: false},
+		}
+
+		for _, tt := range test {
+			t.Run(tt.Name, func(t *testing.T) {
+				breached, err := list.Contains(tt.Password)
+
+				assert.NoError(t, err)
+				assert.Equal(t, tt.Expected, breached)
+			})
+		}
+	}
+}
+
+func TestChecker(t *testing.T) {
+	path := writeBreachedFile(t, []string{"Password1234"}, "\n")
+
+	checker, err := NewChecker(DefaultPolicy(), path)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, checker.Check("Password1234", "johndoe", "johndoe@example.com"), config.ErrBreachedPassword)
+	assert.ErrorIs(t, checker.Check("short", "johndoe", "johndoe@example.com"), config.ErrInvalidPassword)
+	assert.NoError(t, checker.Check("Unbreached5678", "johndoe", "johndoe@example.com"))
+
+	_, missingErr := NewChecker(DefaultPolicy(), filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, missingErr)
+}
+
+func TestReused(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Password1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checker := Checker{Policy: DefaultPolicy()}
+
+	assert.Equal(t, true, checker.Reused("Password1234", []string{"other", string(hash)}))
+	assert.Equal(t, false, checker.Reused("Password5678", []string{string(hash)}))
+	assert.Equal(t, false, checker.Reused("Password1234", nil))
+}
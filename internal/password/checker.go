@@ -0,0 +1,52 @@
+package password
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+)
+
+type Checker struct {
+	Policy   Policy
+	Breached *BreachedList
+}
+
+func NewChecker(policy Policy, breachedFile string) (*Checker, error) {
+	checker := &Checker{Policy: policy}
+	if breachedFile == "" {
+		return checker, nil
+	}
+
+	breached, err := OpenBreachedList(breachedFile)
+	if err != nil {
+		return nil, err
+	}
+	checker.Breached = breached
+	return checker, nil
+}
+
+func (c *Checker) Check(password, username, email string) error {
+	if err := c.Policy.Validate(password, username, email); err != nil {
+		return err
+	}
+
+	if c.Breached != nil {
+		breached, err := c.Breached.Contains(password)
+		if err != nil {
+			return errors.New("error checking breached passwords. Error: " + err.Error())
+		}
+		if breached {
+			return config.ErrBreachedPassword
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) Reused(password string, history []string) bool {
+	for _, hash := range history {
+		if matched, _ := Verify(hash, password); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go-manage/cmd/config"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+type Hasher struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+func DefaultHasher() Hasher {
+	return Hasher{
+		Algorithm:  config.DefaultPasswordHash,
+		BcryptCost: config.DefaultBcryptCost,
+		Argon2: Argon2Params{
+			Memory:      config.DefaultArgon2Memory,
+			Iterations:  config.DefaultArgon2Iterations,
+			Parallelism: config.DefaultArgon2Parallelism,
+		},
+	}
+}
+
+func HasherFromEnv() (Hasher, error) {
+	defaults := DefaultHasher()
+	hasher := Hasher{
+		Algorithm:  defaults.Algorithm,
+		BcryptCost: config.EnvInt(config.BcryptCostEnv, defaults.BcryptCost),
+		Argon2: Argon2Params{
+			Memory:      uint32(config.EnvInt(config.Argon2MemoryEnv, int(defaults.Argon2.Memory))),
+			Iterations:  uint32(config.EnvInt(config.Argon2IterationsEnv, int(defaults.Argon2.Iterations))),
+			Parallelism: uint8(config.EnvInt(config.Argon2ParallelismEnv, int(defaults.Argon2.Parallelism))),
+		},
+	}
+	if algorithm := os.Getenv(config.PasswordHashEnv); algorithm != "" {
+		hasher.Algorithm = algorithm
+	}
+
+	switch hasher.Algorithm {
+	case config.HashArgon2id:
+		if hasher.Argon2.Memory == 0 || hasher.Argon2.Iterations == 0 || hasher.Argon2.Parallelism == 0 {
+			return Hasher{}, errors.New("argon2id memory, iterations and parallelism must be positive")
+		}
+	case config.HashBcrypt:
+		if hasher.BcryptCost < bcrypt.MinCost || hasher.BcryptCost > bcrypt.MaxCost {
+			return Hasher{}, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return Hasher{}, fmt.Errorf("%w: %s", config.ErrUnsupportedHash, hasher.Algorithm)
+	}
+
+	return hasher, nil
+}
+
+func (h Hasher) Hash(password string) (string, error) {
+	if h.Algorithm == config.HashBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, config.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Argon2.Iterations, h.Argon2.Memory, h.Argon2.Parallelism, config.Argon2KeyLength)
+
+	return encodeArgon2(h.Argon2, salt, key), nil
+}
+
+var dummies sync.Map
+
+func (h Hasher) Dummy() (string, error) {
+	if hash, found := dummies.Load(h); found {
+		return hash.(string), nil
+	}
+	hash, err := h.Hash(config.DummyPassword)
+	if err != nil {
+		return "", err
+	}
+	actual, _ := dummies.LoadOrStore(h, hash)
+	return actual.(string), nil
+}
+
+func (h Hasher) NeedsRehash(encoded string) bool {
+	if isBcrypt(encoded) {
+		if h.Algorithm != config.HashBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != h.BcryptCost
+	}
+
+	params, _, _, err := decodeArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	return h.Algorithm != config.HashArgon2id || params != h.Argon2
+}
+
+func Verify(encoded, password string) (bool, error) {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	params, salt, key, err := decodeArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+func isBcrypt(encoded string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(encoded, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeArgon2(params Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", config.HashArgon2id, config.Argon2Version,
+		params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2(encoded string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != config.HashArgon2id {
+		return Argon2Params{}, nil, nil, config.ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != config.Argon2Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2id version %s", config.ErrUnsupportedHash, parts[2])
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil ||
+		params.Iterations == 0 || params.Parallelism == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2id parameters %s", config.ErrUnsupportedHash, parts[3])
+	}
+
+	salt, saltErr := base64.RawStdEncoding.DecodeString(parts[4])
+	if saltErr != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2id salt", config.ErrUnsupportedHash)
+	}
+	key, keyErr := base64.RawStdEncoding.DecodeString(parts[5])
+	if keyErr != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2id key", config.ErrUnsupportedHash)
+	}
+
+	return params, salt, key, nil
+}
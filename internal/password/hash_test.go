@@ -0,0 +1,153 @@
+package password
+
+import (
+	"go-manage/cmd/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testArgon2 = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashAndVerify(t *testing.T) {
+	test := []struct {
+		Name   string
+		Hasher Hasher
+		Prefix string
+	}{
+		{
+			Name:   "Argon2id",
+			Hasher: Hasher{Algorithm: config.HashArgon2id, Argon2: testArgon2},
+			Prefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+		{
+			Name:   "Bcrypt",
+			Hasher: Hasher{Algorithm: config.HashBcrypt, BcryptCost: bcrypt.MinCost},
+			Prefix: "$2a$04$",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			hash, hashErr := tt.Hasher.Hash("Password1234")
+			assert.NoError(t, hashErr)
+			assert.Equal(t, true, strings.HasPrefix(hash, tt.Prefix))
+
+			matched, verifyErr := Verify(hash, "Password1234")
+			assert.NoError(t, verifyErr)
+			assert.Equal(t, true, matched)
+
+			mismatched, mismatchErr := Verify(hash, "Password5678")
+			assert.NoError(t, mismatchErr)
+			assert.Equal(t, false, mismatched)
+
+			assert.Equal(t, false, tt.Hasher.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon := Hasher{Algorithm: config.HashArgon2id, Argon2: testArgon2}
+	weakArgon, _ := Hasher{Algorithm: config.HashArgon2id, Argon2: Argon2Params{Memory: 512, Iterations: 1, Parallelism: 1}}.Hash("Password1234")
+	weakBcrypt, _ := bcrypt.GenerateFromPassword([]byte("Password1234"), bcrypt.MinCost)
+
+	test := []struct {
+		Name     string
+		Hasher   Hasher
+		Hash     string
+		Expected bool
+	}{
+		{
+			Name:     "Bcrypt to argon2id",
+			Hasher:   argon,
+			Hash:     string(weakBcrypt),
+			Expected: true,
+		},
+		{
+			Name:     "Outdated argon2id parameters",
+			Hasher:   argon,
+			Hash:     weakArgon,
+			Expected: true,
+		},
+		{
+			Name:     "Outdated bcrypt cost",
+			Hasher:   Hasher{Algorithm: config.HashBcrypt, BcryptCost: bcrypt.MinCost + 1},
+			Hash:     string(weakBcrypt),
+			Expected: true,
+		},
+		{
+			Name:     "Argon2id to bcrypt",
+			Hasher:   Hasher{Algorithm: config.HashBcrypt, BcryptCost: bcrypt.MinCost},
+			Hash:     weakArgon,
+			Expected: true,
+		},
+		{
+			Name:     "Unknown format",
+			Hasher:   argon,
+			Hash:     "plaintext",
+			Expected: true,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Hasher.NeedsRehash(tt.Hash))
+		})
+	}
+}
+
+func TestVerifyUnsupportedHash(t *testing.T) {
+	for _, hash := range []string{
+		"plaintext",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+	} {
+		matched, err := Verify(hash, "Password1234")
+
+		assert.ErrorIs(t, err, config.ErrUnsupportedHash)
+		assert.Equal(t, false, matched)
+	}
+}
+
+func TestHasherFromEnv(t *testing.T) {
+	t.Setenv(config.PasswordHashEnv, config.HashBcrypt)
+	t.Setenv(config.BcryptCostEnv, "11")
+
+	hasher, err := HasherFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, config.HashBcrypt, hasher.Algorithm)
+	assert.Equal(t, 11, hasher.BcryptCost)
+
+	t.Setenv(config.BcryptCostEnv, "40")
+	_, costErr := HasherFromEnv()
+	assert.Error(t, costErr)
+
+	t.Setenv(config.PasswordHashEnv, "md5")
+	_, algorithmErr := HasherFromEnv()
+	assert.ErrorIs(t, algorithmErr, config.ErrUnsupportedHash)
+}
+
+func TestDummy(t *testing.T) {
+	hasher := Hasher{Algorithm: config.HashArgon2id, Argon2: Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}}
+
+	dummy, err := hasher.Dummy()
+	assert.NoError(t, err)
+	assert.Equal(t, false, hasher.NeedsRehash(dummy))
+
+	cached, err := hasher.Dummy()
+	assert.NoError(t, err)
+	assert.Equal(t, dummy, cached)
+
+	matched, err := Verify(dummy, "Password1234")
+	assert.NoError(t, err)
+	assert.Equal(t, false, matched)
+
+	bcryptHasher := Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	other, err := bcryptHasher.Dummy()
+	assert.NoError(t, err)
+	assert.Equal(t, false, bcryptHasher.NeedsRehash(other))
+}
@@ -0,0 +1,95 @@
+package password
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Policy struct {
+	MinLength        int
+	MaxLength        int
+	RequireUpper     bool
+	RequireLower     bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	DisallowIdentity bool
+	History          int
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:        config.DefaultPasswordMinLength,
+		MaxLength:        config.DefaultPasswordMaxLength,
+		RequireUpper:     config.DefaultPasswordRequireUpper,
+		RequireLower:     config.DefaultPasswordRequireLower,
+		RequireDigit:     config.DefaultPasswordRequireDigit,
+		RequireSymbol:    config.DefaultPasswordRequireSymbol,
+		DisallowIdentity: config.DefaultPasswordDisallowID,
+		History:          config.DefaultPasswordHistory,
+	}
+}
+
+func PolicyFromEnv() Policy {
+	defaults := DefaultPolicy()
+	return Policy{
+		MinLength:        config.EnvInt(config.PasswordMinLengthEnv, defaults.MinLength),
+		MaxLength:        config.EnvInt(config.PasswordMaxLengthEnv, defaults.MaxLength),
+		RequireUpper:     config.EnvBool(config.PasswordRequireUpperEnv, defaults.RequireUpper),
+		RequireLower:     config.EnvBool(config.PasswordRequireLowerEnv, defaults.RequireLower),
+		RequireDigit:     config.EnvBool(config.PasswordRequireDigitEnv, defaults.RequireDigit),
+		RequireSymbol:    config.EnvBool(config.PasswordRequireSymbolEnv, defaults.RequireSymbol),
+		DisallowIdentity: config.EnvBool(config.PasswordDisallowIDEnv, defaults.DisallowIdentity),
+		History:          config.EnvInt(config.PasswordHistoryEnv, defaults.History),
+	}
+}
+
+func (p Policy) Validate(password, username, email string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", config.ErrInvalidPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", config.ErrInvalidPassword, p.MaxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		return fmt.Errorf("%w: must contain an uppercase letter", config.ErrInvalidPassword)
+	}
+	if p.RequireLower && !lower {
+		return fmt.Errorf("%w: must contain a lowercase letter", config.ErrInvalidPassword)
+	}
+	if p.RequireDigit && !digit {
+		return fmt.Errorf("%w: must contain a digit", config.ErrInvalidPassword)
+	}
+	if p.RequireSymbol && !symbol {
+		return fmt.Errorf("%w: must contain a symbol", config.ErrInvalidPassword)
+	}
+
+	if p.DisallowIdentity {
+		lowered := strings.ToLower(password)
+		localPart, _, _ := strings.Cut(email, "@")
+		for _, identity := range []string{username, localPart} {
+			if len(identity) >= 3 && strings.Contains(lowered, strings.ToLower(identity)) {
+				return fmt.Errorf("%w: must not contain the username or email", config.ErrInvalidPassword)
+			}
+		}
+	}
+
+	return nil
+}
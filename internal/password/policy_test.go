@@ -0,0 +1,115 @@
+package password
+
+import (
+	"go-manage/cmd/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	strict := DefaultPolicy()
+	strict.MinLength = 12
+	strict.RequireSymbol = true
+
+	relaxed := Policy{MinLength: 4}
+
+	test := []struct {
+		Name        string
+		Policy      Policy
+		Password    string
+		ExpectedErr string
+	}{
+		{
+			Name:     "Default policy",
+			Policy:   DefaultPolicy(),
+			Password: "Password1234",
+		},
+		{
+			Name:        "Too short",
+			Policy:      DefaultPolicy(),
+			Password:    "Pass1",
+			ExpectedErr: "at least 8 characters",
+		},
+		{
+			Name:        "Too long",
+			Policy:      Policy{MinLength: 1, MaxLength: 5},
+			Password:    "Password1234",
+			ExpectedErr: "at most 5 characters",
+		},
+		{
+			Name:        "Missing uppercase",
+			Policy:      DefaultPolicy(),
+			Password:    "password1234",
+			ExpectedErr: "uppercase",
+		},
+		{
+			Name:        "Missing lowercase",
+			Policy:      DefaultPolicy(),
+			Password:    "PASSWORD1234",
+			ExpectedErr: "lowercase",
+		},
+		{
+			Name:        "Missing digit",
+			Policy:      DefaultPolicy(),
+			Password:    "PasswordOnly",
+			ExpectedErr: "digit",
+		},
+		{
+			Name:        "Missing symbol",
+			Policy:      strict,
+			Password:    "Password12345",
+			ExpectedErr: "symbol",
+		},
+		{
+			Name:     "Strict policy",
+			Policy:   strict,
+			Password: "Password1234!",
+		},
+		{
+			Name:        "Contains username",
+			Policy:      DefaultPolicy(),
+			Password:    "JohnDoe2024x",
+			ExpectedErr: "username or email",
+		},
+		{
+			Name:        "Contains email local part",
+			Policy:      DefaultPolicy(),
+			Password:    "Xjdoe.mail99",
+			ExpectedErr: "username or email",
+		},
+		{
+			Name:     "Relaxed policy",
+			Policy:   relaxed,
+			Password: "johndoe",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.Policy.Validate(tt.Password, "johndoe", "jdoe.mail@example.com")
+
+			if tt.ExpectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, config.ErrInvalidPassword)
+			assert.ErrorContains(t, err, tt.ExpectedErr)
+		})
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv(config.PasswordMinLengthEnv, "14")
+	t.Setenv(config.PasswordRequireSymbolEnv, "true")
+	t.Setenv(config.PasswordHistoryEnv, "0")
+	t.Setenv(config.PasswordRequireDigitEnv, "not-a-bool")
+
+	policy := PolicyFromEnv()
+
+	assert.Equal(t, 14, policy.MinLength)
+	assert.Equal(t, true, policy.RequireSymbol)
+	assert.Equal(t, 0, policy.History)
+	assert.Equal(t, config.DefaultPasswordRequireDigit, policy.RequireDigit)
+	assert.Equal(t, config.DefaultPasswordMaxLength, policy.MaxLength)
+}
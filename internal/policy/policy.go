@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"go-manage/cmd/config"
+	"slices"
+	"strings"
+)
+
+func Valid(permission string) bool {
+	if permission == config.PermissionWildcard || slices.Contains(config.Permissions, permission) {
+		return true
+	}
+	action, self := strings.CutSuffix(permission, config.PermissionSelf)
+	return self && slices.Contains(config.SelfPermissions, action)
+}
+
+func Known(action string) bool {
+	return slices.Contains(config.Permissions, action)
+}
+
+func Catalog() []string {
+	catalog := append([]string{config.PermissionWildcard}, config.Permissions...)
+	for _, action := range config.SelfPermissions {
+		catalog = append(catalog, action+config.PermissionSelf)
+	}
+	return catalog
+}
+
+func Match(grants []string, actor, action, resource string) (string, bool) {
+	for _, grant := range grants {
+		switch grant {
+		case config.PermissionWildcard, action:
+			return grant, true
+		case action + config.PermissionSelf:
+			if resource != "" && resource == actor {
+				return grant, true
+			}
+		}
+	}
+	return "", false
+}
+
+func ScopeGrants(scopes []string) []string {
+	var grants []string
+	for _, scope := range scopes {
+		if mapped, ok := config.ScopePermissions[scope]; ok {
+			grants = append(grants, mapped...)
+			continue
+		}
+		if Known(scope) {
+			grants = append(grants, scope)
+		}
+	}
+	return grants
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"go-manage/cmd/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	test := []struct {
+		Name       string
+		Permission string
+		Expected   bool
+	}{
+		{Name: "Catalog permission", Permission: config.PermUsersDelete, Expected: true},
+		{Name: "Wildcard", Permission: config.PermissionWildcard, Expected: true},
+		{Name: "Self scoped", Permission: "users:write:self", Expected: true},
+		{Name: "Self scoped group", Permission: "groups:write:self", Expected: false},
+		{Name: "Unknown", Permission: "users:impersonate", Expected: false},
+		{Name: "Blank", Permission: "", Expected: false},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, Valid(tt.Permission))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	test := []struct {
+		Name          string
+		Grants        []string
+		Action        string
+		Resource      string
+		ExpectedGrant string
+		ExpectedOK    bool
+	}{
+		{Name: "Exact grant", Grants: []string{config.PermUsersRead}, Action: config.PermUsersRead, Resource: "user-2", ExpectedGrant: config.PermUsersRead, ExpectedOK: true},
+		{Name: "Wildcard", Grants: []string{config.PermissionWildcard}, Action: config.PermRolesManage, ExpectedGrant: config.PermissionWildcard, ExpectedOK: true},
+		{Name: "Self on own user", Grants: []string{"users:write:self"}, Action: config.PermUsersWrite, Resource: "user-1", ExpectedGrant: "users:write:self", ExpectedOK: true},
+		{Name: "Self on other user", Grants: []string{"users:write:self"}, Action: config.PermUsersWrite, Resource: "user-2", ExpectedOK: false},
+		{Name: "Self without resource", Grants: []string{"users:read:self"}, Action: config.PermUsersRead, ExpectedOK: false},
+		{Name: "Other action", Grants: []string{config.PermUsersWrite}, Action: config.PermUsersDelete, Resource: "user-2", ExpectedOK: false},
+		{Name: "No grants", Action: config.PermUsersRead, ExpectedOK: false},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			grant, ok := Match(tt.Grants, "user-1", tt.Action, tt.Resource)
+
+			assert.Equal(t, tt.ExpectedOK, ok)
+			assert.Equal(t, tt.ExpectedGrant, grant)
+		})
+	}
+}
+
+func TestScopeGrants(t *testing.T) {
+	test := []struct {
+		Name     string
+		Scopes   []string
+		Expected []string
+	}{
+		{Name: "Read scope", Scopes: []string{config.ScopeRead}, Expected: []string{config.PermUsersRead, config.PermGroupsRead}},
+		{Name: "Write scope", Scopes: []string{config.ScopeWrite}, Expected: []string{config.PermUsersWrite, config.PermGroupsWrite}},
+		{Name: "Admin scope", Scopes: []string{config.ScopeAdmin}, Expected: []string{config.PermissionWildcard}},
+		{Name: "Permission scope", Scopes: []string{config.PermUsersDelete}, Expected: []string{config.PermUsersDelete}},
+		{Name: "Unknown scope", Scopes: []string{"users:impersonate"}, Expected: nil},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, ScopeGrants(tt.Scopes))
+		})
+	}
+}
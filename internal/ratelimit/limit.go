@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	Take(key string, limit Limit) (Decision, error)
+}
+
+func ParseLimit(value string) (Limit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("%w: %q", config.ErrInvalidRateLimit, value)
+	}
+
+	burst := ""
+	period, burst, _ = strings.Cut(period, ",")
+
+	count, countErr := strconv.Atoi(requests)
+	window, windowErr := time.ParseDuration(period)
+	if countErr != nil || windowErr != nil || count <= 0 || window <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q", config.ErrInvalidRateLimit, value)
+	}
+
+	limit := Limit{Requests: count, Period: window, Burst: count}
+	if burst != "" {
+		size, burstErr := strconv.Atoi(burst)
+		if burstErr != nil || size <= 0 {
+			return Limit{}, fmt.Errorf("%w: %q", config.ErrInvalidRateLimit, value)
+		}
+		limit.Burst = size
+	}
+	return limit, nil
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int(math.Ceil(l.Period.Seconds())))
+}
+
+func LimitsFromEnv() (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for group, fallback := range config.DefaultRateLimits {
+		value := os.Getenv(config.RateLimitEnvPrefix + strings.ToUpper(group))
+		if value == "" {
+			value = fallback
+		}
+		if value == config.RateLimitDisabled {
+			continue
+		}
+
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		limits[group] = limit
+	}
+	return limits, nil
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"go-manage/cmd/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	test := []struct {
+		Name          string
+		Value         string
+		ExpectedLimit Limit
+		ExpectedErr   error
+	}{
+		{
+			Name:          "Requests per period",
+			Value:         "60/1m",
+			ExpectedLimit: Limit{Requests: 60, Period: time.Minute, Burst: 60},
+		},
+		{
+			Name:          "With burst",
+			Value:         "10/1s,20",
+			ExpectedLimit: Limit{Requests: 10, Period: time.Second, Burst: 20},
+		},
+		{
+			Name:        "Missing period",
+			Value:       "60",
+			ExpectedErr: config.ErrInvalidRateLimit,
+		},
+		{
+			Name:        "Zero requests",
+			Value:       "0/1m",
+			ExpectedErr: config.ErrInvalidRateLimit,
+		},
+		{
+			Name:        "Invalid burst",
+			Value:       "60/1m,x",
+			ExpectedErr: config.ErrInvalidRateLimit,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			limit, err := ParseLimit(tt.Value)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectedLimit, limit)
+		})
+	}
+}
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv(config.RateLimitEnvPrefix+"WRITE", "5/1s")
+	t.Setenv(config.RateLimitEnvPrefix+"ADMIN", config.RateLimitDisabled)
+
+	limits, err := LimitsFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 5, Period: time.Second, Burst: 5}, limits[config.RateLimitWrite])
+	assert.Equal(t, Limit{Requests: 300, Period: time.Minute, Burst: 300}, limits[config.RateLimitRead])
+
+	_, disabled := limits[config.RateLimitAdmin]
+	assert.Equal(t, false, disabled)
+
+	t.Setenv(config.RateLimitEnvPrefix+"LOGIN", "fast")
+	_, invalidErr := LimitsFromEnv()
+	assert.ErrorIs(t, invalidErr, config.ErrInvalidRateLimit)
+}
+
+func TestPolicy(t *testing.T) {
+	assert.Equal(t, "60;w=60", Limit{Requests: 60, Period: time.Minute}.Policy())
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+type MemoryStore struct {
+	Clock func() time.Time
+
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	sweptAt    time.Time
+	sweepEvery time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:    map[string]*bucket{},
+		sweepEvery: time.Minute,
+	}
+}
+
+func (ms *MemoryStore) Take(key string, limit Limit) (Decision, error) {
+	now := ms.now()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.buckets == nil {
+		ms.buckets = map[string]*bucket{}
+	}
+	ms.sweep(now)
+
+	b, found := ms.buckets[key]
+	if !found || b.limit != limit {
+		b = &bucket{tokens: limit.capacity(), updatedAt: now, limit: limit}
+		ms.buckets[key] = b
+	}
+	b.refill(now)
+
+	decision := Decision{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.tokens) / limit.rate())
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = seconds((limit.capacity() - b.tokens) / limit.rate())
+
+	return decision, nil
+}
+
+func (ms *MemoryStore) sweep(now time.Time) {
+	if now.Sub(ms.sweptAt) < ms.sweepEvery {
+		return
+	}
+	ms.sweptAt = now
+
+	for key, b := range ms.buckets {
+		b.refill(now)
+		if b.tokens >= b.limit.capacity() {
+			delete(ms.buckets, key)
+		}
+	}
+}
+
+func (ms *MemoryStore) now() time.Time {
+	if ms.Clock != nil {
+		return ms.Clock()
+	}
+	return time.Now()
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(b.limit.capacity(), b.tokens+elapsed*b.limit.rate())
+	b.updatedAt = now
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.Clock = func() time.Time { return now }
+
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		decision, err := store.Take("ip:10.0.0.1", limit)
+		assert.NoError(t, err)
+		assert.Equal(t, true, decision.Allowed)
+		assert.Equal(t, i, decision.Remaining)
+		assert.Equal(t, 2, decision.Limit)
+	}
+
+	denied, _ := store.Take("ip:10.0.0.1", limit)
+	assert.Equal(t, false, denied.Allowed)
+	assert.Equal(t, 0, denied.Remaining)
+	assert.Equal(t, 500*time.Millisecond, denied.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, denied.Reset)
+
+	other, _ := store.Take("ip:10.0.0.2", limit)
+	assert.Equal(t, true, other.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	refilled, _ := store.Take("ip:10.0.0.1", limit)
+	assert.Equal(t, true, refilled.Allowed)
+	assert.Equal(t, 0, refilled.Remaining)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.Clock = func() time.Time { return now }
+
+	limit := Limit{Requests: 10, Period: time.Second, Burst: 10}
+	store.Take("ip:10.0.0.1", limit)
+	store.Take("ip:10.0.0.2", limit)
+	assert.Equal(t, 2, len(store.buckets))
+
+	now = now.Add(2 * time.Minute)
+	store.Take("ip:10.0.0.3", limit)
+	assert.Equal(t, 1, len(store.buckets))
+}
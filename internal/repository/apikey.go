@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"strings"
+	"time"
+)
+
+type APIKeyRepository struct {
+	DB     DBTX
+	Tenant string
+}
+
+func (ar *APIKeyRepository) Save(saveQuery string, key models.APIKey) error {
+	_, saveErr := ar.DB.Exec(saveQuery, key.ID, key.Name, key.Prefix, key.Hash, strings.Join(key.Scopes, ","),
+		key.CreatedBy, key.CreatedAt, nullableTime(key.ExpiresAt), nullableTime(key.LastUsedAt), nullableTime(key.RevokedAt), scopedTenant(ar.Tenant))
+	return saveErr
+}
+
+func (ar *APIKeyRepository) List(listQuery string) ([]models.APIKey, error) {
+	rows, err := ar.DB.Query(listQuery, scopedTenant(ar.Tenant))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, scanErr := scanAPIKey(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (ar *APIKeyRepository) SearchByPrefix(searchQuery, prefix string) (models.APIKey, error) {
+	key, err := scanAPIKey(ar.DB.QueryRow(searchQuery, prefix, scopedTenant(ar.Tenant)))
+	if err == sql.ErrNoRows {
+		return models.APIKey{}, nil
+	}
+	return key, err
+}
+
+func (ar *APIKeyRepository) Revoke(revokeQuery, id string, revokedAt time.Time) (bool, error) {
+	result, revokeErr := ar.DB.Exec(revokeQuery, revokedAt, id, scopedTenant(ar.Tenant))
+	if revokeErr != nil {
+		return false, revokeErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (ar *APIKeyRepository) Touch(touchQuery, id string, usedAt time.Time) error {
+	_, touchErr := ar.DB.Exec(touchQuery, usedAt, id)
+	return touchErr
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (models.APIKey, error) {
+	var key models.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.CreatedBy, &key.CreatedAt,
+		&expiresAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	key.Scopes = strings.Split(scopes, ",")
+	key.ExpiresAt = timePointer(expiresAt)
+	key.LastUsedAt = timePointer(lastUsedAt)
+	key.RevokedAt = timePointer(revokedAt)
+	return key, nil
+}
+
+func nullableTime(value *time.Time) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func timePointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchAPIKeyByPrefix(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := APIKeyRepository{DB: db}
+	expiresAt := config.TestTime.Add(time.Hour)
+
+	test := []struct {
+		Name        string
+		ExpectedKey models.APIKey
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name: "Success",
+			ExpectedKey: models.APIKey{
+				ID:        "key-1",
+				Name:      "nightly export",
+				Prefix:    "gm_abcdefgh",
+				Hash:      "hash",
+				Scopes:    []string{"read", "write"},
+				CreatedBy: "admin",
+				CreatedAt: config.TestTime,
+				ExpiresAt: &expiresAt,
+			},
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAPIKeyByPrefixQuery).
+					WithArgs("gm_abcdefgh", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestAPIKeyColumns).
+						AddRow("key-1", "nightly export", "gm_abcdefgh", "hash", "read,write", "admin", config.TestTime, expiresAt, nil, nil))
+			},
+		},
+		{
+			Name:        "Not found",
+			ExpectedKey: models.APIKey{},
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAPIKeyByPrefixQuery).
+					WithArgs("gm_abcdefgh", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestAPIKeyColumns))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedKey: models.APIKey{},
+			ExpectedErr: fmt.Errorf("error searching api key"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAPIKeyByPrefixQuery).
+					WillReturnError(fmt.Errorf("error searching api key"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			key, searchErr := repo.SearchByPrefix(config.SearchAPIKeyByPrefixQuery, "gm_abcdefgh")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedKey, key)
+		})
+	}
+}
+
+func TestSaveAndListAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := APIKeyRepository{DB: db}
+	key := models.APIKey{
+		ID:        "key-1",
+		Name:      "nightly export",
+		Prefix:    "gm_abcdefgh",
+		Hash:      "hash",
+		Scopes:    []string{"read"},
+		CreatedBy: "admin",
+		CreatedAt: config.TestTime,
+	}
+
+	mock.ExpectExec(config.TestSaveAPIKeyQuery).
+		WithArgs("key-1", "nightly export", "gm_abcdefgh", "hash", "read", "admin", config.TestTime, nil, nil, nil, config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(config.TestListAPIKeysQuery).
+		WillReturnRows(sqlmock.NewRows(config.TestAPIKeyColumns).
+			AddRow("key-1", "nightly export", "gm_abcdefgh", "hash", "read", "admin", config.TestTime, nil, config.TestTime, nil))
+
+	assert.NoError(t, repo.Save(config.SaveAPIKeyQuery, key))
+
+	keys, listErr := repo.List(config.ListAPIKeysQuery)
+	assert.NoError(t, listErr)
+	key.LastUsedAt = &config.TestTime
+	assert.Equal(t, []models.APIKey{key}, keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := APIKeyRepository{DB: db}
+
+	test := []struct {
+		Name            string
+		ExpectedRevoked bool
+		ExpectedErr     error
+		MockAct         func()
+	}{
+		{
+			Name:            "Success",
+			ExpectedRevoked: true,
+			ExpectedErr:     nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestRevokeAPIKeyQuery).
+					WithArgs(config.TestTime, "key-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			Name:            "Unknown or already revoked",
+			ExpectedRevoked: false,
+			ExpectedErr:     nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestRevokeAPIKeyQuery).
+					WithArgs(config.TestTime, "key-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			Name:            "Error",
+			ExpectedRevoked: false,
+			ExpectedErr:     fmt.Errorf("error revoking api key"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestRevokeAPIKeyQuery).
+					WillReturnError(fmt.Errorf("error revoking api key"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			revoked, revokeErr := repo.Revoke(config.RevokeAPIKeyQuery, "key-1", config.TestTime)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), revokeErr.Error())
+			} else {
+				assert.NoError(t, revokeErr)
+			}
+			assert.Equal(t, tt.ExpectedRevoked, revoked)
+		})
+	}
+}
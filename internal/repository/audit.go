@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"strings"
+)
+
+type AuditRepository struct {
+	DB DBTX
+}
+
+func (ar *AuditRepository) Save(saveQuery string, event models.AuditEvent) error {
+	changes, marshalErr := json.Marshal(event.Changes)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	_, saveErr := ar.DB.Exec(saveQuery, event.ID, event.OccurredAt, event.Actor, event.Action, event.Target,
+		string(changes), event.RequestID, event.IP, scopedTenant(event.TenantID))
+	return saveErr
+}
+
+func (ar *AuditRepository) List(listQuery string, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	query := strings.TrimSuffix(listQuery, ";")
+	args := []any{scopedTenant(filter.TenantID)}
+
+	conditions := map[string]string{
+		"actor":  filter.Actor,
+		"action": filter.Action,
+		"target": filter.Target,
+	}
+	for _, column := range []string{"actor", "action", "target"} {
+		if conditions[column] != "" {
+			query += " AND " + column + " = ?"
+			args = append(args, conditions[column])
+		}
+	}
+	query += config.AuditOrderQuery
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := ar.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+		var changes, requestID, ip sql.NullString
+
+		if scanErr := rows.Scan(&event.ID, &event.OccurredAt, &event.Actor, &event.Action, &event.Target,
+			&changes, &requestID, &ip); scanErr != nil {
+			return nil, scanErr
+		}
+		if changes.Valid && changes.String != "" {
+			if unmarshalErr := json.Unmarshal([]byte(changes.String), &event.Changes); unmarshalErr != nil {
+				return nil, unmarshalErr
+			}
+		}
+		event.RequestID = requestID.String
+		event.IP = ip.String
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := AuditRepository{DB: db}
+
+	event := models.AuditEvent{
+		ID:         "1",
+		OccurredAt: config.TestTime,
+		Actor:      "admin",
+		Action:     config.AuditActionUpdate,
+		Target:     "johndoe",
+		Changes: map[string]models.FieldChange{
+			"name": {Before: "John", After: "Johncito"},
+		},
+		RequestID: "request-1",
+		IP:        "127.0.0.1",
+	}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs("1", config.TestTime, "admin", config.AuditActionUpdate, "johndoe",
+						`{"name":{"before":"John","after":"Johncito"}}`, "request-1", "127.0.0.1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: fmt.Errorf("error saving audit event"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnError(fmt.Errorf("error saving audit event"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			saveErr := repo.Save(config.SaveAuditQuery, event)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), saveErr.Error())
+			} else {
+				assert.NoError(t, saveErr)
+			}
+		})
+	}
+}
+
+func TestListAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := AuditRepository{DB: db}
+
+	test := []struct {
+		Name          string
+		Filter        models.AuditFilter
+		ExpectedCount int
+		ExpectedErr   error
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			Filter:        models.AuditFilter{Limit: 50},
+			ExpectedCount: 2,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery+` ORDER BY occurred_at DESC, id DESC LIMIT \? OFFSET \?;`).
+					WithArgs(config.DefaultTenant, 50, 0).
+					WillReturnRows(mock.NewRows(config.TestAuditColumns).
+						AddRow("1", config.TestTime, "admin", config.AuditActionCreate, "johndoe", `{"name":{"before":null,"after":"John"}}`, "request-1", "127.0.0.1").
+						AddRow("2", config.TestTime, "system", config.AuditActionPurge, "users", nil, nil, nil))
+			},
+		},
+		{
+			Name:          "Filtered",
+			Filter:        models.AuditFilter{Actor: "admin", Target: "johndoe", Limit: 10, Offset: 10},
+			ExpectedCount: 0,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery+` AND actor = \? AND target = \? ORDER BY`).
+					WithArgs(config.DefaultTenant, "admin", "johndoe", 10, 10).
+					WillReturnRows(mock.NewRows(config.TestAuditColumns))
+			},
+		},
+		{
+			Name:          "Error",
+			Filter:        models.AuditFilter{Limit: 50},
+			ExpectedCount: 0,
+			ExpectedErr:   fmt.Errorf("error listing audit events"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery).
+					WillReturnError(fmt.Errorf("error listing audit events"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			events, listErr := repo.List(config.ListAuditQuery, tt.Filter)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), listErr.Error())
+			} else {
+				assert.NoError(t, listErr)
+			}
+			assert.Equal(t, tt.ExpectedCount, len(events))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"time"
+)
+
+type FederationRepository struct {
+	DB     DBTX
+	Tenant string
+}
+
+func (fr *FederationRepository) SaveIdentity(saveQuery string, identity models.ExternalIdentity) error {
+	_, saveErr := fr.DB.Exec(saveQuery, identity.ID, identity.UserID, identity.Provider, identity.Issuer, identity.Subject,
+		identity.Email, identity.CreatedAt, nullableTime(identity.LastLoginAt), scopedTenant(fr.Tenant))
+	return saveErr
+}
+
+func (fr *FederationRepository) SearchIdentity(searchQuery, issuer, subject string) (models.ExternalIdentity, error) {
+	identity, err := scanIdentity(fr.DB.QueryRow(searchQuery, issuer, subject, scopedTenant(fr.Tenant)))
+	if err == sql.ErrNoRows {
+		return models.ExternalIdentity{}, nil
+	}
+	return identity, err
+}
+
+func (fr *FederationRepository) ListIdentities(listQuery, userID string) ([]models.ExternalIdentity, error) {
+	rows, err := fr.DB.Query(listQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.ExternalIdentity{}
+	for rows.Next() {
+		identity, scanErr := scanIdentity(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+func (fr *FederationRepository) DeleteIdentity(deleteQuery, id, userID string) (bool, error) {
+	result, deleteErr := fr.DB.Exec(deleteQuery, id, userID)
+	if deleteErr != nil {
+		return false, deleteErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (fr *FederationRepository) TouchIdentity(touchQuery, id string, loginAt time.Time) error {
+	_, touchErr := fr.DB.Exec(touchQuery, loginAt, id)
+	return touchErr
+}
+
+func (fr *FederationRepository) PurgeIdentities(purgeQuery string) error {
+	_, purgeErr := fr.DB.Exec(purgeQuery)
+	return purgeErr
+}
+
+func (fr *FederationRepository) SaveState(saveQuery string, state models.FederationState) error {
+	_, saveErr := fr.DB.Exec(saveQuery, state.Hash, state.Provider, state.Nonce, state.CodeVerifier, state.UserID,
+		state.CreatedAt, state.ExpiresAt, scopedTenant(fr.Tenant))
+	return saveErr
+}
+
+func (fr *FederationRepository) SearchState(searchQuery, hash string, now time.Time) (models.FederationState, error) {
+	var state models.FederationState
+	err := fr.DB.QueryRow(searchQuery, hash, now, scopedTenant(fr.Tenant)).Scan(&state.Hash, &state.Provider, &state.Nonce, &state.CodeVerifier,
+		&state.UserID, &state.CreatedAt, &state.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return models.FederationState{}, nil
+	}
+	return state, err
+}
+
+func (fr *FederationRepository) DeleteState(deleteQuery, hash string) (bool, error) {
+	result, deleteErr := fr.DB.Exec(deleteQuery, hash)
+	if deleteErr != nil {
+		return false, deleteErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (fr *FederationRepository) PurgeStates(purgeQuery string, now time.Time) error {
+	_, purgeErr := fr.DB.Exec(purgeQuery, now)
+	return purgeErr
+}
+
+func scanIdentity(row interface{ Scan(dest ...any) error }) (models.ExternalIdentity, error) {
+	var identity models.ExternalIdentity
+	var lastLoginAt sql.NullTime
+
+	err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Issuer, &identity.Subject,
+		&identity.Email, &identity.CreatedAt, &lastLoginAt)
+	if err != nil {
+		return models.ExternalIdentity{}, err
+	}
+
+	identity.LastLoginAt = timePointer(lastLoginAt)
+	return identity, nil
+}
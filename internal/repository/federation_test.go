@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndSearchIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := FederationRepository{DB: db}
+	lastLoginAt := config.TestTime.Add(time.Hour)
+	identity := models.ExternalIdentity{
+		ID:          "identity-1",
+		UserID:      "user-1",
+		Provider:    "corp",
+		Issuer:      "https://idp.corp.example.com",
+		Subject:     "248289761001",
+		Email:       "johndoe@corp.example.com",
+		CreatedAt:   config.TestTime,
+		LastLoginAt: &lastLoginAt,
+	}
+
+	test := []struct {
+		Name             string
+		ExpectedIdentity models.ExternalIdentity
+		ExpectedErr      error
+		MockAct          func()
+	}{
+		{
+			Name:             "Success",
+			ExpectedIdentity: identity,
+			ExpectedErr:      nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchIdentityQuery).
+					WithArgs("https://idp.corp.example.com", "248289761001", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestIdentityColumns).
+						AddRow("identity-1", "user-1", "corp", "https://idp.corp.example.com", "248289761001", "johndoe@corp.example.com", config.TestTime, lastLoginAt))
+			},
+		},
+		{
+			Name:             "Not found",
+			ExpectedIdentity: models.ExternalIdentity{},
+			ExpectedErr:      nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchIdentityQuery).
+					WithArgs("https://idp.corp.example.com", "248289761001", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestIdentityColumns))
+			},
+		},
+		{
+			Name:             "Error",
+			ExpectedIdentity: models.ExternalIdentity{},
+			ExpectedErr:      fmt.Errorf("error searching identity"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchIdentityQuery).
+					WillReturnError(fmt.Errorf("error searching identity"))
+			},
+		},
+	}
+
+	mock.ExpectExec(config.TestSaveIdentityQuery).
+		WithArgs("identity-1", "user-1", "corp", "https://idp.corp.example.com", "248289761001", "johndoe@corp.example.com", config.TestTime, lastLoginAt, config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.SaveIdentity(config.SaveIdentityQuery, identity))
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			found, searchErr := repo.SearchIdentity(config.SearchIdentityQuery, "https://idp.corp.example.com", "248289761001")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedIdentity, found)
+		})
+	}
+}
+
+func TestListAndDeleteIdentities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := FederationRepository{DB: db}
+
+	mock.ExpectQuery(config.TestListIdentitiesQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(config.TestIdentityColumns).
+			AddRow("identity-1", "user-1", "corp", "https://idp.corp.example.com", "248289761001", "", config.TestTime, nil))
+	identities, listErr := repo.ListIdentities(config.ListIdentitiesQuery, "user-1")
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.ExternalIdentity{{
+		ID:        "identity-1",
+		UserID:    "user-1",
+		Provider:  "corp",
+		Issuer:    "https://idp.corp.example.com",
+		Subject:   "248289761001",
+		CreatedAt: config.TestTime,
+	}}, identities)
+
+	mock.ExpectExec(config.TestDeleteIdentityQuery).
+		WithArgs("identity-1", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	deleted, deleteErr := repo.DeleteIdentity(config.DeleteIdentityQuery, "identity-1", "user-1")
+	assert.NoError(t, deleteErr)
+	assert.True(t, deleted)
+
+	mock.ExpectExec(config.TestDeleteIdentityQuery).
+		WithArgs("identity-1", "user-2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	deleted, deleteErr = repo.DeleteIdentity(config.DeleteIdentityQuery, "identity-1", "user-2")
+	assert.NoError(t, deleteErr)
+	assert.False(t, deleted)
+
+	mock.ExpectExec(config.TestTouchIdentityQuery).
+		WithArgs(config.TestTime, "identity-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.TouchIdentity(config.TouchIdentityQuery, "identity-1", config.TestTime))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchFederationState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := FederationRepository{DB: db}
+	state := models.FederationState{
+		Hash:         "hash",
+		Provider:     "corp",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		UserID:       "",
+		CreatedAt:    config.TestTime,
+		ExpiresAt:    config.TestTime.Add(config.FederationStateTTL),
+	}
+
+	mock.ExpectExec(config.TestSaveFedStateQuery).
+		WithArgs("hash", "corp", "nonce", "verifier", "", config.TestTime, state.ExpiresAt, config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.SaveState(config.SaveFederationStateQuery, state))
+
+	mock.ExpectQuery(config.TestSearchFedStateQuery).
+		WithArgs("hash", config.TestTime, config.DefaultTenant).
+		WillReturnRows(sqlmock.NewRows(config.TestFedStateColumns).
+			AddRow("hash", "corp", "nonce", "verifier", "", config.TestTime, state.ExpiresAt))
+	found, searchErr := repo.SearchState(config.SearchFederationStateQuery, "hash", config.TestTime)
+	assert.NoError(t, searchErr)
+	assert.Equal(t, state, found)
+
+	mock.ExpectQuery(config.TestSearchFedStateQuery).
+		WithArgs("hash", state.ExpiresAt, config.DefaultTenant).
+		WillReturnRows(sqlmock.NewRows(config.TestFedStateColumns))
+	expired, searchErr := repo.SearchState(config.SearchFederationStateQuery, "hash", state.ExpiresAt)
+	assert.NoError(t, searchErr)
+	assert.Equal(t, models.FederationState{}, expired)
+
+	mock.ExpectExec(config.TestDeleteFedStateQuery).
+		WithArgs("hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	deleted, deleteErr := repo.DeleteState(config.DeleteFederationStateQuery, "hash")
+	assert.NoError(t, deleteErr)
+	assert.True(t, deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
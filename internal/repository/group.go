@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"strings"
+)
+
+type GroupRepository struct {
+	DB     DBTX
+	Tenant string
+}
+
+func (gr *GroupRepository) Save(saveQuery string, group models.Group) error {
+	_, saveErr := gr.DB.Exec(saveQuery, group.ID, group.Name, group.Description, nullableString(group.ParentID),
+		group.CreatedAt, group.UpdatedAt, scopedTenant(gr.Tenant))
+	return groupUniqueViolation(saveErr)
+}
+
+func (gr *GroupRepository) Search(searchQuery, id string) (models.Group, error) {
+	group, err := scanGroup(gr.DB.QueryRow(searchQuery, id, scopedTenant(gr.Tenant)))
+	if err == sql.ErrNoRows {
+		return models.Group{}, nil
+	}
+	return group, err
+}
+
+func (gr *GroupRepository) List(listQuery string) ([]models.Group, error) {
+	rows, err := gr.DB.Query(listQuery, scopedTenant(gr.Tenant))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		group, scanErr := scanGroup(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+func (gr *GroupRepository) Update(updateQuery string, group models.Group) error {
+	_, updateErr := gr.DB.Exec(updateQuery, group.Name, group.Description, nullableString(group.ParentID),
+		group.UpdatedAt, group.ID, scopedTenant(gr.Tenant))
+	return groupUniqueViolation(updateErr)
+}
+
+func (gr *GroupRepository) Delete(deleteQuery, id string) (bool, error) {
+	result, deleteErr := gr.DB.Exec(deleteQuery, id, id, scopedTenant(gr.Tenant))
+	if deleteErr != nil {
+		return false, deleteErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (gr *GroupRepository) InSubtree(subtreeQuery, rootID, id string) (bool, error) {
+	var count int
+	if err := gr.DB.QueryRow(subtreeQuery, rootID, id).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (gr *GroupRepository) SaveMember(saveQuery string, member models.GroupMember) error {
+	_, saveErr := gr.DB.Exec(saveQuery, member.GroupID, member.UserID, member.Role, member.CreatedAt)
+	return saveErr
+}
+
+func (gr *GroupRepository) SearchMember(searchQuery, groupID, userID string) (models.GroupMember, error) {
+	member, err := scanMember(gr.DB.QueryRow(searchQuery, groupID, userID))
+	if err == sql.ErrNoRows {
+		return models.GroupMember{}, nil
+	}
+	return member, err
+}
+
+func (gr *GroupRepository) DeleteMember(deleteQuery, groupID, userID string) (bool, error) {
+	result, deleteErr := gr.DB.Exec(deleteQuery, groupID, userID)
+	if deleteErr != nil {
+		return false, deleteErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (gr *GroupRepository) DeleteMembers(deleteQuery, groupID string) error {
+	_, deleteErr := gr.DB.Exec(deleteQuery, groupID)
+	return deleteErr
+}
+
+func (gr *GroupRepository) ListMembers(listQuery, groupID string) ([]models.GroupMember, error) {
+	rows, err := gr.DB.Query(listQuery, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.GroupMember{}
+	for rows.Next() {
+		member, scanErr := scanMember(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (gr *GroupRepository) ListMemberships(listQuery, userID string) ([]models.Membership, error) {
+	rows, err := gr.DB.Query(listQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []models.Membership{}
+	for rows.Next() {
+		var membership models.Membership
+		var parentID, viaGroupID sql.NullString
+
+		if scanErr := rows.Scan(&membership.Group.ID, &membership.Group.Name, &membership.Group.Description, &parentID,
+			&membership.Group.CreatedAt, &membership.Group.UpdatedAt, &membership.Role, &viaGroupID,
+			&membership.CreatedAt); scanErr != nil {
+			return nil, scanErr
+		}
+		membership.Group.ParentID = stringPointer(parentID)
+		membership.ViaGroupID = stringPointer(viaGroupID)
+
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, rows.Err()
+}
+
+func (gr *GroupRepository) PurgeMembers(purgeQuery string) error {
+	_, purgeErr := gr.DB.Exec(purgeQuery)
+	return purgeErr
+}
+
+func scanGroup(row interface{ Scan(dest ...any) error }) (models.Group, error) {
+	var group models.Group
+	var parentID sql.NullString
+
+	err := row.Scan(&group.ID, &group.Name, &group.Description, &parentID, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	group.ParentID = stringPointer(parentID)
+	return group, nil
+}
+
+func scanMember(row interface{ Scan(dest ...any) error }) (models.GroupMember, error) {
+	var member models.GroupMember
+	if err := row.Scan(&member.GroupID, &member.UserID, &member.Username, &member.Role, &member.CreatedAt); err != nil {
+		return models.GroupMember{}, err
+	}
+	return member, nil
+}
+
+func groupUniqueViolation(err error) error {
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return config.ErrGroupAlreadyExists
+	}
+	return err
+}
+
+func nullableString(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func stringPointer(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndSearchGroup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := GroupRepository{DB: db}
+	parentID := "group-1"
+	group := models.Group{
+		ID:          "group-2",
+		Name:        "Engineering",
+		Description: "Builds things",
+		ParentID:    &parentID,
+		CreatedAt:   config.TestTime,
+		UpdatedAt:   config.TestTime,
+	}
+
+	test := []struct {
+		Name          string
+		ExpectedGroup models.Group
+		ExpectedErr   error
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			ExpectedGroup: group,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WithArgs("group-2", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestGroupColumns).
+						AddRow("group-2", "Engineering", "Builds things", "group-1", config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:          "Not found",
+			ExpectedGroup: models.Group{},
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WithArgs("group-2", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestGroupColumns))
+			},
+		},
+		{
+			Name:          "Error",
+			ExpectedGroup: models.Group{},
+			ExpectedErr:   fmt.Errorf("error searching group"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WillReturnError(fmt.Errorf("error searching group"))
+			},
+		},
+	}
+
+	mock.ExpectExec(config.TestSaveGroupQuery).
+		WithArgs("group-2", "Engineering", "Builds things", "group-1", config.TestTime, config.TestTime, config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.Save(config.SaveGroupQuery, group))
+
+	mock.ExpectExec(config.TestSaveGroupQuery).
+		WillReturnError(fmt.Errorf("UNIQUE constraint failed: groups.name"))
+	assert.ErrorIs(t, repo.Save(config.SaveGroupQuery, group), config.ErrGroupAlreadyExists)
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			found, searchErr := repo.Search(config.SearchGroupQuery, "group-2")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedGroup, found)
+		})
+	}
+}
+
+func TestGroupHierarchyQueries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := GroupRepository{DB: db}
+
+	mock.ExpectQuery(config.TestInSubtreeQuery).
+		WithArgs("group-1", "group-3").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	inSubtree, subtreeErr := repo.InSubtree(config.InSubtreeQuery, "group-1", "group-3")
+	assert.NoError(t, subtreeErr)
+	assert.True(t, inSubtree)
+
+	mock.ExpectExec(config.TestDeleteGroupQuery).
+		WithArgs("group-1", "group-1", config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	deleted, deleteErr := repo.Delete(config.DeleteGroupQuery, "group-1")
+	assert.NoError(t, deleteErr)
+	assert.False(t, deleted)
+
+	mock.ExpectQuery(config.TestListNestedMembersQuery).
+		WithArgs("group-1").
+		WillReturnRows(sqlmock.NewRows(config.TestGroupMemberColumns).
+			AddRow("group-3", "user-1", "johndoe", "member", config.TestTime))
+	members, membersErr := repo.ListMembers(config.ListNestedMembersQuery, "group-1")
+	assert.NoError(t, membersErr)
+	assert.Equal(t, []models.GroupMember{{GroupID: "group-3", UserID: "user-1", Username: "johndoe", Role: "member", CreatedAt: config.TestTime}}, members)
+
+	mock.ExpectQuery(config.TestListNestedMembershipQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(config.TestMembershipColumns).
+			AddRow("group-1", "Acme", "", nil, config.TestTime, config.TestTime, "member", "group-3", config.TestTime).
+			AddRow("group-3", "Platform", "", "group-1", config.TestTime, config.TestTime, "member", nil, config.TestTime))
+	memberships, membershipsErr := repo.ListMemberships(config.ListNestedMembershipQuery, "user-1")
+	assert.NoError(t, membershipsErr)
+	assert.Equal(t, 2, len(memberships))
+	assert.Nil(t, memberships[0].Group.ParentID)
+	assert.Equal(t, "group-3", *memberships[0].ViaGroupID)
+	assert.Equal(t, "group-1", *memberships[1].Group.ParentID)
+	assert.Nil(t, memberships[1].ViaGroupID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"time"
+)
+
+type MFARepository struct {
+	DB DBTX
+}
+
+func (mr *MFARepository) SearchTOTP(searchQuery, userID string) (models.TOTPSecret, error) {
+	secret := models.TOTPSecret{}
+	var confirmedAt sql.NullTime
+
+	err := mr.DB.QueryRow(searchQuery, userID).Scan(&secret.UserID, &secret.Secret, &confirmedAt, &secret.LastUsedStep, &secret.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.TOTPSecret{}, nil
+	}
+	if err != nil {
+		return models.TOTPSecret{}, err
+	}
+
+	if confirmedAt.Valid {
+		secret.ConfirmedAt = &confirmedAt.Time
+	}
+	return secret, nil
+}
+
+func (mr *MFARepository) SaveTOTP(saveQuery string, secret models.TOTPSecret) error {
+	var confirmedAt any
+	if secret.ConfirmedAt != nil {
+		confirmedAt = *secret.ConfirmedAt
+	}
+
+	_, saveErr := mr.DB.Exec(saveQuery, secret.UserID, secret.Secret, confirmedAt, secret.LastUsedStep, secret.CreatedAt)
+	return saveErr
+}
+
+func (mr *MFARepository) ConfirmTOTP(confirmQuery, userID string, confirmedAt time.Time, step int64) (bool, error) {
+	return mr.affected(confirmQuery, confirmedAt, step, userID)
+}
+
+func (mr *MFARepository) UseTOTPStep(useQuery, userID string, step int64) (bool, error) {
+	return mr.affected(useQuery, step, userID, step)
+}
+
+func (mr *MFARepository) DeleteTOTP(deleteQuery, userID string) error {
+	_, deleteErr := mr.DB.Exec(deleteQuery, userID)
+	return deleteErr
+}
+
+func (mr *MFARepository) SaveRecoveryCodes(saveQuery, userID string, hashes []string, createdAt time.Time) error {
+	for _, hash := range hashes {
+		if _, saveErr := mr.DB.Exec(saveQuery, userID, hash, createdAt); saveErr != nil {
+			return saveErr
+		}
+	}
+	return nil
+}
+
+func (mr *MFARepository) UseRecoveryCode(useQuery, userID, hash string, usedAt time.Time) (bool, error) {
+	return mr.affected(useQuery, usedAt, userID, hash)
+}
+
+func (mr *MFARepository) DeleteRecoveryCodes(deleteQuery, userID string) error {
+	_, deleteErr := mr.DB.Exec(deleteQuery, userID)
+	return deleteErr
+}
+
+func (mr *MFARepository) Purge(purgeQuery string) error {
+	_, purgeErr := mr.DB.Exec(purgeQuery)
+	return purgeErr
+}
+
+func (mr *MFARepository) affected(query string, args ...any) (bool, error) {
+	result, execErr := mr.DB.Exec(query, args...)
+	if execErr != nil {
+		return false, execErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
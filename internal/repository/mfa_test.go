@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := MFARepository{DB: db}
+	confirmedAt := config.TestTime
+
+	test := []struct {
+		Name           string
+		ExpectedSecret models.TOTPSecret
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name: "Success",
+			ExpectedSecret: models.TOTPSecret{
+				UserID:       "1",
+				Secret:       "JBSWY3DPEHPK3PXP",
+				ConfirmedAt:  &confirmedAt,
+				LastUsedStep: 42,
+				CreatedAt:    config.TestTime,
+			},
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+						AddRow("1", "JBSWY3DPEHPK3PXP", confirmedAt, 42, config.TestTime))
+			},
+		},
+		{
+			Name:           "Pending enrollment",
+			ExpectedSecret: models.TOTPSecret{UserID: "1", Secret: "JBSWY3DPEHPK3PXP", CreatedAt: config.TestTime},
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+						AddRow("1", "JBSWY3DPEHPK3PXP", nil, 0, config.TestTime))
+			},
+		},
+		{
+			Name:           "Not found",
+			ExpectedSecret: models.TOTPSecret{},
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns))
+			},
+		},
+		{
+			Name:           "Error",
+			ExpectedSecret: models.TOTPSecret{},
+			ExpectedErr:    fmt.Errorf("error searching secret"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnError(fmt.Errorf("error searching secret"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			secret, searchErr := repo.SearchTOTP(config.SearchTOTPQuery, "1")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedSecret, secret)
+		})
+	}
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := MFARepository{DB: db}
+
+	test := []struct {
+		Name         string
+		ExpectedUsed bool
+		ExpectedErr  error
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			ExpectedUsed: true,
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestUseTOTPStepQuery).
+					WithArgs(int64(42), "1", int64(42)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			Name:         "Replayed step",
+			ExpectedUsed: false,
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestUseTOTPStepQuery).
+					WithArgs(int64(42), "1", int64(42)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			Name:         "Error",
+			ExpectedUsed: false,
+			ExpectedErr:  fmt.Errorf("error using step"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestUseTOTPStepQuery).
+					WillReturnError(fmt.Errorf("error using step"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			used, useErr := repo.UseTOTPStep(config.UseTOTPStepQuery, "1", 42)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), useErr.Error())
+			} else {
+				assert.NoError(t, useErr)
+			}
+			assert.Equal(t, tt.ExpectedUsed, used)
+		})
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := MFARepository{DB: db}
+
+	test := []struct {
+		Name         string
+		ExpectedUsed bool
+		ExpectedErr  error
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			ExpectedUsed: true,
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveRecoveryCodeQuery).
+					WithArgs("1", "hash-1", config.TestTime).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveRecoveryCodeQuery).
+					WithArgs("1", "hash-2", config.TestTime).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec(config.TestUseRecoveryCodeQuery).
+					WithArgs(config.TestTime, "1", "hash-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			Name:         "Code already used",
+			ExpectedUsed: false,
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveRecoveryCodeQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveRecoveryCodeQuery).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec(config.TestUseRecoveryCodeQuery).
+					WithArgs(config.TestTime, "1", "hash-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			Name:         "Save error",
+			ExpectedUsed: false,
+			ExpectedErr:  fmt.Errorf("error saving code"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveRecoveryCodeQuery).
+					WillReturnError(fmt.Errorf("error saving code"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			saveErr := repo.SaveRecoveryCodes(config.SaveRecoveryCodeQuery, "1", []string{"hash-1", "hash-2"}, config.TestTime)
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), saveErr.Error())
+				return
+			}
+			assert.NoError(t, saveErr)
+
+			used, useErr := repo.UseRecoveryCode(config.UseRecoveryCodeQuery, "1", "hash-1", config.TestTime)
+			assert.NoError(t, useErr)
+			assert.Equal(t, tt.ExpectedUsed, used)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
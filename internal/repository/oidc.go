@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"strings"
+	"time"
+)
+
+type OAuthRepository struct {
+	DB     DBTX
+	Tenant string
+}
+
+func (or *OAuthRepository) SaveClient(saveQuery string, client models.OAuthClient) error {
+	_, saveErr := or.DB.Exec(saveQuery, client.ID, client.Name, client.SecretHash, strings.Join(client.RedirectURIs, " "),
+		client.Public, client.CreatedBy, client.CreatedAt, scopedTenant(or.Tenant))
+	return saveErr
+}
+
+func (or *OAuthRepository) ListClients(listQuery string) ([]models.OAuthClient, error) {
+	rows, err := or.DB.Query(listQuery, scopedTenant(or.Tenant))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.OAuthClient{}
+	for rows.Next() {
+		client, scanErr := scanOAuthClient(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+func (or *OAuthRepository) SearchClient(searchQuery, id string) (models.OAuthClient, error) {
+	client, err := scanOAuthClient(or.DB.QueryRow(searchQuery, id, scopedTenant(or.Tenant)))
+	if err == sql.ErrNoRows {
+		return models.OAuthClient{}, nil
+	}
+	return client, err
+}
+
+func (or *OAuthRepository) DeleteClient(deleteQuery, id string) (bool, error) {
+	return or.affected(deleteQuery, id, scopedTenant(or.Tenant))
+}
+
+func (or *OAuthRepository) DeleteClientCodes(deleteQuery, clientID string) error {
+	_, deleteErr := or.DB.Exec(deleteQuery, clientID)
+	return deleteErr
+}
+
+func (or *OAuthRepository) SaveCode(saveQuery string, code models.AuthorizationCode) error {
+	_, saveErr := or.DB.Exec(saveQuery, code.Hash, code.ClientID, code.UserID, code.RedirectURI, code.Scope, code.Nonce,
+		code.CodeChallenge, code.CreatedAt, code.ExpiresAt)
+	return saveErr
+}
+
+func (or *OAuthRepository) SearchCode(searchQuery, hash string, now time.Time) (models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+	err := or.DB.QueryRow(searchQuery, hash, now).Scan(&code.Hash, &code.ClientID, &code.UserID, &code.RedirectURI,
+		&code.Scope, &code.Nonce, &code.CodeChallenge, &code.CreatedAt, &code.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return models.AuthorizationCode{}, nil
+	}
+	return code, err
+}
+
+func (or *OAuthRepository) DeleteCode(deleteQuery, hash string) (bool, error) {
+	return or.affected(deleteQuery, hash)
+}
+
+func (or *OAuthRepository) PurgeCodes(purgeQuery string, now time.Time) error {
+	_, purgeErr := or.DB.Exec(purgeQuery, now)
+	return purgeErr
+}
+
+func (or *OAuthRepository) SaveSigningKey(saveQuery string, key models.SigningKey) error {
+	_, saveErr := or.DB.Exec(saveQuery, key.ID, key.PrivateKey, key.CreatedAt, nullableTime(key.RetiredAt))
+	return saveErr
+}
+
+func (or *OAuthRepository) ListSigningKeys(listQuery string, retiredAfter time.Time) ([]models.SigningKey, error) {
+	rows, err := or.DB.Query(listQuery, retiredAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.SigningKey{}
+	for rows.Next() {
+		var key models.SigningKey
+		var retiredAt sql.NullTime
+		if scanErr := rows.Scan(&key.ID, &key.PrivateKey, &key.CreatedAt, &retiredAt); scanErr != nil {
+			return nil, scanErr
+		}
+		key.RetiredAt = timePointer(retiredAt)
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (or *OAuthRepository) RetireSigningKeys(retireQuery string, retiredAt time.Time, activeID string) error {
+	_, retireErr := or.DB.Exec(retireQuery, retiredAt, activeID)
+	return retireErr
+}
+
+func (or *OAuthRepository) PurgeSigningKeys(purgeQuery string, retiredBefore time.Time) error {
+	_, purgeErr := or.DB.Exec(purgeQuery, retiredBefore)
+	return purgeErr
+}
+
+func (or *OAuthRepository) affected(query string, args ...any) (bool, error) {
+	result, execErr := or.DB.Exec(query, args...)
+	if execErr != nil {
+		return false, execErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func scanOAuthClient(row interface{ Scan(dest ...any) error }) (models.OAuthClient, error) {
+	var client models.OAuthClient
+	var redirectURIs string
+
+	err := row.Scan(&client.ID, &client.Name, &client.SecretHash, &redirectURIs, &client.Public, &client.CreatedBy, &client.CreatedAt)
+	if err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	return client, nil
+}
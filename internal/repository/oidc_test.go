@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndSearchOAuthClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := OAuthRepository{DB: db}
+	client := models.OAuthClient{
+		ID:           "client-1",
+		Name:         "wiki",
+		SecretHash:   "hash",
+		RedirectURIs: []string{"https://wiki.example.com/callback", "http://localhost:3000/callback"},
+		CreatedBy:    "admin",
+		CreatedAt:    config.TestTime,
+	}
+
+	test := []struct {
+		Name           string
+		ExpectedClient models.OAuthClient
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Success",
+			ExpectedClient: client,
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+					WithArgs("client-1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestOAuthClientColumns).
+						AddRow("client-1", "wiki", "hash", "https://wiki.example.com/callback http://localhost:3000/callback", false, "admin", config.TestTime))
+			},
+		},
+		{
+			Name:           "Not found",
+			ExpectedClient: models.OAuthClient{},
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+					WithArgs("client-1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestOAuthClientColumns))
+			},
+		},
+		{
+			Name:           "Error",
+			ExpectedClient: models.OAuthClient{},
+			ExpectedErr:    fmt.Errorf("error searching client"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+					WillReturnError(fmt.Errorf("error searching client"))
+			},
+		},
+	}
+
+	mock.ExpectExec(config.TestSaveOAuthClientQuery).
+		WithArgs("client-1", "wiki", "hash", "https://wiki.example.com/callback http://localhost:3000/callback", false, "admin", config.TestTime, config.DefaultTenant).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.SaveClient(config.SaveOAuthClientQuery, client))
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			found, searchErr := repo.SearchClient(config.SearchOAuthClientQuery, "client-1")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedClient, found)
+		})
+	}
+}
+
+func TestSearchAuthorizationCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := OAuthRepository{DB: db}
+	expiresAt := config.TestTime.Add(time.Minute)
+	code := models.AuthorizationCode{
+		Hash:          "hash",
+		ClientID:      "client-1",
+		UserID:        "user-1",
+		RedirectURI:   "https://wiki.example.com/callback",
+		Scope:         "openid email",
+		Nonce:         "nonce",
+		CodeChallenge: "challenge",
+		CreatedAt:     config.TestTime,
+		ExpiresAt:     expiresAt,
+	}
+
+	test := []struct {
+		Name         string
+		ExpectedCode models.AuthorizationCode
+		ExpectedErr  error
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			ExpectedCode: code,
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAuthCodeQuery).
+					WithArgs("hash", config.TestTime).
+					WillReturnRows(sqlmock.NewRows(config.TestAuthCodeColumns).
+						AddRow("hash", "client-1", "user-1", "https://wiki.example.com/callback", "openid email", "nonce", "challenge", config.TestTime, expiresAt))
+			},
+		},
+		{
+			Name:         "Unknown or expired",
+			ExpectedCode: models.AuthorizationCode{},
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAuthCodeQuery).
+					WithArgs("hash", config.TestTime).
+					WillReturnRows(sqlmock.NewRows(config.TestAuthCodeColumns))
+			},
+		},
+		{
+			Name:         "Error",
+			ExpectedCode: models.AuthorizationCode{},
+			ExpectedErr:  fmt.Errorf("error searching code"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAuthCodeQuery).
+					WillReturnError(fmt.Errorf("error searching code"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			found, searchErr := repo.SearchCode(config.SearchAuthorizationCodeQuery, "hash", config.TestTime)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedCode, found)
+		})
+	}
+}
+
+func TestRotateSigningKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := OAuthRepository{DB: db}
+	retiredAt := config.TestTime.Add(-time.Minute)
+	key := models.SigningKey{ID: "kid-2", PrivateKey: "pem-2", CreatedAt: config.TestTime}
+
+	mock.ExpectExec(config.TestSaveSigningKeyQuery).
+		WithArgs("kid-2", "pem-2", config.TestTime, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(config.TestRetireSigningKeysQuery).
+		WithArgs(config.TestTime, "kid-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(config.TestListSigningKeysQuery).
+		WithArgs(retiredAt).
+		WillReturnRows(sqlmock.NewRows(config.TestSigningKeyColumns).
+			AddRow("kid-2", "pem-2", config.TestTime, nil).
+			AddRow("kid-1", "pem-1", retiredAt, config.TestTime))
+
+	assert.NoError(t, repo.SaveSigningKey(config.SaveSigningKeyQuery, key))
+	assert.NoError(t, repo.RetireSigningKeys(config.RetireSigningKeysQuery, config.TestTime, "kid-2"))
+
+	keys, listErr := repo.ListSigningKeys(config.ListSigningKeysQuery, retiredAt)
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.SigningKey{key, {ID: "kid-1", PrivateKey: "pem-1", CreatedAt: retiredAt, RetiredAt: &config.TestTime}}, keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,12 +1,135 @@
 package repository
 
-import "go-manage/internal/models"
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"time"
+)
+
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
 
 type Repository interface {
 	Exists(existsQuery, username string) bool
 	Search(searchQuery, username string) (models.User, error)
+	SearchBySession(searchQuery, tokenHash string, now time.Time) (models.User, error)
+	List(listQuery string, filter models.UserFilter) ([]models.User, error)
+	Stream(listQuery string, filter models.UserFilter, fn func(models.User) error) error
 	Save(saveQuery string, user models.User) (models.User, error)
-	Delete(deleteQuery, username string) error
-	Update(updateQuery, username string, user models.User) error
+	Delete(deleteQuery, username string, version int) error
+	Restore(restoreQuery, username string) error
+	Purge(purgeQuery string, deletedBefore time.Time) (int64, error)
+	PurgeTenants(tenantsQuery string, deletedBefore time.Time) ([]string, error)
+	Update(updateQuery, username string, version int, user models.User) (models.User, error)
 	ChangePwd(changePwdQuery, username, newPassword string) error
+	RecordLogin(loginQuery, username string) error
+	RehashPassword(rehashQuery, userID, oldHash, newHash string) error
+	SavePasswordHistory(historyQuery, userID, password string) error
+	PasswordHistory(historyQuery, userID string, limit int) ([]string, error)
+	PurgePasswordHistory(purgeQuery string) error
+}
+
+type AuditRepo interface {
+	Save(saveQuery string, event models.AuditEvent) error
+	List(listQuery string, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type SessionRepo interface {
+	Save(saveQuery, tokenHash string, session models.Session) error
+	SaveChallenge(saveQuery, tokenHash, userID string, createdAt, expiresAt time.Time) error
+	SearchChallenge(searchQuery, tokenHash string, now time.Time) (string, error)
+	DeleteChallenge(deleteQuery, tokenHash string, now time.Time) error
+}
+
+type MFARepo interface {
+	SearchTOTP(searchQuery, userID string) (models.TOTPSecret, error)
+	SaveTOTP(saveQuery string, secret models.TOTPSecret) error
+	ConfirmTOTP(confirmQuery, userID string, confirmedAt time.Time, step int64) (bool, error)
+	UseTOTPStep(useQuery, userID string, step int64) (bool, error)
+	DeleteTOTP(deleteQuery, userID string) error
+	SaveRecoveryCodes(saveQuery, userID string, hashes []string, createdAt time.Time) error
+	UseRecoveryCode(useQuery, userID, hash string, usedAt time.Time) (bool, error)
+	DeleteRecoveryCodes(deleteQuery, userID string) error
+	Purge(purgeQuery string) error
+}
+
+type APIKeyRepo interface {
+	Save(saveQuery string, key models.APIKey) error
+	List(listQuery string) ([]models.APIKey, error)
+	SearchByPrefix(searchQuery, prefix string) (models.APIKey, error)
+	Revoke(revokeQuery, id string, revokedAt time.Time) (bool, error)
+	Touch(touchQuery, id string, usedAt time.Time) error
+}
+
+type OAuthRepo interface {
+	SaveClient(saveQuery string, client models.OAuthClient) error
+	ListClients(listQuery string) ([]models.OAuthClient, error)
+	SearchClient(searchQuery, id string) (models.OAuthClient, error)
+	DeleteClient(deleteQuery, id string) (bool, error)
+	DeleteClientCodes(deleteQuery, clientID string) error
+	SaveCode(saveQuery string, code models.AuthorizationCode) error
+	SearchCode(searchQuery, hash string, now time.Time) (models.AuthorizationCode, error)
+	DeleteCode(deleteQuery, hash string) (bool, error)
+	PurgeCodes(purgeQuery string, now time.Time) error
+	SaveSigningKey(saveQuery string, key models.SigningKey) error
+	ListSigningKeys(listQuery string, retiredAfter time.Time) ([]models.SigningKey, error)
+	RetireSigningKeys(retireQuery string, retiredAt time.Time, activeID string) error
+	PurgeSigningKeys(purgeQuery string, retiredBefore time.Time) error
+}
+
+type FederationRepo interface {
+	SaveIdentity(saveQuery string, identity models.ExternalIdentity) error
+	SearchIdentity(searchQuery, issuer, subject string) (models.ExternalIdentity, error)
+	ListIdentities(listQuery, userID string) ([]models.ExternalIdentity, error)
+	DeleteIdentity(deleteQuery, id, userID string) (bool, error)
+	TouchIdentity(touchQuery, id string, loginAt time.Time) error
+	PurgeIdentities(purgeQuery string) error
+	SaveState(saveQuery string, state models.FederationState) error
+	SearchState(searchQuery, hash string, now time.Time) (models.FederationState, error)
+	DeleteState(deleteQuery, hash string) (bool, error)
+	PurgeStates(purgeQuery string, now time.Time) error
+}
+
+type GroupRepo interface {
+	Save(saveQuery string, group models.Group) error
+	Search(searchQuery, id string) (models.Group, error)
+	List(listQuery string) ([]models.Group, error)
+	Update(updateQuery string, group models.Group) error
+	Delete(deleteQuery, id string) (bool, error)
+	InSubtree(subtreeQuery, rootID, id string) (bool, error)
+	SaveMember(saveQuery string, member models.GroupMember) error
+	SearchMember(searchQuery, groupID, userID string) (models.GroupMember, error)
+	DeleteMember(deleteQuery, groupID, userID string) (bool, error)
+	DeleteMembers(deleteQuery, groupID string) error
+	ListMembers(listQuery, groupID string) ([]models.GroupMember, error)
+	ListMemberships(listQuery, userID string) ([]models.Membership, error)
+	PurgeMembers(purgeQuery string) error
+}
+
+type RoleRepo interface {
+	Save(saveQuery string, role models.Role) error
+	Search(searchQuery, id string) (models.Role, error)
+	List(listQuery string) ([]models.Role, error)
+	Update(updateQuery string, role models.Role) error
+	Delete(deleteQuery, id string) (bool, error)
+	Assign(assignQuery, userID, roleID string, assignedAt time.Time) (bool, error)
+	Unassign(unassignQuery, userID, roleID string) (bool, error)
+	DeleteAssignments(deleteQuery, roleID string) error
+	ListUserRoles(listQuery, userID string) ([]models.Role, error)
+	PurgeAssignments(purgeQuery string) error
+}
+
+type TenantRepo interface {
+	SearchBySession(searchQuery, tokenHash string, now time.Time) (string, error)
+	SearchByAPIKey(searchQuery, prefix string) (string, error)
+}
+
+type ThrottleRepo interface {
+	Search(searchQuery, key string) (models.LoginThrottle, error)
+	Save(saveQuery string, throttle models.LoginThrottle) error
+	Delete(deleteQuery, key string) error
+	Purge(purgeQuery string, now, windowStartedBefore time.Time) (int64, error)
 }
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"strings"
+	"time"
+)
+
+type RoleRepository struct {
+	DB     DBTX
+	Tenant string
+}
+
+func (rr *RoleRepository) Save(saveQuery string, role models.Role) error {
+	_, saveErr := rr.DB.Exec(saveQuery, role.ID, role.Name, role.Description, strings.Join(role.Permissions, ","),
+		role.CreatedAt, role.UpdatedAt, scopedTenant(rr.Tenant))
+	return roleUniqueViolation(saveErr)
+}
+
+func (rr *RoleRepository) Search(searchQuery, id string) (models.Role, error) {
+	role, err := scanRole(rr.DB.QueryRow(searchQuery, id, scopedTenant(rr.Tenant)))
+	if err == sql.ErrNoRows {
+		return models.Role{}, nil
+	}
+	return role, err
+}
+
+func (rr *RoleRepository) List(listQuery string) ([]models.Role, error) {
+	rows, err := rr.DB.Query(listQuery, scopedTenant(rr.Tenant))
+	if err != nil {
+		return nil, err
+	}
+	return scanRoles(rows)
+}
+
+func (rr *RoleRepository) Update(updateQuery string, role models.Role) error {
+	_, updateErr := rr.DB.Exec(updateQuery, role.Name, role.Description, strings.Join(role.Permissions, ","),
+		role.UpdatedAt, role.ID, scopedTenant(rr.Tenant))
+	return roleUniqueViolation(updateErr)
+}
+
+func (rr *RoleRepository) Delete(deleteQuery, id string) (bool, error) {
+	result, deleteErr := rr.DB.Exec(deleteQuery, id, scopedTenant(rr.Tenant))
+	if deleteErr != nil {
+		return false, deleteErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (rr *RoleRepository) Assign(assignQuery, userID, roleID string, assignedAt time.Time) (bool, error) {
+	result, assignErr := rr.DB.Exec(assignQuery, userID, roleID, assignedAt)
+	if assignErr != nil {
+		return false, assignErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (rr *RoleRepository) Unassign(unassignQuery, userID, roleID string) (bool, error) {
+	result, unassignErr := rr.DB.Exec(unassignQuery, userID, roleID)
+	if unassignErr != nil {
+		return false, unassignErr
+	}
+	rows, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return rows == 1, nil
+}
+
+func (rr *RoleRepository) DeleteAssignments(deleteQuery, roleID string) error {
+	_, deleteErr := rr.DB.Exec(deleteQuery, roleID)
+	return deleteErr
+}
+
+func (rr *RoleRepository) ListUserRoles(listQuery, userID string) ([]models.Role, error) {
+	rows, err := rr.DB.Query(listQuery, userID, scopedTenant(rr.Tenant))
+	if err != nil {
+		return nil, err
+	}
+	return scanRoles(rows)
+}
+
+func (rr *RoleRepository) PurgeAssignments(purgeQuery string) error {
+	_, purgeErr := rr.DB.Exec(purgeQuery)
+	return purgeErr
+}
+
+func scanRoles(rows *sql.Rows) ([]models.Role, error) {
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		role, scanErr := scanRole(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func scanRole(row interface{ Scan(dest ...any) error }) (models.Role, error) {
+	var role models.Role
+	var permissions string
+
+	err := row.Scan(&role.ID, &role.Name, &role.Description, &permissions, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	role.Permissions = strings.Split(permissions, ",")
+	return role, nil
+}
+
+func roleUniqueViolation(err error) error {
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return config.ErrRoleAlreadyExists
+	}
+	return err
+}
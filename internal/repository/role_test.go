@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndSearchRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := RoleRepository{DB: db, Tenant: "acme"}
+	role := models.Role{
+		ID:          "role-1",
+		Name:        "Support",
+		Description: "Helpdesk staff",
+		Permissions: []string{config.PermUsersRead, config.PermUsersWrite},
+		CreatedAt:   config.TestTime,
+		UpdatedAt:   config.TestTime,
+	}
+
+	test := []struct {
+		Name         string
+		ExpectedRole models.Role
+		ExpectedErr  error
+		MockAct      func()
+	}{
+		{
+			Name:         "Success",
+			ExpectedRole: role,
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchRoleQuery).
+					WithArgs("role-1", "acme").
+					WillReturnRows(sqlmock.NewRows(config.TestRoleColumns).
+						AddRow("role-1", "Support", "Helpdesk staff", "users:read,users:write", config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:         "Not found",
+			ExpectedRole: models.Role{},
+			ExpectedErr:  nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchRoleQuery).
+					WithArgs("role-1", "acme").
+					WillReturnRows(sqlmock.NewRows(config.TestRoleColumns))
+			},
+		},
+		{
+			Name:         "Error",
+			ExpectedRole: models.Role{},
+			ExpectedErr:  fmt.Errorf("error searching role"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchRoleQuery).
+					WillReturnError(fmt.Errorf("error searching role"))
+			},
+		},
+	}
+
+	mock.ExpectExec(config.TestSaveRoleQuery).
+		WithArgs("role-1", "Support", "Helpdesk staff", "users:read,users:write", config.TestTime, config.TestTime, "acme").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.Save(config.SaveRoleQuery, role))
+
+	mock.ExpectExec(config.TestSaveRoleQuery).
+		WillReturnError(fmt.Errorf("UNIQUE constraint failed: roles.tenant_id, roles.name"))
+	assert.ErrorIs(t, repo.Save(config.SaveRoleQuery, role), config.ErrRoleAlreadyExists)
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			found, searchErr := repo.Search(config.SearchRoleQuery, "role-1")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedRole, found)
+		})
+	}
+}
+
+func TestRoleAssignments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := RoleRepository{DB: db}
+
+	mock.ExpectExec(config.TestAssignRoleQuery).
+		WithArgs("user-1", "role-1", config.TestTime).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assigned, assignErr := repo.Assign(config.AssignRoleQuery, "user-1", "role-1", config.TestTime)
+	assert.NoError(t, assignErr)
+	assert.False(t, assigned)
+
+	mock.ExpectExec(config.TestUnassignRoleQuery).
+		WithArgs("user-1", "role-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	removed, unassignErr := repo.Unassign(config.UnassignRoleQuery, "user-1", "role-1")
+	assert.NoError(t, unassignErr)
+	assert.True(t, removed)
+
+	mock.ExpectQuery(config.TestListUserRolesQuery).
+		WithArgs("user-1", config.DefaultTenant).
+		WillReturnRows(sqlmock.NewRows(config.TestRoleColumns).
+			AddRow("role-2", "Auditor", "", "audit:read", config.TestTime, config.TestTime).
+			AddRow("role-1", "Support", "", "users:read,users:write:self", config.TestTime, config.TestTime))
+	roles, listErr := repo.ListUserRoles(config.ListUserRolesQuery, "user-1")
+	assert.NoError(t, listErr)
+	assert.Equal(t, 2, len(roles))
+	assert.Equal(t, []string{config.PermUsersRead, "users:write:self"}, roles[1].Permissions)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"time"
+)
+
+type SessionRepository struct {
+	DB DBTX
+}
+
+func (sr *SessionRepository) Save(saveQuery, tokenHash string, session models.Session) error {
+	_, saveErr := sr.DB.Exec(saveQuery, tokenHash, session.UserID, session.CreatedAt, session.ExpiresAt)
+	return saveErr
+}
+
+func (sr *SessionRepository) Purge(purgeQuery string, now time.Time) (int64, error) {
+	result, purgeErr := sr.DB.Exec(purgeQuery, now)
+	if purgeErr != nil {
+		return 0, purgeErr
+	}
+	return result.RowsAffected()
+}
+
+func (sr *SessionRepository) SaveChallenge(saveQuery, tokenHash, userID string, createdAt, expiresAt time.Time) error {
+	_, saveErr := sr.DB.Exec(saveQuery, tokenHash, userID, createdAt, expiresAt)
+	return saveErr
+}
+
+func (sr *SessionRepository) SearchChallenge(searchQuery, tokenHash string, now time.Time) (string, error) {
+	var userID string
+
+	err := sr.DB.QueryRow(searchQuery, tokenHash, now).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return userID, err
+}
+
+func (sr *SessionRepository) DeleteChallenge(deleteQuery, tokenHash string, now time.Time) error {
+	_, deleteErr := sr.DB.Exec(deleteQuery, tokenHash, now)
+	return deleteErr
+}
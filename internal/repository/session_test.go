@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := SessionRepository{DB: db}
+
+	session := models.Session{
+		Token:     "token",
+		UserID:    "1",
+		CreatedAt: config.TestTime,
+		ExpiresAt: config.TestTime.Add(time.Hour),
+	}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WithArgs("token-hash", "1", config.TestTime, config.TestTime.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: fmt.Errorf("error saving session"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WillReturnError(fmt.Errorf("error saving session"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			saveErr := repo.Save(config.SaveSessionQuery, "token-hash", session)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), saveErr.Error())
+			} else {
+				assert.NoError(t, saveErr)
+			}
+		})
+	}
+}
+
+func TestPurgeSessions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := SessionRepository{DB: db}
+
+	test := []struct {
+		Name           string
+		ExpectedPurged int64
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Success",
+			ExpectedPurged: 3,
+			MockAct: func() {
+				mock.ExpectExec(config.TestPurgeSessionsQuery).
+					WithArgs(config.TestTime).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: fmt.Errorf("error purging sessions"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestPurgeSessionsQuery).
+					WillReturnError(fmt.Errorf("error purging sessions"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			purged, purgeErr := repo.Purge(config.PurgeSessionsQuery, config.TestTime)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), purgeErr.Error())
+			} else {
+				assert.NoError(t, purgeErr)
+			}
+			assert.Equal(t, tt.ExpectedPurged, purged)
+		})
+	}
+}
+
+func TestSearchChallenge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := SessionRepository{DB: db}
+
+	test := []struct {
+		Name           string
+		ExpectedUserID string
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Success",
+			ExpectedUserID: "1",
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchChallengeQuery).
+					WithArgs("challenge-hash", config.TestTime).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("1"))
+			},
+		},
+		{
+			Name:           "Expired or unknown",
+			ExpectedUserID: "",
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchChallengeQuery).
+					WithArgs("challenge-hash", config.TestTime).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+			},
+		},
+		{
+			Name:           "Error",
+			ExpectedUserID: "",
+			ExpectedErr:    fmt.Errorf("error searching challenge"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchChallengeQuery).
+					WillReturnError(fmt.Errorf("error searching challenge"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			userID, searchErr := repo.SearchChallenge(config.SearchChallengeQuery, "challenge-hash", config.TestTime)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedUserID, userID)
+		})
+	}
+}
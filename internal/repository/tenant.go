@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type TenantRepository struct {
+	DB DBTX
+}
+
+func (tr *TenantRepository) SearchBySession(searchQuery, tokenHash string, now time.Time) (string, error) {
+	return tr.search(searchQuery, tokenHash, now)
+}
+
+func (tr *TenantRepository) SearchByAPIKey(searchQuery, prefix string) (string, error) {
+	return tr.search(searchQuery, prefix)
+}
+
+func (tr *TenantRepository) search(searchQuery string, args ...any) (string, error) {
+	var tenant string
+
+	err := tr.DB.QueryRow(searchQuery, args...).Scan(&tenant)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return tenant, err
+}
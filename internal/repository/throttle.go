@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"go-manage/internal/models"
+	"time"
+)
+
+type ThrottleRepository struct {
+	DB DBTX
+}
+
+func (tr *ThrottleRepository) Search(searchQuery, key string) (models.LoginThrottle, error) {
+	throttle := models.LoginThrottle{Key: key}
+	var lockedUntil sql.NullTime
+
+	err := tr.DB.QueryRow(searchQuery, key).Scan(&throttle.Key, &throttle.Failures, &throttle.WindowStartedAt, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return throttle, nil
+	}
+	if err != nil {
+		return models.LoginThrottle{}, err
+	}
+
+	if lockedUntil.Valid {
+		throttle.LockedUntil = &lockedUntil.Time
+	}
+	return throttle, nil
+}
+
+func (tr *ThrottleRepository) Save(saveQuery string, throttle models.LoginThrottle) error {
+	var lockedUntil any
+	if throttle.LockedUntil != nil {
+		lockedUntil = *throttle.LockedUntil
+	}
+
+	_, saveErr := tr.DB.Exec(saveQuery, throttle.Key, throttle.Failures, throttle.WindowStartedAt, lockedUntil)
+	return saveErr
+}
+
+func (tr *ThrottleRepository) Delete(deleteQuery, key string) error {
+	_, deleteErr := tr.DB.Exec(deleteQuery, key)
+	return deleteErr
+}
+
+func (tr *ThrottleRepository) Purge(purgeQuery string, now, windowStartedBefore time.Time) (int64, error) {
+	result, purgeErr := tr.DB.Exec(purgeQuery, windowStartedBefore, now)
+	if purgeErr != nil {
+		return 0, purgeErr
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchThrottle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := ThrottleRepository{DB: db}
+	lockedUntil := config.TestTime.Add(time.Hour)
+
+	test := []struct {
+		Name             string
+		ExpectedThrottle models.LoginThrottle
+		ExpectedErr      error
+		MockAct          func()
+	}{
+		{
+			Name: "Success",
+			ExpectedThrottle: models.LoginThrottle{
+				Key:             "user:1",
+				Failures:        10,
+				WindowStartedAt: config.TestTime,
+				LockedUntil:     &lockedUntil,
+			},
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchThrottleQuery).
+					WithArgs("user:1").
+					WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns).
+						AddRow("user:1", 10, config.TestTime, lockedUntil))
+			},
+		},
+		{
+			Name:             "Not found",
+			ExpectedThrottle: models.LoginThrottle{Key: "user:1"},
+			ExpectedErr:      nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchThrottleQuery).
+					WithArgs("user:1").
+					WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns))
+			},
+		},
+		{
+			Name:             "Error",
+			ExpectedThrottle: models.LoginThrottle{},
+			ExpectedErr:      fmt.Errorf("error searching throttle"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchThrottleQuery).
+					WithArgs("user:1").
+					WillReturnError(fmt.Errorf("error searching throttle"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			throttle, searchErr := repo.Search(config.SearchThrottleQuery, "user:1")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedThrottle, throttle)
+		})
+	}
+}
+
+func TestSaveThrottle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := ThrottleRepository{DB: db}
+	lockedUntil := config.TestTime.Add(time.Minute)
+
+	mock.ExpectExec(config.TestSaveThrottleQuery).
+		WithArgs("ip:10.0.0.1", 1, config.TestTime, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(config.TestSaveThrottleQuery).
+		WithArgs("user:1", 4, config.TestTime, lockedUntil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(config.TestDeleteThrottleQuery).
+		WithArgs("user:1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(config.TestPurgeThrottlesQuery).
+		WithArgs(config.TestTime.Add(-time.Hour), config.TestTime).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.Save(config.SaveThrottleQuery, models.LoginThrottle{Key: "ip:10.0.0.1", Failures: 1, WindowStartedAt: config.TestTime}))
+	assert.NoError(t, repo.Save(config.SaveThrottleQuery, models.LoginThrottle{Key: "user:1", Failures: 4, WindowStartedAt: config.TestTime, LockedUntil: &lockedUntil}))
+	assert.NoError(t, repo.Delete(config.DeleteThrottleQuery, "user:1"))
+
+	purged, purgeErr := repo.Purge(config.PurgeThrottlesQuery, config.TestTime, config.TestTime.Add(-time.Hour))
+	assert.NoError(t, purgeErr)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
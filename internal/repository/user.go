@@ -4,10 +4,25 @@ import (
 	"database/sql"
 	"go-manage/cmd/config"
 	"go-manage/internal/models"
+	"slices"
+	"strings"
+	"time"
 )
 
 type UserRepository struct {
-	DB *sql.DB
+	DB     DBTX
+	Clock  func() time.Time
+	Tenant string
+}
+
+func (ur UserRepository) WithTx(tx *sql.Tx) UserRepository {
+	ur.DB = tx
+	return ur
+}
+
+func (ur UserRepository) ForTenant(tenant string) UserRepository {
+	ur.Tenant = tenant
+	return ur
 }
 
 func (ur *UserRepository) Exists(existsQuery, username string) bool {
@@ -25,14 +40,14 @@ func (ur *UserRepository) Exists(existsQuery, username string) bool {
 
 func (ur *UserRepository) Search(searchQuery, username string) (models.User, error) {
 	user := models.User{}
-	rows, err := ur.DB.Query(config.SearchUserQuery, username)
+	rows, err := ur.DB.Query(searchQuery, username, scopedTenant(ur.Tenant))
 	if err != nil {
 		return models.User{}, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&user.ID, &user.Name, &user.Surname, &user.Username, &user.Email, &user.Password)
+		user, err = scanUser(rows)
 		if err != nil {
 			return models.User{}, config.ErrUserNotFound
 		}
@@ -41,31 +56,292 @@ func (ur *UserRepository) Search(searchQuery, username string) (models.User, err
 	return user, nil
 }
 
+func (ur *UserRepository) SearchBySession(searchQuery, tokenHash string, now time.Time) (models.User, error) {
+	user := models.User{}
+	rows, err := ur.DB.Query(searchQuery, tokenHash, now, scopedTenant(ur.Tenant))
+	if err != nil {
+		return models.User{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err = scanUser(rows)
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return user, rows.Err()
+}
+
+func (ur *UserRepository) List(listQuery string, filter models.UserFilter) ([]models.User, error) {
+	query, args, buildErr := buildListQuery(listQuery, filter)
+	if buildErr != nil {
+		return nil, buildErr
+	}
+
+	rows, err := ur.DB.Query(query, append([]any{scopedTenant(ur.Tenant)}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, scanErr := scanUser(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (ur *UserRepository) Stream(listQuery string, filter models.UserFilter, fn func(models.User) error) error {
+	query, args, buildErr := buildListQuery(listQuery, filter)
+	if buildErr != nil {
+		return buildErr
+	}
+
+	rows, err := ur.DB.Query(query, append([]any{scopedTenant(ur.Tenant)}, args...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, scanErr := scanUser(rows)
+		if scanErr != nil {
+			return scanErr
+		}
+		if fnErr := fn(user); fnErr != nil {
+			return fnErr
+		}
+	}
+
+	return rows.Err()
+}
+
 func (ur *UserRepository) Save(saveQuery string, user models.User) error {
-	_, saveErr := ur.DB.Exec(saveQuery, user.ID, user.Name, user.Surname, user.Username, user.Email, user.Password)
+	_, saveErr := ur.DB.Exec(saveQuery, user.ID, user.Name, user.Surname, user.Username, user.Email, user.Password,
+		user.Version, user.CreatedAt, user.UpdatedAt, user.PasswordChangedAt, scopedTenant(ur.Tenant))
 	if saveErr != nil {
-		return saveErr
+		return uniqueViolation(saveErr)
 	}
 	return nil
 }
 
-func (ur *UserRepository) Delete(deleteQuery, username string) error {
-	_, err := ur.DB.Exec(deleteQuery, username)
-	return err
+func (ur *UserRepository) Delete(deleteQuery, username string, version int) error {
+	now := ur.Now()
+	result, err := ur.DB.Exec(deleteQuery, now, now, username, version, scopedTenant(ur.Tenant))
+	if err != nil {
+		return err
+	}
+	return checkVersion(result)
 }
 
-func (ur *UserRepository) Update(updateQuery, username string, user models.User) error {
-	_, updateErr := ur.DB.Exec(updateQuery, user.Name, user.Surname, user.Email, username)
-	if updateErr != nil {
-		return updateErr
+func (ur *UserRepository) Restore(restoreQuery, username string) error {
+	result, err := ur.DB.Exec(restoreQuery, ur.Now(), username, scopedTenant(ur.Tenant))
+	if err != nil {
+		return uniqueViolation(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return config.ErrUserNotFound
 	}
 	return nil
 }
 
+func (ur *UserRepository) Purge(purgeQuery string, deletedBefore time.Time) (int64, error) {
+	result, err := ur.DB.Exec(purgeQuery, deletedBefore.UTC(), scopedTenant(ur.Tenant))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (ur *UserRepository) PurgeTenants(tenantsQuery string, deletedBefore time.Time) ([]string, error) {
+	rows, err := ur.DB.Query(tenantsQuery, deletedBefore.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var tenant string
+		if scanErr := rows.Scan(&tenant); scanErr != nil {
+			return nil, scanErr
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
+func (ur *UserRepository) Update(updateQuery, username string, version int, user models.User) (models.User, error) {
+	now := ur.Now()
+	result, updateErr := ur.DB.Exec(updateQuery, user.Name, user.Surname, user.Username, user.Email, now, username, version, scopedTenant(ur.Tenant))
+	if updateErr != nil {
+		return models.User{}, uniqueViolation(updateErr)
+	}
+	if versionErr := checkVersion(result); versionErr != nil {
+		return models.User{}, versionErr
+	}
+
+	user.UpdatedAt = now
+	user.Version = version + 1
+
+	return user, nil
+}
+
 func (ur *UserRepository) ChangePwd(changePwdQuery, username, newPassword string) error {
-	_, changePwdErr := ur.DB.Exec(changePwdQuery, newPassword, username)
+	now := ur.Now()
+	_, changePwdErr := ur.DB.Exec(changePwdQuery, newPassword, now, now, username, scopedTenant(ur.Tenant))
 	if changePwdErr != nil {
 		return changePwdErr
 	}
 	return nil
 }
+
+func (ur *UserRepository) RecordLogin(loginQuery, username string) error {
+	_, loginErr := ur.DB.Exec(loginQuery, ur.Now(), username, scopedTenant(ur.Tenant))
+	return loginErr
+}
+
+func (ur *UserRepository) RehashPassword(rehashQuery, userID, oldHash, newHash string) error {
+	_, rehashErr := ur.DB.Exec(rehashQuery, newHash, userID, oldHash, scopedTenant(ur.Tenant))
+	return rehashErr
+}
+
+func (ur *UserRepository) SavePasswordHistory(historyQuery, userID, password string) error {
+	_, historyErr := ur.DB.Exec(historyQuery, userID, password, ur.Now())
+	return historyErr
+}
+
+func (ur *UserRepository) PasswordHistory(historyQuery, userID string, limit int) ([]string, error) {
+	rows, err := ur.DB.Query(historyQuery, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []string
+	for rows.Next() {
+		var password string
+		if scanErr := rows.Scan(&password); scanErr != nil {
+			return nil, scanErr
+		}
+		history = append(history, password)
+	}
+
+	return history, rows.Err()
+}
+
+func (ur *UserRepository) PurgePasswordHistory(purgeQuery string) error {
+	_, purgeErr := ur.DB.Exec(purgeQuery)
+	return purgeErr
+}
+
+func (ur *UserRepository) Savepoint(savepointQuery string) error {
+	_, err := ur.DB.Exec(savepointQuery)
+	return err
+}
+
+func (ur *UserRepository) Now() time.Time {
+	if ur.Clock != nil {
+		return ur.Clock().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func scanUser(rows *sql.Rows) (models.User, error) {
+	var user models.User
+	var lastLogin, passwordChanged sql.NullTime
+
+	err := rows.Scan(&user.ID, &user.Name, &user.Surname, &user.Username, &user.Email, &user.Password, &user.Version,
+		&user.CreatedAt, &user.UpdatedAt, &lastLogin, &passwordChanged)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if lastLogin.Valid {
+		user.LastLoginAt = &lastLogin.Time
+	}
+	if passwordChanged.Valid {
+		user.PasswordChangedAt = &passwordChanged.Time
+	}
+
+	return user, nil
+}
+
+func buildListQuery(listQuery string, filter models.UserFilter) (string, []any, error) {
+	var conditions []string
+	var args []any
+
+	for _, timeRange := range filter.Ranges {
+		if !slices.Contains(config.UserTimestampFields, timeRange.Field) {
+			return "", nil, config.ErrInvalidFilter
+		}
+		if timeRange.After != nil {
+			conditions = append(conditions, timeRange.Field+" >= ?")
+			args = append(args, timeRange.After.UTC())
+		}
+		if timeRange.Before != nil {
+			conditions = append(conditions, timeRange.Field+" < ?")
+			args = append(args, timeRange.Before.UTC())
+		}
+	}
+
+	sort := filter.Sort
+	if sort == "" {
+		sort = config.DefaultSortField
+	}
+	if !slices.Contains(config.UserSortFields, sort) {
+		return "", nil, config.ErrInvalidSortField
+	}
+
+	order := "ASC"
+	if filter.Desc {
+		order = "DESC"
+	}
+
+	query := strings.TrimSuffix(listQuery, ";")
+	for _, condition := range conditions {
+		query += " AND " + condition
+	}
+	query += " ORDER BY " + sort + " " + order + ", id ASC LIMIT ? OFFSET ?;"
+	args = append(args, filter.Limit, filter.Offset)
+
+	return query, args, nil
+}
+
+func scopedTenant(tenant string) string {
+	if tenant == "" {
+		return config.DefaultTenant
+	}
+	return tenant
+}
+
+func uniqueViolation(err error) error {
+	if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return config.ErrUserAlreadyExists
+	}
+	return err
+}
+
+func checkVersion(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return config.ErrVersionMismatch
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"go-manage/internal/models"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -18,7 +19,7 @@ func TestExists(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := UserRepository{DB: db}
+	repo := UserRepository{DB: db, Clock: config.TestClock}
 
 	tests := []struct {
 		Name         string
@@ -32,9 +33,9 @@ func TestExists(t *testing.T) {
 			ExpectedBool: true,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "password123"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe", "johndoe@example.com", "password123", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 		},
 		{
@@ -50,8 +51,8 @@ func TestExists(t *testing.T) {
 			ExpectedBool: false,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("lala").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("lala", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 		},
 	}
@@ -74,41 +75,57 @@ func TestSearch(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := UserRepository{DB: db}
+	repo := UserRepository{DB: db, Clock: config.TestClock}
 
 	test := []struct {
 		Name          string
+		Query         string
 		Username      string
 		ExpectedError error
 		MockAct       func()
 	}{
 		{
 			Name:          "Success",
+			Query:         config.SearchUserQuery,
 			Username:      "johndoe2024",
 			ExpectedError: nil,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe2024").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(1, "John", "Doe", "johndoe2024", "john@example.com", "password123"))
+					WithArgs("johndoe2024", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow(1, "John", "Doe", "johndoe2024", "john@example.com", "password123", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 		},
 		{
 			Name:          "Error",
+			Query:         config.SearchUserQuery,
 			Username:      "johndoe2024",
 			ExpectedError: err,
 			MockAct: func() {
 			},
 		},
+		{
+			Name:          "Success by id",
+			Query:         config.SearchUserByIDQuery,
+			Username:      "1",
+			ExpectedError: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe2024", "john@example.com", "password123", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
 		{
 			Name:          "User not found",
+			Query:         config.SearchUserQuery,
 			Username:      "johndoe2024",
 			ExpectedError: fmt.Errorf("user not found"),
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe2024").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow(nil, nil, nil, nil, nil, nil))
+					WithArgs("johndoe2024", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
 			},
 		},
 	}
@@ -117,7 +134,7 @@ func TestSearch(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockAct()
 
-			search, searchErr := repo.Search(config.TestSearchQuery, tt.Username)
+			search, searchErr := repo.Search(tt.Query, tt.Username)
 
 			if tt.ExpectedError != nil {
 				assert.Equal(t, tt.ExpectedError, searchErr)
@@ -129,6 +146,69 @@ func TestSearch(t *testing.T) {
 	}
 }
 
+func TestSearchBySession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	test := []struct {
+		Name           string
+		ExpectedUserID string
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Success",
+			ExpectedUserID: "1",
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchSessionUserQuery).
+					WithArgs("token-hash", config.TestTime, config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe2024", "john@example.com", "password123", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:           "Expired session",
+			ExpectedUserID: "",
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchSessionUserQuery).
+					WithArgs("token-hash", config.TestTime, config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+			},
+		},
+		{
+			Name:           "Error",
+			ExpectedUserID: "",
+			ExpectedErr:    fmt.Errorf("error searching session"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchSessionUserQuery).
+					WillReturnError(fmt.Errorf("error searching session"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			user, searchErr := repo.SearchBySession(config.SearchSessionUserQuery, "token-hash", config.TestTime)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), searchErr.Error())
+			} else {
+				assert.NoError(t, searchErr)
+			}
+			assert.Equal(t, tt.ExpectedUserID, user.ID)
+		})
+	}
+}
+
 func TestSave(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -136,7 +216,7 @@ func TestSave(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := UserRepository{DB: db}
+	repo := UserRepository{DB: db, Clock: config.TestClock}
 
 	test := []struct {
 		Name          string
@@ -147,17 +227,21 @@ func TestSave(t *testing.T) {
 		{
 			Name: "Success",
 			User: models.User{
-				ID:       "1",
-				Name:     "John",
-				Surname:  "Doe",
-				Username: "johndoe",
-				Email:    "johndoe@example.com",
-				Password: "Password1234",
+				ID:                "1",
+				Name:              "John",
+				Surname:           "Doe",
+				Username:          "johndoe",
+				Email:             "johndoe@example.com",
+				Password:          "Password1234",
+				Version:           1,
+				CreatedAt:         config.TestTime,
+				UpdatedAt:         config.TestTime,
+				PasswordChangedAt: &config.TestTime,
 			},
 			ExpectedError: nil,
 			MockAct: func() {
 				mock.ExpectExec(config.TestSaveQuery).
-					WithArgs("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234").
+					WithArgs("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -199,7 +283,7 @@ func TestDelete(t *testing.T) {
 
 	defer db.Close()
 
-	repo := UserRepository{DB: db}
+	repo := UserRepository{DB: db, Clock: config.TestClock}
 
 	test := []struct {
 		Name          string
@@ -213,7 +297,7 @@ func TestDelete(t *testing.T) {
 			ExpectedError: nil,
 			MockAct: func() {
 				mock.ExpectExec(config.TestDeleteQuery).
-					WithArgs("johndoe").
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -230,7 +314,7 @@ func TestDelete(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockAct()
 
-			deleteErr := repo.Delete(config.TestDeleteQuery, tt.Username)
+			deleteErr := repo.Delete(config.DeleteUserQuery, tt.Username, 1)
 
 			if tt.ExpectedError != nil {
 				assert.Equal(t, tt.ExpectedError, deleteErr)
@@ -246,7 +330,7 @@ func TestUpdate(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := UserRepository{DB: db}
+	repo := UserRepository{DB: db, Clock: config.TestClock}
 
 	test := []struct {
 		Name        string
@@ -269,7 +353,7 @@ func TestUpdate(t *testing.T) {
 			ExpectedErr: nil,
 			MockAct: func() {
 				mock.ExpectExec(config.TestUpdateQuery).
-					WithArgs("Johncito", "Doecito", "johndoe2024@example.com", "johndoe").
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -287,22 +371,42 @@ func TestUpdate(t *testing.T) {
 			ExpectedErr: fmt.Errorf("error updating user"),
 			MockAct: func() {
 				mock.ExpectExec(config.TestUpdateQuery).
-					WithArgs("Johncito", "Doecito", "johndoe2024@example.com", "johndoe").
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnError(fmt.Errorf("error updating user"))
 			},
 		},
+		{
+			Name:     "Version mismatch",
+			Username: "johndoe",
+			User: models.User{
+				ID:       "1",
+				Name:     "Johncito",
+				Surname:  "Doecito",
+				Username: "johndoe",
+				Email:    "johndoe2024@example.com",
+				Password: "Password1234",
+			},
+			ExpectedErr: config.ErrVersionMismatch,
+			MockAct: func() {
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
 	}
 
 	for _, tt := range test {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockAct()
 
-			updateErr := repo.Update(config.UpdateUserQuery, tt.Username, tt.User)
+			updated, updateErr := repo.Update(config.UpdateUserQuery, tt.Username, 1, tt.User)
 
 			if tt.ExpectedErr != nil {
 				assert.Equal(t, tt.ExpectedErr.Error(), updateErr.Error())
 			} else {
 				assert.NoError(t, updateErr)
+				assert.Equal(t, 2, updated.Version)
+				assert.Equal(t, config.TestTime, updated.UpdatedAt)
 			}
 		})
 	}
@@ -315,7 +419,7 @@ func TestChangePwd(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := UserRepository{DB: db}
+	repo := UserRepository{DB: db, Clock: config.TestClock}
 
 	test := []struct {
 		Name        string
@@ -331,7 +435,7 @@ func TestChangePwd(t *testing.T) {
 			ExpectedErr: nil,
 			MockAct: func() {
 				mock.ExpectExec(config.TestChangePwdQuery).
-					WithArgs("NewPassword1234", "johndoe").
+					WithArgs("NewPassword1234", config.TestTime, config.TestTime, "johndoe", config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 			},
@@ -343,7 +447,7 @@ func TestChangePwd(t *testing.T) {
 			ExpectedErr: fmt.Errorf("error changing user password"),
 			MockAct: func() {
 				mock.ExpectExec(config.TestChangePwdQuery).
-					WithArgs("NewPassword1234", "johndoe").
+					WithArgs("NewPassword1234", config.TestTime, config.TestTime, "johndoe", config.DefaultTenant).
 					WillReturnError(fmt.Errorf("error changing user password"))
 
 			},
@@ -364,3 +468,455 @@ func TestChangePwd(t *testing.T) {
 		})
 	}
 }
+
+func TestList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	after := config.TestTime.Add(-time.Hour)
+
+	test := []struct {
+		Name          string
+		Filter        models.UserFilter
+		ExpectedCount int
+		ExpectedErr   error
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			Filter:        models.UserFilter{Limit: 50},
+			ExpectedCount: 2,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery+` ORDER BY created_at ASC, id ASC LIMIT \? OFFSET \?;`).
+					WithArgs(config.DefaultTenant, 50, 0).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime).
+						AddRow("2", "Jane", "Doe", "janedoe", "janedoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name: "Sort and filter",
+			Filter: models.UserFilter{
+				Sort:   "last_login_at",
+				Desc:   true,
+				Ranges: []models.TimeRange{{Field: "created_at", After: &after}},
+				Limit:  10,
+				Offset: 20,
+			},
+			ExpectedCount: 1,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery+` AND created_at >= \? ORDER BY last_login_at DESC, id ASC LIMIT \? OFFSET \?;`).
+					WithArgs(config.DefaultTenant, after, 10, 20).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, config.TestTime, config.TestTime))
+			},
+		},
+		{
+			Name:          "Invalid sort field",
+			Filter:        models.UserFilter{Sort: "password"},
+			ExpectedCount: 0,
+			ExpectedErr:   config.ErrInvalidSortField,
+			MockAct:       func() {},
+		},
+		{
+			Name:          "Invalid filter field",
+			Filter:        models.UserFilter{Ranges: []models.TimeRange{{Field: "password", After: &after}}},
+			ExpectedCount: 0,
+			ExpectedErr:   config.ErrInvalidFilter,
+			MockAct:       func() {},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			users, listErr := repo.List(config.ListUsersQuery, tt.Filter)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, listErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, listErr)
+			}
+			assert.Equal(t, tt.ExpectedCount, len(users))
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock, Tenant: "acme"}
+	rows := func() *sqlmock.Rows {
+		return mock.NewRows(config.TestUserColumns).
+			AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime).
+			AddRow("2", "Jane", "Doe", "janedoe", "janedoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime)
+	}
+
+	test := []struct {
+		Name          string
+		Filter        models.UserFilter
+		Stop          error
+		ExpectedCount int
+		ExpectedErr   error
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			Filter:        models.UserFilter{Sort: "username", Limit: -1},
+			ExpectedCount: 2,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery+` ORDER BY username ASC, id ASC LIMIT \? OFFSET \?;`).
+					WithArgs("acme", -1, 0).
+					WillReturnRows(rows())
+			},
+		},
+		{
+			Name:          "Callback error stops the stream",
+			Filter:        models.UserFilter{Limit: -1},
+			Stop:          fmt.Errorf("broken pipe"),
+			ExpectedCount: 1,
+			ExpectedErr:   fmt.Errorf("broken pipe"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WillReturnRows(rows())
+			},
+		},
+		{
+			Name:        "Invalid sort field",
+			Filter:      models.UserFilter{Sort: "password"},
+			ExpectedErr: config.ErrInvalidSortField,
+			MockAct:     func() {},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			count := 0
+			streamErr := repo.Stream(config.ListUsersQuery, tt.Filter, func(user models.User) error {
+				count++
+				return tt.Stop
+			})
+
+			if tt.ExpectedErr != nil {
+				assert.EqualError(t, streamErr, tt.ExpectedErr.Error())
+			} else {
+				assert.NoError(t, streamErr)
+			}
+			assert.Equal(t, tt.ExpectedCount, count)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecordLogin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	test := []struct {
+		Name        string
+		Username    string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Username:    "johndoe",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestLoginQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:        "Error",
+			Username:    "johndoe",
+			ExpectedErr: fmt.Errorf("error recording login"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestLoginQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnError(fmt.Errorf("error recording login"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			loginErr := repo.RecordLogin(config.RecordLoginQuery, tt.Username)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), loginErr.Error())
+			} else {
+				assert.NoError(t, loginErr)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	test := []struct {
+		Name        string
+		Username    string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Username:    "johndoe",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:        "User not deleted",
+			Username:    "johndoe",
+			ExpectedErr: config.ErrUserNotFound,
+			MockAct: func() {
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			Name:        "Username taken by an active user",
+			Username:    "johndoe",
+			ExpectedErr: config.ErrUserAlreadyExists,
+			MockAct: func() {
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnError(fmt.Errorf("UNIQUE constraint failed: users.tenant_id, users.username"))
+			},
+		},
+		{
+			Name:        "Error",
+			Username:    "johndoe",
+			ExpectedErr: fmt.Errorf("error restoring user"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnError(fmt.Errorf("error restoring user"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			restoreErr := repo.Restore(config.RestoreUserQuery, tt.Username)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), restoreErr.Error())
+			} else {
+				assert.NoError(t, restoreErr)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	test := []struct {
+		Name           string
+		ExpectedPurged int64
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Success",
+			ExpectedPurged: 3,
+			ExpectedErr:    nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestPurgeQuery).
+					WithArgs(config.TestTime, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+		},
+		{
+			Name:           "Error",
+			ExpectedPurged: 0,
+			ExpectedErr:    fmt.Errorf("error purging users"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestPurgeQuery).
+					WithArgs(config.TestTime, config.DefaultTenant).
+					WillReturnError(fmt.Errorf("error purging users"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			purged, purgeErr := repo.Purge(config.PurgeUsersQuery, config.TestTime)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), purgeErr.Error())
+			} else {
+				assert.NoError(t, purgeErr)
+			}
+			assert.Equal(t, tt.ExpectedPurged, purged)
+		})
+	}
+}
+
+func TestPasswordHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	test := []struct {
+		Name            string
+		ExpectedHistory []string
+		ExpectedErr     error
+		MockAct         func()
+	}{
+		{
+			Name:            "Success",
+			ExpectedHistory: []string{"hash-2", "hash-1"},
+			ExpectedErr:     nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WithArgs("1", 5).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}).AddRow("hash-2").AddRow("hash-1"))
+			},
+		},
+		{
+			Name:            "Error",
+			ExpectedHistory: nil,
+			ExpectedErr:     fmt.Errorf("error reading password history"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WithArgs("1", 5).
+					WillReturnError(fmt.Errorf("error reading password history"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			history, historyErr := repo.PasswordHistory(config.PasswordHistoryQuery, "1", 5)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), historyErr.Error())
+			} else {
+				assert.NoError(t, historyErr)
+			}
+			assert.Equal(t, tt.ExpectedHistory, history)
+		})
+	}
+}
+
+func TestSavePasswordHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	mock.ExpectExec(config.TestSaveHistoryQuery).
+		WithArgs("1", "hash-1", config.TestTime).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(config.TestPurgeHistoryQuery).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.SavePasswordHistory(config.SavePasswordHistoryQuery, "1", "hash-1"))
+	assert.NoError(t, repo.PurgePasswordHistory(config.PurgePasswordHistoryQuery))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRehashPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := UserRepository{DB: db, Clock: config.TestClock}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectExec(config.TestRehashQuery).
+					WithArgs("new-hash", "1", "old-hash", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: fmt.Errorf("error rehashing password"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestRehashQuery).
+					WithArgs("new-hash", "1", "old-hash", config.DefaultTenant).
+					WillReturnError(fmt.Errorf("error rehashing password"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			rehashErr := repo.RehashPassword(config.RehashPasswordQuery, "1", "old-hash", "new-hash")
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr.Error(), rehashErr.Error())
+			} else {
+				assert.NoError(t, rehashErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestBackups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	conn, err := data.Open(filepath.Join(dir, "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	userService := services.UserServices{
+		DB:              conn,
+		Repo:            repository.UserRepository{DB: conn},
+		BackupDir:       filepath.Join(dir, "backups"),
+		BackupRetention: 3,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPost, "/api/v1/backups", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request(http.MethodPost, "/api/v1/backups", "secret")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.BackupResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, config.BackupMessage, created.Message)
+	assert.Equal(t, nil, data.CheckIntegrity(filepath.Join(userService.BackupDir, created.Backup.Name)))
+
+	w = request(http.MethodGet, "/api/v2/backups", "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var listed models.ListBackupsV2Response
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	assert.Equal(t, 1, len(listed.Data))
+	assert.Equal(t, created.Backup.Name, listed.Data[0].Name)
+	assert.Equal(t, created.Backup.Size, listed.Data[0].Size)
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	batch := func(body string) (int, models.BatchReportV2) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v2/users/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.BatchV2Response
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+	statuses := func(report models.BatchReportV2) []int {
+		codes := []int{}
+		for _, result := range report.Results {
+			codes = append(codes, result.Status)
+		}
+		return codes
+	}
+	exists := func(username string) bool {
+		return userService.Exists(context.Background(), username)
+	}
+
+	jane, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+		Name: "Jane", Surname: "Doe", Username: "janedoe", Email: "janedoe@example.com", Password: "Sup3r-Secret-pass",
+	})
+	if createErr != nil {
+		t.Fatal(createErr)
+	}
+
+	newUser := func(username string) string {
+		return `{"op":"create","user":{"name":"Test","surname":"Doe","username":"` + username + `","email":"` + username + `@example.com","password":"Sup3r-Secret-pass"}}`
+	}
+
+	code, report := batch(`{"operations":[` + newUser("johndoe") + `,{"op":"update","id":"` + jane.ID + `","version":9,"patch":{"surname":"Smith"}}]}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, report.Committed)
+	assert.Equal(t, []int{http.StatusFailedDependency, http.StatusPreconditionFailed}, statuses(report))
+	assert.Equal(t, false, exists("johndoe"))
+
+	code, report = batch(`{"mode":"best_effort","operations":[` + newUser("johndoe") + `,` + newUser("janedoe") + `,` + newUser("jimdoe") + `,{"op":"delete","id":"missing","version":1}]}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, report.Committed)
+	assert.Equal(t, 2, report.Succeeded)
+	assert.Equal(t, []int{http.StatusCreated, http.StatusConflict, http.StatusCreated, http.StatusNotFound}, statuses(report))
+	assert.Equal(t, true, exists("johndoe"))
+	assert.Equal(t, true, exists("jimdoe"))
+
+	code, report = batch(`{"operations":[{"op":"update","id":"` + jane.ID + `","version":1,"patch":{"surname":"Smith"}},{"op":"delete","id":"` + report.Results[2].User.ID + `","version":1}]}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, report.Committed)
+	assert.Equal(t, []int{http.StatusOK, http.StatusNoContent}, statuses(report))
+	assert.Equal(t, "Smith", report.Results[0].User.Surname)
+	assert.Equal(t, false, exists("jimdoe"))
+
+	code, _ = batch(`{"mode":"eventual","operations":[` + newUser("amydoe") + `]}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = batch(`{"operations":[]}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = batch(`{"operations":[` + strings.Repeat(newUser("amydoe")+",", 100) + newUser("amydoe") + `]}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,549 @@
+package router
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/openapi"
+	"net/http"
+	"strings"
+)
+
+type versionModels struct {
+	user      any
+	created   any
+	list      any
+	updated   any
+	restore   any
+	session   any
+	challenge any
+	enroll    any
+	codes     any
+	apiKey    any
+	apiKeys   any
+	client    any
+	clients   any
+	linked    any
+	group     any
+	groups    any
+	member    any
+	members   any
+	joined    any
+	role      any
+	roles     any
+	perms     any
+	decision  any
+	imported  any
+	batch     any
+	backup    any
+	backups   any
+}
+
+func operations() []openapi.Operation {
+	ops := []openapi.Operation{
+		{Method: http.MethodGet, Path: config.OpenAPIPath, Summary: "OpenAPI document", Tag: "docs",
+			Responses: map[int]any{http.StatusOK: map[string]any{}}},
+		{Method: http.MethodGet, Path: config.DocsPath, Summary: "Swagger UI", Tag: "docs",
+			Responses: map[int]any{http.StatusOK: nil}},
+	}
+
+	ops = append(ops, versionOperations("/api/v1", versionModels{
+		user:      models.SearchResponse{},
+		created:   models.CreateUserResponse{},
+		list:      models.ListUsersResponse{},
+		updated:   models.UpdateUserResponse{},
+		restore:   models.RestoreUserResponse{},
+		session:   models.LoginResponse{},
+		challenge: models.MFAChallengeResponse{},
+		enroll:    models.TOTPEnrollmentResponse{},
+		codes:     models.RecoveryCodesResponse{},
+		apiKey:    models.APIKeyResponse{},
+		apiKeys:   models.ListAPIKeysResponse{},
+		client:    models.OAuthClientResponse{},
+		clients:   models.ListOAuthClientsResponse{},
+		linked:    models.ListIdentitiesResponse{},
+		group:     models.GroupResponse{},
+		groups:    models.ListGroupsResponse{},
+		member:    models.GroupMemberResponse{},
+		members:   models.ListGroupMembersResponse{},
+		joined:    models.ListMembershipsResponse{},
+		role:      models.RoleResponse{},
+		roles:     models.ListRolesResponse{},
+		perms:     models.ListPermissionsResponse{},
+		decision:  models.PermissionDecisionResponse{},
+		imported:  models.ImportResponse{},
+		batch:     models.BatchResponse{},
+		backup:    models.BackupResponse{},
+		backups:   models.ListBackupsResponse{},
+	})...)
+	ops = append(ops, versionOperations("/api/v2", versionModels{
+		user:      models.UserV2Response{},
+		created:   models.UserV2Response{},
+		list:      models.ListUsersV2Response{},
+		updated:   models.UserV2Response{},
+		restore:   models.UserV2Response{},
+		session:   models.SessionV2Response{},
+		challenge: models.MFAChallengeV2Response{},
+		enroll:    models.TOTPEnrollmentV2Response{},
+		codes:     models.RecoveryCodesV2Response{},
+		apiKey:    models.APIKeyV2Response{},
+		apiKeys:   models.ListAPIKeysV2Response{},
+		client:    models.OAuthClientV2Response{},
+		clients:   models.ListOAuthClientsV2Response{},
+		linked:    models.ListIdentitiesV2Response{},
+		group:     models.GroupV2Response{},
+		groups:    models.ListGroupsV2Response{},
+		member:    models.GroupMemberV2Response{},
+		members:   models.ListGroupMembersV2Response{},
+		joined:    models.ListMembershipsV2Response{},
+		role:      models.RoleV2Response{},
+		roles:     models.ListRolesV2Response{},
+		perms:     models.ListPermissionsV2Response{},
+		decision:  models.PermissionDecisionV2Response{},
+		imported:  models.ImportV2Response{},
+		batch:     models.BatchV2Response{},
+		backup:    models.BackupV2Response{},
+		backups:   models.ListBackupsV2Response{},
+	})...)
+	ops = append(ops, providerOperations()...)
+
+	return append(ops, legacyOperations("/api/go-manage")...)
+}
+
+func versionOperations(prefix string, views versionModels) []openapi.Operation {
+	users := prefix + "/users"
+	groups := prefix + "/groups"
+	roles := prefix + "/roles"
+	ops := []openapi.Operation{
+		{Method: http.MethodGet, Path: prefix + "/ping", Summary: "Health check", Tag: "health",
+			Responses: map[int]any{http.StatusOK: ""}},
+		{Method: http.MethodPost, Path: prefix + "/login", Summary: "Log in with a username and password", Tag: "sessions",
+			Body:      models.LoginRequest{},
+			Responses: map[int]any{http.StatusOK: views.session, http.StatusAccepted: views.challenge},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusLocked, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/login/mfa", Summary: "Complete a login with a TOTP or recovery code", Tag: "sessions",
+			Body:      models.MFALoginRequest{},
+			Responses: map[int]any{http.StatusOK: views.session},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusLocked, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/login/:provider", Summary: "Start a login with an external identity provider", Tag: "sessions",
+			Responses: map[int]any{http.StatusFound: nil},
+			Errors:    []int{http.StatusNotFound, http.StatusBadGateway, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/login/:provider/callback", Summary: "Finish an external login, or an identity link when started from the link route", Tag: "sessions",
+			Params:    callbackParams(),
+			Responses: map[int]any{http.StatusOK: views.session, http.StatusAccepted: views.challenge},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusLocked, http.StatusBadGateway, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users, Summary: "List users", Tag: "users",
+			Permission: config.PermUsersRead,
+			Params:     listParams(),
+			Responses:  map[int]any{http.StatusOK: views.list},
+			Errors:     []int{http.StatusBadRequest, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: users, Summary: "Create a user", Tag: "users",
+			Permission: config.PermUsersWrite,
+			Body:       models.CreateUserRequest{},
+			Responses:  map[int]any{http.StatusCreated: views.created},
+			Errors:     []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: users + "/import", Summary: "Bulk import users from a CSV or NDJSON stream, reporting per-row errors", Tag: "users",
+			Admin: true,
+			Params: []openapi.Parameter{
+				{Name: "dry_run", In: "query", Type: "boolean"},
+				{Name: "on_conflict", In: "query", Type: "string"},
+			},
+			Body:      models.CreateUserRequest{},
+			BodyTypes: []string{config.CSVMediaType, config.NDJSONMediaType},
+			Responses: map[int]any{http.StatusOK: views.imported},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusUnsupportedMediaType, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/export", Summary: "Stream every user matching the list filters as CSV, NDJSON or XLSX, without password hashes", Tag: "users",
+			Admin:     true,
+			Params:    append(listParams(), openapi.Parameter{Name: "format", In: "query", Type: "string"}),
+			Responses: map[int]any{http.StatusOK: ""},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: users + "/batch", Summary: "Run several create, update and delete operations in one transaction, atomically or best-effort", Tag: "users",
+			Admin:     true,
+			Body:      models.BatchRequest{},
+			Responses: map[int]any{http.StatusOK: views.batch},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/:id", Summary: "Get a user by id", Tag: "users",
+			Permission: config.PermUsersRead,
+			Responses:  map[int]any{http.StatusOK: views.user},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/by-username/:username", Summary: "Get a user by username", Tag: "users",
+			Permission: config.PermUsersRead,
+			Responses:  map[int]any{http.StatusOK: views.user},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPatch, Path: users + "/:id", Summary: "Apply a JSON merge patch to a user", Tag: "users",
+			Permission: config.PermUsersWrite,
+			Params:     []openapi.Parameter{ifMatchParam()},
+			Body:       models.UpdateUserRequest{},
+			BodyTypes:  []string{config.MergePatchMediaType, config.JSONMediaType},
+			Responses:  map[int]any{http.StatusOK: views.updated},
+			Errors:     writeErrors()},
+		{Method: http.MethodPut, Path: users + "/:id", Summary: "Replace a user's profile; every field is required", Tag: "users",
+			Permission: config.PermUsersWrite,
+			Params:     []openapi.Parameter{ifMatchParam()},
+			Body:       models.UpdateUserRequest{},
+			Responses:  map[int]any{http.StatusOK: views.updated},
+			Errors:     writeErrors()},
+		{Method: http.MethodDelete, Path: users + "/:id", Summary: "Soft delete a user", Tag: "users",
+			Permission: config.PermUsersDelete,
+			Params:     []openapi.Parameter{ifMatchParam()},
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired, http.StatusInternalServerError}},
+		{Method: http.MethodPut, Path: users + "/:id/password", Summary: "Set a user's password", Tag: "users",
+			Permission: config.PermUsersWrite,
+			Body:       models.ChangePwdRequest{},
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: users + "/:id/mfa/totp", Summary: "Start TOTP enrollment", Tag: "mfa",
+			Session:   true,
+			Responses: map[int]any{http.StatusCreated: views.enroll},
+			Errors:    mfaErrors()},
+		{Method: http.MethodPost, Path: users + "/:id/mfa/totp/confirm", Summary: "Confirm TOTP enrollment and issue recovery codes", Tag: "mfa",
+			Session:   true,
+			Body:      models.MFACodeRequest{},
+			Responses: map[int]any{http.StatusOK: views.codes},
+			Errors:    append(mfaErrors(), http.StatusBadRequest)},
+		{Method: http.MethodDelete, Path: users + "/:id/mfa/totp", Summary: "Disable two-factor authentication", Tag: "mfa",
+			Session:   true,
+			Body:      models.MFACodeRequest{},
+			Responses: map[int]any{http.StatusNoContent: nil},
+			Errors:    append(mfaErrors(), http.StatusBadRequest)},
+		{Method: http.MethodPost, Path: users + "/:id/mfa/recovery-codes", Summary: "Replace the recovery codes", Tag: "mfa",
+			Session:   true,
+			Body:      models.MFACodeRequest{},
+			Responses: map[int]any{http.StatusOK: views.codes},
+			Errors:    append(mfaErrors(), http.StatusBadRequest)},
+		{Method: http.MethodGet, Path: users + "/:id/identities", Summary: "List linked external identities", Tag: "identities",
+			Session:   true,
+			Responses: map[int]any{http.StatusOK: views.linked},
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/:id/identities/:provider/link", Summary: "Link an external identity; finishes at the provider callback", Tag: "identities",
+			Session:   true,
+			Responses: map[int]any{http.StatusFound: nil},
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusBadGateway, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: users + "/:id/identities/:identity", Summary: "Unlink an external identity", Tag: "identities",
+			Session:   true,
+			Responses: map[int]any{http.StatusNoContent: nil},
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: users + "/by-username/:username/restore", Summary: "Restore a soft deleted user", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusOK: views.restore},
+			Errors:    []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: users + "/by-username/:username/unlock", Summary: "Clear a user's failed login attempts and lockout", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusNoContent: nil},
+			Errors:    []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/:id/groups", Summary: "List the groups of a user; nested=true adds the groups inherited through subgroups", Tag: "groups",
+			Permission: config.PermUsersRead,
+			Params:     []openapi.Parameter{nestedParam()},
+			Responses:  map[int]any{http.StatusOK: views.joined},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: groups, Summary: "List groups", Tag: "groups",
+			Permission: config.PermGroupsRead,
+			Responses:  map[int]any{http.StatusOK: views.groups},
+			Errors:     []int{http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: groups, Summary: "Create a group, optionally nested under a parent", Tag: "groups",
+			Permission: config.PermGroupsWrite,
+			Body:       models.CreateGroupRequest{},
+			Responses:  map[int]any{http.StatusCreated: views.group},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: groups + "/:id", Summary: "Get a group", Tag: "groups",
+			Permission: config.PermGroupsRead,
+			Responses:  map[int]any{http.StatusOK: views.group},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPatch, Path: groups + "/:id", Summary: "Update a group; an empty parent_id moves it to the top level", Tag: "groups",
+			Permission: config.PermGroupsWrite,
+			Body:       models.UpdateGroupRequest{},
+			Responses:  map[int]any{http.StatusOK: views.group},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: groups + "/:id", Summary: "Delete a group without subgroups and its memberships", Tag: "groups",
+			Permission: config.PermGroupsDelete,
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: groups + "/:id/members", Summary: "List group members; nested=true includes the members of subgroups", Tag: "groups",
+			Permission: config.PermGroupsRead,
+			Params:     []openapi.Parameter{nestedParam()},
+			Responses:  map[int]any{http.StatusOK: views.members},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPut, Path: groups + "/:id/members/:user", Summary: "Add a user to a group or change their role", Tag: "groups",
+			Permission: config.PermGroupsWrite,
+			Body:       models.GroupMemberRequest{},
+			Responses:  map[int]any{http.StatusOK: views.member},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: groups + "/:id/members/:user", Summary: "Remove a user from a group", Tag: "groups",
+			Permission: config.PermGroupsWrite,
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/audit", Summary: "List audit events", Tag: "admin",
+			Permission: config.PermAuditRead,
+			Params:     auditParams(),
+			Responses:  map[int]any{http.StatusOK: models.ListAuditResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/:id/roles", Summary: "List the roles assigned to a user", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusOK: views.roles},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPut, Path: users + "/:id/roles/:role", Summary: "Assign a role to a user", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: users + "/:id/roles/:role", Summary: "Remove a role from a user", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: users + "/:id/permissions/check", Summary: "Evaluate whether a user may perform an action and which role grants it", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Params: []openapi.Parameter{
+				{Name: "action", In: "query", Type: "string", Required: true},
+				{Name: "resource", In: "query", Type: "string"},
+			},
+			Responses: map[int]any{http.StatusOK: views.decision},
+			Errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/permissions", Summary: "List the permissions that can be granted to a role", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusOK: views.perms}},
+		{Method: http.MethodGet, Path: roles, Summary: "List roles", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusOK: views.roles},
+			Errors:     []int{http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: roles, Summary: "Create a role from a set of permissions", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Body:       models.CreateRoleRequest{},
+			Responses:  map[int]any{http.StatusCreated: views.role},
+			Errors:     []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: roles + "/:id", Summary: "Get a role", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusOK: views.role},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPatch, Path: roles + "/:id", Summary: "Update a role; permissions replaces the whole set", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Body:       models.UpdateRoleRequest{},
+			Responses:  map[int]any{http.StatusOK: views.role},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: roles + "/:id", Summary: "Delete a role and its assignments", Tag: "permissions",
+			Permission: config.PermRolesManage,
+			Responses:  map[int]any{http.StatusNoContent: nil},
+			Errors:     []int{http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/api-keys", Summary: "Create an API key; the key is only returned once", Tag: "admin",
+			Admin:     true,
+			Body:      models.CreateAPIKeyRequest{},
+			Responses: map[int]any{http.StatusCreated: views.apiKey},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/api-keys", Summary: "List API keys", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusOK: views.apiKeys},
+			Errors:    []int{http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: prefix + "/api-keys/:id", Summary: "Revoke an API key", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusNoContent: nil},
+			Errors:    []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/oauth/clients", Summary: "Register an OpenID Connect client; the secret is only returned once", Tag: "admin",
+			Admin:     true,
+			Body:      models.CreateOAuthClientRequest{},
+			Responses: map[int]any{http.StatusCreated: views.client},
+			Errors:    []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/oauth/clients", Summary: "List OpenID Connect clients", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusOK: views.clients},
+			Errors:    []int{http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: prefix + "/oauth/clients/:id", Summary: "Delete an OpenID Connect client", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusNoContent: nil},
+			Errors:    []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/oauth/keys/rotate", Summary: "Rotate the ID token signing key", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusNoContent: nil},
+			Errors:    []int{http.StatusUnauthorized, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/backups", Summary: "Take an online database snapshot, verify its integrity and prune old snapshots", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusCreated: views.backup},
+			Errors:    []int{http.StatusUnauthorized, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/backups", Summary: "List database snapshots, newest first", Tag: "admin",
+			Admin:     true,
+			Responses: map[int]any{http.StatusOK: views.backups},
+			Errors:    []int{http.StatusUnauthorized, http.StatusInternalServerError}},
+	}
+
+	for i := range ops {
+		ops[i].Errors = append(ops[i].Errors, http.StatusNotAcceptable)
+		if ops[i].Admin {
+			ops[i].Errors = append(ops[i].Errors, http.StatusForbidden)
+		}
+	}
+	return rateLimited(ops)
+}
+
+func providerOperations() []openapi.Operation {
+	authorizeParams := []openapi.Parameter{
+		{Name: "response_type", In: "query", Type: "string", Required: true},
+		{Name: "client_id", In: "query", Type: "string", Required: true},
+		{Name: "redirect_uri", In: "query", Type: "string", Required: true},
+		{Name: "scope", In: "query", Type: "string", Required: true},
+		{Name: "state", In: "query", Type: "string"},
+		{Name: "nonce", In: "query", Type: "string"},
+		{Name: "code_challenge", In: "query", Type: "string", Required: true},
+		{Name: "code_challenge_method", In: "query", Type: "string", Required: true},
+	}
+	oauthErrors := func(statuses ...int) map[int]any {
+		responses := map[int]any{}
+		for _, status := range statuses {
+			responses[status] = models.OAuthError{}
+		}
+		return responses
+	}
+
+	token := oauthErrors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+	token[http.StatusOK] = models.TokenResponse{}
+	userInfo := oauthErrors(http.StatusUnauthorized, http.StatusInternalServerError)
+	userInfo[http.StatusOK] = models.UserInfo{}
+	authorize := oauthErrors(http.StatusBadRequest, http.StatusInternalServerError)
+	authorize[http.StatusFound] = nil
+	jwks := oauthErrors(http.StatusInternalServerError)
+	jwks[http.StatusOK] = models.JWKS{}
+
+	return rateLimited([]openapi.Operation{
+		{Method: http.MethodGet, Path: config.OIDCDiscoveryPath, Summary: "OpenID Connect discovery document", Tag: "oidc",
+			Responses: map[int]any{http.StatusOK: models.OIDCDiscovery{}}},
+		{Method: http.MethodGet, Path: config.OIDCJWKSPath, Summary: "Public keys used to sign ID tokens", Tag: "oidc",
+			Responses: jwks},
+		{Method: http.MethodGet, Path: config.OIDCAuthorizePath, Summary: "Authorization code request with PKCE; redirects back to the client", Tag: "oidc",
+			Session:   true,
+			Params:    authorizeParams,
+			Responses: authorize,
+			Errors:    []int{http.StatusUnauthorized}},
+		{Method: http.MethodPost, Path: config.OIDCTokenPath, Summary: "Exchange an authorization code for ID and access tokens", Tag: "oidc",
+			Body:      models.TokenRequest{},
+			BodyTypes: []string{config.FormMediaType},
+			Responses: token},
+		{Method: http.MethodGet, Path: config.OIDCUserInfoPath, Summary: "Claims about the user owning the access token", Tag: "oidc",
+			Responses: userInfo},
+		{Method: http.MethodPost, Path: config.OIDCUserInfoPath, Summary: "Claims about the user owning the access token", Tag: "oidc",
+			Responses: userInfo},
+	})
+}
+
+func legacyOperations(prefix string) []openapi.Operation {
+	username := openapi.Parameter{Name: "username", In: "query", Type: "string", Required: true}
+
+	ops := []openapi.Operation{
+		{Method: http.MethodGet, Path: prefix + "/ping", Summary: "Health check", Tag: "legacy",
+			Responses: map[int]any{http.StatusOK: ""}},
+		{Method: http.MethodGet, Path: prefix + "/search", Summary: "Search a user by username", Tag: "legacy",
+			Permission: config.PermUsersRead,
+			Params:     []openapi.Parameter{username},
+			Responses:  map[int]any{http.StatusOK: models.SearchResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/list", Summary: "List users", Tag: "legacy",
+			Permission: config.PermUsersRead,
+			Params:     listParams(),
+			Responses:  map[int]any{http.StatusOK: models.ListUsersResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/create", Summary: "Create a user", Tag: "legacy",
+			Permission: config.PermUsersWrite,
+			Body:       models.CreateUserRequest{},
+			Responses:  map[int]any{http.StatusOK: models.CreateUserResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodDelete, Path: prefix + "/delete", Summary: "Soft delete a user", Tag: "legacy",
+			Permission: config.PermUsersDelete,
+			Params:     []openapi.Parameter{username, ifMatchParam()},
+			Responses:  map[int]any{http.StatusOK: models.DeleteUserResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired, http.StatusInternalServerError}},
+		{Method: http.MethodPatch, Path: prefix + "/update", Summary: "Apply a JSON merge patch to a user", Tag: "legacy",
+			Permission: config.PermUsersWrite,
+			Params:     []openapi.Parameter{username, ifMatchParam()},
+			Body:       models.UpdateUserRequest{},
+			BodyTypes:  []string{config.MergePatchMediaType, config.JSONMediaType},
+			Responses:  map[int]any{http.StatusOK: models.UpdateUserResponse{}},
+			Errors:     writeErrors()},
+		{Method: http.MethodPatch, Path: prefix + "/change-password", Summary: "Change a user's password", Tag: "legacy",
+			Permission: config.PermUsersWrite,
+			Params:     []openapi.Parameter{username, {Name: "new_password", In: "query", Type: "string", Required: true}},
+			Responses:  map[int]any{http.StatusOK: models.ChangePwdResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusInternalServerError}},
+		{Method: http.MethodPost, Path: prefix + "/users/:username/restore", Summary: "Restore a soft deleted user", Tag: "legacy",
+			Admin:     true,
+			Responses: map[int]any{http.StatusOK: models.RestoreUserResponse{}},
+			Errors:    []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		{Method: http.MethodGet, Path: prefix + "/audit", Summary: "List audit events", Tag: "legacy",
+			Permission: config.PermAuditRead,
+			Params:     auditParams(),
+			Responses:  map[int]any{http.StatusOK: models.ListAuditResponse{}},
+			Errors:     []int{http.StatusBadRequest, http.StatusInternalServerError}},
+	}
+
+	for i := range ops {
+		ops[i].Deprecated = true
+		if ops[i].Admin {
+			ops[i].Errors = append(ops[i].Errors, http.StatusForbidden)
+		}
+	}
+	return rateLimited(ops)
+}
+
+func rateLimited(ops []openapi.Operation) []openapi.Operation {
+	for i := range ops {
+		if ops[i].Permission != "" {
+			ops[i].Errors = append(ops[i].Errors, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		if !strings.HasSuffix(ops[i].Path, "/ping") {
+			ops[i].Errors = append(ops[i].Errors, http.StatusTooManyRequests)
+		}
+	}
+	return ops
+}
+
+func ifMatchParam() openapi.Parameter {
+	return openapi.Parameter{Name: "If-Match", In: "header", Type: "string", Required: true}
+}
+
+func writeErrors() []int {
+	return []int{
+		http.StatusBadRequest,
+		http.StatusNotFound,
+		http.StatusConflict,
+		http.StatusPreconditionFailed,
+		http.StatusUnsupportedMediaType,
+		http.StatusPreconditionRequired,
+		http.StatusInternalServerError,
+	}
+}
+
+func mfaErrors() []int {
+	return []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusInternalServerError}
+}
+
+func nestedParam() openapi.Parameter {
+	return openapi.Parameter{Name: "nested", In: "query", Type: "boolean"}
+}
+
+func callbackParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		{Name: "code", In: "query", Type: "string"},
+		{Name: "state", In: "query", Type: "string", Required: true},
+		{Name: "error", In: "query", Type: "string"},
+		{Name: "error_description", In: "query", Type: "string"},
+	}
+}
+
+func listParams() []openapi.Parameter {
+	params := []openapi.Parameter{
+		{Name: "sort", In: "query", Type: "string"},
+		{Name: "order", In: "query", Type: "string"},
+		{Name: "limit", In: "query", Type: "integer"},
+		{Name: "offset", In: "query", Type: "integer"},
+	}
+	for _, field := range config.UserTimestampFields {
+		params = append(params,
+			openapi.Parameter{Name: field + "_after", In: "query", Type: "string"},
+			openapi.Parameter{Name: field + "_before", In: "query", Type: "string"})
+	}
+	return params
+}
+
+func auditParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		{Name: "actor", In: "query", Type: "string"},
+		{Name: "action", In: "query", Type: "string"},
+		{Name: "target", In: "query", Type: "string"},
+		{Name: "limit", In: "query", Type: "integer"},
+		{Name: "offset", In: "query", Type: "integer"},
+	}
+}
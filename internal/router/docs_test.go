@@ -0,0 +1,75 @@
+package router
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	handler := handlers.NewUserHandler(services.UserServices{})
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", nil), middlewares.SessionAuth(nil), middlewares.APIKeyAuth(nil), middlewares.Permission("secret", nil, nil, nil), nil)
+	assert.Equal(t, nil, routesErr)
+
+	req, _ := http.NewRequest(http.MethodGet, config.OpenAPIPath, nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var document struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config.OpenAPIVersion, document.OpenAPI)
+
+	for _, route := range r.Routes() {
+		segments := strings.Split(route.Path, "/")
+		for i, segment := range segments {
+			if name, found := strings.CutPrefix(segment, ":"); found {
+				segments[i] = "{" + name + "}"
+			}
+		}
+		path := strings.Join(segments, "/")
+
+		_, documented := document.Paths[path][strings.ToLower(route.Method)]
+		assert.Equal(t, true, documented)
+	}
+}
+
+func TestDocsUI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	handler := handlers.NewUserHandler(services.UserServices{})
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{})
+	if err := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", nil), middlewares.SessionAuth(nil), middlewares.APIKeyAuth(nil), middlewares.Permission("secret", nil, nil, nil), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, config.DocsPath, nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), config.OpenAPIPath))
+}
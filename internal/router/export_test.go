@@ -0,0 +1,101 @@
+package router
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestExportUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant([]string{"acme"}, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	for _, username := range []string{"johndoe", "janedoe", "jimdoe"} {
+		_, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+			Name: "Test", Surname: "Doe", Username: username, Email: username + "@example.com", Password: "Sup3r-Secret-pass",
+		})
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
+	}
+	if deleteErr := userService.DeleteUser(context.Background(), "jimdoe", 1); deleteErr != nil {
+		t.Fatal(deleteErr)
+	}
+
+	export := func(path, tenant, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", token)
+		if tenant != "" {
+			req.Header.Set(config.TenantHeader, tenant)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, export("/api/v1/users/export", "", "").Code)
+
+	w := export("/api/v1/users/export?sort=username", "", "Bearer secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+	records, csvErr := csv.NewReader(w.Body).ReadAll()
+	assert.Equal(t, nil, csvErr)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "janedoe", records[1][3])
+	assert.Equal(t, "johndoe", records[2][3])
+
+	w = export("/api/v2/users/export?format=ndjson&sort=username&order=desc&limit=1", "", "Bearer secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "\n"))
+	assert.Equal(t, true, strings.Contains(w.Body.String(), `"username":"johndoe"`))
+	assert.Equal(t, false, strings.Contains(w.Body.String(), "$2a$"))
+
+	w = export("/api/v2/users/export?format=xlsx", "", "Bearer secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, config.XLSXMediaType, w.Header().Get("Content-Type"))
+	_, zipErr := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.Equal(t, nil, zipErr)
+
+	w = export("/api/v1/users/export", "acme", "Bearer secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "\n"))
+}
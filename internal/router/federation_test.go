@@ -0,0 +1,206 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/federation"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+type idpGrant struct {
+	nonce     string
+	challenge string
+	subject   string
+}
+
+func TestFederatedLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, _, keyErr := oidc.GenerateKey()
+	if keyErr != nil {
+		t.Fatal(keyErr)
+	}
+	kid := oidc.KeyID(&key.PublicKey)
+
+	subject := "248289761001"
+	grants := map[string]idpGrant{}
+	var lastCallback string
+
+	idp := http.NewServeMux()
+	idpServer := httptest.NewServer(idp)
+	defer idpServer.Close()
+
+	idp.HandleFunc(config.OIDCDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.OIDCDiscovery{
+			Issuer:                idpServer.URL,
+			AuthorizationEndpoint: idpServer.URL + "/authorize",
+			TokenEndpoint:         idpServer.URL + "/token",
+			JWKSURI:               idpServer.URL + "/jwks",
+		})
+	})
+	idp.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.JWKS{Keys: []models.JWK{oidc.PublicJWK(kid, &key.PublicKey)}})
+	})
+	idp.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		code := "code-" + query.Get("state")[:8]
+		grants[code] = idpGrant{nonce: query.Get("nonce"), challenge: query.Get("code_challenge"), subject: subject}
+
+		lastCallback = query.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+		http.Redirect(w, r, lastCallback, http.StatusFound)
+	})
+	idp.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		grant, found := grants[r.PostFormValue("code")]
+		delete(grants, r.PostFormValue("code"))
+		if !found || !oidc.VerifyPKCE(r.PostFormValue("code_verifier"), grant.challenge) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.OAuthError{Code: config.OAuthInvalidGrant})
+			return
+		}
+		token, _ := oidc.Sign(key, kid, config.OIDCIDTokenType, map[string]any{
+			"iss":                idpServer.URL,
+			"aud":                "go-manage",
+			"sub":                grant.subject,
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"nonce":              grant.nonce,
+			"given_name":         "Jane",
+			"family_name":        "Doe",
+			"email":              "janedoe@corp.example.com",
+			"preferred_username": "janedoe",
+		})
+		json.NewEncoder(w).Encode(map[string]string{"id_token": token})
+	})
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	providers := map[string]*federation.Provider{
+		"corp": {
+			Name:        "corp",
+			Issuer:      idpServer.URL,
+			ClientID:    "go-manage",
+			RedirectURI: server.URL + "/api/v1/login/corp/callback",
+			Scopes:      config.OIDCScopes,
+			Claims:      federation.ClaimMapping{Name: "given_name", Surname: "family_name", Email: "email", Username: "preferred_username"},
+			Provision:   true,
+		},
+	}
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:        conn,
+		Repo:      repository.UserRepository{DB: conn},
+		Hasher:    &hasher,
+		Providers: providers,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	john, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+		Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "Sup3r-Secret-pass",
+	})
+	if createErr != nil {
+		t.Fatal(createErr)
+	}
+
+	browser := func() func(method, path, body string, out any) int {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{Jar: jar}
+		return func(method, path, body string, out any) int {
+			req, _ := http.NewRequest(method, path, strings.NewReader(body))
+			res, callErr := client.Do(req)
+			if callErr != nil {
+				t.Fatal(callErr)
+			}
+			defer res.Body.Close()
+			if out != nil {
+				json.NewDecoder(res.Body).Decode(out)
+			}
+			return res.StatusCode
+		}
+	}
+
+	jane := browser()
+	var provisioned models.LoginResponse
+	assert.Equal(t, http.StatusOK, jane(http.MethodGet, server.URL+"/api/v1/login/corp", "", &provisioned))
+	assert.NotEqual(t, "", provisioned.Session.UserID)
+
+	user, searchErr := userService.SearchUserByID(context.Background(), provisioned.Session.UserID)
+	assert.Equal(t, nil, searchErr)
+	assert.Equal(t, "janedoe", user.Username)
+	assert.Equal(t, "janedoe@corp.example.com", user.Email)
+
+	var history int
+	assert.Equal(t, nil, conn.QueryRow(`SELECT COUNT(*) FROM password_history WHERE user_id = ?`, user.ID).Scan(&history))
+	assert.Equal(t, 1, history)
+
+	assert.Equal(t, http.StatusBadRequest, jane(http.MethodGet, lastCallback, "", nil))
+
+	var again models.LoginResponse
+	assert.Equal(t, http.StatusOK, jane(http.MethodGet, server.URL+"/api/v1/login/corp", "", &again))
+	assert.Equal(t, provisioned.Session.UserID, again.Session.UserID)
+
+	var listed models.ListIdentitiesV2Response
+	assert.Equal(t, http.StatusOK, jane(http.MethodGet, server.URL+"/api/v2/users/"+user.ID+"/identities", "", &listed))
+	assert.Equal(t, 1, len(listed.Data))
+	assert.Equal(t, idpServer.URL, listed.Data[0].Issuer)
+	assert.Equal(t, "248289761001", listed.Data[0].Subject)
+
+	johnBrowser := browser()
+	var johnLogin models.LoginResponse
+	assert.Equal(t, http.StatusOK, johnBrowser(http.MethodPost, server.URL+"/api/v1/login", `{"username":"johndoe","password":"Sup3r-Secret-pass"}`, &johnLogin))
+	assert.Equal(t, http.StatusConflict, johnBrowser(http.MethodGet, server.URL+"/api/v1/users/"+john.ID+"/identities/corp/link", "", nil))
+
+	subject = "johndoe-corp"
+	var linked models.IdentityResponse
+	assert.Equal(t, http.StatusOK, johnBrowser(http.MethodGet, server.URL+"/api/v1/users/"+john.ID+"/identities/corp/link", "", &linked))
+	assert.Equal(t, john.ID, linked.Identity.UserID)
+	assert.Equal(t, "johndoe-corp", linked.Identity.Subject)
+
+	var federated models.LoginResponse
+	assert.Equal(t, http.StatusOK, browser()(http.MethodGet, server.URL+"/api/v1/login/corp", "", &federated))
+	assert.Equal(t, john.ID, federated.Session.UserID)
+
+	unlink, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/users/"+john.ID+"/identities/"+linked.Identity.ID, nil)
+	unlink.Header.Set("Authorization", "Bearer "+johnLogin.Session.Token)
+	res, unlinkErr := http.DefaultClient.Do(unlink)
+	if unlinkErr != nil {
+		t.Fatal(unlinkErr)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Equal(t, http.StatusConflict, browser()(http.MethodGet, server.URL+"/api/v1/login/corp", "", nil))
+	assert.Equal(t, http.StatusNotFound, browser()(http.MethodGet, server.URL+"/api/v1/login/unknown", "", nil))
+}
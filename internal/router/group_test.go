@@ -0,0 +1,162 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestGroupHierarchy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	call := func(method, path, body string, out any) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+		return w.Code
+	}
+	createUser := func(username string) models.User {
+		user, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+			Name: "John", Surname: "Doe", Username: username, Email: username + "@example.com", Password: "Sup3r-Secret-pass",
+		})
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
+		return user
+	}
+	createGroup := func(body string) models.Group {
+		var created models.GroupV2Response
+		assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/groups", body, &created))
+		return created.Data
+	}
+
+	john, jane, bob := createUser("johndoe"), createUser("janedoe"), createUser("bobdoe")
+
+	acme := createGroup(`{"name":"Acme","description":"The whole company"}`)
+	engineering := createGroup(`{"name":"Engineering","parent_id":"` + acme.ID + `"}`)
+	platform := createGroup(`{"name":"Platform","parent_id":"` + engineering.ID + `"}`)
+	assert.Equal(t, engineering.ID, *platform.ParentID)
+
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/api/v2/groups", `{"name":"Acme"}`, nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPost, "/api/v2/groups", `{"name":"Sales","parent_id":"missing"}`, nil))
+
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/api/v2/groups/"+acme.ID+"/members/"+john.ID, `{"role":"owner"}`, nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/api/v2/groups/"+engineering.ID+"/members/"+jane.ID, `{"role":"manager"}`, nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/api/v2/groups/"+platform.ID+"/members/"+bob.ID, `{"role":"member"}`, nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/api/v2/groups/"+platform.ID+"/members/"+jane.ID, `{"role":"member"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPut, "/api/v2/groups/"+platform.ID+"/members/"+bob.ID, `{"role":"root"}`, nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPut, "/api/v2/groups/"+platform.ID+"/members/missing", `{"role":"member"}`, nil))
+
+	var promoted models.GroupMemberV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/api/v2/groups/"+platform.ID+"/members/"+bob.ID, `{"role":"manager"}`, &promoted))
+	assert.Equal(t, "manager", promoted.Data.Role)
+	assert.Equal(t, "bobdoe", promoted.Data.Username)
+
+	var direct models.ListGroupMembersV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/groups/"+acme.ID+"/members", "", &direct))
+	assert.Equal(t, 1, len(direct.Data))
+
+	var nested models.ListGroupMembersV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/groups/"+acme.ID+"/members?nested=true", "", &nested))
+	members := map[string]bool{}
+	for _, member := range nested.Data {
+		members[member.Username+"@"+member.GroupID] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"bobdoe@" + platform.ID:     true,
+		"janedoe@" + engineering.ID: true,
+		"janedoe@" + platform.ID:    true,
+		"johndoe@" + acme.ID:        true,
+	}, members)
+
+	var bobGroups models.ListMembershipsV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+bob.ID+"/groups", "", &bobGroups))
+	assert.Equal(t, 1, len(bobGroups.Data))
+	assert.Equal(t, (*string)(nil), bobGroups.Data[0].ViaGroupID)
+
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+bob.ID+"/groups?nested=true", "", &bobGroups))
+	inherited := map[string]string{}
+	for _, membership := range bobGroups.Data {
+		via := ""
+		if membership.ViaGroupID != nil {
+			via = *membership.ViaGroupID
+		}
+		inherited[membership.Group.Name] = via
+	}
+	assert.Equal(t, map[string]string{"Acme": platform.ID, "Engineering": platform.ID, "Platform": ""}, inherited)
+
+	assert.Equal(t, http.StatusConflict, call(http.MethodPatch, "/api/v2/groups/"+acme.ID, `{"parent_id":"`+platform.ID+`"}`, nil))
+	assert.Equal(t, http.StatusConflict, call(http.MethodPatch, "/api/v2/groups/"+acme.ID, `{"parent_id":"`+acme.ID+`"}`, nil))
+	assert.Equal(t, http.StatusConflict, call(http.MethodDelete, "/api/v2/groups/"+engineering.ID, "", nil))
+
+	var moved models.GroupV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodPatch, "/api/v2/groups/"+platform.ID, `{"parent_id":""}`, &moved))
+	assert.Equal(t, (*string)(nil), moved.Data.ParentID)
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/groups/"+acme.ID+"/members?nested=true", "", &nested))
+	assert.Equal(t, 2, len(nested.Data))
+
+	assert.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/api/v2/groups/"+platform.ID+"/members/"+bob.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodDelete, "/api/v2/groups/"+platform.ID+"/members/"+bob.ID, "", nil))
+	assert.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/api/v2/groups/"+platform.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/groups/"+platform.ID, "", nil))
+
+	anonymous := func(method, path, body string) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusUnauthorized, anonymous(http.MethodPost, "/api/v1/groups", `{"name":"Intruders"}`))
+	assert.Equal(t, http.StatusUnauthorized, anonymous(http.MethodGet, "/api/v2/groups", ""))
+	assert.Equal(t, http.StatusUnauthorized, anonymous(http.MethodPut, "/api/v1/groups/"+engineering.ID+"/members/"+bob.ID, `{"role":"owner"}`))
+	assert.Equal(t, http.StatusUnauthorized, anonymous(http.MethodDelete, "/api/v2/groups/"+engineering.ID, ""))
+
+	var janeGroups models.ListMembershipsResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v1/users/"+jane.ID+"/groups", "", &janeGroups))
+	assert.Equal(t, 1, len(janeGroups.Groups))
+	assert.Equal(t, "Engineering", janeGroups.Groups[0].Group.Name)
+}
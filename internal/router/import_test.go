@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestImportUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:              conn,
+		Repo:            repository.UserRepository{DB: conn},
+		Hasher:          &hasher,
+		ImportBatchSize: 2,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	importUsers := func(query, mediaType, body string) (int, models.ImportReport) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v2/users/import"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", mediaType)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.ImportV2Response
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+	exists := func(username string) bool {
+		return userService.Exists(context.Background(), username)
+	}
+
+	csvBody := "name,surname,username,email,password\n" +
+		"John,Doe,johndoe,johndoe@example.com,Sup3r-Secret-pass\n" +
+		"Jane,Doe,janedoe,janedoe@example.com,Sup3r-Secret-pass\n" +
+		"Jim,Doe,jimdoe,johndoe@example.com,Sup3r-Secret-pass\n"
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v2/users/import", strings.NewReader(csvBody))
+	req.Header.Set("Content-Type", config.CSVMediaType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	code, report := importUsers("?dry_run=true", config.CSVMediaType, csvBody)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, config.ErrDuplicateImportRow.Error(), report.Errors[0].Error)
+	assert.Equal(t, false, exists("johndoe"))
+
+	code, report = importUsers("", config.CSVMediaType, csvBody)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, true, exists("johndoe"))
+	assert.Equal(t, true, exists("janedoe"))
+
+	_, loginErr := userService.Login(context.Background(), models.LoginRequest{Username: "janedoe", Password: "Sup3r-Secret-pass"})
+	assert.Equal(t, nil, loginErr)
+
+	ndjsonBody := `{"name":"Johnny","surname":"Doe","username":"johndoe","email":"johnny@example.com","password":"Sup3r-Secret-pass"}` + "\n" +
+		`{"name":"Jane","surname":"Doe","username":"janedoe","email":"johnny@example.com","password":"Sup3r-Secret-pass"}` + "\n"
+
+	code, report = importUsers("?on_conflict=update", config.NDJSONMediaType, ndjsonBody)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, report.Failed)
+	john, _ := userService.SearchUser(context.Background(), "johndoe")
+	assert.Equal(t, "Johnny", john.Name)
+	assert.Equal(t, 2, john.Version)
+
+	failBody := "name,surname,username,email,password\n" +
+		"Amy,Doe,amydoe,amydoe@example.com,Sup3r-Secret-pass\n" +
+		"Bob,Doe,bobdoe,bobdoe@example.com,Sup3r-Secret-pass\n" +
+		"Cat,Doe,catdoe,catdoe@example.com,Sup3r-Secret-pass\n" +
+		"Jane,Doe,janedoe,janedoe@example.com,Sup3r-Secret-pass\n" +
+		"Dan,Doe,dandoe,dandoe@example.com,Sup3r-Secret-pass\n"
+
+	code, report = importUsers("?on_conflict=fail", config.CSVMediaType, failBody)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, report.Aborted)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 5, report.Errors[0].Row)
+	assert.Equal(t, true, exists("bobdoe"))
+	assert.Equal(t, false, exists("catdoe"))
+	assert.Equal(t, false, exists("dandoe"))
+
+	code, _ = importUsers("?on_conflict=overwrite", config.CSVMediaType, failBody)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = importUsers("", config.JSONMediaType, "[]")
+	assert.Equal(t, http.StatusUnsupportedMediaType, code)
+}
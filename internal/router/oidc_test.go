@@ -0,0 +1,204 @@
+package router
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:         conn,
+		Repo:       repository.UserRepository{DB: conn},
+		Hasher:     &hasher,
+		OIDCIssuer: server.URL,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	user, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+		Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "Sup3r-Secret-pass",
+	})
+	if createErr != nil {
+		t.Fatal(createErr)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	call := func(req *http.Request, out any) *http.Response {
+		res, callErr := client.Do(req)
+		if callErr != nil {
+			t.Fatal(callErr)
+		}
+		defer res.Body.Close()
+		if out != nil {
+			json.NewDecoder(res.Body).Decode(out)
+		}
+		return res
+	}
+
+	redirectURI := "https://wiki.example.com/callback"
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v2/oauth/clients",
+		strings.NewReader(`{"name":"wiki","redirect_uris":["`+redirectURI+`"]}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	var registered models.OAuthClientV2Response
+	assert.Equal(t, http.StatusCreated, call(req, &registered).StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/api/v1/login",
+		strings.NewReader(`{"username":"johndoe","password":"Sup3r-Secret-pass"}`))
+	assert.Equal(t, http.StatusOK, call(req, nil).StatusCode)
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	authorize := func(verifierChallenge string) *url.URL {
+		query := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {registered.Data.ID},
+			"redirect_uri":          {redirectURI},
+			"scope":                 {"openid email profile"},
+			"state":                 {"af0ifjsldkj"},
+			"nonce":                 {"n-0S6_WzA2Mj"},
+			"code_challenge":        {verifierChallenge},
+			"code_challenge_method": {"S256"},
+		}
+		req, _ := http.NewRequest(http.MethodGet, server.URL+config.OIDCAuthorizePath+"?"+query.Encode(), nil)
+		res := call(req, nil)
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+
+		location, _ := url.Parse(res.Header.Get("Location"))
+		assert.Equal(t, true, strings.HasPrefix(location.String(), redirectURI+"?"))
+		assert.Equal(t, "af0ifjsldkj", location.Query().Get("state"))
+		return location
+	}
+	exchange := func(code, codeVerifier string, out any) *http.Response {
+		form := url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {redirectURI},
+			"code_verifier": {codeVerifier},
+		}
+		req, _ := http.NewRequest(http.MethodPost, server.URL+config.OIDCTokenPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", config.FormMediaType)
+		req.SetBasicAuth(url.QueryEscape(registered.Data.ID), url.QueryEscape(registered.Data.Secret))
+		return call(req, out)
+	}
+
+	code := authorize(oidc.Challenge(verifier)).Query().Get("code")
+
+	var tokens models.TokenResponse
+	assert.Equal(t, http.StatusOK, exchange(code, verifier, &tokens).StatusCode)
+	assert.Equal(t, "openid profile email", tokens.Scope)
+
+	var replay models.OAuthError
+	assert.Equal(t, http.StatusBadRequest, exchange(code, verifier, &replay).StatusCode)
+	assert.Equal(t, config.OAuthInvalidGrant, replay.Code)
+
+	wrongVerifier := authorize(oidc.Challenge(verifier)).Query().Get("code")
+	assert.Equal(t, http.StatusBadRequest, exchange(wrongVerifier, verifier+"x", &replay).StatusCode)
+	assert.Equal(t, config.OAuthInvalidGrant, replay.Code)
+
+	var discovery models.OIDCDiscovery
+	req, _ = http.NewRequest(http.MethodGet, server.URL+config.OIDCDiscoveryPath, nil)
+	assert.Equal(t, http.StatusOK, call(req, &discovery).StatusCode)
+	assert.Equal(t, server.URL, discovery.Issuer)
+
+	keys := fetchKeys(t, call, discovery.JWKSURI)
+	var identity models.TokenClaims
+	assert.Equal(t, nil, oidc.Verify(tokens.IDToken, config.OIDCIDTokenType, keys, &identity))
+	assert.Equal(t, server.URL, identity.Issuer)
+	assert.Equal(t, registered.Data.ID, identity.Audience)
+	assert.Equal(t, user.ID, identity.Subject)
+	assert.Equal(t, "n-0S6_WzA2Mj", identity.Nonce)
+	assert.Equal(t, "johndoe@example.com", identity.Email)
+
+	userInfo := func(token string, out any) int {
+		req, _ := http.NewRequest(http.MethodGet, discovery.UserInfoEndpoint, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return call(req, out).StatusCode
+	}
+	var info models.UserInfo
+	assert.Equal(t, http.StatusOK, userInfo(tokens.AccessToken, &info))
+	assert.Equal(t, models.UserInfo{Subject: user.ID, PreferredUsername: "johndoe", Name: "John Doe", GivenName: "John", FamilyName: "Doe", Email: "johndoe@example.com"}, info)
+	assert.Equal(t, http.StatusUnauthorized, userInfo(tokens.IDToken, nil))
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/api/v1/oauth/keys/rotate", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	assert.Equal(t, http.StatusNoContent, call(req, nil).StatusCode)
+
+	rotated := fetchKeys(t, call, discovery.JWKSURI)
+	assert.Equal(t, 2, len(rotated))
+	assert.Equal(t, http.StatusOK, userInfo(tokens.AccessToken, nil))
+
+	var refreshed models.TokenResponse
+	assert.Equal(t, http.StatusOK, exchange(authorize(oidc.Challenge(verifier)).Query().Get("code"), verifier, &refreshed).StatusCode)
+	assert.NotEqual(t, tokenKeyID(tokens.IDToken), tokenKeyID(refreshed.IDToken))
+	assert.Equal(t, nil, oidc.Verify(refreshed.IDToken, config.OIDCIDTokenType, rotated, &identity))
+}
+
+func fetchKeys(t *testing.T, call func(*http.Request, any) *http.Response, jwksURI string) map[string]*rsa.PublicKey {
+	var jwks models.JWKS
+	req, _ := http.NewRequest(http.MethodGet, jwksURI, nil)
+	assert.Equal(t, http.StatusOK, call(req, &jwks).StatusCode)
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		modulus, _ := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+		exponent, _ := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+		keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}
+	}
+	return keys
+}
+
+func tokenKeyID(token string) string {
+	header, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	var decoded struct {
+		KeyID string `json:"kid"`
+	}
+	json.Unmarshal(header, &decoded)
+	return decoded.KeyID
+}
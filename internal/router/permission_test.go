@@ -0,0 +1,158 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestUserPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	call := func(method, path, authorization, body string, out any) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		req.Header.Set("If-Match", `"1"`)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+		return w.Code
+	}
+	createUser := func(username string) models.User {
+		user, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+			Name: "John", Surname: "Doe", Username: username, Email: username + "@example.com", Password: "Sup3r-Secret-pass",
+		})
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
+		return user
+	}
+	grant := func(user models.User, permissions string) {
+		var role models.RoleV2Response
+		assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/roles", "Bearer secret", `{"name":"`+permissions+`","permissions":["`+permissions+`"]}`, &role))
+		assert.Equal(t, http.StatusNoContent, call(http.MethodPut, "/api/v2/users/"+user.ID+"/roles/"+role.Data.ID, "Bearer secret", "", nil))
+	}
+
+	john, jane, bob := createUser("johndoe"), createUser("janedoe"), createUser("bobdoe")
+
+	var login models.LoginResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/api/v1/login", "", `{"username":"janedoe","password":"Sup3r-Secret-pass"}`, &login))
+	session := "Bearer " + login.Session.Token
+
+	tests := []struct {
+		Name         string
+		Method       string
+		Path         string
+		Auth         string
+		Body         string
+		ExpectedCode int
+	}{
+		{Name: "Anonymous list", Method: http.MethodGet, Path: "/api/v2/users", ExpectedCode: http.StatusUnauthorized},
+		{Name: "Anonymous legacy create", Method: http.MethodPost, Path: "/api/go-manage/create", Body: `{}`, ExpectedCode: http.StatusUnauthorized},
+		{Name: "Unknown session", Method: http.MethodGet, Path: "/api/v2/users/" + jane.ID, Auth: "Bearer expired", ExpectedCode: http.StatusUnauthorized},
+		{Name: "List without users:read", Method: http.MethodGet, Path: "/api/v2/users", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Read self", Method: http.MethodGet, Path: "/api/v2/users/" + jane.ID, Auth: session, ExpectedCode: http.StatusOK},
+		{Name: "Read self by username", Method: http.MethodGet, Path: "/api/v1/users/by-username/janedoe", Auth: session, ExpectedCode: http.StatusOK},
+		{Name: "Read another user", Method: http.MethodGet, Path: "/api/v2/users/" + john.ID, Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Read another user by username", Method: http.MethodGet, Path: "/api/v1/users/by-username/johndoe", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Legacy search another user", Method: http.MethodGet, Path: "/api/go-manage/search?username=johndoe", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Create without users:write", Method: http.MethodPost, Path: "/api/v2/users", Auth: session, Body: `{}`, ExpectedCode: http.StatusForbidden},
+		{Name: "Update self", Method: http.MethodPatch, Path: "/api/v2/users/" + jane.ID, Auth: session, Body: `{"name":"Jane"}`, ExpectedCode: http.StatusOK},
+		{Name: "Update another user", Method: http.MethodPatch, Path: "/api/v2/users/" + john.ID, Auth: session, Body: `{"name":"Jane"}`, ExpectedCode: http.StatusForbidden},
+		{Name: "Set another user's password", Method: http.MethodPut, Path: "/api/v2/users/" + john.ID + "/password", Auth: session, Body: `{"password":"An0ther-Secret-pass"}`, ExpectedCode: http.StatusForbidden},
+		{Name: "Delete self without users:delete", Method: http.MethodDelete, Path: "/api/v2/users/" + jane.ID, Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Admin token lists", Method: http.MethodGet, Path: "/api/v2/users", Auth: "Bearer secret", ExpectedCode: http.StatusOK},
+		{Name: "Anonymous group list", Method: http.MethodGet, Path: "/api/v2/groups", ExpectedCode: http.StatusUnauthorized},
+		{Name: "Anonymous group create", Method: http.MethodPost, Path: "/api/v1/groups", Body: `{"name":"Intruders"}`, ExpectedCode: http.StatusUnauthorized},
+		{Name: "Group list without groups:read", Method: http.MethodGet, Path: "/api/v2/groups", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Group create without groups:write", Method: http.MethodPost, Path: "/api/v1/groups", Auth: session, Body: `{"name":"Intruders"}`, ExpectedCode: http.StatusForbidden},
+		{Name: "Group member without groups:write", Method: http.MethodPut, Path: "/api/v2/groups/1/members/" + jane.ID, Auth: session, Body: `{"role":"owner"}`, ExpectedCode: http.StatusForbidden},
+		{Name: "Group delete without groups:delete", Method: http.MethodDelete, Path: "/api/v2/groups/1", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Audit without audit:read", Method: http.MethodGet, Path: "/api/v2/audit", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Anonymous audit", Method: http.MethodGet, Path: "/api/go-manage/audit", ExpectedCode: http.StatusUnauthorized},
+		{Name: "Roles without roles:manage", Method: http.MethodGet, Path: "/api/v2/roles", Auth: session, ExpectedCode: http.StatusForbidden},
+		{Name: "Role assignment without roles:manage", Method: http.MethodPut, Path: "/api/v2/users/" + jane.ID + "/roles/1", Auth: session, ExpectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.ExpectedCode, call(tt.Method, tt.Path, tt.Auth, tt.Body, nil))
+		})
+	}
+
+	grant(jane, config.PermUsersRead)
+	var listed models.ListUsersV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users", session, "", &listed))
+	assert.Equal(t, 3, len(listed.Data))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodDelete, "/api/v2/users/"+john.ID, session, "", nil))
+
+	grant(jane, config.PermGroupsRead)
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/groups", session, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/api/v2/groups", session, `{"name":"Support"}`, nil))
+
+	apiKey := func(scope string) models.APIKey {
+		var created models.APIKeyV2Response
+		assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/api-keys", "Bearer secret", `{"name":"`+scope+`","scopes":["`+scope+`"]}`, &created))
+		return created.Data
+	}
+	deleter := apiKey(config.PermUsersDelete)
+	writeKey, deleteKey := config.APIKeyScheme+apiKey(config.ScopeWrite).Key, config.APIKeyScheme+deleter.Key
+	assert.Equal(t, http.StatusForbidden, call(http.MethodDelete, "/api/v2/users/"+john.ID, writeKey, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v2/audit", writeKey, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v2/users", deleteKey, "", nil))
+
+	grant(jane, config.PermUsersDelete)
+	assert.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/api/v2/users/"+john.ID, session, "", nil))
+	assert.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/api/v2/users/"+bob.ID, deleteKey, "", nil))
+
+	var events models.ListAuditResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v1/audit?action="+config.AuditActionDelete, "Bearer secret", "", &events))
+	actors := map[string]bool{}
+	for _, event := range events.Events {
+		actors[event.Actor] = true
+	}
+	assert.Equal(t, map[string]bool{"janedoe": true, config.APIKeyActorPrefix + deleter.Prefix: true}, actors)
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/ratelimit"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRateLimitBuckets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+	limiter := &middlewares.RateLimiter{
+		Store:  ratelimit.NewMemoryStore(),
+		Limits: map[string]ratelimit.Limit{config.RateLimitRead: {Requests: 1, Period: time.Minute, Burst: 1}},
+		Key:    middlewares.CredentialKey("secret", userService.Authenticate),
+	}
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant(nil, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), limiter)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	call := func(method, path, authorization, body string, out any) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+		return w.Code
+	}
+	login := func(username string) (models.User, string) {
+		user, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+			Name: "John", Surname: "Doe", Username: username, Email: username + "@example.com", Password: "Sup3r-Secret-pass",
+		})
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
+		var response models.LoginResponse
+		assert.Equal(t, http.StatusOK, call(http.MethodPost, "/api/v1/login", "", `{"username":"`+username+`","password":"Sup3r-Secret-pass"}`, &response))
+		return user, "Bearer " + response.Session.Token
+	}
+
+	john, johnSession := login("johndoe")
+	jane, janeSession := login("janedoe")
+
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+john.ID, johnSession, "", nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+jane.ID, janeSession, "", nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users", "Bearer secret", "", nil))
+	assert.Equal(t, http.StatusTooManyRequests, call(http.MethodGet, "/api/v2/users/"+john.ID, johnSession, "", nil))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/v2/users", "", "", nil))
+	assert.Equal(t, http.StatusTooManyRequests, call(http.MethodGet, "/api/v2/users", "Bearer guess", "", nil))
+}
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRolePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant([]string{"acme"}, "", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	call := func(method, path, body string, out any) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+		return w.Code
+	}
+	check := func(userID, action, resource string) models.PermissionDecision {
+		var decision models.PermissionDecisionV2Response
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+userID+"/permissions/check?action="+action+"&resource="+resource, "", &decision))
+		return decision.Data
+	}
+
+	john, createErr := userService.CreateUser(context.Background(), models.CreateUserRequest{
+		Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "Sup3r-Secret-pass",
+	})
+	if createErr != nil {
+		t.Fatal(createErr)
+	}
+
+	var support models.RoleV2Response
+	assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/roles", `{"name":"Support","permissions":["users:read","groups:read"]}`, &support))
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/api/v2/roles", `{"name":"Support","permissions":["users:read"]}`, nil))
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/api/v2/roles", `{"name":"Empty","permissions":[]}`, nil))
+
+	assert.Equal(t, false, check(john.ID, config.PermUsersWrite, "someone-else").Allowed)
+	assert.Equal(t, config.BaselineRole, check(john.ID, config.PermUsersWrite, john.ID).Role)
+
+	assert.Equal(t, http.StatusNoContent, call(http.MethodPut, "/api/v2/users/"+john.ID+"/roles/"+support.Data.ID, "", nil))
+	assert.Equal(t, http.StatusNoContent, call(http.MethodPut, "/api/v2/users/"+john.ID+"/roles/"+support.Data.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPut, "/api/v2/users/"+john.ID+"/roles/missing", "", nil))
+
+	var johnRoles models.ListRolesV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+john.ID+"/roles", "", &johnRoles))
+	assert.Equal(t, 1, len(johnRoles.Data))
+
+	granted := check(john.ID, config.PermUsersRead, "someone-else")
+	assert.Equal(t, true, granted.Allowed)
+	assert.Equal(t, "Support", granted.Role)
+	assert.Equal(t, false, check(john.ID, config.PermUsersDelete, "someone-else").Allowed)
+
+	var updated models.RoleV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodPatch, "/api/v2/roles/"+support.Data.ID, `{"permissions":["*"]}`, &updated))
+	assert.Equal(t, []string{config.PermissionWildcard}, updated.Data.Permissions)
+	assert.Equal(t, true, check(john.ID, config.PermRolesManage, "").Allowed)
+
+	allowed, canErr := userService.Can(context.Background(), john, config.PermUsersDelete, "someone-else")
+	assert.Equal(t, nil, canErr)
+	assert.Equal(t, true, allowed)
+
+	var acmeRole models.RoleV2Response
+	req, _ := http.NewRequest(http.MethodPost, "/api/v2/roles", strings.NewReader(`{"name":"Support","permissions":["audit:read"]}`))
+	req.Header.Set("Content-Type", config.JSONMediaType)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set(config.TenantHeader, "acme")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &acmeRole)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPut, "/api/v2/users/"+john.ID+"/roles/"+acmeRole.Data.ID, "", nil))
+
+	var catalog models.ListPermissionsV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/permissions", "", &catalog))
+	assert.Equal(t, true, len(catalog.Data) > len(config.Permissions))
+
+	assert.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/api/v2/roles/"+support.Data.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodDelete, "/api/v2/users/"+john.ID+"/roles/"+support.Data.ID, "", nil))
+	assert.Equal(t, false, check(john.ID, config.PermUsersRead, "someone-else").Allowed)
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+john.ID+"/roles", "", &johnRoles))
+	assert.Equal(t, 0, len(johnRoles.Data))
+}
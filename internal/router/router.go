@@ -1,11 +1,27 @@
 package router
 
-import "github.com/gin-gonic/gin"
+import (
+	"go-manage/cmd/config"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
 
 func SetupRouter() *gin.Engine {
-	router := gin.Default()
+	router, routerErr := newEngine(config.EnvList(config.TrustedProxiesEnv, nil))
+	if routerErr != nil {
+		log.Fatal("cannot configure trusted proxies. Error: " + routerErr.Error())
+	}
 
 	Urlmapping(router)
 
 	return router
 }
+
+func newEngine(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.Default()
+	if proxiesErr := router.SetTrustedProxies(trustedProxies); proxiesErr != nil {
+		return nil, proxiesErr
+	}
+	return router, nil
+}
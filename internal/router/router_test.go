@@ -0,0 +1,70 @@
+package router
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		Name           string
+		TrustedProxies []string
+		ExpectedCodes  []int
+		ExpectedErr    bool
+	}{
+		{
+			Name:          "Spoofed forwarded header is ignored by default",
+			ExpectedCodes: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		},
+		{
+			Name:           "Forwarded header from a trusted proxy",
+			TrustedProxies: []string{"192.0.2.0/24"},
+			ExpectedCodes:  []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			Name:           "Invalid proxy",
+			TrustedProxies: []string{"not-an-ip"},
+			ExpectedErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r, engineErr := newEngine(tt.TrustedProxies)
+			if tt.ExpectedErr {
+				assert.NotEqual(t, nil, engineErr)
+				return
+			}
+			assert.Equal(t, nil, engineErr)
+
+			limiter := &middlewares.RateLimiter{
+				Store:  ratelimit.NewMemoryStore(),
+				Limits: map[string]ratelimit.Limit{config.RateLimitLogin: {Requests: 1, Period: time.Minute, Burst: 1}},
+			}
+			r.POST("/login", middlewares.RequestContext(), limiter.Group(config.RateLimitLogin), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			for i, forwarded := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+				req, _ := http.NewRequest(http.MethodPost, "/login", nil)
+				req.RemoteAddr = "192.0.2.1:1234"
+				req.Header.Set("X-Forwarded-For", forwarded)
+				w := httptest.NewRecorder()
+
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.ExpectedCodes[i], w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"encoding/json"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := gin.New()
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := services.UserServices{
+		DB:     conn,
+		Repo:   repository.UserRepository{DB: conn},
+		Hasher: &hasher,
+	}
+	handler := handlers.NewUserHandler(userService)
+	auditHandler := handlers.NewAuditHandler(services.AuditServices{Repo: repository.AuditRepository{DB: conn}})
+
+	routesErr := mapRoutes(r, handler, auditHandler, middlewares.AdminAuth("secret"), middlewares.Tenant([]string{"acme", "globex"}, "example.com", "secret", userService.LookupTenant),
+		middlewares.SessionAuth(userService.Authenticate), middlewares.APIKeyAuth(userService.AuthenticateAPIKey),
+		middlewares.Permission("secret", userService.Authenticate, userService.Can, userService.CanAPIKey), nil)
+	if routesErr != nil {
+		t.Fatal(routesErr)
+	}
+
+	call := func(method, path, tenant, authorization, body string, out any) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", config.JSONMediaType)
+		if tenant != "" {
+			req.Header.Set(config.TenantHeader, tenant)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+		return w.Code
+	}
+	createUser := func(tenant, username string) models.UserV2 {
+		var created models.UserV2Response
+		body := `{"name":"John","surname":"Doe","username":"` + username + `","email":"` + username + `@example.com","password":"Sup3r-Secret-pass"}`
+		assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/users", tenant, "Bearer secret", body, &created))
+		return created.Data
+	}
+
+	acmeJohn := createUser("acme", "johndoe")
+	createUser("acme", "janedoe")
+	globexJohn := createUser("globex", "johndoe")
+	assert.NotEqual(t, acmeJohn.ID, globexJohn.ID)
+
+	duplicate := `{"name":"John","surname":"Doe","username":"johndoe","email":"other@example.com","password":"Sup3r-Secret-pass"}`
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/api/v2/users", "acme", "Bearer secret", duplicate, nil))
+
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/users", "initech", "Bearer secret", "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/users/"+acmeJohn.ID, "initech", "Bearer secret", "", nil))
+
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v2/users", "acme", "", "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v2/users/"+acmeJohn.ID, "acme", "", "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/api/v2/groups", "acme", "", `{"name":"Engineering"}`, nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/go-manage/list", "acme", "", "", nil))
+
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users/"+acmeJohn.ID, "acme", "Bearer secret", "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/users/"+acmeJohn.ID, "globex", "Bearer secret", "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/users/"+globexJohn.ID, "", "Bearer secret", "", nil))
+
+	var acmeUsers, globexUsers models.ListUsersV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users", "acme", "Bearer secret", "", &acmeUsers))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users", "globex", "Bearer secret", "", &globexUsers))
+	assert.Equal(t, 2, len(acmeUsers.Data))
+	assert.Equal(t, 1, len(globexUsers.Data))
+	assert.Equal(t, globexJohn.ID, globexUsers.Data[0].ID)
+
+	var login models.LoginResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/api/v1/login", "acme", "", `{"username":"johndoe","password":"Sup3r-Secret-pass"}`, &login))
+	token := "Bearer " + login.Session.Token
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v1/users/"+acmeJohn.ID+"/identities", "", token, "", nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v1/users/"+acmeJohn.ID+"/identities", "acme", token, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v1/users/"+globexJohn.ID+"/identities", "globex", token, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/api/v1/login", "globex", token, `{"username":"johndoe","password":"Sup3r-Secret-pass"}`, nil))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/api/v1/login", "globex", "", `{"username":"janedoe","password":"Sup3r-Secret-pass"}`, nil))
+
+	var acmeGroup, globexGroup models.GroupV2Response
+	assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/groups", "acme", "Bearer secret", `{"name":"Engineering"}`, &acmeGroup))
+	assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v2/groups", "globex", "Bearer secret", `{"name":"Engineering"}`, &globexGroup))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/groups/"+acmeGroup.Data.ID, "globex", "Bearer secret", "", nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPost, "/api/v2/groups", "globex", "Bearer secret", `{"name":"Platform","parent_id":"`+acmeGroup.Data.ID+`"}`, nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPut, "/api/v2/groups/"+globexGroup.Data.ID+"/members/"+acmeJohn.ID, "globex", "Bearer secret", `{"role":"member"}`, nil))
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/api/v2/groups/"+acmeGroup.Data.ID+"/members/"+acmeJohn.ID, "acme", "Bearer secret", `{"role":"owner"}`, nil))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/api/v2/groups/"+acmeGroup.Data.ID+"/members", "globex", "Bearer secret", "", nil))
+
+	var key models.APIKeyResponse
+	assert.Equal(t, http.StatusCreated, call(http.MethodPost, "/api/v1/api-keys", "acme", "Bearer secret", `{"name":"ci","scopes":["admin"]}`, &key))
+	apiKey := config.APIKeyScheme + key.APIKey.Key
+
+	var keyUsers models.ListUsersV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users", "", apiKey, "", &keyUsers))
+	assert.Equal(t, 2, len(keyUsers.Data))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/users", "acme", apiKey, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v2/users", "globex", apiKey, "", nil))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v2/users/"+globexJohn.ID, "globex", apiKey, "", nil))
+
+	var globexKeys models.ListAPIKeysV2Response
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v2/api-keys", "globex", "Bearer secret", "", &globexKeys))
+	assert.Equal(t, 0, len(globexKeys.Data))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodDelete, "/api/v2/api-keys/"+key.APIKey.ID, "globex", "Bearer secret", "", nil))
+
+	var acmeAudit, globexAudit models.ListAuditResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v1/audit?action="+config.AuditActionCreate, "acme", "Bearer secret", "", &acmeAudit))
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/api/v1/audit?action="+config.AuditActionCreate, "globex", "Bearer secret", "", &globexAudit))
+	assert.Equal(t, 2, len(acmeAudit.Events))
+	assert.Equal(t, 1, len(globexAudit.Events))
+}
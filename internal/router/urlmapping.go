@@ -1,12 +1,21 @@
 package router
 
 import (
+	"context"
+	"go-manage/cmd/config"
 	"go-manage/internal/data"
+	"go-manage/internal/federation"
 	"go-manage/internal/handlers"
+	"go-manage/internal/middlewares"
+	"go-manage/internal/models"
+	"go-manage/internal/openapi"
+	"go-manage/internal/password"
+	"go-manage/internal/ratelimit"
 	"go-manage/internal/repository"
 	"go-manage/internal/services"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,20 +27,266 @@ func Urlmapping(r *gin.Engine) {
 		log.Fatal("cannot initialize database. Error: " + connErr.Error())
 	}
 
+	passwords, passwordsErr := password.NewChecker(password.PolicyFromEnv(), os.Getenv(config.BreachedPasswordsFileEnv))
+	if passwordsErr != nil {
+		log.Fatal("cannot initialize password checker. Error: " + passwordsErr.Error())
+	}
+
+	hasher, hasherErr := password.HasherFromEnv()
+	if hasherErr != nil {
+		log.Fatal("cannot initialize password hasher. Error: " + hasherErr.Error())
+	}
+
+	throttle := services.ThrottlePolicyFromEnv()
+
+	providers, providersErr := federation.LoadProviders(os.Getenv(config.FederationProvidersFileEnv))
+	if providersErr != nil {
+		log.Fatal("cannot load identity providers. Error: " + providersErr.Error())
+	}
+
 	repo := repository.UserRepository{DB: conn}
-	userService := services.UserServices{DB: conn, Repo: repo}
+	userService := services.UserServices{
+		DB:              conn,
+		Repo:            repo,
+		Passwords:       passwords,
+		Hasher:          &hasher,
+		SessionTTL:      config.EnvDuration(config.SessionTTLEnv, config.DefaultSessionTTL),
+		Throttle:        &throttle,
+		TOTPIssuer:      config.EnvString(config.TOTPIssuerEnv, config.DefaultTOTPIssuer),
+		OIDCIssuer:      config.EnvString(config.OIDCIssuerEnv, config.DefaultOIDCIssuer),
+		OIDCKeyRotation: config.EnvDuration(config.OIDCKeyRotationEnv, config.DefaultOIDCKeyRotation),
+		Providers:       providers,
+		ImportBatchSize: config.EnvInt(config.ImportBatchSizeEnv, config.DefaultImportBatchSize),
+		BackupDir:       config.EnvString(config.BackupDirEnv, config.DefaultBackupDir),
+		BackupRetention: config.EnvInt(config.BackupRetentionEnv, config.DefaultBackupRetention),
+	}
 
 	handler := handlers.NewUserHandler(userService)
 
-	api := r.Group("/api/go-manage")
+	auditService := services.AuditServices{Repo: repository.AuditRepository{DB: conn}}
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	go userService.StartPurger(context.Background(),
+		config.EnvDuration(config.PurgeIntervalEnv, config.DefaultPurgeInterval),
+		config.EnvDuration(config.PurgeRetentionEnv, config.DefaultPurgeRetention))
+
+	if backupInterval := config.EnvDuration(config.BackupIntervalEnv, config.DefaultBackupInterval); backupInterval > 0 {
+		go userService.StartBackups(context.Background(), backupInterval)
+	}
 
-	api.GET("/ping", func(ctx *gin.Context) {
+	admin := middlewares.AdminAuth(os.Getenv(config.AdminTokenEnv))
+
+	limits, limitsErr := ratelimit.LimitsFromEnv()
+	if limitsErr != nil {
+		log.Fatal("cannot initialize rate limits. Error: " + limitsErr.Error())
+	}
+	limiter := &middlewares.RateLimiter{
+		Store:  ratelimit.NewMemoryStore(),
+		Limits: limits,
+		Key:    middlewares.CredentialKey(os.Getenv(config.AdminTokenEnv), userService.Authenticate),
+	}
+
+	tenant := middlewares.Tenant(config.EnvList(config.TenantsEnv, nil), os.Getenv(config.TenantDomainEnv), os.Getenv(config.AdminTokenEnv), userService.LookupTenant)
+	session := middlewares.SessionAuth(userService.Authenticate)
+	apiKeys := middlewares.APIKeyAuth(userService.AuthenticateAPIKey)
+	permit := middlewares.Permission(os.Getenv(config.AdminTokenEnv), userService.Authenticate, userService.Can, userService.CanAPIKey)
+
+	if routesErr := mapRoutes(r, handler, auditHandler, admin, tenant, session, apiKeys, permit, limiter); routesErr != nil {
+		log.Fatal("cannot document routes. Error: " + routesErr.Error())
+	}
+}
+
+func mapRoutes(r *gin.Engine, handler *handlers.UserHandler, auditHandler *handlers.AuditHandler, admin, tenant, session, apiKeys gin.HandlerFunc, permit middlewares.PermissionCheck, limiter *middlewares.RateLimiter) error {
+	ping := func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, "pong")
-	})
+	}
+
+	read := limiter.Group(config.RateLimitRead)
+	write := limiter.Group(config.RateLimitWrite)
+	login := limiter.Group(config.RateLimitLogin)
+	adminLimit := limiter.Group(config.RateLimitAdmin)
+
+	handlerV2 := handlers.NewUserV2Handler(handler)
+	readUsers := permit(config.PermUsersRead)
+	writeUsers := permit(config.PermUsersWrite)
+	deleteUsers := permit(config.PermUsersDelete)
+	readGroups := permit(config.PermGroupsRead)
+	writeGroups := permit(config.PermGroupsWrite)
+	deleteGroups := permit(config.PermGroupsDelete)
+	readAudit := permit(config.PermAuditRead)
+	manageRoles := permit(config.PermRolesManage)
+
+	registerVersions(r, []apiVersion{
+		{
+			name: "v1",
+			register: func(v1 *gin.RouterGroup) {
+				v1.GET("/ping", ping)
+				v1.POST("/login", login, handler.Login)
+				v1.POST("/login/mfa", login, handler.LoginMFA)
+				v1.GET("/login/:provider", login, handler.StartFederatedLogin)
+				v1.GET("/login/:provider/callback", login, handler.FederatedCallback)
+
+				users := v1.Group("/users")
+				users.GET("", read, readUsers, handler.List)
+				users.POST("", write, writeUsers, handler.CreateUser)
+				users.POST("/import", adminLimit, admin, handler.ImportUsers)
+				users.GET("/export", adminLimit, admin, handler.ExportUsers)
+				users.POST("/batch", adminLimit, admin, handler.ExecuteBatch)
+				users.GET("/:id", read, readUsers, handler.GetUser)
+				users.GET("/by-username/:username", read, readUsers, handler.GetUserByUsername)
+				users.PATCH("/:id", write, writeUsers, handler.PatchUser)
+				users.PUT("/:id", write, writeUsers, handler.ReplaceUser)
+				users.DELETE("/:id", write, deleteUsers, handler.DeleteUser)
+				users.PUT("/:id/password", write, writeUsers, handler.SetPassword)
+				users.POST("/:id/mfa/totp", write, session, handler.EnrollTOTP)
+				users.POST("/:id/mfa/totp/confirm", write, session, handler.ConfirmTOTP)
+				users.DELETE("/:id/mfa/totp", write, session, handler.DisableTOTP)
+				users.POST("/:id/mfa/recovery-codes", write, session, handler.RegenerateRecoveryCodes)
+				users.GET("/:id/identities", read, session, handler.ListIdentities)
+				users.GET("/:id/identities/:provider/link", read, session, handler.LinkIdentity)
+				users.DELETE("/:id/identities/:identity", write, session, handler.UnlinkIdentity)
+				users.POST("/by-username/:username/restore", adminLimit, admin, handler.Restore)
+				users.POST("/by-username/:username/unlock", adminLimit, admin, handler.Unlock)
+				users.GET("/:id/groups", read, readUsers, handler.ListUserGroups)
+				users.GET("/:id/roles", adminLimit, manageRoles, handler.ListUserRoles)
+				users.PUT("/:id/roles/:role", adminLimit, manageRoles, handler.AssignRole)
+				users.DELETE("/:id/roles/:role", adminLimit, manageRoles, handler.UnassignRole)
+				users.GET("/:id/permissions/check", adminLimit, manageRoles, handler.CheckPermission)
+
+				groups := v1.Group("/groups")
+				groups.GET("", read, readGroups, handler.ListGroups)
+				groups.POST("", write, writeGroups, handler.CreateGroup)
+				groups.GET("/:id", read, readGroups, handler.GetGroup)
+				groups.PATCH("/:id", write, writeGroups, handler.UpdateGroup)
+				groups.DELETE("/:id", write, deleteGroups, handler.DeleteGroup)
+				groups.GET("/:id/members", read, readGroups, handler.ListGroupMembers)
+				groups.PUT("/:id/members/:user", write, writeGroups, handler.SetGroupMember)
+				groups.DELETE("/:id/members/:user", write, writeGroups, handler.RemoveGroupMember)
+
+				v1.GET("/audit", adminLimit, readAudit, auditHandler.List)
+
+				roles := v1.Group("/roles", adminLimit, manageRoles)
+				roles.POST("", handler.CreateRole)
+				roles.GET("", handler.ListRoles)
+				roles.GET("/:id", handler.GetRole)
+				roles.PATCH("/:id", handler.UpdateRole)
+				roles.DELETE("/:id", handler.DeleteRole)
+				v1.GET("/permissions", adminLimit, manageRoles, handler.ListPermissions)
+
+				keys := v1.Group("/api-keys", adminLimit, admin)
+				keys.POST("", handler.CreateAPIKey)
+				keys.GET("", handler.ListAPIKeys)
+				keys.DELETE("/:id", handler.RevokeAPIKey)
+
+				clients := v1.Group("/oauth/clients", adminLimit, admin)
+				clients.POST("", handler.CreateOAuthClient)
+				clients.GET("", handler.ListOAuthClients)
+				clients.DELETE("/:id", handler.DeleteOAuthClient)
+				v1.POST("/oauth/keys/rotate", adminLimit, admin, handler.RotateSigningKey)
+
+				backups := v1.Group("/backups", adminLimit, admin)
+				backups.POST("", handler.CreateBackup)
+				backups.GET("", handler.ListBackups)
+			},
+		},
+		{
+			name: "v2",
+			register: func(v2 *gin.RouterGroup) {
+				v2.GET("/ping", ping)
+				v2.POST("/login", login, handlerV2.Login)
+				v2.POST("/login/mfa", login, handlerV2.LoginMFA)
+				v2.GET("/login/:provider", login, handlerV2.StartFederatedLogin)
+				v2.GET("/login/:provider/callback", login, handlerV2.FederatedCallback)
+
+				users := v2.Group("/users")
+				users.GET("", read, readUsers, handlerV2.List)
+				users.POST("", write, writeUsers, handlerV2.CreateUser)
+				users.POST("/import", adminLimit, admin, handlerV2.ImportUsers)
+				users.GET("/export", adminLimit, admin, handlerV2.ExportUsers)
+				users.POST("/batch", adminLimit, admin, handlerV2.ExecuteBatch)
+				users.GET("/:id", read, readUsers, handlerV2.GetUser)
+				users.GET("/by-username/:username", read, readUsers, handlerV2.GetUserByUsername)
+				users.PATCH("/:id", write, writeUsers, handlerV2.PatchUser)
+				users.PUT("/:id", write, writeUsers, handlerV2.ReplaceUser)
+				users.DELETE("/:id", write, deleteUsers, handlerV2.DeleteUser)
+				users.PUT("/:id/password", write, writeUsers, handlerV2.SetPassword)
+				users.POST("/:id/mfa/totp", write, session, handlerV2.EnrollTOTP)
+				users.POST("/:id/mfa/totp/confirm", write, session, handlerV2.ConfirmTOTP)
+				users.DELETE("/:id/mfa/totp", write, session, handlerV2.DisableTOTP)
+				users.POST("/:id/mfa/recovery-codes", write, session, handlerV2.RegenerateRecoveryCodes)
+				users.GET("/:id/identities", read, session, handlerV2.ListIdentities)
+				users.GET("/:id/identities/:provider/link", read, session, handlerV2.LinkIdentity)
+				users.DELETE("/:id/identities/:identity", write, session, handlerV2.UnlinkIdentity)
+				users.POST("/by-username/:username/restore", adminLimit, admin, handlerV2.Restore)
+				users.POST("/by-username/:username/unlock", adminLimit, admin, handlerV2.Unlock)
+				users.GET("/:id/groups", read, readUsers, handlerV2.ListUserGroups)
+				users.GET("/:id/roles", adminLimit, manageRoles, handlerV2.ListUserRoles)
+				users.PUT("/:id/roles/:role", adminLimit, manageRoles, handlerV2.AssignRole)
+				users.DELETE("/:id/roles/:role", adminLimit, manageRoles, handlerV2.UnassignRole)
+				users.GET("/:id/permissions/check", adminLimit, manageRoles, handlerV2.CheckPermission)
+
+				groups := v2.Group("/groups")
+				groups.GET("", read, readGroups, handlerV2.ListGroups)
+				groups.POST("", write, writeGroups, handlerV2.CreateGroup)
+				groups.GET("/:id", read, readGroups, handlerV2.GetGroup)
+				groups.PATCH("/:id", write, writeGroups, handlerV2.UpdateGroup)
+				groups.DELETE("/:id", write, deleteGroups, handlerV2.DeleteGroup)
+				groups.GET("/:id/members", read, readGroups, handlerV2.ListGroupMembers)
+				groups.PUT("/:id/members/:user", write, writeGroups, handlerV2.SetGroupMember)
+				groups.DELETE("/:id/members/:user", write, writeGroups, handlerV2.RemoveGroupMember)
+
+				v2.GET("/audit", adminLimit, readAudit, auditHandler.List)
+
+				roles := v2.Group("/roles", adminLimit, manageRoles)
+				roles.POST("", handlerV2.CreateRole)
+				roles.GET("", handlerV2.ListRoles)
+				roles.GET("/:id", handlerV2.GetRole)
+				roles.PATCH("/:id", handlerV2.UpdateRole)
+				roles.DELETE("/:id", handlerV2.DeleteRole)
+				v2.GET("/permissions", adminLimit, manageRoles, handlerV2.ListPermissions)
+
+				keys := v2.Group("/api-keys", adminLimit, admin)
+				keys.POST("", handlerV2.CreateAPIKey)
+				keys.GET("", handlerV2.ListAPIKeys)
+				keys.DELETE("/:id", handlerV2.RevokeAPIKey)
+
+				clients := v2.Group("/oauth/clients", adminLimit, admin)
+				clients.POST("", handlerV2.CreateOAuthClient)
+				clients.GET("", handlerV2.ListOAuthClients)
+				clients.DELETE("/:id", handlerV2.DeleteOAuthClient)
+				v2.POST("/oauth/keys/rotate", adminLimit, admin, handlerV2.RotateSigningKey)
+
+				backups := v2.Group("/backups", adminLimit, admin)
+				backups.POST("", handlerV2.CreateBackup)
+				backups.GET("", handlerV2.ListBackups)
+			},
+		},
+	}, middlewares.RequestContext(), tenant, apiKeys)
+
+	legacy := r.Group("/api/go-manage", middlewares.RequestContext(), tenant, apiKeys)
+	deprecated := middlewares.Deprecated(config.UsersResourcePath)
+
+	legacy.GET("/ping", middlewares.Deprecated("/api/v1/ping"), ping)
+	legacy.GET("/search", deprecated, read, readUsers, handler.Search)
+	legacy.GET("/list", deprecated, read, readUsers, handler.List)
+	legacy.POST("/create", deprecated, write, writeUsers, handler.Create)
+	legacy.DELETE("/delete", deprecated, write, deleteUsers, handler.Delete)
+	legacy.PATCH("/update", deprecated, write, writeUsers, handler.Update)
+	legacy.PATCH("/change-password", deprecated, write, writeUsers, handler.ChangePwd)
+	legacy.POST("/users/:username/restore", deprecated, adminLimit, admin, handler.Restore)
+	legacy.GET("/audit", middlewares.Deprecated(config.AuditResourcePath), adminLimit, readAudit, auditHandler.List)
+
+	provider := r.Group("", middlewares.RequestContext(), tenant)
+	provider.GET(config.OIDCDiscoveryPath, read, handler.Discovery)
+	provider.GET(config.OIDCJWKSPath, read, handler.JWKS)
+	provider.GET(config.OIDCAuthorizePath, read, session, handler.Authorize)
+	provider.POST(config.OIDCTokenPath, login, handler.Token)
+	provider.GET(config.OIDCUserInfoPath, read, handler.UserInfo)
+	provider.POST(config.OIDCUserInfoPath, read, handler.UserInfo)
+
+	docs := &openapi.Docs{}
+	r.GET(config.OpenAPIPath, docs.Spec)
+	r.GET(config.DocsPath, docs.UI)
 
-	api.GET("/search", handler.Search)
-	api.POST("/create", handler.Create)
-	api.DELETE("/delete", handler.Delete)
-	api.PATCH("/update", handler.Update)
-	api.PATCH("/change-password", handler.ChangePwd)
+	return docs.Load(r.Routes(), operations(), models.ErrorResponse{})
 }
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/middlewares"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type apiVersion struct {
+	name     string
+	register func(group *gin.RouterGroup)
+}
+
+func registerVersions(r *gin.Engine, versions []apiVersion, shared ...gin.HandlerFunc) {
+	supported := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		handlers := append(slices.Clone(shared), middlewares.APIVersion(version.name))
+		version.register(r.Group("/api/"+version.name, handlers...))
+		supported[version.name] = true
+	}
+
+	r.NoRoute(negotiateVersion(r, supported))
+}
+
+func negotiateVersion(r *gin.Engine, supported map[string]bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rest, found := strings.CutPrefix(ctx.Request.URL.Path, "/api/")
+		prefix, _, _ := strings.Cut(rest, "/")
+		if !found || supported[prefix] {
+			web.NewError(ctx, http.StatusNotFound, config.ErrRouteNotFound.Error())
+			return
+		}
+
+		version := middlewares.AcceptedVersion(ctx.GetHeader("Accept"))
+		if version == "" {
+			version = config.DefaultAPIVersion
+		}
+		if !supported[version] {
+			web.NewError(ctx, http.StatusNotAcceptable, config.ErrVersionNotAcceptable.Error())
+			return
+		}
+
+		ctx.Request.URL.Path = "/api/" + version + "/" + rest
+		r.HandleContext(ctx)
+		ctx.Abort()
+	}
+}
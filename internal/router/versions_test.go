@@ -0,0 +1,109 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRegisterVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	registerVersions(r, []apiVersion{
+		{
+			name: "v1",
+			register: func(group *gin.RouterGroup) {
+				group.GET("/users", func(ctx *gin.Context) { ctx.String(http.StatusOK, "v1") })
+			},
+		},
+		{
+			name: "v2",
+			register: func(group *gin.RouterGroup) {
+				group.GET("/users", func(ctx *gin.Context) { ctx.String(http.StatusOK, "v2") })
+			},
+		},
+	})
+
+	tests := []struct {
+		Name         string
+		Path         string
+		Accept       string
+		ExpectedCode int
+		ExpectedBody string
+	}{
+		{
+			Name:         "Path prefix v1",
+			Path:         "/api/v1/users",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: "v1",
+		},
+		{
+			Name:         "Path prefix v2",
+			Path:         "/api/v2/users",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: "v2",
+		},
+		{
+			Name:         "Unversioned defaults to v1",
+			Path:         "/api/users",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: "v1",
+		},
+		{
+			Name:         "Unversioned negotiated by Accept",
+			Path:         "/api/users",
+			Accept:       "application/vnd.go-manage.v2+json",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: "v2",
+		},
+		{
+			Name:         "Unsupported version",
+			Path:         "/api/users",
+			Accept:       "application/vnd.go-manage.v9+json",
+			ExpectedCode: http.StatusNotAcceptable,
+		},
+		{
+			Name:         "Path and Accept disagree",
+			Path:         "/api/v1/users",
+			Accept:       "application/vnd.go-manage.v2+json",
+			ExpectedCode: http.StatusNotAcceptable,
+		},
+		{
+			Name:         "Unknown versioned route",
+			Path:         "/api/v2/groups",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Name:         "Unknown unversioned route",
+			Path:         "/api/groups",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Name:         "Outside the API",
+			Path:         "/users",
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.Path, nil)
+			if tt.Accept != "" {
+				req.Header.Set("Accept", tt.Accept)
+			}
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			if tt.ExpectedBody != "" {
+				assert.Equal(t, tt.ExpectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func (us *UserServices) CreateAPIKey(ctx context.Context, request models.CreateAPIKeyRequest) (created models.APIKey, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.APIKey{}, checkErr
+	}
+
+	now := us.Repo.Now()
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return models.APIKey{}, config.ErrInvalidExpiry
+	}
+
+	prefix, secret, keyErr := apiKeyParts()
+	if keyErr != nil {
+		return models.APIKey{}, errors.New("error generating api key. Error: " + keyErr.Error())
+	}
+
+	scopes := slices.Clone(request.Scopes)
+	slices.Sort(scopes)
+
+	created = models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(request.Name),
+		Prefix:    prefix,
+		Key:       prefix + "_" + secret,
+		Scopes:    slices.Compact(scopes),
+		CreatedBy: AuditContextFrom(ctx).Actor,
+		CreatedAt: now,
+		ExpiresAt: request.ExpiresAt,
+	}
+	created.Hash = hashToken(created.Key)
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		keys := repository.APIKeyRepository{DB: repo.DB, Tenant: repo.Tenant}
+		if saveErr := keys.Save(config.SaveAPIKeyQuery, created); saveErr != nil {
+			return errors.New("error saving api key. Error: " + saveErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionCreateAPIKey, created.ID, map[string]models.FieldChange{
+			"name":       {Before: nil, After: created.Name},
+			"prefix":     {Before: nil, After: created.Prefix},
+			"scopes":     {Before: nil, After: created.Scopes},
+			"expires_at": {Before: nil, After: created.ExpiresAt},
+		})
+	})
+	if txErr != nil {
+		return models.APIKey{}, txErr
+	}
+
+	return created, nil
+}
+
+func (us *UserServices) ListAPIKeys(ctx context.Context) (keys []models.APIKey, err error) {
+	repo := us.apiKeys(ctx)
+	keys, listErr := repo.List(config.ListAPIKeysQuery)
+	if listErr != nil {
+		return nil, errors.New("error listing api keys. Error: " + listErr.Error())
+	}
+	return keys, nil
+}
+
+func (us *UserServices) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		keys := repository.APIKeyRepository{DB: repo.DB, Tenant: repo.Tenant}
+		now := repo.Now()
+
+		revoked, revokeErr := keys.Revoke(config.RevokeAPIKeyQuery, id, now)
+		if revokeErr != nil {
+			return errors.New("error revoking api key. Error: " + revokeErr.Error())
+		}
+		if !revoked {
+			return config.ErrAPIKeyNotFound
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionRevokeAPIKey, id, map[string]models.FieldChange{
+			"revoked_at": {Before: nil, After: now},
+		})
+	})
+}
+
+func (us *UserServices) AuthenticateAPIKey(ctx context.Context, raw string) (key models.APIKey, err error) {
+	prefix, found := apiKeyPrefix(raw)
+	if !found {
+		return models.APIKey{}, config.ErrUnauthorized
+	}
+
+	repo := us.apiKeys(ctx)
+	key, searchErr := repo.SearchByPrefix(config.SearchAPIKeyByPrefixQuery, prefix)
+	if searchErr != nil {
+		return models.APIKey{}, errors.New("error searching api key. Error: " + searchErr.Error())
+	}
+
+	now := us.Repo.Now()
+	if key.ID == "" || subtle.ConstantTimeCompare([]byte(hashToken(raw)), []byte(key.Hash)) != 1 || !key.Active(now) {
+		return models.APIKey{}, config.ErrUnauthorized
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= config.APIKeyTouchInterval {
+		if touchErr := repo.Touch(config.TouchAPIKeyQuery, key.ID, now); touchErr != nil {
+			return models.APIKey{}, errors.New("error recording api key use. Error: " + touchErr.Error())
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+func apiKeyParts() (string, string, error) {
+	prefix := make([]byte, config.APIKeyPrefixLength)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, config.APIKeySecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(prefix))
+	return config.APIKeyPrefix + encoded[:config.APIKeyPrefixLength], base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func apiKeyPrefix(raw string) (string, bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(raw), config.APIKeyPrefix)
+	if !found || len(rest) <= config.APIKeyPrefixLength || rest[config.APIKeyPrefixLength] != '_' {
+		return "", false
+	}
+	return config.APIKeyPrefix + rest[:config.APIKeyPrefixLength], true
+}
+
+func (us *UserServices) apiKeys(ctx context.Context) repository.APIKeyRepository {
+	return repository.APIKeyRepository{DB: us.Repo.DB, Tenant: TenantFrom(ctx)}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{Actor: config.AdminActor, RequestID: "request-1", IP: "10.0.0.1"})
+
+	past := config.TestTime.Add(-time.Hour)
+	future := config.TestTime.Add(24 * time.Hour)
+
+	test := []struct {
+		Name        string
+		Request     models.CreateAPIKeyRequest
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Request:     models.CreateAPIKeyRequest{Name: " nightly export ", Scopes: []string{"write", "read", "read"}, ExpiresAt: &future},
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestSaveAPIKeyQuery).
+					WithArgs(sqlmock.AnyArg(), "nightly export", sqlmock.AnyArg(), sqlmock.AnyArg(), "read,write", config.AdminActor,
+						config.TestTime, future, nil, nil, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.AdminActor, config.AuditActionCreateAPIKey, sqlmock.AnyArg(),
+						sqlmock.AnyArg(), "request-1", "10.0.0.1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Invalid scope",
+			Request:     models.CreateAPIKeyRequest{Name: "nightly export", Scopes: []string{"read", "superuser"}},
+			ExpectedErr: config.ErrInvalidScope,
+			MockAct: func() {
+			},
+		},
+		{
+			Name:        "Missing scopes",
+			Request:     models.CreateAPIKeyRequest{Name: "nightly export"},
+			ExpectedErr: config.ErrAllFieldsAreRequired,
+			MockAct: func() {
+			},
+		},
+		{
+			Name:        "Expiry in the past",
+			Request:     models.CreateAPIKeyRequest{Name: "nightly export", Scopes: []string{"read"}, ExpiresAt: &past},
+			ExpectedErr: config.ErrInvalidExpiry,
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			created, createErr := userService.CreateAPIKey(ctx, tt.Request)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, createErr, tt.ExpectedErr)
+				assert.Equal(t, models.APIKey{}, created)
+			} else {
+				assert.NoError(t, createErr)
+				assert.True(t, strings.HasPrefix(created.Key, created.Prefix+"_"))
+				assert.Len(t, created.Prefix, len(config.APIKeyPrefix)+config.APIKeyPrefixLength)
+				assert.Equal(t, hashToken(created.Key), created.Hash)
+				assert.Equal(t, []string{"read", "write"}, created.Scopes)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	raw := "gm_abcdefgh_secret"
+	keyRow := func(hash string, expiresAt, lastUsedAt, revokedAt any) {
+		mock.ExpectQuery(config.TestSearchAPIKeyByPrefixQuery).
+			WithArgs("gm_abcdefgh", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestAPIKeyColumns).
+				AddRow("key-1", "nightly export", "gm_abcdefgh", hash, "read", config.AdminActor, config.TestTime, expiresAt, lastUsedAt, revokedAt))
+	}
+
+	test := []struct {
+		Name        string
+		Key         string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success records use",
+			Key:         raw,
+			ExpectedErr: nil,
+			MockAct: func() {
+				keyRow(hashToken(raw), nil, nil, nil)
+				mock.ExpectExec(config.TestTouchAPIKeyQuery).
+					WithArgs(config.TestTime, "key-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			Name:        "Recently used",
+			Key:         raw,
+			ExpectedErr: nil,
+			MockAct: func() {
+				keyRow(hashToken(raw), nil, config.TestTime.Add(-time.Second), nil)
+			},
+		},
+		{
+			Name:        "Wrong secret",
+			Key:         "gm_abcdefgh_other",
+			ExpectedErr: config.ErrUnauthorized,
+			MockAct: func() {
+				keyRow(hashToken(raw), nil, nil, nil)
+			},
+		},
+		{
+			Name:        "Expired",
+			Key:         raw,
+			ExpectedErr: config.ErrUnauthorized,
+			MockAct: func() {
+				keyRow(hashToken(raw), config.TestTime, nil, nil)
+			},
+		},
+		{
+			Name:        "Revoked",
+			Key:         raw,
+			ExpectedErr: config.ErrUnauthorized,
+			MockAct: func() {
+				keyRow(hashToken(raw), nil, nil, config.TestTime.Add(-time.Hour))
+			},
+		},
+		{
+			Name:        "Unknown prefix",
+			Key:         raw,
+			ExpectedErr: config.ErrUnauthorized,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchAPIKeyByPrefixQuery).
+					WithArgs("gm_abcdefgh", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestAPIKeyColumns))
+			},
+		},
+		{
+			Name:        "Malformed key",
+			Key:         "gm_short",
+			ExpectedErr: config.ErrUnauthorized,
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			key, authErr := userService.AuthenticateAPIKey(&gin.Context{}, tt.Key)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, authErr, tt.ExpectedErr)
+				assert.Equal(t, models.APIKey{}, key)
+			} else {
+				assert.NoError(t, authErr)
+				assert.Equal(t, "key-1", key.ID)
+				assert.NotNil(t, key.LastUsedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRevokeAPIKeyService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{Actor: config.AdminActor})
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRevokeAPIKeyQuery).
+					WithArgs(config.TestTime, "key-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.AdminActor, config.AuditActionRevokeAPIKey, "key-1",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Not found",
+			ExpectedErr: config.ErrAPIKeyNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRevokeAPIKeyQuery).
+					WithArgs(config.TestTime, "key-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			revokeErr := userService.RevokeAPIKey(ctx, "key-1")
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, revokeErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, revokeErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditServices struct {
+	Repo repository.AuditRepository
+}
+
+func (as *AuditServices) ListEvents(ctx context.Context, filter models.AuditFilter) (events []models.AuditEvent, err error) {
+	if filter.Limit <= 0 {
+		filter.Limit = config.DefaultListLimit
+	}
+	if filter.Limit > config.MaxListLimit {
+		filter.Limit = config.MaxListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	filter.TenantID = TenantFrom(ctx)
+	events, listErr := as.Repo.List(config.ListAuditQuery, filter)
+	if listErr != nil {
+		return nil, errors.New("error listing audit events. Error: " + listErr.Error())
+	}
+
+	return events, nil
+}
+
+func AuditContextFrom(ctx context.Context) models.AuditContext {
+	if meta, ok := ctx.Value(config.AuditContextKey).(models.AuditContext); ok {
+		if meta.Actor == "" {
+			meta.Actor = config.AnonymousActor
+		}
+		return meta
+	}
+	return models.AuditContext{Actor: config.AnonymousActor}
+}
+
+func withActor(ctx context.Context, actor string) context.Context {
+	meta := AuditContextFrom(ctx)
+	meta.Actor = actor
+	return context.WithValue(ctx, config.AuditContextKey, meta)
+}
+
+func recordAudit(ctx context.Context, audit repository.AuditRepository, occurredAt time.Time, action, target string, changes map[string]models.FieldChange) error {
+	meta := AuditContextFrom(ctx)
+
+	event := models.AuditEvent{
+		ID:         uuid.New().String(),
+		OccurredAt: occurredAt,
+		Actor:      meta.Actor,
+		Action:     action,
+		Target:     target,
+		Changes:    changes,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+		TenantID:   TenantFrom(ctx),
+	}
+
+	if saveErr := audit.Save(config.SaveAuditQuery, event); saveErr != nil {
+		return fmt.Errorf("%w. Error: %s", config.ErrRecordingAudit, saveErr.Error())
+	}
+	return nil
+}
+
+func userChanges(before, after models.User) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"name", before.Name, after.Name},
+		{"surname", before.Surname, after.Surname},
+		{"username", before.Username, after.Username},
+		{"email", before.Email, after.Email},
+	}
+	for _, field := range fields {
+		if field.before != field.after {
+			changes[field.name] = models.FieldChange{Before: nullable(field.before), After: nullable(field.after)}
+		}
+	}
+
+	if before.Password != after.Password {
+		changes["password"] = models.FieldChange{Before: config.RedactedValue, After: config.RedactedValue}
+	}
+
+	return changes
+}
+
+func nullable(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListEvents(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	auditService := AuditServices{Repo: repository.AuditRepository{DB: db}}
+
+	test := []struct {
+		Name          string
+		Filter        models.AuditFilter
+		ExpectedCount int
+		ExpectedErr   bool
+		MockAct       func()
+	}{
+		{
+			Name:          "Default limit",
+			Filter:        models.AuditFilter{},
+			ExpectedCount: 1,
+			ExpectedErr:   false,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery).
+					WithArgs(config.DefaultTenant, config.DefaultListLimit, 0).
+					WillReturnRows(mock.NewRows(config.TestAuditColumns).
+						AddRow("1", config.TestTime, "admin", config.AuditActionDelete, "johndoe", nil, "request-1", "127.0.0.1"))
+			},
+		},
+		{
+			Name:          "Error",
+			Filter:        models.AuditFilter{Limit: 500},
+			ExpectedCount: 0,
+			ExpectedErr:   true,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListAuditQuery).
+					WithArgs(config.DefaultTenant, config.MaxListLimit, 0).
+					WillReturnError(errors.New("list error"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			events, listErr := auditService.ListEvents(ctx, tt.Filter)
+
+			if tt.ExpectedErr {
+				assert.Error(t, listErr)
+			} else {
+				assert.NoError(t, listErr)
+			}
+			assert.Equal(t, tt.ExpectedCount, len(events))
+		})
+	}
+}
+
+func TestMutationAuditEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	ginCtx := &gin.Context{}
+	ginCtx.Set(config.AuditContextKey, models.AuditContext{Actor: "admin", RequestID: "request-1", IP: "10.0.0.1"})
+
+	surname := "Doecito"
+
+	test := []struct {
+		Name        string
+		ExpectedErr bool
+		MockAct     func()
+	}{
+		{
+			Name:        "Recorded in the same transaction",
+			ExpectedErr: false,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, "admin", config.AuditActionUpdate, "johndoe",
+						`{"surname":{"before":"Doe","after":"Doecito"}}`, "request-1", "10.0.0.1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Audit failure rolls back the mutation",
+			ExpectedErr: true,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnError(errors.New("audit error"))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			_, updateErr := userService.UpdateUser(ginCtx, "johndoe", 1, models.UpdateUserRequest{Surname: &surname})
+
+			if tt.ExpectedErr {
+				assert.ErrorIs(t, updateErr, config.ErrRecordingAudit)
+			} else {
+				assert.NoError(t, updateErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserChanges(t *testing.T) {
+	before := models.User{Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "hash1"}
+	after := models.User{Name: "John", Surname: "Doe", Username: "janedoe", Email: "johndoe@example.com", Password: "hash2"}
+
+	changes := userChanges(before, after)
+
+	assert.Equal(t, map[string]models.FieldChange{
+		"username": {Before: "johndoe", After: "janedoe"},
+		"password": {Before: config.RedactedValue, After: config.RedactedValue},
+	}, changes)
+}
+
+func TestAuditContextFrom(t *testing.T) {
+	assert.Equal(t, models.AuditContext{Actor: config.AnonymousActor}, AuditContextFrom(context.Background()))
+
+	ginCtx := &gin.Context{}
+	ginCtx.Set(config.AuditContextKey, models.AuditContext{RequestID: "request-1"})
+	assert.Equal(t, models.AuditContext{Actor: config.AnonymousActor, RequestID: "request-1"}, AuditContextFrom(ginCtx))
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (us *UserServices) CreateBackup(ctx context.Context) (backup models.Backup, err error) {
+	return us.createBackup(ctx, config.BackupFilePrefix)
+}
+
+func (us *UserServices) CreateRestorePoint(ctx context.Context) (backup models.Backup, err error) {
+	return us.createBackup(ctx, config.RestorePointFilePrefix)
+}
+
+func (us *UserServices) createBackup(ctx context.Context, prefix string) (models.Backup, error) {
+	dir := us.backupDir()
+	if mkdirErr := os.MkdirAll(dir, 0o700); mkdirErr != nil {
+		return models.Backup{}, errors.New("error creating backup directory. Error: " + mkdirErr.Error())
+	}
+
+	now := us.Repo.Now().UTC()
+	name := prefix + now.Format(config.BackupTimeLayout) + config.BackupFileExt
+	path := filepath.Join(dir, name)
+
+	if backupErr := data.Backup(us.DB, path); backupErr != nil {
+		return models.Backup{}, backupErr
+	}
+	if checkErr := data.CheckIntegrity(path); checkErr != nil {
+		os.Remove(path)
+		return models.Backup{}, checkErr
+	}
+
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return models.Backup{}, errors.New("error reading backup. Error: " + statErr.Error())
+	}
+	backup := models.Backup{Name: name, Size: info.Size(), CreatedAt: now}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		return recordAudit(ctx, audit, now, config.AuditActionBackup, name, nil)
+	})
+	if txErr != nil {
+		return models.Backup{}, txErr
+	}
+
+	if prefix != config.BackupFilePrefix {
+		return backup, nil
+	}
+	if pruneErr := us.pruneBackups(); pruneErr != nil {
+		return models.Backup{}, pruneErr
+	}
+
+	return backup, nil
+}
+
+func (us *UserServices) ListBackups(ctx context.Context) (backups []models.Backup, err error) {
+	entries, readErr := os.ReadDir(us.backupDir())
+	if errors.Is(readErr, os.ErrNotExist) {
+		return []models.Backup{}, nil
+	}
+	if readErr != nil {
+		return nil, errors.New("error listing backups. Error: " + readErr.Error())
+	}
+
+	backups = []models.Backup{}
+	for _, entry := range entries {
+		createdAt, ok := backupTime(entry.Name())
+		if entry.IsDir() || !ok {
+			continue
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return nil, errors.New("error listing backups. Error: " + infoErr.Error())
+		}
+		backups = append(backups, models.Backup{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+
+	slices.SortFunc(backups, func(a, b models.Backup) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return backups, nil
+}
+
+func (us *UserServices) BackupPath(name string) (string, error) {
+	if _, ok := backupTime(name); !ok || filepath.Base(name) != name {
+		return "", config.ErrBackupNotFound
+	}
+	path := filepath.Join(us.backupDir(), name)
+	if _, statErr := os.Stat(path); statErr != nil {
+		return "", config.ErrBackupNotFound
+	}
+	return path, nil
+}
+
+func (us *UserServices) StartBackups(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			backup, err := us.CreateBackup(withActor(ctx, config.SystemActor))
+			if err != nil {
+				log.Println(err.Error())
+			} else {
+				log.Printf("created backup %s", backup.Name)
+			}
+		}
+	}
+}
+
+func (us *UserServices) pruneBackups() error {
+	if us.BackupRetention <= 0 {
+		return nil
+	}
+
+	listed, listErr := us.ListBackups(context.Background())
+	if listErr != nil {
+		return listErr
+	}
+	backups := slices.DeleteFunc(listed, func(backup models.Backup) bool {
+		return !strings.HasPrefix(backup.Name, config.BackupFilePrefix)
+	})
+	for _, backup := range backups[min(us.BackupRetention, len(backups)):] {
+		if removeErr := os.Remove(filepath.Join(us.backupDir(), backup.Name)); removeErr != nil {
+			return errors.New("error removing old backup. Error: " + removeErr.Error())
+		}
+	}
+	return nil
+}
+
+func (us *UserServices) backupDir() string {
+	if us.BackupDir != "" {
+		return us.BackupDir
+	}
+	return config.DefaultBackupDir
+}
+
+func backupTime(name string) (time.Time, bool) {
+	for _, prefix := range []string{config.BackupFilePrefix, config.RestorePointFilePrefix} {
+		stamp, found := strings.CutPrefix(name, prefix)
+		if !found || !strings.HasSuffix(stamp, config.BackupFileExt) {
+			continue
+		}
+		createdAt, err := time.Parse(config.BackupTimeLayout, strings.TrimSuffix(stamp, config.BackupFileExt))
+		if err != nil {
+			return time.Time{}, false
+		}
+		return createdAt, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBackup(t *testing.T) {
+	dir := t.TempDir()
+	conn, err := data.Open(filepath.Join(dir, "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	now := config.TestTime
+	clock := func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	userService := UserServices{
+		DB:              conn,
+		Repo:            repository.UserRepository{DB: conn, Clock: clock},
+		BackupDir:       filepath.Join(dir, "backups"),
+		BackupRetention: 2,
+	}
+	ctx := withActor(context.Background(), config.AdminActor)
+
+	backups, listErr := userService.ListBackups(ctx)
+	assert.NoError(t, listErr)
+	assert.Empty(t, backups)
+
+	created := []models.Backup{}
+	for range 3 {
+		backup, backupErr := userService.CreateBackup(ctx)
+		assert.NoError(t, backupErr)
+		assert.Greater(t, backup.Size, int64(0))
+		assert.NoError(t, data.CheckIntegrity(filepath.Join(userService.BackupDir, backup.Name)))
+		created = append(created, backup)
+	}
+
+	backups, listErr = userService.ListBackups(ctx)
+	assert.NoError(t, listErr)
+	assert.Equal(t, []string{created[2].Name, created[1].Name}, names(backups))
+	assert.Equal(t, "users-20250101T120003.000Z.db", created[2].Name)
+
+	restorePoint, restoreErr := userService.CreateRestorePoint(ctx)
+	assert.NoError(t, restoreErr)
+	assert.Equal(t, "restore-20250101T120004.000Z.db", restorePoint.Name)
+	backups, _ = userService.ListBackups(ctx)
+	assert.Equal(t, []string{restorePoint.Name, created[2].Name, created[1].Name}, names(backups))
+
+	for range 2 {
+		backup, backupErr := userService.CreateBackup(ctx)
+		assert.NoError(t, backupErr)
+		created = append(created, backup)
+	}
+	backups, _ = userService.ListBackups(ctx)
+	assert.Equal(t, []string{created[4].Name, created[3].Name, restorePoint.Name}, names(backups))
+
+	var actor string
+	assert.NoError(t, conn.QueryRow(`SELECT actor FROM audit_events WHERE action = ? AND target = ?`, config.AuditActionBackup, created[0].Name).Scan(&actor))
+	assert.Equal(t, config.AdminActor, actor)
+}
+
+func TestBackupPath(t *testing.T) {
+	dir := t.TempDir()
+	userService := UserServices{BackupDir: dir}
+	name := "users-20250101T120000.000Z.db"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "restore-20250101T120000.000Z.db"), []byte{}, 0o600))
+
+	test := []struct {
+		Name         string
+		Backup       string
+		ExpectedPath string
+		ExpectedErr  error
+	}{
+		{Name: "Existing backup", Backup: name, ExpectedPath: filepath.Join(dir, name)},
+		{Name: "Missing backup", Backup: "users-20250101T130000.000Z.db", ExpectedErr: config.ErrBackupNotFound},
+		{Name: "Restore point", Backup: "restore-20250101T120000.000Z.db", ExpectedPath: filepath.Join(dir, "restore-20250101T120000.000Z.db")},
+		{Name: "Not a backup name", Backup: "users.db", ExpectedErr: config.ErrBackupNotFound},
+		{Name: "Path traversal", Backup: "../" + name, ExpectedErr: config.ErrBackupNotFound},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			path, err := userService.BackupPath(tt.Backup)
+			assert.Equal(t, tt.ExpectedPath, path)
+			assert.ErrorIs(t, err, tt.ExpectedErr)
+		})
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "users.db")
+	conn, err := data.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userService := UserServices{DB: conn, Repo: repository.UserRepository{DB: conn, Clock: config.TestClock}, BackupDir: dir}
+	backup, backupErr := userService.CreateBackup(context.Background())
+	assert.NoError(t, backupErr)
+
+	_, execErr := conn.Exec(`INSERT INTO users (id, name, surname, username, email, password, created_at, updated_at) VALUES ('1', 'John', 'Doe', 'johndoe', 'johndoe@example.com', 'hash', ?, ?)`, config.TestTime, config.TestTime)
+	assert.NoError(t, execErr)
+	conn.Close()
+
+	corrupt := filepath.Join(dir, "corrupt.db")
+	assert.NoError(t, os.WriteFile(corrupt, []byte("not a database"), 0o600))
+	assert.ErrorIs(t, data.Restore(corrupt, dbPath), config.ErrBackupCorrupt)
+	assert.ErrorIs(t, data.Restore(filepath.Join(dir, "missing.db"), dbPath), config.ErrBackupNotFound)
+
+	assert.NoError(t, data.Restore(filepath.Join(dir, backup.Name), dbPath))
+
+	conn, err = data.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var count int
+	assert.NoError(t, conn.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func names(backups []models.Backup) []string {
+	result := []string{}
+	for _, backup := range backups {
+		result = append(result, backup.Name)
+	}
+	return result
+}
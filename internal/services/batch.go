@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"slices"
+)
+
+func (us *UserServices) ExecuteBatch(ctx context.Context, request models.BatchRequest) (report models.BatchReport, err error) {
+	if request.Mode == "" {
+		request.Mode = config.BatchModeAtomic
+	}
+	if !slices.Contains(config.BatchModes, request.Mode) {
+		return models.BatchReport{}, config.ErrInvalidBatchMode
+	}
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.BatchReport{}, checkErr
+	}
+
+	atomic := request.Mode == config.BatchModeAtomic
+	report = models.BatchReport{Mode: request.Mode, Results: make([]models.BatchResult, len(request.Operations))}
+	created := make([]models.User, len(request.Operations))
+
+	invalid := false
+	for i, operation := range request.Operations {
+		report.Results[i] = models.BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+		created[i], report.Results[i].Err = us.prepareBatchOperation(operation)
+		invalid = invalid || report.Results[i].Err != nil
+	}
+
+	if atomic && invalid {
+		return finishBatch(report, false), nil
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		for i, operation := range request.Operations {
+			result := &report.Results[i]
+			if result.Err != nil {
+				continue
+			}
+
+			if atomic {
+				if result.User, result.Err = applyBatchOperation(ctx, repo, audit, operation, created[i]); result.Err != nil {
+					return errRollback
+				}
+				continue
+			}
+
+			if savepointErr := repo.Savepoint(config.SavepointQuery); savepointErr != nil {
+				return errors.New("error creating savepoint. Error: " + savepointErr.Error())
+			}
+			if result.User, result.Err = applyBatchOperation(ctx, repo, audit, operation, created[i]); result.Err != nil {
+				if rollbackErr := repo.Savepoint(config.RollbackSavepointQuery); rollbackErr != nil {
+					return errors.New("error rolling back savepoint. Error: " + rollbackErr.Error())
+				}
+			}
+			if releaseErr := repo.Savepoint(config.ReleaseSavepointQuery); releaseErr != nil {
+				return errors.New("error releasing savepoint. Error: " + releaseErr.Error())
+			}
+		}
+		return nil
+	})
+	if txErr != nil && !errors.Is(txErr, errRollback) {
+		return models.BatchReport{}, txErr
+	}
+
+	return finishBatch(report, txErr == nil), nil
+}
+
+func (us *UserServices) prepareBatchOperation(operation models.BatchOperation) (models.User, error) {
+	switch operation.Op {
+	case config.BatchOpCreate:
+		if operation.User == nil || operation.Patch != nil || operation.ID != "" {
+			return models.User{}, config.ErrInvalidBatchOp
+		}
+		if checkErr := us.checkNewUser(*operation.User); checkErr != nil {
+			return models.User{}, checkErr
+		}
+		return us.newUser(*operation.User)
+	case config.BatchOpUpdate:
+		if operation.ID == "" || operation.Patch == nil || operation.User != nil {
+			return models.User{}, config.ErrInvalidBatchOp
+		}
+		if operation.Version <= 0 {
+			return models.User{}, config.ErrPreconditionRequired
+		}
+		return models.User{}, validation.Struct(*operation.Patch)
+	case config.BatchOpDelete:
+		if operation.ID == "" || operation.Patch != nil || operation.User != nil {
+			return models.User{}, config.ErrInvalidBatchOp
+		}
+		if operation.Version <= 0 {
+			return models.User{}, config.ErrPreconditionRequired
+		}
+		return models.User{}, nil
+	default:
+		return models.User{}, config.ErrInvalidBatchOp
+	}
+}
+
+func applyBatchOperation(ctx context.Context, repo repository.UserRepository, audit repository.AuditRepository, operation models.BatchOperation, created models.User) (*models.User, error) {
+	if operation.Op == config.BatchOpCreate {
+		if saveErr := saveUser(ctx, repo, audit, created); saveErr != nil {
+			return nil, saveErr
+		}
+		return &created, nil
+	}
+
+	current, searchErr := repo.Search(config.SearchUserByIDQuery, operation.ID)
+	if searchErr != nil {
+		return nil, errors.New("error searching user. Error: " + searchErr.Error())
+	}
+	if current.ID == "" {
+		return nil, config.ErrUserNotFound
+	}
+
+	if operation.Op == config.BatchOpDelete {
+		return nil, deleteUser(ctx, repo, audit, current.Username, operation.Version)
+	}
+
+	updated, updateErr := updateUser(ctx, repo, audit, current, operation.Version, mergePatch(current, *operation.Patch))
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	return &updated, nil
+}
+
+func finishBatch(report models.BatchReport, committed bool) models.BatchReport {
+	report.Committed = committed
+	for i := range report.Results {
+		result := &report.Results[i]
+		if !committed && result.Err == nil {
+			result.Err = config.ErrBatchAborted
+			result.User = nil
+		}
+		if result.Err != nil {
+			result.Error = result.Err.Error()
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+	return report
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := UserServices{DB: db, Repo: repo, Hasher: &hasher}
+
+	surname := "Smith"
+	create := models.BatchOperation{Op: config.BatchOpCreate, User: &models.CreateUserRequest{
+		Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "Sup3r-Secret-pass",
+	}}
+	update := models.BatchOperation{Op: config.BatchOpUpdate, ID: "2", Version: 1, Patch: &models.UpdateUserRequest{Surname: &surname}}
+	remove := models.BatchOperation{Op: config.BatchOpDelete, ID: "2", Version: 1}
+
+	userRow := func() {
+		mock.ExpectQuery(config.TestSearchByIDQuery).
+			WithArgs("2", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("2", "Jane", "Doe", "janedoe", "janedoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+	}
+	created := func() {
+		mock.ExpectExec(config.TestSaveQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveHistoryQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	test := []struct {
+		Name              string
+		Request           models.BatchRequest
+		ExpectedCommitted bool
+		ExpectedErrors    []error
+		ExpectedErr       error
+		MockAct           func()
+	}{
+		{
+			Name:              "Atomic success",
+			Request:           models.BatchRequest{Operations: []models.BatchOperation{create, update}},
+			ExpectedCommitted: true,
+			ExpectedErrors:    []error{nil, nil},
+			MockAct: func() {
+				mock.ExpectBegin()
+				created()
+				userRow()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("Jane", "Smith", "janedoe", "janedoe@example.com", config.TestTime, "janedoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:              "Atomic failure rolls everything back",
+			Request:           models.BatchRequest{Operations: []models.BatchOperation{create, remove, update}},
+			ExpectedCommitted: false,
+			ExpectedErrors:    []error{config.ErrBatchAborted, config.ErrVersionMismatch, config.ErrBatchAborted},
+			MockAct: func() {
+				mock.ExpectBegin()
+				created()
+				userRow()
+				mock.ExpectExec(config.TestDeleteQuery).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:              "Atomic invalid operation touches nothing",
+			Request:           models.BatchRequest{Operations: []models.BatchOperation{create, {Op: config.BatchOpDelete, ID: "2"}}},
+			ExpectedCommitted: false,
+			ExpectedErrors:    []error{config.ErrBatchAborted, config.ErrPreconditionRequired},
+			MockAct:           func() {},
+		},
+		{
+			Name: "Best effort keeps the operations that succeed",
+			Request: models.BatchRequest{Mode: config.BatchModeBestEffort, Operations: []models.BatchOperation{
+				{Op: "disable", ID: "2"}, create, update,
+			}},
+			ExpectedCommitted: true,
+			ExpectedErrors:    []error{config.ErrInvalidBatchOp, config.ErrUserAlreadyExists, nil},
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestSavepointQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(config.TestSaveQuery).WillReturnError(config.ErrUserAlreadyExists)
+				mock.ExpectExec(config.TestRollbackToQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(config.TestReleaseQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(config.TestSavepointQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				userRow()
+				mock.ExpectExec(config.TestUpdateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestReleaseQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Invalid mode",
+			Request:     models.BatchRequest{Mode: "eventual", Operations: []models.BatchOperation{create}},
+			ExpectedErr: config.ErrInvalidBatchMode,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Empty batch",
+			Request:     models.BatchRequest{Operations: []models.BatchOperation{}},
+			ExpectedErr: config.ErrFieldLength,
+			MockAct:     func() {},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			report, batchErr := userService.ExecuteBatch(context.Background(), tt.Request)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, batchErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, batchErr)
+				assert.Equal(t, tt.ExpectedCommitted, report.Committed)
+				assert.Equal(t, len(tt.ExpectedErrors), len(report.Results))
+				for i, expected := range tt.ExpectedErrors {
+					if expected != nil {
+						assert.ErrorIs(t, report.Results[i].Err, expected)
+					} else {
+						assert.NoError(t, report.Results[i].Err)
+						assert.NotNil(t, report.Results[i].User)
+					}
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
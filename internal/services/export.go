@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+)
+
+func (us *UserServices) ExportUsers(ctx context.Context, filter models.UserFilter, write func(user models.User) error) (err error) {
+	if filter.Limit <= 0 {
+		filter.Limit = -1
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	repo := us.repo(ctx)
+	streamErr := repo.Stream(config.ListUsersQuery, filter, func(user models.User) error {
+		user.Password = ""
+		return write(user)
+	})
+	if streamErr != nil {
+		if errors.Is(streamErr, config.ErrInvalidSortField) || errors.Is(streamErr, config.ErrInvalidFilter) {
+			return streamErr
+		}
+		return errors.New("error exporting users. Error: " + streamErr.Error())
+	}
+
+	return nil
+}
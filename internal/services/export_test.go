@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	test := []struct {
+		Name          string
+		Filter        models.UserFilter
+		ExpectedUsers []string
+		ExpectedErr   error
+		MockAct       func()
+	}{
+		{
+			Name:          "Exports every user without password",
+			Filter:        models.UserFilter{Offset: -5},
+			ExpectedUsers: []string{"johndoe", "janedoe"},
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WithArgs(config.DefaultTenant, -1, 0).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime).
+						AddRow("2", "Jane", "Doe", "janedoe", "janedoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:        "Invalid sort field",
+			Filter:      models.UserFilter{Sort: "password"},
+			ExpectedErr: config.ErrInvalidSortField,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: fmt.Errorf("error exporting users. Error: database is locked"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WillReturnError(fmt.Errorf("database is locked"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			exported := []string{}
+			exportErr := userService.ExportUsers(context.Background(), tt.Filter, func(user models.User) error {
+				assert.Equal(t, "", user.Password)
+				exported = append(exported, user.Username)
+				return nil
+			})
+
+			if tt.ExpectedErr != nil {
+				assert.EqualError(t, exportErr, tt.ExpectedErr.Error())
+			} else {
+				assert.NoError(t, exportErr)
+				assert.Equal(t, tt.ExpectedUsers, exported)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
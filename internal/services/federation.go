@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/federation"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (us *UserServices) StartFederation(ctx context.Context, name string, user models.User) (redirect string, err error) {
+	provider, found := us.Providers[name]
+	if !found {
+		return "", config.ErrUnknownProvider
+	}
+
+	secrets := make([]string, 3)
+	for i := range secrets {
+		secret, secretErr := randomToken(config.FederationStateSize)
+		if secretErr != nil {
+			return "", errors.New("error generating login state. Error: " + secretErr.Error())
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	redirect, urlErr := provider.AuthCodeURL(ctx, state, nonce, oidc.Challenge(verifier))
+	if urlErr != nil {
+		return "", urlErr
+	}
+
+	now := us.Repo.Now()
+	states := us.federation(ctx)
+	saveErr := states.SaveState(config.SaveFederationStateQuery, models.FederationState{
+		Hash:         hashToken(state),
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       user.ID,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(config.FederationStateTTL),
+	})
+	if saveErr != nil {
+		return "", errors.New("error saving login state. Error: " + saveErr.Error())
+	}
+
+	return redirect, nil
+}
+
+func (us *UserServices) CompleteFederation(ctx context.Context, name string, callback models.FederationCallback) (result models.FederationResult, err error) {
+	provider, found := us.Providers[name]
+	if !found {
+		return models.FederationResult{}, config.ErrUnknownProvider
+	}
+	if callback.Error != "" {
+		return models.FederationResult{}, fmt.Errorf("%w: %s", config.ErrFederationDenied, callback.Error)
+	}
+	if callback.Code == "" || callback.State == "" {
+		return models.FederationResult{}, config.ErrInvalidLoginState
+	}
+
+	state, stateErr := us.consumeFederationState(ctx, name, callback.State)
+	if stateErr != nil {
+		return models.FederationResult{}, stateErr
+	}
+
+	idToken, exchangeErr := provider.Exchange(ctx, callback.Code, state.CodeVerifier)
+	if exchangeErr != nil {
+		return models.FederationResult{}, exchangeErr
+	}
+	now := us.Repo.Now()
+	profile, verifyErr := provider.VerifyIDToken(ctx, idToken, state.Nonce, now)
+	if verifyErr != nil {
+		return models.FederationResult{}, verifyErr
+	}
+
+	identities := us.federation(ctx)
+	identity, searchErr := identities.SearchIdentity(config.SearchIdentityQuery, profile.Issuer, profile.Subject)
+	if searchErr != nil {
+		return models.FederationResult{}, errors.New("error searching external identity. Error: " + searchErr.Error())
+	}
+
+	if state.UserID != "" {
+		linked, linkErr := us.linkIdentity(ctx, state.UserID, name, profile, identity, now)
+		if linkErr != nil {
+			return models.FederationResult{}, linkErr
+		}
+		return models.FederationResult{Linked: &linked}, nil
+	}
+
+	login, loginErr := us.federatedLogin(withActor(ctx, config.FederationActorPrefix+name), provider, profile, identity, now)
+	if loginErr != nil {
+		return models.FederationResult{}, loginErr
+	}
+	return models.FederationResult{Login: login}, nil
+}
+
+func (us *UserServices) ListIdentities(ctx context.Context, user models.User) (identities []models.ExternalIdentity, err error) {
+	repo := us.federation(ctx)
+	identities, listErr := repo.ListIdentities(config.ListIdentitiesQuery, user.ID)
+	if listErr != nil {
+		return nil, errors.New("error listing external identities. Error: " + listErr.Error())
+	}
+	return identities, nil
+}
+
+func (us *UserServices) UnlinkIdentity(ctx context.Context, user models.User, id string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		identities := repository.FederationRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		deleted, deleteErr := identities.DeleteIdentity(config.DeleteIdentityQuery, id, user.ID)
+		if deleteErr != nil {
+			return errors.New("error unlinking external identity. Error: " + deleteErr.Error())
+		}
+		if !deleted {
+			return config.ErrIdentityNotFound
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionUnlinkIdentity, user.Username, map[string]models.FieldChange{
+			"identity": {Before: id, After: nil},
+		})
+	})
+}
+
+func (us *UserServices) PurgeFederation(ctx context.Context) (err error) {
+	repo := us.federation(ctx)
+
+	if statesErr := repo.PurgeStates(config.PurgeFederationStatesQuery, us.Repo.Now()); statesErr != nil {
+		return errors.New("error purging login states. Error: " + statesErr.Error())
+	}
+	if identitiesErr := repo.PurgeIdentities(config.PurgeIdentitiesQuery); identitiesErr != nil {
+		return errors.New("error purging external identities. Error: " + identitiesErr.Error())
+	}
+	return nil
+}
+
+func (us *UserServices) consumeFederationState(ctx context.Context, name, raw string) (models.FederationState, error) {
+	var state models.FederationState
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		states := repository.FederationRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		var searchErr error
+		state, searchErr = states.SearchState(config.SearchFederationStateQuery, hashToken(raw), repo.Now())
+		if searchErr != nil {
+			return errors.New("error searching login state. Error: " + searchErr.Error())
+		}
+		if state.Hash == "" || state.Provider != name {
+			return config.ErrInvalidLoginState
+		}
+
+		deleted, deleteErr := states.DeleteState(config.DeleteFederationStateQuery, state.Hash)
+		if deleteErr != nil {
+			return errors.New("error consuming login state. Error: " + deleteErr.Error())
+		}
+		if !deleted {
+			return config.ErrInvalidLoginState
+		}
+		return nil
+	})
+	if txErr != nil {
+		return models.FederationState{}, txErr
+	}
+
+	return state, nil
+}
+
+func (us *UserServices) linkIdentity(ctx context.Context, userID, name string, profile federation.Profile, existing models.ExternalIdentity, now time.Time) (models.ExternalIdentity, error) {
+	if existing.ID != "" {
+		if existing.UserID != userID {
+			return models.ExternalIdentity{}, config.ErrIdentityLinked
+		}
+		return existing, nil
+	}
+
+	user, searchErr := us.SearchUserByID(ctx, userID)
+	if searchErr != nil {
+		return models.ExternalIdentity{}, searchErr
+	}
+	identity := newIdentity(user.ID, name, profile, now)
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		identities := repository.FederationRepository{DB: repo.DB, Tenant: repo.Tenant}
+		if saveErr := identities.SaveIdentity(config.SaveIdentityQuery, identity); saveErr != nil {
+			return errors.New("error linking external identity. Error: " + saveErr.Error())
+		}
+		return recordAudit(withActor(ctx, user.Username), audit, now, config.AuditActionLinkIdentity, user.Username, identityChanges(identity))
+	})
+	if txErr != nil {
+		return models.ExternalIdentity{}, txErr
+	}
+
+	return identity, nil
+}
+
+func (us *UserServices) federatedLogin(ctx context.Context, provider *federation.Provider, profile federation.Profile, identity models.ExternalIdentity, now time.Time) (models.LoginResult, error) {
+	if identity.ID == "" {
+		if !provider.Provision {
+			return models.LoginResult{}, config.ErrIdentityNotLinked
+		}
+		return us.provisionUser(ctx, provider.Name, profile, now)
+	}
+
+	repo := us.repo(ctx)
+	user, searchErr := repo.Search(config.SearchUserByIDQuery, identity.UserID)
+	if searchErr != nil {
+		return models.LoginResult{}, errors.New("error searching user. Error: " + searchErr.Error())
+	}
+	if user.ID == "" {
+		return models.LoginResult{}, config.ErrIdentityNotLinked
+	}
+
+	throttle, throttleErr := us.checkAccountThrottle(user, now)
+	if throttleErr != nil {
+		return models.LoginResult{}, throttleErr
+	}
+
+	return us.beginSession(ctx, user, throttle, now, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		identities := repository.FederationRepository{DB: repo.DB, Tenant: repo.Tenant}
+		if touchErr := identities.TouchIdentity(config.TouchIdentityQuery, identity.ID, now); touchErr != nil {
+			return errors.New("error recording external login. Error: " + touchErr.Error())
+		}
+		return nil
+	})
+}
+
+func (us *UserServices) provisionUser(ctx context.Context, name string, profile federation.Profile, now time.Time) (models.LoginResult, error) {
+	username := profile.Username
+	if username == "" {
+		username, _, _ = strings.Cut(profile.Email, "@")
+	}
+
+	secret, secretErr := randomToken(config.SessionTokenSize)
+	if secretErr != nil {
+		return models.LoginResult{}, errors.New("error generating password. Error: " + secretErr.Error())
+	}
+	request := models.CreateUserRequest{
+		Name:     profile.Name,
+		Surname:  profile.Surname,
+		Username: username,
+		Email:    profile.Email,
+		Password: secret,
+	}
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.LoginResult{}, fmt.Errorf("cannot provision a user from the %s claims: %w", name, checkErr)
+	}
+	if us.Exists(ctx, request.Username) {
+		return models.LoginResult{}, fmt.Errorf("%w; sign in and link the external identity instead", config.ErrUserAlreadyExists)
+	}
+
+	hashedPwd, hashErr := us.hasher().Hash(secret)
+	if hashErr != nil {
+		return models.LoginResult{}, hashErr
+	}
+	user := models.User{
+		ID:                uuid.New().String(),
+		Name:              request.Name,
+		Surname:           request.Surname,
+		Username:          request.Username,
+		Email:             request.Email,
+		Password:          hashedPwd,
+		Version:           1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		PasswordChangedAt: &now,
+	}
+	identity := newIdentity(user.ID, name, profile, now)
+	identity.LastLoginAt = &now
+
+	return us.beginSession(ctx, user, models.LoginThrottle{}, now, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		if createErr := repo.Save(config.SaveUserQuery, user); createErr != nil {
+			if errors.Is(createErr, config.ErrUserAlreadyExists) {
+				return fmt.Errorf("%w; sign in and link the external identity instead", createErr)
+			}
+			return errors.New("error creating user. Error: " + createErr.Error())
+		}
+		if historyErr := repo.SavePasswordHistory(config.SavePasswordHistoryQuery, user.ID, user.Password); historyErr != nil {
+			return errors.New("error saving password history. Error: " + historyErr.Error())
+		}
+		if auditErr := recordAudit(ctx, audit, now, config.AuditActionCreate, user.Username, userChanges(models.User{}, user)); auditErr != nil {
+			return auditErr
+		}
+
+		identities := repository.FederationRepository{DB: repo.DB, Tenant: repo.Tenant}
+		if saveErr := identities.SaveIdentity(config.SaveIdentityQuery, identity); saveErr != nil {
+			return errors.New("error linking external identity. Error: " + saveErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionLinkIdentity, user.Username, identityChanges(identity))
+	})
+}
+
+func newIdentity(userID, name string, profile federation.Profile, now time.Time) models.ExternalIdentity {
+	return models.ExternalIdentity{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Provider:  name,
+		Issuer:    profile.Issuer,
+		Subject:   profile.Subject,
+		Email:     profile.Email,
+		CreatedAt: now,
+	}
+}
+
+func identityChanges(identity models.ExternalIdentity) map[string]models.FieldChange {
+	return map[string]models.FieldChange{
+		"provider": {Before: nil, After: identity.Provider},
+		"issuer":   {Before: nil, After: identity.Issuer},
+		"subject":  {Before: nil, After: identity.Subject},
+	}
+}
+
+func (us *UserServices) federation(ctx context.Context) repository.FederationRepository {
+	return repository.FederationRepository{DB: us.Repo.DB, Tenant: TenantFrom(ctx)}
+}
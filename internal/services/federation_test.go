@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"go-manage/internal/federation"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteFederation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo, Providers: map[string]*federation.Provider{
+		"corp": {Name: "corp", Issuer: "https://idp.corp.example.com", ClientID: "go-manage"},
+	}}
+
+	test := []struct {
+		Name        string
+		Provider    string
+		Callback    models.FederationCallback
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Unknown provider",
+			Provider:    "other",
+			Callback:    models.FederationCallback{Code: "code", State: "state"},
+			ExpectedErr: config.ErrUnknownProvider,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Denied by provider",
+			Provider:    "corp",
+			Callback:    models.FederationCallback{Error: "access_denied", State: "state"},
+			ExpectedErr: config.ErrFederationDenied,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Missing code",
+			Provider:    "corp",
+			Callback:    models.FederationCallback{State: "state"},
+			ExpectedErr: config.ErrInvalidLoginState,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Unknown or expired state",
+			Provider:    "corp",
+			Callback:    models.FederationCallback{Code: "code", State: "state"},
+			ExpectedErr: config.ErrInvalidLoginState,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchFedStateQuery).
+					WithArgs(hashToken("state"), config.TestTime, config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestFedStateColumns))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "State issued for another provider",
+			Provider:    "corp",
+			Callback:    models.FederationCallback{Code: "code", State: "state"},
+			ExpectedErr: config.ErrInvalidLoginState,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchFedStateQuery).
+					WithArgs(hashToken("state"), config.TestTime, config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestFedStateColumns).
+						AddRow(hashToken("state"), "partner", "nonce", "verifier", "", config.TestTime, config.TestTime.Add(config.FederationStateTTL)))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			_, completeErr := userService.CompleteFederation(context.Background(), tt.Provider, tt.Callback)
+
+			assert.ErrorIs(t, completeErr, tt.ExpectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+	user := models.User{ID: "user-1", Username: "johndoe"}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteIdentityQuery).
+					WithArgs("identity-1", "user-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionUnlinkIdentity, "johndoe",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Not found",
+			ExpectedErr: config.ErrIdentityNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteIdentityQuery).
+					WithArgs("identity-1", "user-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			unlinkErr := userService.UnlinkIdentity(context.Background(), user, "identity-1")
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, unlinkErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, unlinkErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
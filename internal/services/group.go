@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func (us *UserServices) CreateGroup(ctx context.Context, request models.CreateGroupRequest) (created models.Group, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.Group{}, checkErr
+	}
+
+	now := us.Repo.Now()
+	created = models.Group{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(request.Name),
+		Description: strings.TrimSpace(request.Description),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		groups := repository.GroupRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		if request.ParentID != nil && *request.ParentID != "" {
+			if parentErr := requireGroup(groups, *request.ParentID); parentErr != nil {
+				return parentErr
+			}
+			created.ParentID = request.ParentID
+		}
+
+		if saveErr := groups.Save(config.SaveGroupQuery, created); saveErr != nil {
+			if errors.Is(saveErr, config.ErrGroupAlreadyExists) {
+				return saveErr
+			}
+			return errors.New("error saving group. Error: " + saveErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionCreateGroup, created.ID, groupChanges(models.Group{}, created))
+	})
+	if txErr != nil {
+		return models.Group{}, txErr
+	}
+
+	return created, nil
+}
+
+func (us *UserServices) SearchGroup(ctx context.Context, id string) (group models.Group, err error) {
+	repo := us.groups(ctx)
+	group, searchErr := repo.Search(config.SearchGroupQuery, id)
+	if searchErr != nil {
+		return models.Group{}, errors.New("error searching group. Error: " + searchErr.Error())
+	}
+	if group.ID == "" {
+		return models.Group{}, config.ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (us *UserServices) ListGroups(ctx context.Context) (groups []models.Group, err error) {
+	repo := us.groups(ctx)
+	groups, listErr := repo.List(config.ListGroupsQuery)
+	if listErr != nil {
+		return nil, errors.New("error listing groups. Error: " + listErr.Error())
+	}
+	return groups, nil
+}
+
+func (us *UserServices) UpdateGroup(ctx context.Context, id string, request models.UpdateGroupRequest) (updated models.Group, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.Group{}, checkErr
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		groups := repository.GroupRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		current, searchErr := groups.Search(config.SearchGroupQuery, id)
+		if searchErr != nil {
+			return errors.New("error searching group. Error: " + searchErr.Error())
+		}
+		if current.ID == "" {
+			return config.ErrGroupNotFound
+		}
+
+		updated = current
+		if request.Name != nil {
+			updated.Name = strings.TrimSpace(*request.Name)
+		}
+		if request.Description != nil {
+			updated.Description = strings.TrimSpace(*request.Description)
+		}
+		if request.ParentID != nil {
+			updated.ParentID = nil
+			if *request.ParentID != "" {
+				if parentErr := requireGroup(groups, *request.ParentID); parentErr != nil {
+					return parentErr
+				}
+				cycle, cycleErr := groups.InSubtree(config.InSubtreeQuery, id, *request.ParentID)
+				if cycleErr != nil {
+					return errors.New("error checking group hierarchy. Error: " + cycleErr.Error())
+				}
+				if cycle {
+					return config.ErrGroupCycle
+				}
+				updated.ParentID = request.ParentID
+			}
+		}
+
+		changes := groupChanges(current, updated)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		updated.UpdatedAt = repo.Now()
+		if updateErr := groups.Update(config.UpdateGroupQuery, updated); updateErr != nil {
+			if errors.Is(updateErr, config.ErrGroupAlreadyExists) {
+				return updateErr
+			}
+			return errors.New("error updating group. Error: " + updateErr.Error())
+		}
+		return recordAudit(ctx, audit, updated.UpdatedAt, config.AuditActionUpdateGroup, id, changes)
+	})
+	if txErr != nil {
+		return models.Group{}, txErr
+	}
+
+	return updated, nil
+}
+
+func (us *UserServices) DeleteGroup(ctx context.Context, id string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		groups := repository.GroupRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		current, searchErr := groups.Search(config.SearchGroupQuery, id)
+		if searchErr != nil {
+			return errors.New("error searching group. Error: " + searchErr.Error())
+		}
+		if current.ID == "" {
+			return config.ErrGroupNotFound
+		}
+
+		deleted, deleteErr := groups.Delete(config.DeleteGroupQuery, id)
+		if deleteErr != nil {
+			return errors.New("error deleting group. Error: " + deleteErr.Error())
+		}
+		if !deleted {
+			return config.ErrGroupHasSubgroups
+		}
+		if membersErr := groups.DeleteMembers(config.DeleteGroupMembersQuery, id); membersErr != nil {
+			return errors.New("error deleting group members. Error: " + membersErr.Error())
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionDeleteGroup, id, groupChanges(current, models.Group{}))
+	})
+}
+
+func (us *UserServices) SetGroupMember(ctx context.Context, groupID, userID string, request models.GroupMemberRequest) (member models.GroupMember, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.GroupMember{}, checkErr
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		groups := repository.GroupRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		group, searchErr := groups.Search(config.SearchGroupQuery, groupID)
+		if searchErr != nil {
+			return errors.New("error searching group. Error: " + searchErr.Error())
+		}
+		if group.ID == "" {
+			return config.ErrGroupNotFound
+		}
+
+		user, userErr := repo.Search(config.SearchUserByIDQuery, userID)
+		if userErr != nil {
+			return errors.New("error searching user. Error: " + userErr.Error())
+		}
+		if user.ID == "" {
+			return config.ErrUserNotFound
+		}
+
+		existing, memberErr := groups.SearchMember(config.SearchGroupMemberQuery, groupID, userID)
+		if memberErr != nil {
+			return errors.New("error searching group member. Error: " + memberErr.Error())
+		}
+		if existing.Role == request.Role {
+			member = existing
+			return nil
+		}
+
+		now := repo.Now()
+		member = models.GroupMember{GroupID: groupID, UserID: user.ID, Username: user.Username, Role: request.Role, CreatedAt: now}
+		if existing.UserID != "" {
+			member.CreatedAt = existing.CreatedAt
+		}
+		if saveErr := groups.SaveMember(config.SaveGroupMemberQuery, member); saveErr != nil {
+			return errors.New("error saving group member. Error: " + saveErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionSetMember, groupID, map[string]models.FieldChange{
+			"user_id": {Before: nullable(existing.UserID), After: member.UserID},
+			"role":    {Before: nullable(existing.Role), After: member.Role},
+		})
+	})
+	if txErr != nil {
+		return models.GroupMember{}, txErr
+	}
+
+	return member, nil
+}
+
+func (us *UserServices) RemoveGroupMember(ctx context.Context, groupID, userID string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		groups := repository.GroupRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		if groupErr := requireGroup(groups, groupID); groupErr != nil {
+			return groupErr
+		}
+
+		removed, deleteErr := groups.DeleteMember(config.DeleteGroupMemberQuery, groupID, userID)
+		if deleteErr != nil {
+			return errors.New("error removing group member. Error: " + deleteErr.Error())
+		}
+		if !removed {
+			return config.ErrMemberNotFound
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionRemoveMember, groupID, map[string]models.FieldChange{
+			"user_id": {Before: userID, After: nil},
+		})
+	})
+}
+
+func (us *UserServices) ListGroupMembers(ctx context.Context, groupID string, nested bool) (members []models.GroupMember, err error) {
+	if _, searchErr := us.SearchGroup(ctx, groupID); searchErr != nil {
+		return nil, searchErr
+	}
+
+	query := config.ListGroupMembersQuery
+	if nested {
+		query = config.ListNestedMembersQuery
+	}
+
+	repo := us.groups(ctx)
+	members, listErr := repo.ListMembers(query, groupID)
+	if listErr != nil {
+		return nil, errors.New("error listing group members. Error: " + listErr.Error())
+	}
+	return members, nil
+}
+
+func (us *UserServices) ListUserGroups(ctx context.Context, userID string, nested bool) (memberships []models.Membership, err error) {
+	if _, searchErr := us.SearchUserByID(ctx, userID); searchErr != nil {
+		return nil, searchErr
+	}
+
+	query := config.ListMembershipsQuery
+	if nested {
+		query = config.ListNestedMembershipQuery
+	}
+
+	repo := us.groups(ctx)
+	memberships, listErr := repo.ListMemberships(query, userID)
+	if listErr != nil {
+		return nil, errors.New("error listing user groups. Error: " + listErr.Error())
+	}
+	return memberships, nil
+}
+
+func (us *UserServices) PurgeGroups(ctx context.Context) (err error) {
+	repo := us.groups(ctx)
+	if purgeErr := repo.PurgeMembers(config.PurgeGroupMembersQuery); purgeErr != nil {
+		return errors.New("error purging group members. Error: " + purgeErr.Error())
+	}
+	return nil
+}
+
+func requireGroup(groups repository.GroupRepository, id string) error {
+	group, searchErr := groups.Search(config.SearchGroupQuery, id)
+	if searchErr != nil {
+		return errors.New("error searching group. Error: " + searchErr.Error())
+	}
+	if group.ID == "" {
+		return config.ErrGroupNotFound
+	}
+	return nil
+}
+
+func groupChanges(before, after models.Group) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"name", before.Name, after.Name},
+		{"description", before.Description, after.Description},
+		{"parent_id", stringValue(before.ParentID), stringValue(after.ParentID)},
+	}
+	for _, field := range fields {
+		if field.before != field.after {
+			changes[field.name] = models.FieldChange{Before: nullable(field.before), After: nullable(field.after)}
+		}
+	}
+
+	return changes
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func (us *UserServices) groups(ctx context.Context) repository.GroupRepository {
+	return repository.GroupRepository{DB: us.Repo.DB, Tenant: TenantFrom(ctx)}
+}
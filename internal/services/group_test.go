@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateGroup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	groupRow := func(id, name, parentID any) func() {
+		return func() {
+			mock.ExpectQuery(config.TestSearchGroupQuery).
+				WithArgs(id, config.DefaultTenant).
+				WillReturnRows(sqlmock.NewRows(config.TestGroupColumns).
+					AddRow(id, name, "", parentID, config.TestTime, config.TestTime))
+		}
+	}
+	name := func(value string) *string { return &value }
+
+	test := []struct {
+		Name        string
+		Request     models.UpdateGroupRequest
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:    "Rename",
+			Request: models.UpdateGroupRequest{Name: name("Platform Engineering")},
+			MockAct: func() {
+				mock.ExpectBegin()
+				groupRow("group-2", "Platform", "group-1")()
+				mock.ExpectExec(config.TestUpdateGroupQuery).
+					WithArgs("Platform Engineering", "", "group-1", config.TestTime, "group-2", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionUpdateGroup, "group-2",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:    "Unchanged",
+			Request: models.UpdateGroupRequest{Name: name("Platform")},
+			MockAct: func() {
+				mock.ExpectBegin()
+				groupRow("group-2", "Platform", "group-1")()
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Nested under a subgroup",
+			Request:     models.UpdateGroupRequest{ParentID: name("group-3")},
+			ExpectedErr: config.ErrGroupCycle,
+			MockAct: func() {
+				mock.ExpectBegin()
+				groupRow("group-2", "Platform", "group-1")()
+				groupRow("group-3", "Databases", "group-2")()
+				mock.ExpectQuery(config.TestInSubtreeQuery).
+					WithArgs("group-2", "group-3").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "Not found",
+			Request:     models.UpdateGroupRequest{Name: name("Platform")},
+			ExpectedErr: config.ErrGroupNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WithArgs("group-2", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestGroupColumns))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "Blank name",
+			Request:     models.UpdateGroupRequest{Name: name("  ")},
+			ExpectedErr: config.ErrFieldRequired,
+			MockAct:     func() {},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			_, updateErr := userService.UpdateGroup(context.Background(), "group-2", tt.Request)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, updateErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, updateErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteGroupService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WithArgs("group-1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestGroupColumns).
+						AddRow("group-1", "Acme", "", nil, config.TestTime, config.TestTime))
+				mock.ExpectExec(config.TestDeleteGroupQuery).
+					WithArgs("group-1", "group-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestDeleteGroupMembersQuery).
+					WithArgs("group-1").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionDeleteGroup, "group-1",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Has subgroups",
+			ExpectedErr: config.ErrGroupHasSubgroups,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchGroupQuery).
+					WithArgs("group-1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestGroupColumns).
+						AddRow("group-1", "Acme", "", nil, config.TestTime, config.TestTime))
+				mock.ExpectExec(config.TestDeleteGroupQuery).
+					WithArgs("group-1", "group-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			deleteErr := userService.DeleteGroup(context.Background(), "group-1")
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, deleteErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, deleteErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/importer"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var errRollback = errors.New("rollback")
+
+type importRow struct {
+	row    int
+	user   models.User
+	hashed bool
+}
+
+type importBatch struct {
+	created          int
+	updated          int
+	skipped          int
+	passwordsIgnored int
+	aborted          bool
+	errors           []models.ImportError
+}
+
+func (us *UserServices) ImportUsers(ctx context.Context, reader importer.Reader, options models.ImportOptions) (report models.ImportReport, err error) {
+	if options.OnConflict == "" {
+		options.OnConflict = config.ImportConflictSkip
+	}
+	if !slices.Contains(config.ImportConflictModes, options.OnConflict) {
+		return models.ImportReport{}, config.ErrInvalidConflictMode
+	}
+
+	report = models.ImportReport{DryRun: options.DryRun, OnConflict: options.OnConflict, Errors: []models.ImportError{}}
+	seenUsernames := map[string]bool{}
+	seenEmails := map[string]bool{}
+	batch := []importRow{}
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, flushErr := us.importBatch(ctx, batch, options)
+		batch = batch[:0]
+		if flushErr != nil {
+			return flushErr
+		}
+
+		report.Created += result.created
+		report.Updated += result.updated
+		report.Skipped += result.skipped
+		report.PasswordsIgnored += result.passwordsIgnored
+		report.Failed += len(result.errors)
+		report.Errors = append(report.Errors, result.errors...)
+		report.Aborted = report.Aborted || result.aborted
+		return nil
+	}
+
+	for !report.Aborted {
+		record, nextErr := reader.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		if nextErr != nil {
+			return models.ImportReport{}, nextErr
+		}
+		report.Total++
+
+		if rowErr := us.checkImportRecord(record, seenUsernames, seenEmails); rowErr != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, models.ImportError{Row: record.Row, Username: record.Request.Username, Error: rowErr.Error()})
+			continue
+		}
+
+		batch = append(batch, importRow{row: record.Row, user: models.User{
+			Name:     record.Request.Name,
+			Surname:  record.Request.Surname,
+			Username: record.Request.Username,
+			Email:    record.Request.Email,
+			Password: record.Request.Password,
+		}})
+		if len(batch) >= us.importBatchSize() {
+			if flushErr := flush(); flushErr != nil {
+				return models.ImportReport{}, flushErr
+			}
+		}
+	}
+
+	if !report.Aborted {
+		if flushErr := flush(); flushErr != nil {
+			return models.ImportReport{}, flushErr
+		}
+	}
+
+	return report, nil
+}
+
+func (us *UserServices) checkImportRecord(record importer.Record, seenUsernames, seenEmails map[string]bool) error {
+	if record.Err != nil {
+		return record.Err
+	}
+	if checkErr := validation.Struct(record.Request); checkErr != nil {
+		return checkErr
+	}
+	if checkErr := us.passwords().Check(record.Request.Password, record.Request.Username, record.Request.Email); checkErr != nil {
+		return checkErr
+	}
+
+	email := strings.ToLower(record.Request.Email)
+	if seenUsernames[record.Request.Username] || seenEmails[email] {
+		return config.ErrDuplicateImportRow
+	}
+	seenUsernames[record.Request.Username] = true
+	seenEmails[email] = true
+	return nil
+}
+
+func (us *UserServices) importBatch(ctx context.Context, rows []importRow, options models.ImportOptions) (importBatch, error) {
+	for i := range rows {
+		if options.DryRun || us.Exists(ctx, rows[i].user.Username) {
+			continue
+		}
+		hashedPwd, hashErr := us.hasher().Hash(rows[i].user.Password)
+		if hashErr != nil {
+			return importBatch{}, hashErr
+		}
+		rows[i].user.Password = hashedPwd
+		rows[i].hashed = true
+	}
+
+	var result importBatch
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		result = importBatch{}
+		for _, row := range rows {
+			rowErr := us.importRow(ctx, repo, audit, row, options, &result)
+			if rowErr == nil {
+				continue
+			}
+			if !errors.Is(rowErr, config.ErrUserAlreadyExists) {
+				return rowErr
+			}
+
+			result.errors = append(result.errors, models.ImportError{Row: row.row, Username: row.user.Username, Error: rowErr.Error()})
+			if options.OnConflict == config.ImportConflictFail {
+				result.aborted = true
+				return errRollback
+			}
+		}
+		if options.DryRun {
+			return errRollback
+		}
+		return nil
+	})
+	if txErr != nil && !errors.Is(txErr, errRollback) {
+		return importBatch{}, txErr
+	}
+
+	if result.aborted {
+		result.created, result.updated, result.skipped, result.passwordsIgnored = 0, 0, 0, 0
+	}
+	return result, nil
+}
+
+func (us *UserServices) importRow(ctx context.Context, repo repository.UserRepository, audit repository.AuditRepository, row importRow, options models.ImportOptions, result *importBatch) error {
+	current, searchErr := repo.Search(config.SearchUserQuery, row.user.Username)
+	if searchErr != nil {
+		return errors.New("error searching user. Error: " + searchErr.Error())
+	}
+
+	if current.ID != "" {
+		switch options.OnConflict {
+		case config.ImportConflictFail:
+			return config.ErrUserAlreadyExists
+		case config.ImportConflictSkip:
+			result.skipped++
+			return nil
+		}
+
+		result.passwordsIgnored++
+		merged := current
+		merged.Name = row.user.Name
+		merged.Surname = row.user.Surname
+		merged.Email = row.user.Email
+		if merged == current {
+			result.skipped++
+			return nil
+		}
+
+		if _, updateErr := updateUser(ctx, repo, audit, current, current.Version, merged); updateErr != nil {
+			return updateErr
+		}
+		result.updated++
+		return nil
+	}
+
+	now := repo.Now()
+	user := row.user
+	user.ID = uuid.New().String()
+	user.Version = 1
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.PasswordChangedAt = &now
+
+	if options.DryRun {
+		user.Password = uuid.New().String()
+	} else if !row.hashed {
+		hashedPwd, hashErr := us.hasher().Hash(user.Password)
+		if hashErr != nil {
+			return hashErr
+		}
+		user.Password = hashedPwd
+	}
+
+	if saveErr := saveUser(ctx, repo, audit, user); saveErr != nil {
+		return saveErr
+	}
+	result.created++
+	return nil
+}
+
+func (us *UserServices) importBatchSize() int {
+	if us.ImportBatchSize > 0 {
+		return us.ImportBatchSize
+	}
+	return config.DefaultImportBatchSize
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/importer"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := UserServices{DB: db, Repo: repo, Hasher: &hasher}
+
+	john := `{"name":"John","surname":"Doe","username":"johndoe","email":"johndoe@example.com","password":"Sup3r-Secret-pass"}`
+	jane := `{"name":"Jane","surname":"Doe","username":"janedoe","email":"janedoe@example.com","password":"Sup3r-Secret-pass"}`
+
+	missing := func(username string) {
+		mock.ExpectQuery(config.TestSearchQuery).
+			WithArgs(username, config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+	}
+	existing := func(username string) {
+		mock.ExpectQuery(config.TestSearchQuery).
+			WithArgs(username, config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("1", "Old", "Name", username, username+"@example.com", "hash", 3, config.TestTime, config.TestTime, nil, config.TestTime))
+	}
+	created := func() {
+		mock.ExpectExec(config.TestSaveQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveHistoryQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionCreate, sqlmock.AnyArg(),
+				sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	test := []struct {
+		Name           string
+		Body           string
+		Options        models.ImportOptions
+		ExpectedReport models.ImportReport
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Create and skip existing",
+			Body:           john + "\n" + jane,
+			ExpectedReport: models.ImportReport{OnConflict: config.ImportConflictSkip, Total: 2, Created: 1, Skipped: 1},
+			MockAct: func() {
+				missing("johndoe")
+				existing("janedoe")
+				mock.ExpectBegin()
+				missing("johndoe")
+				created()
+				existing("janedoe")
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:           "Update existing",
+			Body:           jane,
+			Options:        models.ImportOptions{OnConflict: config.ImportConflictUpdate},
+			ExpectedReport: models.ImportReport{OnConflict: config.ImportConflictUpdate, Total: 1, Updated: 1, PasswordsIgnored: 1},
+			MockAct: func() {
+				existing("janedoe")
+				mock.ExpectBegin()
+				existing("janedoe")
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("Jane", "Doe", "janedoe", "janedoe@example.com", config.TestTime, "janedoe", 3, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionUpdate, "janedoe",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:    "Fail on conflict rolls the batch back",
+			Body:    john + "\n" + jane,
+			Options: models.ImportOptions{OnConflict: config.ImportConflictFail},
+			ExpectedReport: models.ImportReport{OnConflict: config.ImportConflictFail, Total: 2, Failed: 1, Aborted: true,
+				Errors: []models.ImportError{{Row: 2, Username: "janedoe", Error: config.ErrUserAlreadyExists.Error()}}},
+			MockAct: func() {
+				missing("johndoe")
+				existing("janedoe")
+				mock.ExpectBegin()
+				missing("johndoe")
+				created()
+				existing("janedoe")
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:           "Dry run",
+			Body:           john,
+			Options:        models.ImportOptions{DryRun: true},
+			ExpectedReport: models.ImportReport{DryRun: true, OnConflict: config.ImportConflictSkip, Total: 1, Created: 1},
+			MockAct: func() {
+				mock.ExpectBegin()
+				missing("johndoe")
+				created()
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name: "Invalid rows",
+			Body: john + "\n" + `{"name":"John"}` + "\n" + john + "\n" + strings.Replace(jane, "Sup3r-Secret-pass", "short", 1),
+			ExpectedReport: models.ImportReport{OnConflict: config.ImportConflictSkip, Total: 4, Created: 1, Failed: 3,
+				Errors: []models.ImportError{
+					{Row: 2, Error: config.ErrAllFieldsAreRequired.Error() + ": surname"},
+					{Row: 3, Username: "johndoe", Error: config.ErrDuplicateImportRow.Error()},
+					{Row: 4, Username: "janedoe", Error: "invalid password: must be at least 8 characters"},
+				}},
+			MockAct: func() {
+				missing("johndoe")
+				mock.ExpectBegin()
+				missing("johndoe")
+				created()
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Invalid conflict mode",
+			Body:        john,
+			Options:     models.ImportOptions{OnConflict: "replace"},
+			ExpectedErr: config.ErrInvalidConflictMode,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Error",
+			Body:        john,
+			ExpectedErr: fmt.Errorf("error searching user. Error: database is locked"),
+			MockAct: func() {
+				missing("johndoe")
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchQuery).
+					WillReturnError(fmt.Errorf("database is locked"))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			reader, readerErr := importer.NewReader(config.NDJSONMediaType, strings.NewReader(tt.Body))
+			if readerErr != nil {
+				t.Fatal(readerErr)
+			}
+			report, importErr := userService.ImportUsers(context.Background(), reader, tt.Options)
+
+			if tt.ExpectedErr != nil {
+				assert.EqualError(t, importErr, tt.ExpectedErr.Error())
+			} else {
+				assert.NoError(t, importErr)
+				if tt.ExpectedReport.Errors == nil {
+					tt.ExpectedReport.Errors = []models.ImportError{}
+				}
+				assert.Equal(t, tt.ExpectedReport, report)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/totp"
+	"go-manage/internal/validation"
+	"strings"
+	"time"
+)
+
+func (us *UserServices) EnrollTOTP(ctx context.Context, user models.User) (enrollment models.TOTPEnrollment, err error) {
+	mfa := us.mfa()
+	current, searchErr := mfa.SearchTOTP(config.SearchTOTPQuery, user.ID)
+	if searchErr != nil {
+		return models.TOTPEnrollment{}, errors.New("error searching two-factor secret. Error: " + searchErr.Error())
+	}
+	if current.ConfirmedAt != nil {
+		return models.TOTPEnrollment{}, config.ErrMFAAlreadyEnabled
+	}
+
+	secret, secretErr := totp.GenerateSecret()
+	if secretErr != nil {
+		return models.TOTPEnrollment{}, errors.New("error generating two-factor secret. Error: " + secretErr.Error())
+	}
+
+	saveErr := mfa.SaveTOTP(config.SaveTOTPQuery, models.TOTPSecret{
+		UserID:    user.ID,
+		Secret:    secret,
+		CreatedAt: us.Repo.Now(),
+	})
+	if saveErr != nil {
+		return models.TOTPEnrollment{}, errors.New("error saving two-factor secret. Error: " + saveErr.Error())
+	}
+
+	return models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(us.totpIssuer(), user.Username, secret),
+	}, nil
+}
+
+func (us *UserServices) ConfirmTOTP(ctx context.Context, user models.User, request models.MFACodeRequest) (codes models.RecoveryCodes, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.RecoveryCodes{}, checkErr
+	}
+
+	mfa := us.mfa()
+	secret, searchErr := mfa.SearchTOTP(config.SearchTOTPQuery, user.ID)
+	if searchErr != nil {
+		return models.RecoveryCodes{}, errors.New("error searching two-factor secret. Error: " + searchErr.Error())
+	}
+	if secret.Secret == "" {
+		return models.RecoveryCodes{}, config.ErrMFANotEnrolled
+	}
+	if secret.ConfirmedAt != nil {
+		return models.RecoveryCodes{}, config.ErrMFAAlreadyEnabled
+	}
+
+	now := us.Repo.Now()
+	step, valid := totp.Validate(secret.Secret, request.Code, now)
+	if !valid {
+		return models.RecoveryCodes{}, config.ErrInvalidMFACode
+	}
+
+	codes, hashes, codesErr := recoveryCodes()
+	if codesErr != nil {
+		return models.RecoveryCodes{}, codesErr
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		mfa := repository.MFARepository{DB: repo.DB}
+		confirmed, confirmErr := mfa.ConfirmTOTP(config.ConfirmTOTPQuery, user.ID, now, step)
+		if confirmErr != nil {
+			return errors.New("error confirming two-factor secret. Error: " + confirmErr.Error())
+		}
+		if !confirmed {
+			return config.ErrMFAAlreadyEnabled
+		}
+		if replaceErr := replaceRecoveryCodes(mfa, user.ID, hashes, now); replaceErr != nil {
+			return replaceErr
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionEnableMFA, user.Username, map[string]models.FieldChange{
+			"mfa": {Before: false, After: true},
+		})
+	})
+	if txErr != nil {
+		return models.RecoveryCodes{}, txErr
+	}
+
+	return codes, nil
+}
+
+func (us *UserServices) DisableTOTP(ctx context.Context, user models.User, request models.MFACodeRequest) (err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return checkErr
+	}
+
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		mfa := repository.MFARepository{DB: repo.DB}
+		now := repo.Now()
+
+		if verifyErr := verifySecondFactor(mfa, user.ID, request.Code, now); verifyErr != nil {
+			return verifyErr
+		}
+		if deleteErr := mfa.DeleteTOTP(config.DeleteTOTPQuery, user.ID); deleteErr != nil {
+			return errors.New("error deleting two-factor secret. Error: " + deleteErr.Error())
+		}
+		if deleteErr := mfa.DeleteRecoveryCodes(config.DeleteRecoveryCodesQuery, user.ID); deleteErr != nil {
+			return errors.New("error deleting recovery codes. Error: " + deleteErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionDisableMFA, user.Username, map[string]models.FieldChange{
+			"mfa": {Before: true, After: false},
+		})
+	})
+}
+
+func (us *UserServices) RegenerateRecoveryCodes(ctx context.Context, user models.User, request models.MFACodeRequest) (codes models.RecoveryCodes, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.RecoveryCodes{}, checkErr
+	}
+
+	codes, hashes, codesErr := recoveryCodes()
+	if codesErr != nil {
+		return models.RecoveryCodes{}, codesErr
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		mfa := repository.MFARepository{DB: repo.DB}
+		now := repo.Now()
+
+		if verifyErr := verifySecondFactor(mfa, user.ID, request.Code, now); verifyErr != nil {
+			return verifyErr
+		}
+		if replaceErr := replaceRecoveryCodes(mfa, user.ID, hashes, now); replaceErr != nil {
+			return replaceErr
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionRecoveryCodes, user.Username, map[string]models.FieldChange{
+			"recovery_codes": {Before: nil, After: len(hashes)},
+		})
+	})
+	if txErr != nil {
+		return models.RecoveryCodes{}, txErr
+	}
+
+	return codes, nil
+}
+
+func (us *UserServices) PurgeMFA(ctx context.Context) (err error) {
+	mfa := us.mfa()
+	if purgeErr := mfa.Purge(config.PurgeTOTPQuery); purgeErr != nil {
+		return errors.New("error purging two-factor secrets. Error: " + purgeErr.Error())
+	}
+	if purgeErr := mfa.Purge(config.PurgeRecoveryCodesQuery); purgeErr != nil {
+		return errors.New("error purging recovery codes. Error: " + purgeErr.Error())
+	}
+	return nil
+}
+
+func verifySecondFactor(mfa repository.MFARepository, userID, code string, now time.Time) error {
+	secret, searchErr := mfa.SearchTOTP(config.SearchTOTPQuery, userID)
+	if searchErr != nil {
+		return errors.New("error searching two-factor secret. Error: " + searchErr.Error())
+	}
+	if secret.ConfirmedAt == nil {
+		return config.ErrMFANotEnabled
+	}
+
+	if step, valid := totp.Validate(secret.Secret, code, now); valid {
+		if step <= secret.LastUsedStep {
+			return config.ErrInvalidMFACode
+		}
+		used, useErr := mfa.UseTOTPStep(config.UseTOTPStepQuery, userID, step)
+		if useErr != nil {
+			return errors.New("error recording two-factor code. Error: " + useErr.Error())
+		}
+		if !used {
+			return config.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	used, useErr := mfa.UseRecoveryCode(config.UseRecoveryCodeQuery, userID, hashRecoveryCode(code), now)
+	if useErr != nil {
+		return errors.New("error recording recovery code. Error: " + useErr.Error())
+	}
+	if !used {
+		return config.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(mfa repository.MFARepository, userID string, hashes []string, now time.Time) error {
+	if deleteErr := mfa.DeleteRecoveryCodes(config.DeleteRecoveryCodesQuery, userID); deleteErr != nil {
+		return errors.New("error deleting recovery codes. Error: " + deleteErr.Error())
+	}
+	if saveErr := mfa.SaveRecoveryCodes(config.SaveRecoveryCodeQuery, userID, hashes, now); saveErr != nil {
+		return errors.New("error saving recovery codes. Error: " + saveErr.Error())
+	}
+	return nil
+}
+
+func recoveryCodes() (models.RecoveryCodes, []string, error) {
+	codes := models.RecoveryCodes{Codes: make([]string, 0, config.RecoveryCodeCount)}
+	hashes := make([]string, 0, config.RecoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for range config.RecoveryCodeCount {
+		raw := make([]byte, config.RecoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return models.RecoveryCodes{}, nil, errors.New("error generating recovery codes. Error: " + err.Error())
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw))[:config.RecoveryCodeLength]
+		half := config.RecoveryCodeLength / 2
+
+		codes.Codes = append(codes.Codes, code[:half]+"-"+code[half:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	return hashToken(normalized)
+}
+
+func (us *UserServices) totpIssuer() string {
+	if us.TOTPIssuer != "" {
+		return us.TOTPIssuer
+	}
+	return config.DefaultTOTPIssuer
+}
+
+func (us *UserServices) mfa() repository.MFARepository {
+	return repository.MFARepository{DB: us.Repo.DB}
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"go-manage/internal/totp"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func testTOTPCode(offset int64) string {
+	code, err := totp.Code(testTOTPSecret, totp.Step(config.TestTime)+offset)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return code
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+	user := models.User{ID: "1", Username: "johndoe"}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{Actor: "johndoe", RequestID: "request-1", IP: "10.0.0.1"})
+
+	pending := func() {
+		mock.ExpectQuery(config.TestSearchTOTPQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+				AddRow("1", testTOTPSecret, nil, 0, config.TestTime))
+	}
+
+	test := []struct {
+		Name        string
+		Code        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Code:        testTOTPCode(0),
+			ExpectedErr: nil,
+			MockAct: func() {
+				pending()
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestConfirmTOTPQuery).
+					WithArgs(config.TestTime, totp.Step(config.TestTime), "1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestDeleteRecoveryCodesQuery).
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				for range config.RecoveryCodeCount {
+					mock.ExpectExec(config.TestSaveRecoveryCodeQuery).
+						WithArgs("1", sqlmock.AnyArg(), config.TestTime).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, "johndoe", config.AuditActionEnableMFA, "johndoe",
+						`{"mfa":{"before":false,"after":true}}`, "request-1", "10.0.0.1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Wrong code",
+			Code:        testTOTPCode(5),
+			ExpectedErr: config.ErrInvalidMFACode,
+			MockAct: func() {
+				pending()
+			},
+		},
+		{
+			Name:        "Not enrolled",
+			Code:        testTOTPCode(0),
+			ExpectedErr: config.ErrMFANotEnrolled,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns))
+			},
+		},
+		{
+			Name:        "Already enabled",
+			Code:        testTOTPCode(0),
+			ExpectedErr: config.ErrMFAAlreadyEnabled,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+						AddRow("1", testTOTPSecret, config.TestTime, 0, config.TestTime))
+			},
+		},
+		{
+			Name:        "Missing code",
+			Code:        "",
+			ExpectedErr: config.ErrAllFieldsAreRequired,
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			codes, confirmErr := userService.ConfirmTOTP(ctx, user, models.MFACodeRequest{Code: tt.Code})
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, confirmErr, tt.ExpectedErr)
+				assert.Empty(t, codes.Codes)
+			} else {
+				assert.NoError(t, confirmErr)
+				assert.Len(t, codes.Codes, config.RecoveryCodeCount)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDisableTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+	user := models.User{ID: "1", Username: "johndoe"}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{Actor: "johndoe", RequestID: "request-1", IP: "10.0.0.1"})
+
+	enabled := func(lastUsedStep int64) {
+		mock.ExpectQuery(config.TestSearchTOTPQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+				AddRow("1", testTOTPSecret, config.TestTime, lastUsedStep, config.TestTime))
+	}
+	disabled := func() {
+		mock.ExpectExec(config.TestDeleteTOTPQuery).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(config.TestDeleteRecoveryCodesQuery).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WithArgs(sqlmock.AnyArg(), config.TestTime, "johndoe", config.AuditActionDisableMFA, "johndoe",
+				`{"mfa":{"before":true,"after":false}}`, "request-1", "10.0.0.1", config.DefaultTenant).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	test := []struct {
+		Name        string
+		Code        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success with TOTP code",
+			Code:        testTOTPCode(0),
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				enabled(0)
+				mock.ExpectExec(config.TestUseTOTPStepQuery).
+					WithArgs(totp.Step(config.TestTime), "1", totp.Step(config.TestTime)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				disabled()
+			},
+		},
+		{
+			Name:        "Success with recovery code",
+			Code:        "ABCDE-FGHIJ",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				enabled(0)
+				mock.ExpectExec(config.TestUseRecoveryCodeQuery).
+					WithArgs(config.TestTime, "1", hashToken("abcdefghij")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				disabled()
+			},
+		},
+		{
+			Name:        "Replayed TOTP code",
+			Code:        testTOTPCode(0),
+			ExpectedErr: config.ErrInvalidMFACode,
+			MockAct: func() {
+				mock.ExpectBegin()
+				enabled(totp.Step(config.TestTime))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "Unknown recovery code",
+			Code:        "abcde-fghij",
+			ExpectedErr: config.ErrInvalidMFACode,
+			MockAct: func() {
+				mock.ExpectBegin()
+				enabled(0)
+				mock.ExpectExec(config.TestUseRecoveryCodeQuery).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "Not enabled",
+			Code:        testTOTPCode(0),
+			ExpectedErr: config.ErrMFANotEnabled,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			disableErr := userService.DisableTOTP(ctx, user, models.MFACodeRequest{Code: tt.Code})
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, disableErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, disableErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCompleteMFALogin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo, SessionTTL: time.Hour}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{RequestID: "request-1", IP: "10.0.0.1"})
+
+	challengeHash := hashToken("challenge")
+	noThrottle := func(key string) {
+		mock.ExpectQuery(config.TestSearchThrottleQuery).
+			WithArgs(key).
+			WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns))
+	}
+	challenge := func() {
+		noThrottle("ip:10.0.0.1")
+		mock.ExpectQuery(config.TestSearchChallengeQuery).
+			WithArgs(challengeHash, config.TestTime).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("1"))
+		mock.ExpectQuery(config.TestSearchByIDQuery).
+			WithArgs("1", config.DefaultTenant).
+			WillReturnRows(mock.NewRows(config.TestUserColumns).
+				AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+		noThrottle("user:1")
+		mock.ExpectBegin()
+		mock.ExpectQuery(config.TestSearchTOTPQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+				AddRow("1", testTOTPSecret, config.TestTime, 0, config.TestTime))
+	}
+
+	test := []struct {
+		Name        string
+		Request     models.MFALoginRequest
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Request:     models.MFALoginRequest{Challenge: "challenge", Code: testTOTPCode(-1)},
+			ExpectedErr: nil,
+			MockAct: func() {
+				challenge()
+				mock.ExpectExec(config.TestUseTOTPStepQuery).
+					WithArgs(totp.Step(config.TestTime)-1, "1", totp.Step(config.TestTime)-1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestDeleteChallengeQuery).
+					WithArgs(challengeHash, config.TestTime).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestLoginQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WithArgs(sqlmock.AnyArg(), "1", config.TestTime, config.TestTime.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Wrong code counts as failed login",
+			Request:     models.MFALoginRequest{Challenge: "challenge", Code: "000000"},
+			ExpectedErr: config.ErrInvalidCredentials,
+			MockAct: func() {
+				challenge()
+				mock.ExpectExec(config.TestUseRecoveryCodeQuery).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("ip:10.0.0.1", 1, config.TestTime, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				noThrottle("user:1")
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("user:1", 1, config.TestTime, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Unknown challenge",
+			Request:     models.MFALoginRequest{Challenge: "challenge", Code: testTOTPCode(0)},
+			ExpectedErr: config.ErrInvalidChallenge,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchChallengeQuery).
+					WithArgs(challengeHash, config.TestTime).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+			},
+		},
+		{
+			Name:        "Missing code",
+			Request:     models.MFALoginRequest{Challenge: "challenge"},
+			ExpectedErr: config.ErrAllFieldsAreRequired,
+			MockAct: func() {
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			session, loginErr := userService.CompleteMFALogin(ctx, tt.Request)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, loginErr, tt.ExpectedErr)
+				assert.Equal(t, models.Session{}, session)
+			} else {
+				assert.NoError(t, loginErr)
+				assert.NotEmpty(t, session.Token)
+				assert.Equal(t, "1", session.UserID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchSessionUserQuery).
+					WithArgs(hashToken("token"), config.TestTime, config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:        "Expired session",
+			ExpectedErr: config.ErrUnauthorized,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchSessionUserQuery).
+					WithArgs(hashToken("token"), config.TestTime, config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: errors.New("error searching session"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchSessionUserQuery).
+					WillReturnError(errors.New("database is locked"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			user, authErr := userService.Authenticate(&gin.Context{}, "token")
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorContains(t, authErr, tt.ExpectedErr.Error())
+				assert.Equal(t, models.User{}, user)
+			} else {
+				assert.NoError(t, authErr)
+				assert.Equal(t, "johndoe", user.Username)
+			}
+		})
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := recoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes.Codes, config.RecoveryCodeCount)
+	assert.Len(t, hashes, config.RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for i, code := range codes.Codes {
+		assert.Len(t, code, config.RecoveryCodeLength+1)
+		assert.Equal(t, hashes[i], hashRecoveryCode(code))
+		assert.Equal(t, hashes[i], hashRecoveryCode(" "+strings.ToUpper(code)+" "))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (us *UserServices) CreateOAuthClient(ctx context.Context, request models.CreateOAuthClientRequest) (created models.OAuthClient, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.OAuthClient{}, checkErr
+	}
+
+	now := us.Repo.Now()
+	created = models.OAuthClient{
+		ID:           uuid.New().String(),
+		Name:         strings.TrimSpace(request.Name),
+		RedirectURIs: slices.Compact(slices.Clone(request.RedirectURIs)),
+		Public:       request.Public,
+		CreatedBy:    AuditContextFrom(ctx).Actor,
+		CreatedAt:    now,
+	}
+	if !created.Public {
+		secret, secretErr := randomToken(config.OIDCClientSecretSize)
+		if secretErr != nil {
+			return models.OAuthClient{}, errors.New("error generating client secret. Error: " + secretErr.Error())
+		}
+		created.Secret = secret
+		created.SecretHash = hashToken(secret)
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		clients := repository.OAuthRepository{DB: repo.DB, Tenant: repo.Tenant}
+		if saveErr := clients.SaveClient(config.SaveOAuthClientQuery, created); saveErr != nil {
+			return errors.New("error saving oauth client. Error: " + saveErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionCreateClient, created.ID, map[string]models.FieldChange{
+			"name":          {Before: nil, After: created.Name},
+			"redirect_uris": {Before: nil, After: created.RedirectURIs},
+			"public":        {Before: nil, After: created.Public},
+		})
+	})
+	if txErr != nil {
+		return models.OAuthClient{}, txErr
+	}
+
+	return created, nil
+}
+
+func (us *UserServices) ListOAuthClients(ctx context.Context) (clients []models.OAuthClient, err error) {
+	repo := us.oauth(ctx)
+	clients, listErr := repo.ListClients(config.ListOAuthClientsQuery)
+	if listErr != nil {
+		return nil, errors.New("error listing oauth clients. Error: " + listErr.Error())
+	}
+	return clients, nil
+}
+
+func (us *UserServices) DeleteOAuthClient(ctx context.Context, id string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		clients := repository.OAuthRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		deleted, deleteErr := clients.DeleteClient(config.DeleteOAuthClientQuery, id)
+		if deleteErr != nil {
+			return errors.New("error deleting oauth client. Error: " + deleteErr.Error())
+		}
+		if !deleted {
+			return config.ErrOAuthClientNotFound
+		}
+		if codesErr := clients.DeleteClientCodes(config.DeleteClientCodesQuery, id); codesErr != nil {
+			return errors.New("error deleting authorization codes. Error: " + codesErr.Error())
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionDeleteClient, id, nil)
+	})
+}
+
+func (us *UserServices) Authorize(ctx context.Context, user models.User, request models.AuthorizeRequest) (redirect string, err error) {
+	repo := us.oauth(ctx)
+	client, searchErr := repo.SearchClient(config.SearchOAuthClientQuery, request.ClientID)
+	if searchErr != nil {
+		return "", errors.New("error searching oauth client. Error: " + searchErr.Error())
+	}
+	if client.ID == "" {
+		return "", &models.OAuthError{Code: config.OAuthInvalidRequest, Description: "unknown client_id"}
+	}
+	if !slices.Contains(client.RedirectURIs, request.RedirectURI) {
+		return "", &models.OAuthError{Code: config.OAuthInvalidRequest, Description: "redirect_uri is not registered for this client"}
+	}
+
+	target, _ := url.Parse(request.RedirectURI)
+	scope, requestErr := authorizeRequestError(request)
+	if requestErr != nil {
+		return redirectURL(target, request.State, url.Values{
+			"error":             {requestErr.Code},
+			"error_description": {requestErr.Description},
+		}), nil
+	}
+
+	code, codeErr := randomToken(config.OIDCCodeSize)
+	if codeErr != nil {
+		return "", errors.New("error generating authorization code. Error: " + codeErr.Error())
+	}
+
+	now := us.Repo.Now()
+	saveErr := repo.SaveCode(config.SaveAuthorizationCodeQuery, models.AuthorizationCode{
+		Hash:          hashToken(code),
+		ClientID:      client.ID,
+		UserID:        user.ID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         scope,
+		Nonce:         request.Nonce,
+		CodeChallenge: request.CodeChallenge,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(config.OIDCCodeTTL),
+	})
+	if saveErr != nil {
+		return "", errors.New("error saving authorization code. Error: " + saveErr.Error())
+	}
+
+	return redirectURL(target, request.State, url.Values{"code": {code}}), nil
+}
+
+func (us *UserServices) ExchangeCode(ctx context.Context, request models.TokenRequest) (tokens models.TokenResponse, err error) {
+	repo := us.oauth(ctx)
+	client, searchErr := repo.SearchClient(config.SearchOAuthClientQuery, request.ClientID)
+	if searchErr != nil {
+		return models.TokenResponse{}, errors.New("error searching oauth client. Error: " + searchErr.Error())
+	}
+	if client.ID == "" || !clientAuthenticated(client, request.ClientSecret) {
+		return models.TokenResponse{}, &models.OAuthError{Code: config.OAuthInvalidClient, Description: "client authentication failed"}
+	}
+	if request.GrantType != config.OIDCGrantType {
+		return models.TokenResponse{}, &models.OAuthError{Code: config.OAuthUnsupportedGrantType, Description: "only authorization_code is supported"}
+	}
+	if request.Code == "" || request.CodeVerifier == "" {
+		return models.TokenResponse{}, &models.OAuthError{Code: config.OAuthInvalidRequest, Description: "code and code_verifier are required"}
+	}
+
+	invalidGrant := &models.OAuthError{Code: config.OAuthInvalidGrant, Description: "authorization code is invalid, expired or already used"}
+
+	var code models.AuthorizationCode
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		codes := repository.OAuthRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		var searchErr error
+		code, searchErr = codes.SearchCode(config.SearchAuthorizationCodeQuery, hashToken(request.Code), repo.Now())
+		if searchErr != nil {
+			return errors.New("error searching authorization code. Error: " + searchErr.Error())
+		}
+		if code.Hash == "" {
+			return invalidGrant
+		}
+
+		deleted, deleteErr := codes.DeleteCode(config.DeleteAuthorizationCodeQuery, code.Hash)
+		if deleteErr != nil {
+			return errors.New("error consuming authorization code. Error: " + deleteErr.Error())
+		}
+		if !deleted {
+			return invalidGrant
+		}
+		return nil
+	})
+	if txErr != nil {
+		return models.TokenResponse{}, txErr
+	}
+
+	if code.ClientID != client.ID || code.RedirectURI != request.RedirectURI || !oidc.VerifyPKCE(request.CodeVerifier, code.CodeChallenge) {
+		return models.TokenResponse{}, invalidGrant
+	}
+
+	users := us.repo(ctx)
+	user, userErr := users.Search(config.SearchUserByIDQuery, code.UserID)
+	if userErr != nil {
+		return models.TokenResponse{}, errors.New("error searching user. Error: " + userErr.Error())
+	}
+	if user.ID == "" {
+		return models.TokenResponse{}, invalidGrant
+	}
+
+	return us.issueTokens(ctx, user, code)
+}
+
+func (us *UserServices) UserInfo(ctx context.Context, accessToken string) (info models.UserInfo, err error) {
+	now := us.Repo.Now()
+	keys, keysErr := us.verificationKeys(now)
+	if keysErr != nil {
+		return models.UserInfo{}, keysErr
+	}
+
+	var claims models.TokenClaims
+	if verifyErr := oidc.Verify(accessToken, config.OIDCAccessTokenType, keys, &claims); verifyErr != nil {
+		return models.UserInfo{}, verifyErr
+	}
+	if claims.Issuer != us.issuer() || now.Unix() >= claims.ExpiresAt {
+		return models.UserInfo{}, config.ErrInvalidToken
+	}
+
+	repo := us.repo(ctx)
+	user, searchErr := repo.Search(config.SearchUserByIDQuery, claims.Subject)
+	if searchErr != nil {
+		return models.UserInfo{}, errors.New("error searching user. Error: " + searchErr.Error())
+	}
+	if user.ID == "" {
+		return models.UserInfo{}, config.ErrInvalidToken
+	}
+
+	return userInfo(user, claims.Scope), nil
+}
+
+func (us *UserServices) JWKS(ctx context.Context) (jwks models.JWKS, err error) {
+	now := us.Repo.Now()
+	if _, _, keyErr := us.signingKey(ctx, now); keyErr != nil {
+		return models.JWKS{}, keyErr
+	}
+
+	keys, keysErr := us.verificationKeys(now)
+	if keysErr != nil {
+		return models.JWKS{}, keysErr
+	}
+
+	jwks = models.JWKS{Keys: []models.JWK{}}
+	for kid, key := range keys {
+		jwks.Keys = append(jwks.Keys, oidc.PublicJWK(kid, key))
+	}
+	slices.SortFunc(jwks.Keys, func(a, b models.JWK) int { return strings.Compare(a.KeyID, b.KeyID) })
+	return jwks, nil
+}
+
+func (us *UserServices) RotateSigningKey(ctx context.Context) (err error) {
+	_, _, rotateErr := us.rotateSigningKey(ctx, us.Repo.Now())
+	return rotateErr
+}
+
+func (us *UserServices) Discovery() models.OIDCDiscovery {
+	issuer := us.issuer()
+	return models.OIDCDiscovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + config.OIDCAuthorizePath,
+		TokenEndpoint:                     issuer + config.OIDCTokenPath,
+		UserInfoEndpoint:                  issuer + config.OIDCUserInfoPath,
+		JWKSURI:                           issuer + config.OIDCJWKSPath,
+		ResponseTypesSupported:            []string{config.OIDCResponseType},
+		GrantTypesSupported:               []string{config.OIDCGrantType},
+		SubjectTypesSupported:             []string{config.OIDCSubjectType},
+		IDTokenSigningAlgValuesSupported:  []string{config.OIDCSigningAlg},
+		ScopesSupported:                   config.OIDCScopes,
+		ClaimsSupported:                   config.OIDCClaims,
+		TokenEndpointAuthMethodsSupported: config.OIDCClientAuthMethods,
+		CodeChallengeMethodsSupported:     []string{config.OIDCPKCEMethod},
+	}
+}
+
+func (us *UserServices) PurgeOIDC(ctx context.Context) (err error) {
+	repo := us.oauth(ctx)
+	now := us.Repo.Now()
+
+	if codesErr := repo.PurgeCodes(config.PurgeAuthorizationCodesQuery, now); codesErr != nil {
+		return errors.New("error purging authorization codes. Error: " + codesErr.Error())
+	}
+	if keysErr := repo.PurgeSigningKeys(config.PurgeSigningKeysQuery, now.Add(-config.OIDCTokenTTL)); keysErr != nil {
+		return errors.New("error purging signing keys. Error: " + keysErr.Error())
+	}
+	return nil
+}
+
+func (us *UserServices) issueTokens(ctx context.Context, user models.User, code models.AuthorizationCode) (models.TokenResponse, error) {
+	now := us.Repo.Now()
+	kid, key, keyErr := us.signingKey(ctx, now)
+	if keyErr != nil {
+		return models.TokenResponse{}, keyErr
+	}
+
+	claims := models.TokenClaims{
+		Issuer:    us.issuer(),
+		Audience:  code.ClientID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(config.OIDCTokenTTL).Unix(),
+	}
+
+	access := claims
+	access.Subject = user.ID
+	access.Scope = code.Scope
+	accessToken, accessErr := oidc.Sign(key, kid, config.OIDCAccessTokenType, access)
+	if accessErr != nil {
+		return models.TokenResponse{}, errors.New("error signing access token. Error: " + accessErr.Error())
+	}
+
+	identity := claims
+	identity.UserInfo = userInfo(user, code.Scope)
+	identity.Nonce = code.Nonce
+	idToken, idErr := oidc.Sign(key, kid, config.OIDCIDTokenType, identity)
+	if idErr != nil {
+		return models.TokenResponse{}, errors.New("error signing id token. Error: " + idErr.Error())
+	}
+
+	return models.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   config.OIDCTokenType,
+		ExpiresIn:   int64(config.OIDCTokenTTL / time.Second),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+func (us *UserServices) signingKey(ctx context.Context, now time.Time) (string, *rsa.PrivateKey, error) {
+	repo := us.oauth(ctx)
+	keys, listErr := repo.ListSigningKeys(config.ListSigningKeysQuery, now.Add(-config.OIDCTokenTTL))
+	if listErr != nil {
+		return "", nil, errors.New("error listing signing keys. Error: " + listErr.Error())
+	}
+
+	for _, stored := range keys {
+		if stored.RetiredAt != nil {
+			continue
+		}
+		if now.Sub(stored.CreatedAt) >= us.keyRotation() {
+			break
+		}
+		key, parseErr := oidc.ParseKey(stored.PrivateKey)
+		if parseErr != nil {
+			return "", nil, errors.New("error parsing signing key. Error: " + parseErr.Error())
+		}
+		return stored.ID, key, nil
+	}
+
+	return us.rotateSigningKey(ctx, now)
+}
+
+func (us *UserServices) rotateSigningKey(ctx context.Context, now time.Time) (string, *rsa.PrivateKey, error) {
+	key, encoded, keyErr := oidc.GenerateKey()
+	if keyErr != nil {
+		return "", nil, errors.New("error generating signing key. Error: " + keyErr.Error())
+	}
+	kid := oidc.KeyID(&key.PublicKey)
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		keys := repository.OAuthRepository{DB: repo.DB, Tenant: repo.Tenant}
+		if saveErr := keys.SaveSigningKey(config.SaveSigningKeyQuery, models.SigningKey{ID: kid, PrivateKey: encoded, CreatedAt: now}); saveErr != nil {
+			return errors.New("error saving signing key. Error: " + saveErr.Error())
+		}
+		if retireErr := keys.RetireSigningKeys(config.RetireSigningKeysQuery, now, kid); retireErr != nil {
+			return errors.New("error retiring signing keys. Error: " + retireErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionRotateKey, kid, nil)
+	})
+	if txErr != nil {
+		return "", nil, txErr
+	}
+
+	return kid, key, nil
+}
+
+func (us *UserServices) verificationKeys(now time.Time) (map[string]*rsa.PublicKey, error) {
+	repo := repository.OAuthRepository{DB: us.Repo.DB}
+	stored, listErr := repo.ListSigningKeys(config.ListSigningKeysQuery, now.Add(-config.OIDCTokenTTL))
+	if listErr != nil {
+		return nil, errors.New("error listing signing keys. Error: " + listErr.Error())
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(stored))
+	for _, signingKey := range stored {
+		key, parseErr := oidc.ParseKey(signingKey.PrivateKey)
+		if parseErr != nil {
+			return nil, errors.New("error parsing signing key. Error: " + parseErr.Error())
+		}
+		keys[signingKey.ID] = &key.PublicKey
+	}
+	return keys, nil
+}
+
+func authorizeRequestError(request models.AuthorizeRequest) (string, *models.OAuthError) {
+	if request.ResponseType != config.OIDCResponseType {
+		return "", &models.OAuthError{Code: config.OAuthUnsupportedResponseType, Description: "only the code response type is supported"}
+	}
+
+	requested := strings.Fields(request.Scope)
+	if !slices.Contains(requested, config.ScopeOpenID) {
+		return "", &models.OAuthError{Code: config.OAuthInvalidScope, Description: "the openid scope is required"}
+	}
+	for _, scope := range requested {
+		if !slices.Contains(config.OIDCScopes, scope) {
+			return "", &models.OAuthError{Code: config.OAuthInvalidScope, Description: "unsupported scope " + scope}
+		}
+	}
+	var scopes []string
+	for _, scope := range config.OIDCScopes {
+		if slices.Contains(requested, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if !oidc.ValidChallenge(request.CodeChallenge, request.CodeChallengeMethod) {
+		return "", &models.OAuthError{Code: config.OAuthInvalidRequest, Description: "a S256 code_challenge is required"}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+func redirectURL(target *url.URL, state string, params url.Values) string {
+	query := target.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+
+	redirect := *target
+	redirect.RawQuery = query.Encode()
+	return redirect.String()
+}
+
+func clientAuthenticated(client models.OAuthClient, secret string) bool {
+	if client.Public {
+		return true
+	}
+	return secret != "" && subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(client.SecretHash)) == 1
+}
+
+func userInfo(user models.User, scope string) models.UserInfo {
+	info := models.UserInfo{Subject: user.ID}
+	scopes := strings.Fields(scope)
+
+	if slices.Contains(scopes, config.ScopeProfile) {
+		info.PreferredUsername = user.Username
+		info.Name = strings.TrimSpace(user.Name + " " + user.Surname)
+		info.GivenName = user.Name
+		info.FamilyName = user.Surname
+	}
+	if slices.Contains(scopes, config.ScopeEmail) {
+		info.Email = user.Email
+	}
+	return info
+}
+
+func (us *UserServices) issuer() string {
+	if us.OIDCIssuer != "" {
+		return strings.TrimSuffix(us.OIDCIssuer, "/")
+	}
+	return config.DefaultOIDCIssuer
+}
+
+func (us *UserServices) keyRotation() time.Duration {
+	if us.OIDCKeyRotation > 0 {
+		return us.OIDCKeyRotation
+	}
+	return config.DefaultOIDCKeyRotation
+}
+
+func (us *UserServices) oauth(ctx context.Context) repository.OAuthRepository {
+	return repository.OAuthRepository{DB: us.Repo.DB, Tenant: TenantFrom(ctx)}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/oidc"
+	"go-manage/internal/repository"
+	"log"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	user := models.User{ID: "user-1", Username: "johndoe"}
+	redirectURI := "https://wiki.example.com/callback"
+	challenge := oidc.Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	request := models.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "client-1",
+		RedirectURI:         redirectURI,
+		Scope:               "email openid",
+		State:               "xyz",
+		Nonce:               "nonce",
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: "S256",
+	}
+	clientRow := func() {
+		mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+			WithArgs("client-1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestOAuthClientColumns).
+				AddRow("client-1", "wiki", "hash", redirectURI, false, config.AdminActor, config.TestTime))
+	}
+
+	test := []struct {
+		Name          string
+		Request       func() models.AuthorizeRequest
+		ExpectedError string
+		ExpectedQuery url.Values
+		MockAct       func()
+	}{
+		{
+			Name:          "Success",
+			Request:       func() models.AuthorizeRequest { return request },
+			ExpectedQuery: url.Values{"state": {"xyz"}},
+			MockAct: func() {
+				clientRow()
+				mock.ExpectExec(config.TestSaveAuthCodeQuery).
+					WithArgs(sqlmock.AnyArg(), "client-1", "user-1", redirectURI, "openid email", "nonce", challenge,
+						config.TestTime, config.TestTime.Add(config.OIDCCodeTTL)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name: "Unknown client",
+			Request: func() models.AuthorizeRequest {
+				unknown := request
+				unknown.ClientID = "client-2"
+				return unknown
+			},
+			ExpectedError: config.OAuthInvalidRequest,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+					WithArgs("client-2", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestOAuthClientColumns))
+			},
+		},
+		{
+			Name: "Unregistered redirect",
+			Request: func() models.AuthorizeRequest {
+				unregistered := request
+				unregistered.RedirectURI = "https://evil.example.com/callback"
+				return unregistered
+			},
+			ExpectedError: config.OAuthInvalidRequest,
+			MockAct:       clientRow,
+		},
+		{
+			Name: "Missing openid scope is redirected",
+			Request: func() models.AuthorizeRequest {
+				missing := request
+				missing.Scope = "email"
+				return missing
+			},
+			ExpectedQuery: url.Values{"state": {"xyz"}, "error": {config.OAuthInvalidScope}},
+			MockAct:       clientRow,
+		},
+		{
+			Name: "Plain PKCE is redirected",
+			Request: func() models.AuthorizeRequest {
+				plain := request
+				plain.CodeChallengeMethod = "plain"
+				return plain
+			},
+			ExpectedQuery: url.Values{"state": {"xyz"}, "error": {config.OAuthInvalidRequest}},
+			MockAct:       clientRow,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			redirect, authorizeErr := userService.Authorize(context.Background(), user, tt.Request())
+
+			if tt.ExpectedError != "" {
+				var protocolErr *models.OAuthError
+				assert.ErrorAs(t, authorizeErr, &protocolErr)
+				assert.Equal(t, tt.ExpectedError, protocolErr.Code)
+				assert.Empty(t, redirect)
+			} else {
+				assert.NoError(t, authorizeErr)
+				location, _ := url.Parse(redirect)
+				assert.Equal(t, redirectURI, location.Scheme+"://"+location.Host+location.Path)
+				for name := range tt.ExpectedQuery {
+					assert.Equal(t, tt.ExpectedQuery.Get(name), location.Query().Get(name))
+				}
+				assert.Equal(t, tt.ExpectedQuery.Get("error") == "", location.Query().Get("code") != "")
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	redirectURI := "https://wiki.example.com/callback"
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	request := models.TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         "code",
+		RedirectURI:  redirectURI,
+		CodeVerifier: verifier,
+		ClientID:     "client-1",
+		ClientSecret: "secret",
+	}
+	clientRow := func() {
+		mock.ExpectQuery(config.TestSearchOAuthClientQuery).
+			WithArgs("client-1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestOAuthClientColumns).
+				AddRow("client-1", "wiki", hashToken("secret"), redirectURI, false, config.AdminActor, config.TestTime))
+	}
+	codeRow := func(clientID string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(config.TestSearchAuthCodeQuery).
+			WithArgs(hashToken("code"), config.TestTime).
+			WillReturnRows(sqlmock.NewRows(config.TestAuthCodeColumns).
+				AddRow(hashToken("code"), clientID, "user-1", redirectURI, "openid", "", oidc.Challenge(verifier),
+					config.TestTime, config.TestTime.Add(config.OIDCCodeTTL)))
+		mock.ExpectExec(config.TestDeleteAuthCodeQuery).
+			WithArgs(hashToken("code")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	test := []struct {
+		Name          string
+		Request       func() models.TokenRequest
+		ExpectedError string
+		MockAct       func()
+	}{
+		{
+			Name: "Wrong client secret",
+			Request: func() models.TokenRequest {
+				wrong := request
+				wrong.ClientSecret = "guess"
+				return wrong
+			},
+			ExpectedError: config.OAuthInvalidClient,
+			MockAct:       clientRow,
+		},
+		{
+			Name: "Unsupported grant type",
+			Request: func() models.TokenRequest {
+				unsupported := request
+				unsupported.GrantType = "password"
+				return unsupported
+			},
+			ExpectedError: config.OAuthUnsupportedGrantType,
+			MockAct:       clientRow,
+		},
+		{
+			Name:          "Unknown or used code",
+			Request:       func() models.TokenRequest { return request },
+			ExpectedError: config.OAuthInvalidGrant,
+			MockAct: func() {
+				clientRow()
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchAuthCodeQuery).
+					WithArgs(hashToken("code"), config.TestTime).
+					WillReturnRows(sqlmock.NewRows(config.TestAuthCodeColumns))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:          "Code issued to another client",
+			Request:       func() models.TokenRequest { return request },
+			ExpectedError: config.OAuthInvalidGrant,
+			MockAct: func() {
+				clientRow()
+				codeRow("client-2")
+			},
+		},
+		{
+			Name: "Wrong code verifier",
+			Request: func() models.TokenRequest {
+				wrong := request
+				wrong.CodeVerifier = verifier[1:] + "A"
+				return wrong
+			},
+			ExpectedError: config.OAuthInvalidGrant,
+			MockAct: func() {
+				clientRow()
+				codeRow("client-1")
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			tokens, exchangeErr := userService.ExchangeCode(context.Background(), tt.Request())
+
+			var protocolErr *models.OAuthError
+			assert.ErrorAs(t, exchangeErr, &protocolErr)
+			assert.Equal(t, tt.ExpectedError, protocolErr.Code)
+			assert.Equal(t, models.TokenResponse{}, tokens)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteOAuthClientService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteOAuthClientQuery).
+					WithArgs("client-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestDeleteClientCodesQuery).
+					WithArgs("client-1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionDeleteClient, "client-1",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Not found",
+			ExpectedErr: config.ErrOAuthClientNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteOAuthClientQuery).
+					WithArgs("client-1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			deleteErr := userService.DeleteOAuthClient(context.Background(), "client-1")
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, deleteErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, deleteErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
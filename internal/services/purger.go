@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+func (us *UserServices) StartPurger(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := us.PurgeDeletedUsers(ctx, retention)
+			if err != nil {
+				log.Println(err.Error())
+			} else if purged > 0 {
+				log.Printf("purged %d deleted users", purged)
+			}
+
+			if _, throttleErr := us.PurgeLoginThrottles(ctx); throttleErr != nil {
+				log.Println(throttleErr.Error())
+			}
+
+			if _, sessionsErr := us.PurgeExpiredSessions(ctx); sessionsErr != nil {
+				log.Println(sessionsErr.Error())
+			}
+
+			if mfaErr := us.PurgeMFA(ctx); mfaErr != nil {
+				log.Println(mfaErr.Error())
+			}
+
+			if oidcErr := us.PurgeOIDC(ctx); oidcErr != nil {
+				log.Println(oidcErr.Error())
+			}
+
+			if federationErr := us.PurgeFederation(ctx); federationErr != nil {
+				log.Println(federationErr.Error())
+			}
+
+			if groupsErr := us.PurgeGroups(ctx); groupsErr != nil {
+				log.Println(groupsErr.Error())
+			}
+
+			if rolesErr := us.PurgeRoles(ctx); rolesErr != nil {
+				log.Println(rolesErr.Error())
+			}
+		}
+	}
+}
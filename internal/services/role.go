@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/policy"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func (us *UserServices) CreateRole(ctx context.Context, request models.CreateRoleRequest) (created models.Role, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.Role{}, checkErr
+	}
+
+	now := us.Repo.Now()
+	created = models.Role{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(request.Name),
+		Description: strings.TrimSpace(request.Description),
+		Permissions: normalizePermissions(request.Permissions),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		roles := repository.RoleRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		if saveErr := roles.Save(config.SaveRoleQuery, created); saveErr != nil {
+			if errors.Is(saveErr, config.ErrRoleAlreadyExists) {
+				return saveErr
+			}
+			return errors.New("error saving role. Error: " + saveErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionCreateRole, created.ID, roleChanges(models.Role{}, created))
+	})
+	if txErr != nil {
+		return models.Role{}, txErr
+	}
+
+	return created, nil
+}
+
+func (us *UserServices) SearchRole(ctx context.Context, id string) (role models.Role, err error) {
+	repo := us.roles(ctx)
+	role, searchErr := repo.Search(config.SearchRoleQuery, id)
+	if searchErr != nil {
+		return models.Role{}, errors.New("error searching role. Error: " + searchErr.Error())
+	}
+	if role.ID == "" {
+		return models.Role{}, config.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (us *UserServices) ListRoles(ctx context.Context) (roles []models.Role, err error) {
+	repo := us.roles(ctx)
+	roles, listErr := repo.List(config.ListRolesQuery)
+	if listErr != nil {
+		return nil, errors.New("error listing roles. Error: " + listErr.Error())
+	}
+	return roles, nil
+}
+
+func (us *UserServices) UpdateRole(ctx context.Context, id string, request models.UpdateRoleRequest) (updated models.Role, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.Role{}, checkErr
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		roles := repository.RoleRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		current, searchErr := requireRole(roles, id)
+		if searchErr != nil {
+			return searchErr
+		}
+
+		updated = current
+		if request.Name != nil {
+			updated.Name = strings.TrimSpace(*request.Name)
+		}
+		if request.Description != nil {
+			updated.Description = strings.TrimSpace(*request.Description)
+		}
+		if request.Permissions != nil {
+			updated.Permissions = normalizePermissions(*request.Permissions)
+		}
+
+		changes := roleChanges(current, updated)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		updated.UpdatedAt = repo.Now()
+		if updateErr := roles.Update(config.UpdateRoleQuery, updated); updateErr != nil {
+			if errors.Is(updateErr, config.ErrRoleAlreadyExists) {
+				return updateErr
+			}
+			return errors.New("error updating role. Error: " + updateErr.Error())
+		}
+		return recordAudit(ctx, audit, updated.UpdatedAt, config.AuditActionUpdateRole, id, changes)
+	})
+	if txErr != nil {
+		return models.Role{}, txErr
+	}
+
+	return updated, nil
+}
+
+func (us *UserServices) DeleteRole(ctx context.Context, id string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		roles := repository.RoleRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		current, searchErr := requireRole(roles, id)
+		if searchErr != nil {
+			return searchErr
+		}
+
+		if _, deleteErr := roles.Delete(config.DeleteRoleQuery, id); deleteErr != nil {
+			return errors.New("error deleting role. Error: " + deleteErr.Error())
+		}
+		if assignmentsErr := roles.DeleteAssignments(config.DeleteRoleAssignmentsQuery, id); assignmentsErr != nil {
+			return errors.New("error deleting role assignments. Error: " + assignmentsErr.Error())
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionDeleteRole, id, roleChanges(current, models.Role{}))
+	})
+}
+
+func (us *UserServices) AssignRole(ctx context.Context, userID, roleID string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		roles := repository.RoleRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		user, userErr := repo.Search(config.SearchUserByIDQuery, userID)
+		if userErr != nil {
+			return errors.New("error searching user. Error: " + userErr.Error())
+		}
+		if user.ID == "" {
+			return config.ErrUserNotFound
+		}
+
+		role, roleErr := requireRole(roles, roleID)
+		if roleErr != nil {
+			return roleErr
+		}
+
+		now := repo.Now()
+		assigned, assignErr := roles.Assign(config.AssignRoleQuery, user.ID, role.ID, now)
+		if assignErr != nil {
+			return errors.New("error assigning role. Error: " + assignErr.Error())
+		}
+		if !assigned {
+			return nil
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionAssignRole, user.ID, map[string]models.FieldChange{
+			"role": {Before: nil, After: role.Name},
+		})
+	})
+}
+
+func (us *UserServices) UnassignRole(ctx context.Context, userID, roleID string) (err error) {
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		roles := repository.RoleRepository{DB: repo.DB, Tenant: repo.Tenant}
+
+		role, roleErr := requireRole(roles, roleID)
+		if roleErr != nil {
+			return roleErr
+		}
+
+		removed, unassignErr := roles.Unassign(config.UnassignRoleQuery, userID, role.ID)
+		if unassignErr != nil {
+			return errors.New("error unassigning role. Error: " + unassignErr.Error())
+		}
+		if !removed {
+			return config.ErrRoleNotAssigned
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionUnassignRole, userID, map[string]models.FieldChange{
+			"role": {Before: role.Name, After: nil},
+		})
+	})
+}
+
+func (us *UserServices) ListUserRoles(ctx context.Context, userID string) (roles []models.Role, err error) {
+	if _, searchErr := us.SearchUserByID(ctx, userID); searchErr != nil {
+		return nil, searchErr
+	}
+
+	repo := us.roles(ctx)
+	roles, listErr := repo.ListUserRoles(config.ListUserRolesQuery, userID)
+	if listErr != nil {
+		return nil, errors.New("error listing user roles. Error: " + listErr.Error())
+	}
+	return roles, nil
+}
+
+func (us *UserServices) Can(ctx context.Context, actor models.User, action, resource string) (allowed bool, err error) {
+	if !policy.Known(action) {
+		return false, config.ErrInvalidPermission
+	}
+
+	decision, decideErr := us.decide(ctx, actor, action, resource)
+	if decideErr != nil {
+		return false, decideErr
+	}
+	return decision.Allowed, nil
+}
+
+func (us *UserServices) CanAPIKey(ctx context.Context, key models.APIKey, action, resource string) (allowed bool, err error) {
+	if !policy.Known(action) {
+		return false, config.ErrInvalidPermission
+	}
+
+	_, allowed = policy.Match(policy.ScopeGrants(key.Scopes), key.ID, action, resource)
+	return allowed, nil
+}
+
+func (us *UserServices) CheckPermission(ctx context.Context, userID, action, resource string) (decision models.PermissionDecision, err error) {
+	if !policy.Known(action) {
+		return models.PermissionDecision{}, config.ErrInvalidPermission
+	}
+
+	user, searchErr := us.SearchUserByID(ctx, userID)
+	if searchErr != nil {
+		return models.PermissionDecision{}, searchErr
+	}
+
+	return us.decide(ctx, user, action, resource)
+}
+
+func (us *UserServices) PurgeRoles(ctx context.Context) (err error) {
+	repo := us.roles(ctx)
+	if purgeErr := repo.PurgeAssignments(config.PurgeRoleAssignmentsQuery); purgeErr != nil {
+		return errors.New("error purging role assignments. Error: " + purgeErr.Error())
+	}
+	return nil
+}
+
+func (us *UserServices) decide(ctx context.Context, actor models.User, action, resource string) (models.PermissionDecision, error) {
+	decision := models.PermissionDecision{UserID: actor.ID, Action: action, Resource: resource}
+
+	if grant, ok := policy.Match(config.BaselinePermissions, actor.ID, action, resource); ok {
+		decision.Allowed, decision.Role, decision.Permission = true, config.BaselineRole, grant
+		return decision, nil
+	}
+
+	repo := us.roles(ctx)
+	roles, listErr := repo.ListUserRoles(config.ListUserRolesQuery, actor.ID)
+	if listErr != nil {
+		return decision, errors.New("error listing user roles. Error: " + listErr.Error())
+	}
+	for _, role := range roles {
+		if grant, ok := policy.Match(role.Permissions, actor.ID, action, resource); ok {
+			decision.Allowed, decision.Role, decision.Permission = true, role.Name, grant
+			return decision, nil
+		}
+	}
+
+	return decision, nil
+}
+
+func requireRole(roles repository.RoleRepository, id string) (models.Role, error) {
+	role, searchErr := roles.Search(config.SearchRoleQuery, id)
+	if searchErr != nil {
+		return models.Role{}, errors.New("error searching role. Error: " + searchErr.Error())
+	}
+	if role.ID == "" {
+		return models.Role{}, config.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func roleChanges(before, after models.Role) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"name", before.Name, after.Name},
+		{"description", before.Description, after.Description},
+		{"permissions", strings.Join(before.Permissions, ","), strings.Join(after.Permissions, ",")},
+	}
+	for _, field := range fields {
+		if field.before != field.after {
+			changes[field.name] = models.FieldChange{Before: nullable(field.before), After: nullable(field.after)}
+		}
+	}
+
+	return changes
+}
+
+func normalizePermissions(permissions []string) []string {
+	normalized := slices.Clone(permissions)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+func (us *UserServices) roles(ctx context.Context) repository.RoleRepository {
+	return repository.RoleRepository{DB: us.Repo.DB, Tenant: TenantFrom(ctx)}
+}
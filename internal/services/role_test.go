@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+	actor := models.User{ID: "user-1", Username: "johndoe"}
+
+	userRoles := func(rows *sqlmock.Rows) func() {
+		return func() {
+			mock.ExpectQuery(config.TestListUserRolesQuery).
+				WithArgs("user-1", config.DefaultTenant).
+				WillReturnRows(rows)
+		}
+	}
+
+	test := []struct {
+		Name            string
+		Action          string
+		Resource        string
+		ExpectedAllowed bool
+		ExpectedErr     error
+		MockAct         func()
+	}{
+		{
+			Name:            "Baseline self permission",
+			Action:          config.PermUsersWrite,
+			Resource:        "user-1",
+			ExpectedAllowed: true,
+			MockAct:         func() {},
+		},
+		{
+			Name:            "Granted by a role",
+			Action:          config.PermUsersDelete,
+			Resource:        "user-2",
+			ExpectedAllowed: true,
+			MockAct: userRoles(sqlmock.NewRows(config.TestRoleColumns).
+				AddRow("role-1", "Support", "", "users:read,users:delete", config.TestTime, config.TestTime)),
+		},
+		{
+			Name:            "Self permission on another user",
+			Action:          config.PermUsersWrite,
+			Resource:        "user-2",
+			ExpectedAllowed: false,
+			MockAct: userRoles(sqlmock.NewRows(config.TestRoleColumns).
+				AddRow("role-1", "Support", "", "users:write:self", config.TestTime, config.TestTime)),
+		},
+		{
+			Name:            "No roles",
+			Action:          config.PermAuditRead,
+			ExpectedAllowed: false,
+			MockAct:         userRoles(sqlmock.NewRows(config.TestRoleColumns)),
+		},
+		{
+			Name:        "Unknown action",
+			Action:      "users:impersonate",
+			ExpectedErr: config.ErrInvalidPermission,
+			MockAct:     func() {},
+		},
+		{
+			Name:        "Error",
+			Action:      config.PermAuditRead,
+			ExpectedErr: fmt.Errorf("error listing user roles. Error: database is locked"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListUserRolesQuery).
+					WillReturnError(fmt.Errorf("database is locked"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			allowed, canErr := userService.Can(context.Background(), actor, tt.Action, tt.Resource)
+
+			if tt.ExpectedErr != nil {
+				assert.EqualError(t, canErr, tt.ExpectedErr.Error())
+			} else {
+				assert.NoError(t, canErr)
+			}
+			assert.Equal(t, tt.ExpectedAllowed, allowed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCanAPIKey(t *testing.T) {
+	userService := UserServices{}
+
+	test := []struct {
+		Name            string
+		Scopes          []string
+		Action          string
+		ExpectedAllowed bool
+		ExpectedErr     error
+	}{
+		{Name: "Read scope reads users", Scopes: []string{config.ScopeRead}, Action: config.PermUsersRead, ExpectedAllowed: true},
+		{Name: "Read scope cannot write", Scopes: []string{config.ScopeRead}, Action: config.PermUsersWrite, ExpectedAllowed: false},
+		{Name: "Write scope cannot delete users", Scopes: []string{config.ScopeWrite}, Action: config.PermUsersDelete, ExpectedAllowed: false},
+		{Name: "Write scope cannot manage roles", Scopes: []string{config.ScopeWrite}, Action: config.PermRolesManage, ExpectedAllowed: false},
+		{Name: "Permission scope", Scopes: []string{config.PermUsersDelete}, Action: config.PermUsersDelete, ExpectedAllowed: true},
+		{Name: "Admin scope", Scopes: []string{config.ScopeAdmin}, Action: config.PermAuditRead, ExpectedAllowed: true},
+		{Name: "Unknown action", Scopes: []string{config.ScopeAdmin}, Action: "users:impersonate", ExpectedErr: config.ErrInvalidPermission},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			allowed, canErr := userService.CanAPIKey(context.Background(), models.APIKey{ID: "key-1", Scopes: tt.Scopes}, tt.Action, "user-2")
+
+			assert.Equal(t, tt.ExpectedErr, canErr)
+			assert.Equal(t, tt.ExpectedAllowed, allowed)
+		})
+	}
+}
+
+func TestAssignRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	userRow := func() {
+		mock.ExpectQuery(config.TestSearchByIDQuery).
+			WithArgs("user-1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestUserColumns).
+				AddRow("user-1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+	}
+	roleRow := func() {
+		mock.ExpectQuery(config.TestSearchRoleQuery).
+			WithArgs("role-1", config.DefaultTenant).
+			WillReturnRows(sqlmock.NewRows(config.TestRoleColumns).
+				AddRow("role-1", "Support", "", "users:read", config.TestTime, config.TestTime))
+	}
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name: "Success",
+			MockAct: func() {
+				mock.ExpectBegin()
+				userRow()
+				roleRow()
+				mock.ExpectExec(config.TestAssignRoleQuery).
+					WithArgs("user-1", "role-1", config.TestTime).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionAssignRole, "user-1",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name: "Already assigned",
+			MockAct: func() {
+				mock.ExpectBegin()
+				userRow()
+				roleRow()
+				mock.ExpectExec(config.TestAssignRoleQuery).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Role not found",
+			ExpectedErr: config.ErrRoleNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				userRow()
+				mock.ExpectQuery(config.TestSearchRoleQuery).
+					WithArgs("role-1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestRoleColumns))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "User not found",
+			ExpectedErr: config.ErrUserNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("user-1", config.DefaultTenant).
+					WillReturnRows(sqlmock.NewRows(config.TestUserColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			assignErr := userService.AssignRole(context.Background(), "user-1", "role-1")
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, assignErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, assignErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,13 +2,69 @@ package services
 
 import (
 	"context"
+	"go-manage/internal/importer"
 	"go-manage/internal/models"
+	"time"
 )
 
 type Services interface {
-	Exists(username string) error
-	CreateUser(ctx context.Context, user models.User) (created models.User, err error)
+	Exists(ctx context.Context, username string) bool
+	CreateUser(ctx context.Context, request models.CreateUserRequest) (created models.User, err error)
 	SearchUser(ctx context.Context, username string) (search models.User, err error)
-	DeleteUser(ctx context.Context, username string) (err error)
-	UpdateUser(ctx context.Context, username string, user models.User) (err error)
+	SearchUserByID(ctx context.Context, id string) (search models.User, err error)
+	ListUsers(ctx context.Context, filter models.UserFilter) (users []models.User, err error)
+	DeleteUser(ctx context.Context, username string, version int) (err error)
+	RestoreUser(ctx context.Context, username string) (restored models.User, err error)
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (purged int64, err error)
+	UpdateUser(ctx context.Context, username string, version int, patch models.UpdateUserRequest) (updated models.User, err error)
+	Login(ctx context.Context, request models.LoginRequest) (result models.LoginResult, err error)
+	CompleteMFALogin(ctx context.Context, request models.MFALoginRequest) (session models.Session, err error)
+	Authenticate(ctx context.Context, token string) (user models.User, err error)
+	EnrollTOTP(ctx context.Context, user models.User) (enrollment models.TOTPEnrollment, err error)
+	ConfirmTOTP(ctx context.Context, user models.User, request models.MFACodeRequest) (codes models.RecoveryCodes, err error)
+	DisableTOTP(ctx context.Context, user models.User, request models.MFACodeRequest) (err error)
+	RegenerateRecoveryCodes(ctx context.Context, user models.User, request models.MFACodeRequest) (codes models.RecoveryCodes, err error)
+	UnlockUser(ctx context.Context, username string) (err error)
+	CreateAPIKey(ctx context.Context, request models.CreateAPIKeyRequest) (created models.APIKey, err error)
+	ListAPIKeys(ctx context.Context) (keys []models.APIKey, err error)
+	RevokeAPIKey(ctx context.Context, id string) (err error)
+	AuthenticateAPIKey(ctx context.Context, raw string) (key models.APIKey, err error)
+	CreateOAuthClient(ctx context.Context, request models.CreateOAuthClientRequest) (created models.OAuthClient, err error)
+	ListOAuthClients(ctx context.Context) (clients []models.OAuthClient, err error)
+	DeleteOAuthClient(ctx context.Context, id string) (err error)
+	Authorize(ctx context.Context, user models.User, request models.AuthorizeRequest) (redirect string, err error)
+	ExchangeCode(ctx context.Context, request models.TokenRequest) (tokens models.TokenResponse, err error)
+	UserInfo(ctx context.Context, accessToken string) (info models.UserInfo, err error)
+	JWKS(ctx context.Context) (jwks models.JWKS, err error)
+	RotateSigningKey(ctx context.Context) (err error)
+	Discovery() models.OIDCDiscovery
+	StartFederation(ctx context.Context, name string, user models.User) (redirect string, err error)
+	CompleteFederation(ctx context.Context, name string, callback models.FederationCallback) (result models.FederationResult, err error)
+	ListIdentities(ctx context.Context, user models.User) (identities []models.ExternalIdentity, err error)
+	UnlinkIdentity(ctx context.Context, user models.User, id string) (err error)
+	CreateGroup(ctx context.Context, request models.CreateGroupRequest) (created models.Group, err error)
+	SearchGroup(ctx context.Context, id string) (group models.Group, err error)
+	ListGroups(ctx context.Context) (groups []models.Group, err error)
+	UpdateGroup(ctx context.Context, id string, request models.UpdateGroupRequest) (updated models.Group, err error)
+	DeleteGroup(ctx context.Context, id string) (err error)
+	SetGroupMember(ctx context.Context, groupID, userID string, request models.GroupMemberRequest) (member models.GroupMember, err error)
+	RemoveGroupMember(ctx context.Context, groupID, userID string) (err error)
+	ListGroupMembers(ctx context.Context, groupID string, nested bool) (members []models.GroupMember, err error)
+	ListUserGroups(ctx context.Context, userID string, nested bool) (memberships []models.Membership, err error)
+	CreateRole(ctx context.Context, request models.CreateRoleRequest) (created models.Role, err error)
+	SearchRole(ctx context.Context, id string) (role models.Role, err error)
+	ListRoles(ctx context.Context) (roles []models.Role, err error)
+	UpdateRole(ctx context.Context, id string, request models.UpdateRoleRequest) (updated models.Role, err error)
+	DeleteRole(ctx context.Context, id string) (err error)
+	AssignRole(ctx context.Context, userID, roleID string) (err error)
+	UnassignRole(ctx context.Context, userID, roleID string) (err error)
+	ListUserRoles(ctx context.Context, userID string) (roles []models.Role, err error)
+	Can(ctx context.Context, actor models.User, action, resource string) (allowed bool, err error)
+	CanAPIKey(ctx context.Context, key models.APIKey, action, resource string) (allowed bool, err error)
+	CheckPermission(ctx context.Context, userID, action, resource string) (decision models.PermissionDecision, err error)
+	ImportUsers(ctx context.Context, reader importer.Reader, options models.ImportOptions) (report models.ImportReport, err error)
+	ExportUsers(ctx context.Context, filter models.UserFilter, write func(user models.User) error) (err error)
+	ExecuteBatch(ctx context.Context, request models.BatchRequest) (report models.BatchReport, err error)
+	CreateBackup(ctx context.Context) (backup models.Backup, err error)
+	ListBackups(ctx context.Context) (backups []models.Backup, err error)
 }
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"time"
+)
+
+func (us *UserServices) Login(ctx context.Context, request models.LoginRequest) (result models.LoginResult, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.LoginResult{}, checkErr
+	}
+
+	ip := AuditContextFrom(ctx).IP
+	now := us.Repo.Now()
+
+	if throttleErr := us.checkIPThrottle(ip, now); throttleErr != nil {
+		return models.LoginResult{}, throttleErr
+	}
+
+	repo := us.repo(ctx)
+	user, searchErr := repo.Search(config.SearchUserQuery, request.Username)
+	if searchErr != nil {
+		return models.LoginResult{}, errors.New("error searching user. Error: " + searchErr.Error())
+	}
+	if user.ID == "" {
+		dummy, dummyErr := us.hasher().Dummy()
+		if dummyErr != nil {
+			return models.LoginResult{}, dummyErr
+		}
+		password.Verify(dummy, request.Password)
+
+		if failureErr := us.recordLoginFailure(ctx, ip, user); failureErr != nil {
+			return models.LoginResult{}, failureErr
+		}
+		return models.LoginResult{}, config.ErrInvalidCredentials
+	}
+
+	throttle, throttleErr := us.checkAccountThrottle(user, now)
+	if throttleErr != nil {
+		return models.LoginResult{}, throttleErr
+	}
+
+	matched, verifyErr := password.Verify(user.Password, request.Password)
+	if verifyErr != nil {
+		return models.LoginResult{}, errors.New("error verifying password. Error: " + verifyErr.Error())
+	}
+	if !matched {
+		if failureErr := us.recordLoginFailure(ctx, ip, user); failureErr != nil {
+			return models.LoginResult{}, failureErr
+		}
+		return models.LoginResult{}, config.ErrInvalidCredentials
+	}
+
+	var rehashed string
+	if hasher := us.hasher(); hasher.NeedsRehash(user.Password) {
+		var hashErr error
+		if rehashed, hashErr = hasher.Hash(request.Password); hashErr != nil {
+			return models.LoginResult{}, hashErr
+		}
+	}
+
+	return us.beginSession(ctx, user, throttle, now, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		if rehashed == "" {
+			return nil
+		}
+		if rehashErr := repo.RehashPassword(config.RehashPasswordQuery, user.ID, user.Password, rehashed); rehashErr != nil {
+			return errors.New("error rehashing password. Error: " + rehashErr.Error())
+		}
+		return nil
+	})
+}
+
+func (us *UserServices) CompleteMFALogin(ctx context.Context, request models.MFALoginRequest) (session models.Session, err error) {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return models.Session{}, checkErr
+	}
+
+	ip := AuditContextFrom(ctx).IP
+	now := us.Repo.Now()
+
+	if throttleErr := us.checkIPThrottle(ip, now); throttleErr != nil {
+		return models.Session{}, throttleErr
+	}
+
+	challengeHash := hashToken(request.Challenge)
+	sessions := us.sessions()
+	userID, challengeErr := sessions.SearchChallenge(config.SearchChallengeQuery, challengeHash, now)
+	if challengeErr != nil {
+		return models.Session{}, errors.New("error searching login challenge. Error: " + challengeErr.Error())
+	}
+	if userID == "" {
+		return models.Session{}, config.ErrInvalidChallenge
+	}
+
+	repo := us.repo(ctx)
+	user, searchErr := repo.Search(config.SearchUserByIDQuery, userID)
+	if searchErr != nil {
+		return models.Session{}, errors.New("error searching user. Error: " + searchErr.Error())
+	}
+	if user.ID == "" {
+		return models.Session{}, config.ErrInvalidChallenge
+	}
+
+	throttle, throttleErr := us.checkAccountThrottle(user, now)
+	if throttleErr != nil {
+		return models.Session{}, throttleErr
+	}
+
+	token, tokenErr := sessionToken()
+	if tokenErr != nil {
+		return models.Session{}, errors.New("error generating session token. Error: " + tokenErr.Error())
+	}
+	session = newSession(token, user, now, us.sessionTTL())
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		mfa := repository.MFARepository{DB: repo.DB}
+		if verifyErr := verifySecondFactor(mfa, user.ID, request.Code, now); verifyErr != nil {
+			return verifyErr
+		}
+		sessions := repository.SessionRepository{DB: repo.DB}
+		if deleteErr := sessions.DeleteChallenge(config.DeleteChallengeQuery, challengeHash, now); deleteErr != nil {
+			return errors.New("error deleting login challenge. Error: " + deleteErr.Error())
+		}
+		return openSession(repo, user, throttle, session)
+	})
+	switch {
+	case errors.Is(txErr, config.ErrInvalidMFACode):
+		if failureErr := us.recordLoginFailure(ctx, ip, user); failureErr != nil {
+			return models.Session{}, failureErr
+		}
+		return models.Session{}, config.ErrInvalidCredentials
+	case errors.Is(txErr, config.ErrMFANotEnabled):
+		return models.Session{}, config.ErrInvalidChallenge
+	case txErr != nil:
+		return models.Session{}, txErr
+	}
+
+	return session, nil
+}
+
+func (us *UserServices) Authenticate(ctx context.Context, token string) (user models.User, err error) {
+	repo := us.repo(ctx)
+	user, searchErr := repo.SearchBySession(config.SearchSessionUserQuery, hashToken(token), repo.Now())
+	if searchErr != nil {
+		return models.User{}, errors.New("error searching session. Error: " + searchErr.Error())
+	}
+	if user.ID == "" {
+		return models.User{}, config.ErrUnauthorized
+	}
+	return user, nil
+}
+
+func (us *UserServices) beginSession(ctx context.Context, user models.User, throttle models.LoginThrottle, now time.Time, apply func(repo repository.UserRepository, audit repository.AuditRepository) error) (result models.LoginResult, err error) {
+	mfa := us.mfa()
+	secret, secretErr := mfa.SearchTOTP(config.SearchTOTPQuery, user.ID)
+	if secretErr != nil {
+		return models.LoginResult{}, errors.New("error searching two-factor secret. Error: " + secretErr.Error())
+	}
+
+	token, tokenErr := sessionToken()
+	if tokenErr != nil {
+		return models.LoginResult{}, errors.New("error generating session token. Error: " + tokenErr.Error())
+	}
+
+	if secret.ConfirmedAt != nil {
+		result.Challenge = &models.MFAChallenge{Token: token, ExpiresAt: now.Add(config.MFAChallengeTTL)}
+	} else {
+		result.Session = newSession(token, user, now, us.sessionTTL())
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		if applyErr := apply(repo, audit); applyErr != nil {
+			return applyErr
+		}
+		if result.Challenge != nil {
+			sessions := repository.SessionRepository{DB: repo.DB}
+			if saveErr := sessions.SaveChallenge(config.SaveChallengeQuery, hashToken(token), user.ID, now, result.Challenge.ExpiresAt); saveErr != nil {
+				return errors.New("error saving login challenge. Error: " + saveErr.Error())
+			}
+			return nil
+		}
+		return openSession(repo, user, throttle, result.Session)
+	})
+	if txErr != nil {
+		return models.LoginResult{}, txErr
+	}
+
+	return result, nil
+}
+
+func openSession(repo repository.UserRepository, user models.User, throttle models.LoginThrottle, session models.Session) error {
+	if throttle.Failures > 0 {
+		throttles := repository.ThrottleRepository{DB: repo.DB}
+		if deleteErr := throttles.Delete(config.DeleteThrottleQuery, throttle.Key); deleteErr != nil {
+			return errors.New("error resetting login throttle. Error: " + deleteErr.Error())
+		}
+	}
+	if loginErr := repo.RecordLogin(config.RecordLoginQuery, user.Username); loginErr != nil {
+		return errors.New("error recording login. Error: " + loginErr.Error())
+	}
+	sessions := repository.SessionRepository{DB: repo.DB}
+	if saveErr := sessions.Save(config.SaveSessionQuery, hashToken(session.Token), session); saveErr != nil {
+		return errors.New("error saving session. Error: " + saveErr.Error())
+	}
+	return nil
+}
+
+func newSession(token string, user models.User, now time.Time, ttl time.Duration) models.Session {
+	return models.Session{
+		Token:     token,
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+func (us *UserServices) sessionTTL() time.Duration {
+	if us.SessionTTL > 0 {
+		return us.SessionTTL
+	}
+	return config.DefaultSessionTTL
+}
+
+func sessionToken() (string, error) {
+	return randomToken(config.SessionTokenSize)
+}
+
+func randomToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (us *UserServices) PurgeExpiredSessions(ctx context.Context) (purged int64, err error) {
+	sessions := us.sessions()
+
+	purged, purgeErr := sessions.Purge(config.PurgeSessionsQuery, us.Repo.Now())
+	if purgeErr != nil {
+		return 0, errors.New("error purging sessions. Error: " + purgeErr.Error())
+	}
+	return purged, nil
+}
+
+func (us *UserServices) sessions() repository.SessionRepository {
+	return repository.SessionRepository{DB: us.Repo.DB}
+}
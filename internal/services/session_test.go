@@ -0,0 +1,379 @@
+package services
+
+import (
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLogin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	hasher := password.Hasher{
+		Algorithm: config.HashArgon2id,
+		Argon2:    password.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1},
+	}
+	current, err := hasher.Hash("Password1234")
+	if err != nil {
+		log.Fatal(err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Password1234"), bcrypt.MinCost)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:         db,
+		Repo:       repo,
+		Hasher:     &hasher,
+		SessionTTL: time.Hour,
+	}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{RequestID: "request-1", IP: "10.0.0.1"})
+
+	userRow := func(hash string) *sqlmock.Rows {
+		return mock.NewRows(config.TestUserColumns).
+			AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", hash, 1, config.TestTime, config.TestTime, nil, config.TestTime)
+	}
+	noThrottle := func(key string) {
+		mock.ExpectQuery(config.TestSearchThrottleQuery).
+			WithArgs(key).
+			WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns))
+	}
+	noTOTP := func() {
+		mock.ExpectQuery(config.TestSearchTOTPQuery).
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns))
+	}
+	throttle := func(key string, failures int, lockedUntil any) {
+		mock.ExpectQuery(config.TestSearchThrottleQuery).
+			WithArgs(key).
+			WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns).
+				AddRow(key, failures, config.TestTime.Add(-time.Minute), lockedUntil))
+	}
+
+	test := []struct {
+		Name          string
+		Request       models.LoginRequest
+		ExpectedErr   error
+		ExpectedRetry time.Duration
+		ExpectedMFA   bool
+		MockAct       func()
+	}{
+		{
+			Name:        "Success",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr: nil,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				noThrottle("user:1")
+				noTOTP()
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestLoginQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WithArgs(sqlmock.AnyArg(), "1", config.TestTime, config.TestTime.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Second factor required",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr: nil,
+			ExpectedMFA: true,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				noThrottle("user:1")
+				mock.ExpectQuery(config.TestSearchTOTPQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(config.TestTOTPColumns).
+						AddRow("1", "JBSWY3DPEHPK3PXP", config.TestTime, 0, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestSaveChallengeQuery).
+					WithArgs(sqlmock.AnyArg(), "1", config.TestTime, config.TestTime.Add(config.MFAChallengeTTL)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Rehash legacy bcrypt hash",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr: nil,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(string(legacy)))
+				noThrottle("user:1")
+				noTOTP()
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRehashQuery).
+					WithArgs(sqlmock.AnyArg(), "1", string(legacy), config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestLoginQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Success resets failed attempts",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr: nil,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				throttle("user:1", 2, nil)
+				noTOTP()
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestDeleteThrottleQuery).
+					WithArgs("user:1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestLoginQuery).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Wrong password",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password5678"},
+			ExpectedErr: config.ErrInvalidCredentials,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				noThrottle("user:1")
+				mock.ExpectBegin()
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("ip:10.0.0.1", 1, config.TestTime, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				noThrottle("user:1")
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("user:1", 1, config.TestTime, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Wrong password locks account",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password5678"},
+			ExpectedErr: config.ErrInvalidCredentials,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				throttle("user:1", 9, config.TestTime.Add(-time.Second))
+				mock.ExpectBegin()
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				throttle("user:1", 9, config.TestTime.Add(-time.Second))
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("user:1", 10, config.TestTime.Add(-time.Minute), config.TestTime.Add(config.DefaultLoginLockoutDuration)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.AnonymousActor, config.AuditActionLockout, "johndoe",
+						sqlmock.AnyArg(), "request-1", "10.0.0.1", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:          "Account locked",
+			Request:       models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr:   config.ErrAccountLocked,
+			ExpectedRetry: 10 * time.Minute,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				throttle("user:1", 10, config.TestTime.Add(10*time.Minute))
+			},
+		},
+		{
+			Name:          "Account backoff",
+			Request:       models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr:   config.ErrLoginThrottled,
+			ExpectedRetry: 4 * time.Second,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				throttle("user:1", 5, config.TestTime.Add(4*time.Second))
+			},
+		},
+		{
+			Name:          "IP throttled",
+			Request:       models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr:   config.ErrLoginThrottled,
+			ExpectedRetry: 5 * time.Minute,
+			MockAct: func() {
+				throttle("ip:10.0.0.1", config.DefaultLoginIPLimit, config.TestTime.Add(5*time.Minute))
+			},
+		},
+		{
+			Name:        "User not found",
+			Request:     models.LoginRequest{Username: "nonexistentuser", Password: "Password1234"},
+			ExpectedErr: config.ErrInvalidCredentials,
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+				mock.ExpectBegin()
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectExec(config.TestSaveThrottleQuery).
+					WithArgs("ip:10.0.0.1", 1, config.TestTime, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "Missing password",
+			Request:     models.LoginRequest{Username: "johndoe"},
+			ExpectedErr: config.ErrAllFieldsAreRequired,
+			MockAct: func() {
+			},
+		},
+		{
+			Name:        "Session error",
+			Request:     models.LoginRequest{Username: "johndoe", Password: "Password1234"},
+			ExpectedErr: errors.New("error saving session"),
+			MockAct: func() {
+				noThrottle("ip:10.0.0.1")
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(userRow(current))
+				noThrottle("user:1")
+				noTOTP()
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestLoginQuery).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveSessionQuery).
+					WillReturnError(errors.New("database is locked"))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			result, loginErr := userService.Login(ctx, tt.Request)
+
+			switch {
+			case tt.ExpectedErr != nil:
+				assert.ErrorContains(t, loginErr, tt.ExpectedErr.Error())
+				assert.Equal(t, models.LoginResult{}, result)
+			case tt.ExpectedMFA:
+				assert.NoError(t, loginErr)
+				assert.Equal(t, models.Session{}, result.Session)
+				assert.NotEmpty(t, result.Challenge.Token)
+				assert.Equal(t, config.TestTime.Add(config.MFAChallengeTTL), result.Challenge.ExpiresAt)
+			default:
+				assert.NoError(t, loginErr)
+				assert.Nil(t, result.Challenge)
+				assert.NotEmpty(t, result.Session.Token)
+				assert.Equal(t, "1", result.Session.UserID)
+				assert.Equal(t, config.TestTime.Add(time.Hour), result.Session.ExpiresAt)
+			}
+
+			var retry *RetryError
+			if tt.ExpectedRetry > 0 && assert.ErrorAs(t, loginErr, &retry) {
+				assert.Equal(t, tt.ExpectedRetry, retry.RetryAfter)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeExpiredSessions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	test := []struct {
+		Name           string
+		ExpectedPurged int64
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Success",
+			ExpectedPurged: 2,
+			MockAct: func() {
+				mock.ExpectExec(config.TestPurgeSessionsQuery).
+					WithArgs(config.TestTime).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+		{
+			Name:        "Error",
+			ExpectedErr: errors.New("error purging sessions. Error: database is locked"),
+			MockAct: func() {
+				mock.ExpectExec(config.TestPurgeSessionsQuery).
+					WillReturnError(errors.New("database is locked"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			purged, purgeErr := userService.PurgeExpiredSessions(&gin.Context{})
+
+			if tt.ExpectedErr != nil {
+				assert.EqualError(t, purgeErr, tt.ExpectedErr.Error())
+			} else {
+				assert.NoError(t, purgeErr)
+			}
+			assert.Equal(t, tt.ExpectedPurged, purged)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/repository"
+	"strings"
+)
+
+func TenantFrom(ctx context.Context) string {
+	if tenant, ok := ctx.Value(config.TenantContextKey).(string); ok && tenant != "" {
+		return tenant
+	}
+	return config.DefaultTenant
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, config.TenantContextKey, tenant)
+}
+
+func (us *UserServices) LookupTenant(ctx context.Context, authorization string) (tenant string, err error) {
+	tenants := repository.TenantRepository{DB: us.Repo.DB}
+
+	if token, found := strings.CutPrefix(authorization, "Bearer "); found {
+		tenant, err = tenants.SearchBySession(config.SearchSessionTenantQuery, hashToken(token), us.Repo.Now())
+	} else if prefix, found := apiKeyPrefix(strings.TrimPrefix(authorization, config.APIKeyScheme)); found {
+		tenant, err = tenants.SearchByAPIKey(config.SearchAPIKeyTenantQuery, prefix)
+	}
+	if err != nil {
+		return "", errors.New("error searching tenant. Error: " + err.Error())
+	}
+	return tenant, nil
+}
+
+func (us *UserServices) repo(ctx context.Context) repository.UserRepository {
+	return us.Repo.ForTenant(TenantFrom(ctx))
+}
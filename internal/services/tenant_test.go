@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/data"
+	"go-manage/internal/models"
+	"go-manage/internal/password"
+	"go-manage/internal/repository"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupTenant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{DB: db, Repo: repo}
+
+	test := []struct {
+		Name           string
+		Authorization  string
+		ExpectedTenant string
+		ExpectedErr    error
+		MockAct        func()
+	}{
+		{
+			Name:           "Session token",
+			Authorization:  "Bearer session-token",
+			ExpectedTenant: "acme",
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSessionTenantQuery).
+					WithArgs(hashToken("session-token"), config.TestTime).
+					WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow("acme"))
+			},
+		},
+		{
+			Name:           "API key",
+			Authorization:  "ApiKey gm_abcdefgh_secret",
+			ExpectedTenant: "globex",
+			MockAct: func() {
+				mock.ExpectQuery(config.TestAPIKeyTenantQuery).
+					WithArgs("gm_abcdefgh").
+					WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow("globex"))
+			},
+		},
+		{
+			Name:           "Unknown session",
+			Authorization:  "Bearer expired-token",
+			ExpectedTenant: "",
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSessionTenantQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+			},
+		},
+		{
+			Name:           "Other scheme",
+			Authorization:  "Basic YWRtaW46YWRtaW4=",
+			ExpectedTenant: "",
+			MockAct:        func() {},
+		},
+		{
+			Name:          "Error",
+			Authorization: "Bearer session-token",
+			ExpectedErr:   fmt.Errorf("error searching tenant. Error: database is locked"),
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSessionTenantQuery).
+					WillReturnError(fmt.Errorf("database is locked"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			tenant, lookupErr := userService.LookupTenant(context.Background(), tt.Authorization)
+
+			if tt.ExpectedErr != nil {
+				assert.EqualError(t, lookupErr, tt.ExpectedErr.Error())
+			} else {
+				assert.NoError(t, lookupErr)
+			}
+			assert.Equal(t, tt.ExpectedTenant, tenant)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTenantScopedTables(t *testing.T) {
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := UserServices{DB: conn, Repo: repository.UserRepository{DB: conn, Clock: config.TestClock}, Hasher: &hasher}
+	acme := WithTenant(withActor(context.Background(), config.AdminActor), "acme")
+	globex := WithTenant(withActor(context.Background(), config.AdminActor), "globex")
+
+	john, createErr := userService.CreateUser(acme, models.CreateUserRequest{Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "Sup3r-Secret-pass"})
+	assert.NoError(t, createErr)
+
+	// sessions: the token only resolves to its user inside the user's tenant.
+	login, loginErr := userService.Login(acme, models.LoginRequest{Username: "johndoe", Password: "Sup3r-Secret-pass"})
+	assert.NoError(t, loginErr)
+	_, authErr := userService.Authenticate(globex, login.Session.Token)
+	assert.ErrorIs(t, authErr, config.ErrUnauthorized)
+	_, authErr = userService.Authenticate(acme, login.Session.Token)
+	assert.NoError(t, authErr)
+
+	// login_challenges: the user behind a challenge is searched in the request tenant.
+	_, execErr := conn.Exec(config.SaveChallengeQuery, hashToken("challenge"), john.ID, config.TestTime, config.TestTime.Add(time.Hour))
+	assert.NoError(t, execErr)
+	_, mfaErr := userService.CompleteMFALogin(globex, models.MFALoginRequest{Challenge: "challenge", Code: "123456"})
+	assert.ErrorIs(t, mfaErr, config.ErrInvalidChallenge)
+
+	// password_history: only written after the user is found in the tenant.
+	assert.ErrorIs(t, userService.ChangeUserPwd(globex, "johndoe", "An0ther-Secret-pass"), config.ErrUserNotFound)
+
+	// group_members and user_roles: both sides are searched in the tenant.
+	group, groupErr := userService.CreateGroup(globex, models.CreateGroupRequest{Name: "Engineering"})
+	assert.NoError(t, groupErr)
+	_, memberErr := userService.SetGroupMember(globex, group.ID, john.ID, models.GroupMemberRequest{Role: config.GroupRoleMember})
+	assert.ErrorIs(t, memberErr, config.ErrUserNotFound)
+	role, roleErr := userService.CreateRole(globex, models.CreateRoleRequest{Name: "Support", Permissions: []string{config.PermUsersRead}})
+	assert.NoError(t, roleErr)
+	assert.ErrorIs(t, userService.AssignRole(globex, john.ID, role.ID), config.ErrUserNotFound)
+
+	// oauth_clients
+	client, clientErr := userService.CreateOAuthClient(acme, models.CreateOAuthClientRequest{Name: "wiki", RedirectURIs: []string{"https://wiki.example.com/callback"}})
+	assert.NoError(t, clientErr)
+	clients, listErr := userService.ListOAuthClients(globex)
+	assert.NoError(t, listErr)
+	assert.Empty(t, clients)
+	assert.ErrorIs(t, userService.DeleteOAuthClient(globex, client.ID), config.ErrOAuthClientNotFound)
+
+	// federation_states
+	acmeStates := userService.federation(acme)
+	assert.NoError(t, acmeStates.SaveState(config.SaveFederationStateQuery, models.FederationState{Hash: hashToken("state"), Provider: "corp",
+		Nonce: "nonce", CodeVerifier: "verifier", CreatedAt: config.TestTime, ExpiresAt: config.TestTime.Add(time.Hour)}))
+	_, stateErr := userService.consumeFederationState(globex, "corp", "state")
+	assert.ErrorIs(t, stateErr, config.ErrInvalidLoginState)
+
+	// external_identities: the same IdP account may be linked once per tenant.
+	identity := models.ExternalIdentity{UserID: john.ID, Provider: "corp", Issuer: "https://idp.corp.example.com", Subject: "248289761001", Email: "johndoe@corp.example.com", CreatedAt: config.TestTime}
+	acmeIdentity, globexIdentity := identity, identity
+	acmeIdentity.ID, globexIdentity.ID = "identity-acme", "identity-globex"
+	assert.NoError(t, acmeStates.SaveIdentity(config.SaveIdentityQuery, acmeIdentity))
+	assert.Error(t, acmeStates.SaveIdentity(config.SaveIdentityQuery, models.ExternalIdentity{ID: "identity-dup", UserID: john.ID, Issuer: identity.Issuer, Subject: identity.Subject, CreatedAt: config.TestTime}))
+	globexIdentities := userService.federation(globex)
+	found, searchErr := globexIdentities.SearchIdentity(config.SearchIdentityQuery, identity.Issuer, identity.Subject)
+	assert.NoError(t, searchErr)
+	assert.Equal(t, models.ExternalIdentity{}, found)
+	assert.NoError(t, globexIdentities.SaveIdentity(config.SaveIdentityQuery, globexIdentity))
+	found, searchErr = globexIdentities.SearchIdentity(config.SearchIdentityQuery, identity.Issuer, identity.Subject)
+	assert.NoError(t, searchErr)
+	assert.Equal(t, globexIdentity.ID, found.ID)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"time"
+)
+
+type ThrottlePolicy struct {
+	FreeAttempts     int
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	IPLimit          int
+	Window           time.Duration
+}
+
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func DefaultThrottlePolicy() ThrottlePolicy {
+	return ThrottlePolicy{
+		FreeAttempts:     config.DefaultLoginFreeAttempts,
+		BackoffBase:      config.DefaultLoginBackoffBase,
+		BackoffMax:       config.DefaultLoginBackoffMax,
+		LockoutThreshold: config.DefaultLoginLockoutThreshold,
+		LockoutDuration:  config.DefaultLoginLockoutDuration,
+		IPLimit:          config.DefaultLoginIPLimit,
+		Window:           config.DefaultLoginWindow,
+	}
+}
+
+func ThrottlePolicyFromEnv() ThrottlePolicy {
+	defaults := DefaultThrottlePolicy()
+	return ThrottlePolicy{
+		FreeAttempts:     config.EnvInt(config.LoginFreeAttemptsEnv, defaults.FreeAttempts),
+		BackoffBase:      config.EnvDuration(config.LoginBackoffBaseEnv, defaults.BackoffBase),
+		BackoffMax:       config.EnvDuration(config.LoginBackoffMaxEnv, defaults.BackoffMax),
+		LockoutThreshold: config.EnvInt(config.LoginLockoutThresholdEnv, defaults.LockoutThreshold),
+		LockoutDuration:  config.EnvDuration(config.LoginLockoutDurationEnv, defaults.LockoutDuration),
+		IPLimit:          config.EnvInt(config.LoginIPLimitEnv, defaults.IPLimit),
+		Window:           config.EnvDuration(config.LoginWindowEnv, defaults.Window),
+	}
+}
+
+func (p ThrottlePolicy) blocked(state models.LoginThrottle, now time.Time) time.Duration {
+	if state.LockedUntil != nil && now.Before(*state.LockedUntil) {
+		return state.LockedUntil.Sub(now)
+	}
+	return 0
+}
+
+func (p ThrottlePolicy) expired(state models.LoginThrottle, now time.Time) bool {
+	if state.WindowStartedAt.IsZero() {
+		return true
+	}
+	return now.Sub(state.WindowStartedAt) >= p.Window && p.blocked(state, now) == 0
+}
+
+func (p ThrottlePolicy) locked(state models.LoginThrottle) bool {
+	return p.LockoutThreshold > 0 && state.Failures >= p.LockoutThreshold
+}
+
+func (p ThrottlePolicy) backoff(excess int) time.Duration {
+	delay := p.BackoffBase
+	for i := 1; i < excess && delay < p.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, p.BackoffMax)
+}
+
+func (p ThrottlePolicy) accountFailure(state models.LoginThrottle, now time.Time) (models.LoginThrottle, bool) {
+	if p.expired(state, now) {
+		state = models.LoginThrottle{Key: state.Key, WindowStartedAt: now}
+	}
+	state.Failures++
+	state.LockedUntil = nil
+
+	switch {
+	case p.locked(state):
+		until := now.Add(p.LockoutDuration)
+		state.LockedUntil = &until
+		return state, true
+	case state.Failures > p.FreeAttempts:
+		until := now.Add(p.backoff(state.Failures - p.FreeAttempts))
+		state.LockedUntil = &until
+	}
+	return state, false
+}
+
+func (p ThrottlePolicy) ipFailure(state models.LoginThrottle, now time.Time) models.LoginThrottle {
+	if p.expired(state, now) {
+		state = models.LoginThrottle{Key: state.Key, WindowStartedAt: now}
+	}
+	state.Failures++
+
+	if p.IPLimit > 0 && state.Failures >= p.IPLimit {
+		until := state.WindowStartedAt.Add(p.Window)
+		state.LockedUntil = &until
+	}
+	return state
+}
+
+func (us *UserServices) checkIPThrottle(ip string, now time.Time) error {
+	if ip == "" {
+		return nil
+	}
+
+	throttles := us.throttles()
+	state, searchErr := throttles.Search(config.SearchThrottleQuery, config.ThrottleIPPrefix+ip)
+	if searchErr != nil {
+		return errors.New("error searching login throttle. Error: " + searchErr.Error())
+	}
+	if wait := us.throttlePolicy().blocked(state, now); wait > 0 {
+		return &RetryError{Err: config.ErrLoginThrottled, RetryAfter: wait}
+	}
+	return nil
+}
+
+func (us *UserServices) checkAccountThrottle(user models.User, now time.Time) (models.LoginThrottle, error) {
+	throttles := us.throttles()
+	state, searchErr := throttles.Search(config.SearchThrottleQuery, config.ThrottleUserPrefix+user.ID)
+	if searchErr != nil {
+		return models.LoginThrottle{}, errors.New("error searching login throttle. Error: " + searchErr.Error())
+	}
+
+	policy := us.throttlePolicy()
+	if wait := policy.blocked(state, now); wait > 0 {
+		if policy.locked(state) {
+			return state, &RetryError{Err: config.ErrAccountLocked, RetryAfter: wait}
+		}
+		return state, &RetryError{Err: config.ErrLoginThrottled, RetryAfter: wait}
+	}
+	return state, nil
+}
+
+func (us *UserServices) recordLoginFailure(ctx context.Context, ip string, user models.User) error {
+	if ip == "" && user.ID == "" {
+		return nil
+	}
+	policy := us.throttlePolicy()
+
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		throttles := repository.ThrottleRepository{DB: repo.DB}
+		now := repo.Now()
+
+		if ip != "" {
+			state, searchErr := throttles.Search(config.SearchThrottleQuery, config.ThrottleIPPrefix+ip)
+			if searchErr != nil {
+				return errors.New("error searching login throttle. Error: " + searchErr.Error())
+			}
+			if saveErr := throttles.Save(config.SaveThrottleQuery, policy.ipFailure(state, now)); saveErr != nil {
+				return errors.New("error saving login throttle. Error: " + saveErr.Error())
+			}
+		}
+
+		if user.ID == "" {
+			return nil
+		}
+
+		state, searchErr := throttles.Search(config.SearchThrottleQuery, config.ThrottleUserPrefix+user.ID)
+		if searchErr != nil {
+			return errors.New("error searching login throttle. Error: " + searchErr.Error())
+		}
+		state, locked := policy.accountFailure(state, now)
+		if saveErr := throttles.Save(config.SaveThrottleQuery, state); saveErr != nil {
+			return errors.New("error saving login throttle. Error: " + saveErr.Error())
+		}
+		if !locked {
+			return nil
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionLockout, user.Username, map[string]models.FieldChange{
+			"failures":     {Before: state.Failures - 1, After: state.Failures},
+			"locked_until": {Before: nil, After: *state.LockedUntil},
+		})
+	})
+}
+
+func (us *UserServices) UnlockUser(ctx context.Context, username string) (err error) {
+	user, searchErr := us.SearchUser(ctx, username)
+	if searchErr != nil {
+		return searchErr
+	}
+
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		throttles := repository.ThrottleRepository{DB: repo.DB}
+		key := config.ThrottleUserPrefix + user.ID
+
+		state, searchErr := throttles.Search(config.SearchThrottleQuery, key)
+		if searchErr != nil {
+			return errors.New("error searching login throttle. Error: " + searchErr.Error())
+		}
+		if deleteErr := throttles.Delete(config.DeleteThrottleQuery, key); deleteErr != nil {
+			return errors.New("error unlocking user. Error: " + deleteErr.Error())
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionUnlock, user.Username, map[string]models.FieldChange{
+			"failures": {Before: state.Failures, After: 0},
+		})
+	})
+}
+
+func (us *UserServices) PurgeLoginThrottles(ctx context.Context) (purged int64, err error) {
+	throttles := us.throttles()
+	now := us.Repo.Now()
+
+	purged, purgeErr := throttles.Purge(config.PurgeThrottlesQuery, now, now.Add(-us.throttlePolicy().Window))
+	if purgeErr != nil {
+		return 0, errors.New("error purging login throttles. Error: " + purgeErr.Error())
+	}
+	return purged, nil
+}
+
+func (us *UserServices) throttlePolicy() ThrottlePolicy {
+	if us.Throttle != nil {
+		return *us.Throttle
+	}
+	return DefaultThrottlePolicy()
+}
+
+func (us *UserServices) throttles() repository.ThrottleRepository {
+	return repository.ThrottleRepository{DB: us.Repo.DB}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"go-manage/internal/repository"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountFailure(t *testing.T) {
+	policy := ThrottlePolicy{
+		FreeAttempts:     2,
+		BackoffBase:      time.Second,
+		BackoffMax:       5 * time.Second,
+		LockoutThreshold: 6,
+		LockoutDuration:  time.Hour,
+		Window:           15 * time.Minute,
+	}
+	now := config.TestTime
+
+	test := []struct {
+		Name             string
+		State            models.LoginThrottle
+		ExpectedFailures int
+		ExpectedLock     time.Duration
+		ExpectedLocked   bool
+	}{
+		{
+			Name:             "First failure",
+			State:            models.LoginThrottle{Key: "user:1"},
+			ExpectedFailures: 1,
+		},
+		{
+			Name:             "Last free attempt",
+			State:            models.LoginThrottle{Key: "user:1", Failures: 1, WindowStartedAt: now.Add(-time.Minute)},
+			ExpectedFailures: 2,
+		},
+		{
+			Name:             "First backoff",
+			State:            models.LoginThrottle{Key: "user:1", Failures: 2, WindowStartedAt: now.Add(-time.Minute)},
+			ExpectedFailures: 3,
+			ExpectedLock:     time.Second,
+		},
+		{
+			Name:             "Exponential backoff",
+			State:            models.LoginThrottle{Key: "user:1", Failures: 4, WindowStartedAt: now.Add(-time.Minute)},
+			ExpectedFailures: 5,
+			ExpectedLock:     4 * time.Second,
+		},
+		{
+			Name:             "Lockout",
+			State:            models.LoginThrottle{Key: "user:1", Failures: 5, WindowStartedAt: now.Add(-time.Minute)},
+			ExpectedFailures: 6,
+			ExpectedLock:     time.Hour,
+			ExpectedLocked:   true,
+		},
+		{
+			Name:             "Window expired",
+			State:            models.LoginThrottle{Key: "user:1", Failures: 5, WindowStartedAt: now.Add(-time.Hour)},
+			ExpectedFailures: 1,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			state, locked := policy.accountFailure(tt.State, now)
+
+			assert.Equal(t, tt.ExpectedFailures, state.Failures)
+			assert.Equal(t, tt.ExpectedLocked, locked)
+			assert.Equal(t, tt.ExpectedLock, policy.blocked(state, now))
+		})
+	}
+
+	assert.Equal(t, 5*time.Second, policy.backoff(10))
+}
+
+func TestIPFailure(t *testing.T) {
+	policy := ThrottlePolicy{IPLimit: 3, Window: 15 * time.Minute}
+	now := config.TestTime
+	windowStart := now.Add(-5 * time.Minute)
+
+	state := policy.ipFailure(models.LoginThrottle{Key: "ip:10.0.0.1", Failures: 1, WindowStartedAt: windowStart}, now)
+	assert.Equal(t, 2, state.Failures)
+	assert.Equal(t, time.Duration(0), policy.blocked(state, now))
+
+	state = policy.ipFailure(state, now)
+	assert.Equal(t, 3, state.Failures)
+	assert.Equal(t, 10*time.Minute, policy.blocked(state, now))
+
+	state = policy.ipFailure(state, now.Add(11*time.Minute))
+	assert.Equal(t, 1, state.Failures)
+	assert.Equal(t, now.Add(11*time.Minute), state.WindowStartedAt)
+}
+
+func TestUnlockUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	ctx := &gin.Context{}
+	ctx.Set(config.AuditContextKey, models.AuditContext{Actor: config.AdminActor, RequestID: "request-1"})
+
+	test := []struct {
+		Name        string
+		Username    string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Username:    "johndoe",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectBegin()
+				mock.ExpectQuery(config.TestSearchThrottleQuery).
+					WithArgs("user:1").
+					WillReturnRows(sqlmock.NewRows(config.TestThrottleColumns).
+						AddRow("user:1", 10, config.TestTime, config.TestTime.Add(time.Hour)))
+				mock.ExpectExec(config.TestDeleteThrottleQuery).
+					WithArgs("user:1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.AdminActor, config.AuditActionUnlock, "johndoe",
+						`{"failures":{"before":10,"after":0}}`, "request-1", "", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:        "User not found",
+			Username:    "nonexistentuser",
+			ExpectedErr: config.ErrUserNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			unlockErr := userService.UnlockUser(ctx, tt.Username)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, unlockErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, unlockErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeLoginThrottles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	mock.ExpectExec(config.TestPurgeThrottlesQuery).
+		WithArgs(config.TestTime.Add(-config.DefaultLoginWindow), config.TestTime).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	purged, purgeErr := userService.PurgeLoginThrottles(&gin.Context{})
+
+	assert.NoError(t, purgeErr)
+	assert.Equal(t, int64(4), purged)
+}
@@ -5,27 +5,42 @@ import (
 	"database/sql"
 	"errors"
 	"go-manage/cmd/config"
+	"go-manage/internal/federation"
 	"go-manage/internal/models"
+	"go-manage/internal/password"
 	"go-manage/internal/repository"
+	"go-manage/internal/validation"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/gustyaguero21/go-core/pkg/encrypter"
-	"github.com/gustyaguero21/go-core/pkg/validator"
 )
 
 type UserServices struct {
-	DB   *sql.DB
-	Repo repository.UserRepository
+	DB              *sql.DB
+	Repo            repository.UserRepository
+	Passwords       *password.Checker
+	Hasher          *password.Hasher
+	SessionTTL      time.Duration
+	Throttle        *ThrottlePolicy
+	TOTPIssuer      string
+	OIDCIssuer      string
+	OIDCKeyRotation time.Duration
+	Providers       map[string]*federation.Provider
+	ImportBatchSize int
+	BackupDir       string
+	BackupRetention int
 }
 
-func (us *UserServices) Exists(username string) bool {
-	exists := us.Repo.Exists(config.SearchUserQuery, username)
+func (us *UserServices) Exists(ctx context.Context, username string) bool {
+	repo := us.repo(ctx)
+	exists := repo.Exists(config.SearchUserQuery, username)
 
 	return exists
 }
 
 func (us *UserServices) SearchUser(ctx context.Context, username string) (search models.User, err error) {
-	search, searchErr := us.Repo.Search(config.SearchUserQuery, username)
+	repo := us.repo(ctx)
+	search, searchErr := repo.Search(config.SearchUserQuery, username)
 	if searchErr != nil {
 		return models.User{}, errors.New("error searching user. Error: " + searchErr.Error())
 	}
@@ -36,82 +51,318 @@ func (us *UserServices) SearchUser(ctx context.Context, username string) (search
 
 	return search, nil
 }
-func (us *UserServices) CreateUser(ctx context.Context, user models.User) (created models.User, err error) {
-	if checkErr := paramsValidation(user); checkErr != nil {
-		return models.User{}, checkErr
+
+func (us *UserServices) SearchUserByID(ctx context.Context, id string) (search models.User, err error) {
+	repo := us.repo(ctx)
+	search, searchErr := repo.Search(config.SearchUserByIDQuery, id)
+	if searchErr != nil {
+		return models.User{}, errors.New("error searching user. Error: " + searchErr.Error())
 	}
 
-	if us.Exists(user.Username) {
-		return models.User{}, config.ErrUserAlreadyExists
+	if search.ID == "" {
+		return models.User{}, config.ErrUserNotFound
 	}
 
-	user.ID = uuid.New().String()
+	return search, nil
+}
 
-	hashedPwd, hashErr := encrypter.PasswordEncrypter(user.Password)
-	if hashErr != nil {
-		return models.User{}, hashErr
+func (us *UserServices) ListUsers(ctx context.Context, filter models.UserFilter) (users []models.User, err error) {
+	if filter.Limit <= 0 {
+		filter.Limit = config.DefaultListLimit
 	}
+	if filter.Limit > config.MaxListLimit {
+		filter.Limit = config.MaxListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	repo := us.repo(ctx)
+	users, listErr := repo.List(config.ListUsersQuery, filter)
+	if listErr != nil {
+		if errors.Is(listErr, config.ErrInvalidSortField) || errors.Is(listErr, config.ErrInvalidFilter) {
+			return nil, listErr
+		}
+		return nil, errors.New("error listing users. Error: " + listErr.Error())
+	}
+
+	return users, nil
+}
 
-	user.Password = string(hashedPwd)
+func (us *UserServices) CreateUser(ctx context.Context, request models.CreateUserRequest) (created models.User, err error) {
+	if checkErr := us.checkNewUser(request); checkErr != nil {
+		return models.User{}, checkErr
+	}
+
+	if us.Exists(ctx, request.Username) {
+		return models.User{}, config.ErrUserAlreadyExists
+	}
 
-	if createErr := us.Repo.Save(config.SaveUserQuery, user); createErr != nil {
-		return models.User{}, errors.New("error creating user. Error: " + createErr.Error())
+	user, buildErr := us.newUser(request)
+	if buildErr != nil {
+		return models.User{}, buildErr
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		return saveUser(ctx, repo, audit, user)
+	})
+	if txErr != nil {
+		return models.User{}, txErr
 	}
 
 	return user, nil
 }
 
-func (us *UserServices) DeleteUser(ctx context.Context, username string) (err error) {
-	if !us.Exists(username) {
+func (us *UserServices) DeleteUser(ctx context.Context, username string, version int) (err error) {
+	if !us.Exists(ctx, username) {
 		return config.ErrUserNotFound
 	}
 
-	if deleteErr := us.Repo.Delete(config.DeleteUserQuery, username); deleteErr != nil {
-		return errors.New("error deleting user. Error: " + deleteErr.Error())
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		return deleteUser(ctx, repo, audit, username, version)
+	})
+}
+
+func (us *UserServices) RestoreUser(ctx context.Context, username string) (restored models.User, err error) {
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		if restoreErr := repo.Restore(config.RestoreUserQuery, username); restoreErr != nil {
+			if errors.Is(restoreErr, config.ErrUserNotFound) || errors.Is(restoreErr, config.ErrUserAlreadyExists) {
+				return restoreErr
+			}
+			return errors.New("error restoring user. Error: " + restoreErr.Error())
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionRestore, username, map[string]models.FieldChange{
+			"deleted": {Before: true, After: false},
+		})
+	})
+	if txErr != nil {
+		return models.User{}, txErr
 	}
-	return nil
+
+	return us.SearchUser(ctx, username)
 }
 
-func (us *UserServices) UpdateUser(ctx context.Context, username string, user models.User) (err error) {
-	if !us.Exists(username) {
-		return config.ErrUserNotFound
+func (us *UserServices) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (purged int64, err error) {
+	tenants, tenantsErr := us.Repo.PurgeTenants(config.PurgeTenantsQuery, us.Repo.Now().Add(-retention))
+	if tenantsErr != nil {
+		return 0, errors.New("error searching tenants to purge. Error: " + tenantsErr.Error())
 	}
 
-	if updateErr := us.Repo.Update(config.UpdateUserQuery, username, user); updateErr != nil {
-		return errors.New("error updating user. Error: " + updateErr.Error())
+	for _, tenant := range tenants {
+		count, purgeErr := us.purgeDeletedUsers(WithTenant(ctx, tenant), retention)
+		if purgeErr != nil {
+			return purged, purgeErr
+		}
+		purged += count
 	}
 
-	return nil
+	return purged, nil
+}
+
+func (us *UserServices) purgeDeletedUsers(ctx context.Context, retention time.Duration) (purged int64, err error) {
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		now := repo.Now()
+		var purgeErr error
+		purged, purgeErr = repo.Purge(config.PurgeUsersQuery, now.Add(-retention))
+		if purgeErr != nil {
+			return errors.New("error purging users. Error: " + purgeErr.Error())
+		}
+		if purged == 0 {
+			return nil
+		}
+		if historyErr := repo.PurgePasswordHistory(config.PurgePasswordHistoryQuery); historyErr != nil {
+			return errors.New("error purging password history. Error: " + historyErr.Error())
+		}
+		return recordAudit(ctx, audit, now, config.AuditActionPurge, "users", map[string]models.FieldChange{
+			"purged": {Before: nil, After: purged},
+		})
+	})
+	if txErr != nil {
+		return 0, txErr
+	}
+
+	return purged, nil
+}
+
+func (us *UserServices) UpdateUser(ctx context.Context, username string, version int, patch models.UpdateUserRequest) (updated models.User, err error) {
+	if checkErr := validation.Struct(patch); checkErr != nil {
+		return models.User{}, checkErr
+	}
+
+	current, searchErr := us.SearchUser(ctx, username)
+	if searchErr != nil {
+		return models.User{}, searchErr
+	}
+
+	updated = mergePatch(current, patch)
+
+	if updated.Username != username && us.Exists(ctx, updated.Username) {
+		return models.User{}, config.ErrUserAlreadyExists
+	}
+
+	txErr := us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		var updateErr error
+		updated, updateErr = updateUser(ctx, repo, audit, current, version, updated)
+		return updateErr
+	})
+	if txErr != nil {
+		return models.User{}, txErr
+	}
+
+	return updated, nil
 }
 
 func (us *UserServices) ChangeUserPwd(ctx context.Context, username string, newPassword string) (err error) {
-	if !us.Exists(username) {
+	if checkErr := validation.Struct(models.ChangePwdRequest{Password: newPassword}); checkErr != nil {
+		return checkErr
+	}
+
+	repo := us.repo(ctx)
+	user, searchErr := repo.Search(config.SearchUserQuery, username)
+	if searchErr != nil || user.ID == "" {
 		return config.ErrUserNotFound
 	}
 
-	hashPwd, hashErr := encrypter.PasswordEncrypter(newPassword)
+	checker := us.passwords()
+	if checkErr := checker.Check(newPassword, user.Username, user.Email); checkErr != nil {
+		return checkErr
+	}
+
+	if checker.Policy.History > 0 {
+		history, historyErr := repo.PasswordHistory(config.PasswordHistoryQuery, user.ID, checker.Policy.History)
+		if historyErr != nil {
+			return errors.New("error reading password history. Error: " + historyErr.Error())
+		}
+		if checker.Reused(newPassword, history) {
+			return config.ErrPasswordReused
+		}
+	}
+
+	hashPwd, hashErr := us.hasher().Hash(newPassword)
 	if hashErr != nil {
 		return hashErr
 	}
 
-	if changePwd := us.Repo.ChangePwd(config.ChangeUserPwdQuery, username, string(hashPwd)); changePwd != nil {
-		return errors.New("error changing user password. Error: " + changePwd.Error())
+	return us.withTx(ctx, func(repo repository.UserRepository, audit repository.AuditRepository) error {
+		if changePwd := repo.ChangePwd(config.ChangeUserPwdQuery, username, hashPwd); changePwd != nil {
+			return errors.New("error changing user password. Error: " + changePwd.Error())
+		}
+		if historyErr := repo.SavePasswordHistory(config.SavePasswordHistoryQuery, user.ID, hashPwd); historyErr != nil {
+			return errors.New("error saving password history. Error: " + historyErr.Error())
+		}
+		return recordAudit(ctx, audit, repo.Now(), config.AuditActionChangePassword, username, map[string]models.FieldChange{
+			"password": {Before: config.RedactedValue, After: config.RedactedValue},
+		})
+	})
+}
+
+func (us *UserServices) checkNewUser(request models.CreateUserRequest) error {
+	if checkErr := validation.Struct(request); checkErr != nil {
+		return checkErr
 	}
+	return us.passwords().Check(request.Password, request.Username, request.Email)
+}
 
-	return nil
+func (us *UserServices) newUser(request models.CreateUserRequest) (models.User, error) {
+	hashedPwd, hashErr := us.hasher().Hash(request.Password)
+	if hashErr != nil {
+		return models.User{}, hashErr
+	}
+
+	now := us.Repo.Now()
+	return models.User{
+		ID:                uuid.New().String(),
+		Name:              request.Name,
+		Surname:           request.Surname,
+		Username:          request.Username,
+		Email:             request.Email,
+		Password:          hashedPwd,
+		Version:           1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		PasswordChangedAt: &now,
+	}, nil
 }
 
-func paramsValidation(user models.User) error {
-	if user.Name == "" || user.Surname == "" || user.Username == "" || user.Email == "" || user.Password == "" {
-		return config.ErrAllFieldsAreRequired
+func saveUser(ctx context.Context, repo repository.UserRepository, audit repository.AuditRepository, user models.User) error {
+	if createErr := repo.Save(config.SaveUserQuery, user); createErr != nil {
+		if errors.Is(createErr, config.ErrUserAlreadyExists) {
+			return createErr
+		}
+		return errors.New("error creating user. Error: " + createErr.Error())
 	}
-	if !validator.ValidatePassword(user.Password) {
-		return config.ErrInvalidPassword
+	if historyErr := repo.SavePasswordHistory(config.SavePasswordHistoryQuery, user.ID, user.Password); historyErr != nil {
+		return errors.New("error saving password history. Error: " + historyErr.Error())
 	}
+	return recordAudit(ctx, audit, user.CreatedAt, config.AuditActionCreate, user.Username, userChanges(models.User{}, user))
+}
 
-	if !validator.ValidateEmail(user.Email) {
-		return config.ErrInvalidEmail
+func updateUser(ctx context.Context, repo repository.UserRepository, audit repository.AuditRepository, current models.User, version int, merged models.User) (models.User, error) {
+	updated, updateErr := repo.Update(config.UpdateUserQuery, current.Username, version, merged)
+	if updateErr != nil {
+		if errors.Is(updateErr, config.ErrVersionMismatch) || errors.Is(updateErr, config.ErrUserAlreadyExists) {
+			return models.User{}, updateErr
+		}
+		return models.User{}, errors.New("error updating user. Error: " + updateErr.Error())
 	}
+	return updated, recordAudit(ctx, audit, updated.UpdatedAt, config.AuditActionUpdate, current.Username, userChanges(current, updated))
+}
 
+func deleteUser(ctx context.Context, repo repository.UserRepository, audit repository.AuditRepository, username string, version int) error {
+	if deleteErr := repo.Delete(config.DeleteUserQuery, username, version); deleteErr != nil {
+		if errors.Is(deleteErr, config.ErrVersionMismatch) {
+			return deleteErr
+		}
+		return errors.New("error deleting user. Error: " + deleteErr.Error())
+	}
+	return recordAudit(ctx, audit, repo.Now(), config.AuditActionDelete, username, map[string]models.FieldChange{
+		"deleted": {Before: false, After: true},
+	})
+}
+
+func (us *UserServices) passwords() *password.Checker {
+	if us.Passwords != nil {
+		return us.Passwords
+	}
+	return &password.Checker{Policy: password.DefaultPolicy()}
+}
+
+func (us *UserServices) hasher() password.Hasher {
+	if us.Hasher != nil {
+		return *us.Hasher
+	}
+	return password.DefaultHasher()
+}
+
+func (us *UserServices) withTx(ctx context.Context, fn func(repo repository.UserRepository, audit repository.AuditRepository) error) error {
+	tx, txErr := us.DB.Begin()
+	if txErr != nil {
+		return errors.New("error starting transaction. Error: " + txErr.Error())
+	}
+
+	if fnErr := fn(us.repo(ctx).WithTx(tx), repository.AuditRepository{DB: tx}); fnErr != nil {
+		tx.Rollback()
+		return fnErr
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return errors.New("error committing transaction. Error: " + commitErr.Error())
+	}
 	return nil
 }
+
+func mergePatch(user models.User, patch models.UpdateUserRequest) models.User {
+	if patch.Name != nil {
+		user.Name = *patch.Name
+	}
+	if patch.Surname != nil {
+		user.Surname = *patch.Surname
+	}
+	if patch.Username != nil {
+		user.Username = *patch.Username
+	}
+	if patch.Email != nil {
+		user.Email = *patch.Email
+	}
+	return user
+}
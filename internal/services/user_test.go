@@ -3,13 +3,18 @@ package services
 import (
 	"context"
 	"go-manage/cmd/config"
+	"go-manage/internal/data"
 	"go-manage/internal/models"
+	"go-manage/internal/password"
 	"go-manage/internal/repository"
 	"log"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestExistsUser(t *testing.T) {
@@ -21,7 +26,8 @@ func TestExistsUser(t *testing.T) {
 	defer db.Close()
 
 	repo := repository.UserRepository{
-		DB: db,
+		DB:    db,
+		Clock: config.TestClock,
 	}
 	userService := UserServices{
 		DB:   db,
@@ -40,9 +46,9 @@ func TestExistsUser(t *testing.T) {
 			Expected: true,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 		},
 		{
@@ -51,8 +57,8 @@ func TestExistsUser(t *testing.T) {
 			Expected: false,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("nonexistentuser").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 		},
 	}
@@ -61,7 +67,7 @@ func TestExistsUser(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockAct()
 
-			exists := userService.Exists(tt.Username)
+			exists := userService.Exists(context.Background(), tt.Username)
 
 			assert.Equal(t, tt.Expected, exists)
 		})
@@ -77,7 +83,7 @@ func TestSearchuser(t *testing.T) {
 
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := UserServices{
 		DB:   db,
 		Repo: repo,
@@ -104,9 +110,9 @@ func TestSearchuser(t *testing.T) {
 			ExpectedErr: nil,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 		},
 		{
@@ -123,7 +129,7 @@ func TestSearchuser(t *testing.T) {
 			ExpectedErr: err,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
+					WithArgs("johndoe", config.DefaultTenant).
 					WillReturnError(err)
 			},
 		},
@@ -141,8 +147,8 @@ func TestSearchuser(t *testing.T) {
 			ExpectedErr: config.ErrUserNotFound,
 			MockAct: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("nonexistentuser").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 		},
 	}
@@ -166,6 +172,66 @@ func TestSearchuser(t *testing.T) {
 	}
 }
 
+func TestSearchUserByID(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	test := []struct {
+		Name        string
+		ID          string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			ID:          "1",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("1", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:        "User not found",
+			ID:          "2",
+			ExpectedErr: config.ErrUserNotFound,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestSearchByIDQuery).
+					WithArgs("2", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			search, searchErr := userService.SearchUserByID(ctx, tt.ID)
+
+			if tt.ExpectedErr != nil {
+				assert.Equal(t, tt.ExpectedErr, searchErr)
+			} else {
+				assert.NoError(t, searchErr)
+				assert.Equal(t, tt.ID, search.ID)
+			}
+		})
+	}
+}
+
 func TestCreateUser(t *testing.T) {
 	ctx := context.Background()
 	db, mock, err := sqlmock.New()
@@ -175,7 +241,7 @@ func TestCreateUser(t *testing.T) {
 
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := UserServices{
 		DB:   db,
 		Repo: repo,
@@ -183,15 +249,14 @@ func TestCreateUser(t *testing.T) {
 
 	test := []struct {
 		Name        string
-		User        models.User
+		User        models.CreateUserRequest
 		ExpectedErr error
 		SearchMock  func()
 		MockAct     func()
 	}{
 		{
 			Name: "Success",
-			User: models.User{
-				ID:       "1",
+			User: models.CreateUserRequest{
 				Name:     "John",
 				Surname:  "Doe",
 				Username: "johndoe",
@@ -201,19 +266,24 @@ func TestCreateUser(t *testing.T) {
 			ExpectedErr: nil,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestSaveQuery).
 					WithArgs().
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveHistoryQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
 			Name: "Error",
-			User: models.User{
-				ID:       "1",
+			User: models.CreateUserRequest{
 				Name:     "John",
 				Surname:  "Doe",
 				Username: "johndoe",
@@ -223,7 +293,7 @@ func TestCreateUser(t *testing.T) {
 			ExpectedErr: err,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
+					WithArgs("johndoe", config.DefaultTenant).
 					WillReturnError(err)
 			},
 			MockAct: func() {
@@ -231,8 +301,7 @@ func TestCreateUser(t *testing.T) {
 		},
 		{
 			Name: "Invalid password",
-			User: models.User{
-				ID:       "1",
+			User: models.CreateUserRequest{
 				Name:     "John",
 				Surname:  "Doe",
 				Username: "johndoe",
@@ -246,8 +315,7 @@ func TestCreateUser(t *testing.T) {
 		},
 		{
 			Name: "Invalid email",
-			User: models.User{
-				ID:       "1",
+			User: models.CreateUserRequest{
 				Name:     "John",
 				Surname:  "Doe",
 				Username: "johndoe",
@@ -261,8 +329,7 @@ func TestCreateUser(t *testing.T) {
 		},
 		{
 			Name: "All fields are required",
-			User: models.User{
-				ID:       "1",
+			User: models.CreateUserRequest{
 				Name:     "John",
 				Surname:  "Doe",
 				Username: "johndoe",
@@ -270,9 +337,11 @@ func TestCreateUser(t *testing.T) {
 			ExpectedErr: config.ErrAllFieldsAreRequired,
 			SearchMock:  func() {},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestSaveQuery).
 					WithArgs().
 					WillReturnError(config.ErrAllFieldsAreRequired)
+				mock.ExpectRollback()
 			},
 		},
 	}
@@ -285,7 +354,7 @@ func TestCreateUser(t *testing.T) {
 			createdUser, createErr := userService.CreateUser(ctx, tt.User)
 
 			if tt.ExpectedErr != nil {
-				assert.Equal(t, tt.ExpectedErr, createErr)
+				assert.ErrorIs(t, createErr, tt.ExpectedErr)
 			}
 			if createdUser.Username != "" {
 				assert.Equal(t, tt.User.Username, createdUser.Username)
@@ -303,7 +372,7 @@ func TestDeleteUser(t *testing.T) {
 
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 
 	userService := UserServices{
 		DB:   db,
@@ -323,14 +392,18 @@ func TestDeleteUser(t *testing.T) {
 			ExpectedErr: nil,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestDeleteQuery).
-					WithArgs("johndoe").
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
@@ -339,9 +412,9 @@ func TestDeleteUser(t *testing.T) {
 			ExpectedErr: err,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
 			},
@@ -352,13 +425,15 @@ func TestDeleteUser(t *testing.T) {
 			ExpectedErr: config.ErrUserNotFound,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("nonexistentuser").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestDeleteQuery).
-					WithArgs("johndoe").
+					WithArgs(config.TestTime, config.TestTime, "johndoe", 1, config.DefaultTenant).
 					WillReturnError(config.ErrUserNotFound)
+				mock.ExpectRollback()
 			},
 		},
 	}
@@ -368,7 +443,7 @@ func TestDeleteUser(t *testing.T) {
 			tt.SearchMock()
 			tt.MockAct()
 
-			deleteErr := userService.DeleteUser(ctx, tt.Username)
+			deleteErr := userService.DeleteUser(ctx, tt.Username, 1)
 
 			if tt.ExpectedErr != nil {
 				assert.Equal(t, tt.ExpectedErr, deleteErr)
@@ -386,17 +461,20 @@ func TestUpdateUser(t *testing.T) {
 
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 
 	userService := UserServices{
 		DB:   db,
 		Repo: repo,
 	}
 
+	name, surname, email := "Johncito", "Doecito", "johndoe2024@example.com"
+	newUsername, empty, invalidEmail := "janedoe", "", "invalid-email"
+
 	test := []struct {
 		Name          string
 		Username      string
-		User          models.User
+		Patch         models.UpdateUserRequest
 		ExpectUpdated models.User
 		ExpectedErr   error
 		SearchMock    func()
@@ -405,65 +483,188 @@ func TestUpdateUser(t *testing.T) {
 		{
 			Name:     "Success",
 			Username: "johndoe",
-			User: models.User{
-				ID:       "1",
-				Name:     "Johncito",
-				Surname:  "Doecito",
-				Username: "johndoe",
-				Email:    "johndoe2024@example.com",
-				Password: "NewPassword1234",
+			Patch: models.UpdateUserRequest{
+				Name:    &name,
+				Surname: &surname,
+				Email:   &email,
 			},
 			ExpectUpdated: models.User{
-				ID:       "1",
-				Name:     "Johncito",
-				Surname:  "Doecito",
-				Username: "johndoe",
-				Email:    "johndoe2024@example.com",
-				Password: "NewPassword1234",
+				ID:                "1",
+				Name:              "Johncito",
+				Surname:           "Doecito",
+				Username:          "johndoe",
+				Email:             "johndoe2024@example.com",
+				Password:          "Password1234",
+				Version:           2,
+				CreatedAt:         config.TestTime,
+				UpdatedAt:         config.TestTime,
+				PasswordChangedAt: &config.TestTime,
 			},
 			ExpectedErr: nil,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestUpdateQuery).
-					WithArgs("Johncito", "Doecito", "johndoe2024@example.com", "johndoe").
+					WithArgs("Johncito", "Doecito", "johndoe", "johndoe2024@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
-			Name:          "Error",
-			Username:      "johndoe",
-			User:          models.User{},
-			ExpectUpdated: models.User{},
-			ExpectedErr:   err,
+			Name:     "Partial patch keeps other fields",
+			Username: "johndoe",
+			Patch: models.UpdateUserRequest{
+				Surname: &surname,
+			},
+			ExpectUpdated: models.User{
+				ID:                "1",
+				Name:              "John",
+				Surname:           "Doecito",
+				Username:          "johndoe",
+				Email:             "johndoe@example.com",
+				Password:          "Password1234",
+				Version:           2,
+				CreatedAt:         config.TestTime,
+				UpdatedAt:         config.TestTime,
+				PasswordChangedAt: &config.TestTime,
+			},
+			ExpectedErr: nil,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Doecito", "johndoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:     "Rename username",
+			Username: "johndoe",
+			Patch: models.UpdateUserRequest{
+				Username: &newUsername,
+			},
+			ExpectUpdated: models.User{
+				ID:                "1",
+				Name:              "John",
+				Surname:           "Doe",
+				Username:          "janedoe",
+				Email:             "johndoe@example.com",
+				Password:          "Password1234",
+				Version:           2,
+				CreatedAt:         config.TestTime,
+				UpdatedAt:         config.TestTime,
+				PasswordChangedAt: &config.TestTime,
+			},
+			ExpectedErr: nil,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("janedoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Doe", "janedoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Name:     "Username already exists",
+			Username: "johndoe",
+			Patch: models.UpdateUserRequest{
+				Username: &newUsername,
+			},
+			ExpectUpdated: models.User{},
+			ExpectedErr:   config.ErrUserAlreadyExists,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("janedoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("2", "Jane", "Doe", "janedoe", "janedoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {},
+		},
+		{
+			Name:     "Empty field",
+			Username: "johndoe",
+			Patch: models.UpdateUserRequest{
+				Name: &empty,
+			},
+			ExpectUpdated: models.User{},
+			ExpectedErr:   config.ErrFieldRequired,
+			SearchMock:    func() {},
+			MockAct:       func() {},
+		},
+		{
+			Name:     "Invalid email",
+			Username: "johndoe",
+			Patch: models.UpdateUserRequest{
+				Email: &invalidEmail,
 			},
+			ExpectUpdated: models.User{},
+			ExpectedErr:   config.ErrInvalidEmail,
+			SearchMock:    func() {},
+			MockAct:       func() {},
 		},
 		{
 			Name:          "User not found",
 			Username:      "johndoe",
-			User:          models.User{},
+			Patch:         models.UpdateUserRequest{},
 			ExpectUpdated: models.User{},
 			ExpectedErr:   config.ErrUserNotFound,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+			},
+			MockAct: func() {},
+		},
+		{
+			Name:     "Version mismatch",
+			Username: "johndoe",
+			Patch: models.UpdateUserRequest{
+				Surname: &surname,
+			},
+			ExpectUpdated: models.User{},
+			ExpectedErr:   config.ErrVersionMismatch,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 2, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
-				mock.ExpectExec(config.TestDeleteQuery).
-					WithArgs("johndoe").
-					WillReturnError(config.ErrUserNotFound)
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestUpdateQuery).
+					WithArgs("John", "Doecito", "johndoe", "johndoe@example.com", config.TestTime, "johndoe", 1, config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 			},
 		},
 	}
@@ -473,12 +674,14 @@ func TestUpdateUser(t *testing.T) {
 			tt.SearchMock()
 			tt.MockAct()
 
-			updateErr := userService.UpdateUser(ctx, tt.Username, tt.User)
+			updated, updateErr := userService.UpdateUser(ctx, tt.Username, 1, tt.Patch)
 
 			if tt.ExpectedErr != nil {
-				assert.Equal(t, tt.ExpectedErr, updateErr)
+				assert.ErrorIs(t, updateErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, updateErr)
 			}
-
+			assert.Equal(t, tt.ExpectUpdated, updated)
 		})
 	}
 }
@@ -491,7 +694,12 @@ func TestChangeUserPassword(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.UserRepository{DB: db}
+	reusedHash, err := bcrypt.GenerateFromPassword([]byte("NewPassword1234"), bcrypt.MinCost)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
 	userService := UserServices{
 		DB:   db,
 		Repo: repo,
@@ -512,14 +720,22 @@ func TestChangeUserPassword(t *testing.T) {
 			ExpectedErr: nil,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}))
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestChangePwdQuery).
-					WithArgs(sqlmock.AnyArg(), "johndoe").
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.TestTime, "johndoe", config.DefaultTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveHistoryQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
@@ -529,14 +745,49 @@ func TestChangeUserPassword(t *testing.T) {
 			ExpectedErr: config.ErrChangingPassword,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("johndoe").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}).
-						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234"))
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
 			},
 			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}))
+				mock.ExpectBegin()
 				mock.ExpectExec(config.TestChangePwdQuery).
-					WithArgs(sqlmock.AnyArg(), "johndoe").
+					WithArgs(sqlmock.AnyArg(), config.TestTime, config.TestTime, "johndoe", config.DefaultTenant).
 					WillReturnError(config.ErrChangingPassword)
+				mock.ExpectRollback()
+			},
+		},
+		{
+			Name:        "Password reused",
+			Username:    "johndoe",
+			NewPassword: "NewPassword1234",
+			ExpectedErr: config.ErrPasswordReused,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
+				mock.ExpectQuery(config.TestHistoryQuery).
+					WithArgs("1", config.DefaultPasswordHistory).
+					WillReturnRows(sqlmock.NewRows([]string{"password"}).AddRow(reusedHash))
+			},
+		},
+		{
+			Name:        "Weak password",
+			Username:    "johndoe",
+			NewPassword: "weak",
+			ExpectedErr: config.ErrInvalidPassword,
+			SearchMock: func() {
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+			MockAct: func() {
 			},
 		},
 		{
@@ -546,8 +797,8 @@ func TestChangeUserPassword(t *testing.T) {
 			ExpectedErr: config.ErrUserNotFound,
 			SearchMock: func() {
 				mock.ExpectQuery(config.TestSearchQuery).
-					WithArgs("nonexistentuser").
-					WillReturnRows(mock.NewRows([]string{"id", "name", "surname", "username", "email", "password"}))
+					WithArgs("nonexistentuser", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
 			},
 			MockAct: func() {
 			},
@@ -561,10 +812,221 @@ func TestChangeUserPassword(t *testing.T) {
 
 			changePwdErr := userService.ChangeUserPwd(ctx, tt.Username, tt.NewPassword)
 			if tt.ExpectedErr != nil {
-				assert.Error(t, changePwdErr)
+				assert.ErrorContains(t, changePwdErr, tt.ExpectedErr.Error())
 			} else {
 				assert.NoError(t, changePwdErr)
 			}
 		})
 	}
 }
+
+func TestListUsers(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	test := []struct {
+		Name          string
+		Filter        models.UserFilter
+		ExpectedCount int
+		ExpectedErr   error
+		MockAct       func()
+	}{
+		{
+			Name:          "Default limit",
+			Filter:        models.UserFilter{},
+			ExpectedCount: 1,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WithArgs(config.DefaultTenant, config.DefaultListLimit, 0).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 1, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:          "Limit capped",
+			Filter:        models.UserFilter{Limit: 1000, Offset: -5},
+			ExpectedCount: 0,
+			ExpectedErr:   nil,
+			MockAct: func() {
+				mock.ExpectQuery(config.TestListQuery).
+					WithArgs(config.DefaultTenant, config.MaxListLimit, 0).
+					WillReturnRows(mock.NewRows(config.TestUserColumns))
+			},
+		},
+		{
+			Name:          "Invalid sort field",
+			Filter:        models.UserFilter{Sort: "email"},
+			ExpectedCount: 0,
+			ExpectedErr:   config.ErrInvalidSortField,
+			MockAct:       func() {},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			users, listErr := userService.ListUsers(ctx, tt.Filter)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, listErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, listErr)
+			}
+			assert.Equal(t, tt.ExpectedCount, len(users))
+		})
+	}
+}
+
+func TestRestoreUser(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	test := []struct {
+		Name        string
+		Username    string
+		ExpectedErr error
+		MockAct     func()
+	}{
+		{
+			Name:        "Success",
+			Username:    "johndoe",
+			ExpectedErr: nil,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(config.TestSaveAuditQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery(config.TestSearchQuery).
+					WithArgs("johndoe", config.DefaultTenant).
+					WillReturnRows(mock.NewRows(config.TestUserColumns).
+						AddRow("1", "John", "Doe", "johndoe", "johndoe@example.com", "Password1234", 3, config.TestTime, config.TestTime, nil, config.TestTime))
+			},
+		},
+		{
+			Name:        "User not deleted",
+			Username:    "johndoe",
+			ExpectedErr: config.ErrUserNotFound,
+			MockAct: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(config.TestRestoreQuery).
+					WithArgs(config.TestTime, "johndoe", config.DefaultTenant).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockAct()
+
+			restored, restoreErr := userService.RestoreUser(ctx, tt.Username)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, restoreErr, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, restoreErr)
+				assert.Equal(t, tt.Username, restored.Username)
+			}
+		})
+	}
+}
+
+func TestSoftDeletedUsersReleaseUniqueFields(t *testing.T) {
+	conn, err := data.Open(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	hasher := password.Hasher{Algorithm: config.HashBcrypt, BcryptCost: 4}
+	userService := UserServices{DB: conn, Repo: repository.UserRepository{DB: conn}, Hasher: &hasher}
+	ctx := withActor(context.Background(), config.AdminActor)
+	request := models.CreateUserRequest{Name: "John", Surname: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "Sup3r-Secret-pass"}
+
+	first, createErr := userService.CreateUser(ctx, request)
+	assert.NoError(t, createErr)
+	_, createErr = userService.CreateUser(ctx, request)
+	assert.ErrorIs(t, createErr, config.ErrUserAlreadyExists)
+	assert.NoError(t, userService.DeleteUser(ctx, "johndoe", first.Version))
+
+	second, createErr := userService.CreateUser(ctx, request)
+	assert.NoError(t, createErr)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	_, restoreErr := userService.RestoreUser(ctx, "johndoe")
+	assert.ErrorIs(t, restoreErr, config.ErrUserAlreadyExists)
+
+	assert.NoError(t, userService.DeleteUser(ctx, "johndoe", second.Version))
+	restored, restoreErr := userService.RestoreUser(ctx, "johndoe")
+	assert.NoError(t, restoreErr)
+	assert.Equal(t, second.ID, restored.ID)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.UserRepository{DB: db, Clock: config.TestClock}
+	userService := UserServices{
+		DB:   db,
+		Repo: repo,
+	}
+
+	retention := 24 * time.Hour
+
+	mock.ExpectQuery(config.TestPurgeTenantsQuery).
+		WithArgs(config.TestTime.Add(-retention)).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow("acme").AddRow(config.DefaultTenant))
+	for _, tenant := range []struct {
+		ID     string
+		Purged int64
+	}{{"acme", 2}, {config.DefaultTenant, 1}} {
+		mock.ExpectBegin()
+		mock.ExpectExec(config.TestPurgeQuery).
+			WithArgs(config.TestTime.Add(-retention), tenant.ID).
+			WillReturnResult(sqlmock.NewResult(0, tenant.Purged))
+		mock.ExpectExec(config.TestPurgeHistoryQuery).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(config.TestSaveAuditQuery).
+			WithArgs(sqlmock.AnyArg(), config.TestTime, sqlmock.AnyArg(), config.AuditActionPurge, "users",
+				sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), tenant.ID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	purged, purgeErr := userService.PurgeDeletedUsers(ctx, retention)
+
+	assert.NoError(t, purgeErr)
+	assert.Equal(t, int64(3), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,91 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	test := []struct {
+		Name         string
+		Time         time.Time
+		ExpectedCode string
+	}{
+		{Name: "RFC 6238 59", Time: time.Unix(59, 0), ExpectedCode: "287082"},
+		{Name: "RFC 6238 1111111109", Time: time.Unix(1111111109, 0), ExpectedCode: "081804"},
+		{Name: "RFC 6238 1234567890", Time: time.Unix(1234567890, 0), ExpectedCode: "005924"},
+		{Name: "RFC 6238 2000000000", Time: time.Unix(2000000000, 0), ExpectedCode: "279037"},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(tt.Time))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectedCode, code)
+		})
+	}
+
+	_, err := Code("not base32!", 1)
+	assert.ErrorContains(t, err, "invalid TOTP secret")
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current, _ := Code(rfcSecret, Step(now))
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	stale, _ := Code(rfcSecret, Step(now)-2)
+
+	test := []struct {
+		Name          string
+		Code          string
+		ExpectedValid bool
+		ExpectedStep  int64
+	}{
+		{Name: "Current step", Code: current, ExpectedValid: true, ExpectedStep: Step(now)},
+		{Name: "Previous step within skew", Code: previous, ExpectedValid: true, ExpectedStep: Step(now) - 1},
+		{Name: "Outside skew", Code: stale, ExpectedValid: false},
+		{Name: "Wrong length", Code: "12345", ExpectedValid: false},
+		{Name: "Surrounding spaces", Code: " " + current + " ", ExpectedValid: true, ExpectedStep: Step(now)},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			step, valid := Validate(rfcSecret, tt.Code, now)
+
+			assert.Equal(t, tt.ExpectedValid, valid)
+			assert.Equal(t, tt.ExpectedStep, step)
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+
+	_, codeErr := Code(secret, 1)
+	assert.NoError(t, codeErr)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Go Manage", "john doe", rfcSecret))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Go Manage:john doe", uri.Path)
+	assert.Equal(t, rfcSecret, uri.Query().Get("secret"))
+	assert.Equal(t, "Go Manage", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
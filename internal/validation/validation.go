@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-manage/cmd/config"
+	"go-manage/internal/policy"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	playground "github.com/go-playground/validator/v10"
+	"github.com/gustyaguero21/go-core/pkg/validator"
+)
+
+var (
+	register        sync.Once
+	usernamePattern = regexp.MustCompile(config.UsernamePattern)
+)
+
+var tagErrors = map[string]error{
+	"required":     config.ErrAllFieldsAreRequired,
+	"notblank":     config.ErrFieldRequired,
+	"min":          config.ErrFieldLength,
+	"max":          config.ErrFieldLength,
+	"username":     config.ErrInvalidUsername,
+	"user_email":   config.ErrInvalidEmail,
+	"api_scope":    config.ErrInvalidScope,
+	"redirect_uri": config.ErrInvalidRedirectURI,
+	"group_role":   config.ErrInvalidGroupRole,
+	"permission":   config.ErrInvalidPermission,
+}
+
+func Struct(request any) error {
+	validationErr := engine().Struct(request)
+	if validationErr == nil {
+		return nil
+	}
+
+	var fieldErrs playground.ValidationErrors
+	if !errors.As(validationErr, &fieldErrs) {
+		return fmt.Errorf("%w: %s", config.ErrValidationFailed, validationErr.Error())
+	}
+
+	fieldErr := fieldErrs[0]
+	sentinel, found := tagErrors[fieldErr.Tag()]
+	if !found {
+		sentinel = config.ErrValidationFailed
+	}
+	return fmt.Errorf("%w: %s", sentinel, fieldErr.Field())
+}
+
+func DecodeJSON(body io.Reader, request any) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(request); err != nil {
+		return fmt.Errorf("%w: %s", config.ErrInvalidBody, err.Error())
+	}
+	if decoder.More() {
+		return fmt.Errorf("%w: unexpected data after JSON object", config.ErrInvalidBody)
+	}
+	return nil
+}
+
+func engine() *playground.Validate {
+	validate := binding.Validator.Engine().(*playground.Validate)
+
+	register.Do(func() {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+		validate.RegisterValidation("notblank", func(fl playground.FieldLevel) bool {
+			return strings.TrimSpace(fl.Field().String()) != ""
+		})
+		validate.RegisterValidation("username", func(fl playground.FieldLevel) bool {
+			return usernamePattern.MatchString(fl.Field().String())
+		})
+		validate.RegisterValidation("user_email", func(fl playground.FieldLevel) bool {
+			return validator.ValidateEmail(fl.Field().String())
+		})
+		validate.RegisterValidation("api_scope", func(fl playground.FieldLevel) bool {
+			return slices.Contains(config.APIKeyScopes, fl.Field().String()) || policy.Known(fl.Field().String())
+		})
+		validate.RegisterValidation("redirect_uri", func(fl playground.FieldLevel) bool {
+			return redirectURI(fl.Field().String())
+		})
+		validate.RegisterValidation("group_role", func(fl playground.FieldLevel) bool {
+			return slices.Contains(config.GroupRoles, fl.Field().String())
+		})
+		validate.RegisterValidation("permission", func(fl playground.FieldLevel) bool {
+			return policy.Valid(fl.Field().String())
+		})
+	})
+
+	return validate
+}
+
+func redirectURI(raw string) bool {
+	if strings.ContainsAny(raw, " \t\r\n") {
+		return false
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" || parsed.Fragment != "" || parsed.User != nil {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := parsed.Hostname()
+		ip := net.ParseIP(host)
+		return host == "localhost" || (ip != nil && ip.IsLoopback())
+	default:
+		return false
+	}
+}
@@ -0,0 +1,181 @@
+package validation
+
+import (
+	"go-manage/cmd/config"
+	"go-manage/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStruct(t *testing.T) {
+	valid := models.CreateUserRequest{
+		Name:     "John",
+		Surname:  "Doe",
+		Username: "john.doe_1",
+		Email:    "johndoe@example.com",
+		Password: "Password1234",
+	}
+	blank := "   "
+	short := "jd"
+
+	test := []struct {
+		Name          string
+		Request       any
+		ExpectedErr   error
+		ExpectedField string
+	}{
+		{
+			Name:    "Valid create request",
+			Request: valid,
+		},
+		{
+			Name: "Missing field",
+			Request: func() models.CreateUserRequest {
+				request := valid
+				request.Surname = ""
+				return request
+			}(),
+			ExpectedErr:   config.ErrAllFieldsAreRequired,
+			ExpectedField: "surname",
+		},
+		{
+			Name: "Blank name",
+			Request: func() models.CreateUserRequest {
+				request := valid
+				request.Name = "  "
+				return request
+			}(),
+			ExpectedErr:   config.ErrFieldRequired,
+			ExpectedField: "name",
+		},
+		{
+			Name: "Username charset",
+			Request: func() models.CreateUserRequest {
+				request := valid
+				request.Username = "john doe"
+				return request
+			}(),
+			ExpectedErr:   config.ErrInvalidUsername,
+			ExpectedField: "username",
+		},
+		{
+			Name: "Name too long",
+			Request: func() models.CreateUserRequest {
+				request := valid
+				request.Name = strings.Repeat("a", 101)
+				return request
+			}(),
+			ExpectedErr:   config.ErrFieldLength,
+			ExpectedField: "name",
+		},
+		{
+			Name: "Invalid email",
+			Request: func() models.CreateUserRequest {
+				request := valid
+				request.Email = "johndoe"
+				return request
+			}(),
+			ExpectedErr:   config.ErrInvalidEmail,
+			ExpectedField: "email",
+		},
+		{
+			Name:    "Empty update request",
+			Request: models.UpdateUserRequest{},
+		},
+		{
+			Name:          "Blank update field",
+			Request:       models.UpdateUserRequest{Surname: &blank},
+			ExpectedErr:   config.ErrFieldRequired,
+			ExpectedField: "surname",
+		},
+		{
+			Name:          "Short update username",
+			Request:       models.UpdateUserRequest{Username: &short},
+			ExpectedErr:   config.ErrFieldLength,
+			ExpectedField: "username",
+		},
+		{
+			Name:    "Valid redirect uris",
+			Request: models.CreateOAuthClientRequest{Name: "wiki", RedirectURIs: []string{"https://wiki.example.com/callback", "http://127.0.0.1:8400/cb"}},
+		},
+		{
+			Name:          "Plain http redirect uri",
+			Request:       models.CreateOAuthClientRequest{Name: "wiki", RedirectURIs: []string{"http://wiki.example.com/callback"}},
+			ExpectedErr:   config.ErrInvalidRedirectURI,
+			ExpectedField: "redirect_uris[0]",
+		},
+		{
+			Name:          "Redirect uri with fragment",
+			Request:       models.CreateOAuthClientRequest{Name: "wiki", RedirectURIs: []string{"https://wiki.example.com/callback", "https://wiki.example.com/#cb"}},
+			ExpectedErr:   config.ErrInvalidRedirectURI,
+			ExpectedField: "redirect_uris[1]",
+		},
+		{
+			Name:    "Valid group role",
+			Request: models.GroupMemberRequest{Role: "manager"},
+		},
+		{
+			Name:          "Unknown group role",
+			Request:       models.GroupMemberRequest{Role: "superuser"},
+			ExpectedErr:   config.ErrInvalidGroupRole,
+			ExpectedField: "role",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := Struct(tt.Request)
+
+			if tt.ExpectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.ExpectedErr)
+			assert.ErrorContains(t, err, tt.ExpectedField)
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	test := []struct {
+		Name        string
+		Body        string
+		ExpectedErr error
+	}{
+		{
+			Name: "Known fields",
+			Body: `{"password": "Password1234"}`,
+		},
+		{
+			Name:        "Unknown field",
+			Body:        `{"password": "Password1234", "role": "admin"}`,
+			ExpectedErr: config.ErrInvalidBody,
+		},
+		{
+			Name:        "Trailing data",
+			Body:        `{"password": "Password1234"} {}`,
+			ExpectedErr: config.ErrInvalidBody,
+		},
+		{
+			Name:        "Malformed",
+			Body:        `{"password": }`,
+			ExpectedErr: config.ErrInvalidBody,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var request models.ChangePwdRequest
+
+			err := DecodeJSON(strings.NewReader(tt.Body), &request)
+
+			if tt.ExpectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.ExpectedErr)
+		})
+	}
+}